@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/gin-gonic/gin"
+)
+
+// /adminの管理API
+// 各APIの権限はroutesでmiddlewares.RequirePermissionを使用して確認する
+type IAdminController interface {
+	GetUsers(ctx *gin.Context)
+	SuspendUser(ctx *gin.Context)
+	UnsuspendUser(ctx *gin.Context)
+	DeleteTweet(ctx *gin.Context)
+}
+
+type AdminController struct {
+	service services.IAdminService
+}
+
+func NewAdminController(service services.IAdminService) IAdminController {
+	return &AdminController{service: service}
+}
+
+// ?limit=&cursor=
+// 全てのユーザーをemail、role、停止状態を含めて新しい順に取得
+func (c *AdminController) GetUsers(ctx *gin.Context) {
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, err := c.service.GetUsers(page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get users"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, users)
+}
+
+// idのユーザーのアカウントを停止する
+func (c *AdminController) SuspendUser(ctx *gin.Context) {
+	c.updateSuspension(ctx, c.service.SuspendUser, "failed to suspend user")
+}
+
+// idのユーザーのアカウントの停止を解除する
+func (c *AdminController) UnsuspendUser(ctx *gin.Context) {
+	c.updateSuspension(ctx, c.service.UnsuspendUser, "failed to unsuspend user")
+}
+
+// 自分より弱いroleのユーザーのidのtweetを削除
+func (c *AdminController) DeleteTweet(ctx *gin.Context) {
+	actorId := getUserIdFromCtx(ctx)
+	if actorId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	tweetId := getIdFromReq(ctx, "id")
+	if tweetId == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid tweet id"})
+		return
+	}
+
+	if err := c.service.DeleteTweet(actorId, tweetId); err != nil {
+		switch err.Error() {
+		case "tweet not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "cannot moderate this user":
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete tweet"})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (c *AdminController) updateSuspension(ctx *gin.Context, update func(actorId, userId uint) (*dtos.AdminUser, error), errMsg string) {
+	actorId := getUserIdFromCtx(ctx)
+	if actorId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	userId := getIdFromReq(ctx, "id")
+	if userId == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	user, err := update(actorId, userId)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "cannot moderate this user":
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": user})
+}
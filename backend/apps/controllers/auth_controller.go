@@ -1,20 +1,26 @@
 package controllers
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
 	"github.com/daiki-kim/tweet-app/backend/apps/services"
-	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
 type IAuthController interface {
 	SignupUsingOAuth(ctx *gin.Context)
 	Signup(ctx *gin.Context)
-	LoginUsingOAuth(ctx *gin.Context)
 	Login(ctx *gin.Context)
+	LoginTwoFactor(ctx *gin.Context)
+	RefreshToken(ctx *gin.Context)
+	Logout(ctx *gin.Context)
+	LogoutAll(ctx *gin.Context)
+	ChangePassword(ctx *gin.Context)
+	ForgotPassword(ctx *gin.Context)
+	ResetPassword(ctx *gin.Context)
+	VerifyEmail(ctx *gin.Context)
+	ResendVerificationEmail(ctx *gin.Context)
 }
 
 type AuthController struct {
@@ -25,41 +31,32 @@ func NewAuthController(service services.IAuthService) IAuthController {
 	return &AuthController{service: service}
 }
 
-// OAuthからのサインアップ
+// OAuthのcallbackで返されたticketからのサインアップ
+// 作成したユーザーのtokenを返す
 func (c *AuthController) SignupUsingOAuth(ctx *gin.Context) {
-	// セッションからuserdataを取得
-	session := sessions.Default(ctx)
-	userData := session.Get("user_data")
-	if userData == nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to get user data from session: session in nil"})
-		return
-	}
-
-	// userdataをinputにバインド
 	var input dtos.OAuthSignupInput
-	if err := json.Unmarshal([]byte(userData.(string)), &input); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unmarshal user data from session"})
-		return
-	}
-	// 入力されたdobをctxからinputにバインド
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
-	}
-
-	// OAuthでGoogleからのユーザーデータを使用してサインアップ
-	if err := c.service.SignupUsingOAuth(input.Name, input.Email, input.Dob); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to signup using OAuth"})
 		return
 	}
 
-	// サインアップが成功したらセッションをクリア
-	session.Delete("user_data")
-	if err := session.Save(); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear session after signup"})
+	// ticketのプロバイダーのアカウントを使用してサインアップ
+	loginResponse, err := c.service.SignupUsingOAuth(input.Ticket, input.Name, input.Username, input.Dob)
+	if err != nil {
+		switch err.Error() {
+		case "invalid oauth ticket":
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case "invalid username":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "username is already taken", "email is already taken", "user already exists", "identity already linked":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to signup using OAuth"})
+		}
 		return
 	}
 
-	ctx.Status(http.StatusCreated)
+	ctx.JSON(http.StatusCreated, loginResponse)
 }
 
 // Normalサインアップ
@@ -72,74 +69,248 @@ func (c *AuthController) Signup(ctx *gin.Context) {
 	}
 
 	// ユーザーデータからサインアップ
-	if err := c.service.Signup(input.Name, input.Email, input.Dob, input.Password); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to signup"})
+	if err := c.service.Signup(input.Name, input.Username, input.Email, input.Dob, input.Password); err != nil {
+		switch err.Error() {
+		case "invalid username":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "username is already taken":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to signup"})
+		}
 		return
 	}
 
 	ctx.Status(http.StatusCreated)
 }
 
-// OAuthからのログイン
-func (c *AuthController) LoginUsingOAuth(ctx *gin.Context) {
-	// セッションからuserdataを取得
-	session := sessions.Default(ctx)
-	userData := session.Get("user_data")
-	if userData == nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to get user data from session: session in nil"})
+// Normalログイン
+func (c *AuthController) Login(ctx *gin.Context) {
+	// NormalログインデータをDTOにバインド
+	var input dtos.LoginInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
 		return
 	}
 
-	// userdataをinputにバインド
-	var input dtos.OAuthLoginInput
-	if err := json.Unmarshal([]byte(userData.(string)), &input); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unmarshal user data"})
+	// ユーザーデータからログイン
+	// emailが存在しない場合とパスワードが違う場合は同じ401を返す
+	loginResponse, err := c.service.Login(input.Email, input.Password, ctx.ClientIP())
+	if err != nil {
+		switch err.Error() {
+		case "invalid email or password":
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case "too many login attempts":
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case "account is suspended":
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to login"})
+		}
 		return
 	}
 
-	// userdataのemailを使用してログイン
-	loginResponse, err := c.service.LoginUsingOAuth(input.Email)
+	ctx.JSON(http.StatusOK, loginResponse)
+}
+
+// Login、OAuthのcallbackで返されたmfa_tokenとTOTPのコード(またはリカバリーコード)からトークンを発行
+func (c *AuthController) LoginTwoFactor(ctx *gin.Context) {
+	var input dtos.LoginTwoFactorInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	loginResponse, err := c.service.LoginWithTwoFactor(input.MFAToken, input.Code, ctx.ClientIP())
 	if err != nil {
-		if err.Error() == "user not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
-			return
+		switch err.Error() {
+		case "invalid mfa token", "invalid code":
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case "too many login attempts":
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case "account is suspended":
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to login"})
 		}
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to login using OAuth"})
 		return
 	}
 
-	// セッションをクリア
-	session.Delete("user_data")
-	if err := session.Save(); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear session after login"})
+	ctx.JSON(http.StatusOK, loginResponse)
+}
+
+// リフレッシュトークンからトークンを再発行
+func (c *AuthController) RefreshToken(ctx *gin.Context) {
+	var input dtos.RefreshTokenInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	loginResponse, err := c.service.RefreshToken(input.RefreshToken)
+	if err != nil {
+		switch err.Error() {
+		case "invalid refresh token", "refresh token reuse detected":
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case "account is suspended":
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token"})
+		}
 		return
 	}
 
 	ctx.JSON(http.StatusOK, loginResponse)
 }
 
-// Normalログイン
-func (c *AuthController) Login(ctx *gin.Context) {
-	// NormalログインデータをDTOにバインド
-	var input dtos.LoginInput
+// リフレッシュトークンを失効させてログアウト
+// Authorizationヘッダーのアクセストークンも有効期限を待たずに失効させる(省略可)
+func (c *AuthController) Logout(ctx *gin.Context) {
+	var input dtos.RefreshTokenInput
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
 		return
 	}
 
-	// ユーザーデータからログイン
-	loginResponse, err := c.service.Login(input.Email, input.Password)
+	if err := c.service.Logout(input.RefreshToken, getBearerTokenFromReq(ctx)); err != nil {
+		if err.Error() == "invalid refresh token" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to logout"})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// ログイン中のユーザーの全てのセッションを失効させる
+func (c *AuthController) LogoutAll(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	if err := c.service.LogoutAll(userId); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to logout all sessions"})
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// 現在のパスワードを確認してパスワードを変更
+// 他のセッションは失効するので、新しく発行したトークンを返す
+func (c *AuthController) ChangePassword(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	var input dtos.ChangePasswordInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	loginResponse, err := c.service.ChangePassword(userId, input.CurrentPassword, input.NewPassword)
 	if err != nil {
 		switch err.Error() {
-		case "user not found":
-			ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		case "invalid password":
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid password"})
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case "new password must be different from the current password":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "user not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		default:
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to login"})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change password"})
 		}
 		return
 	}
 
 	ctx.JSON(http.StatusOK, loginResponse)
 }
+
+// emailにパスワード再設定用のリンクを送信
+// 登録されていないemailの場合も同じレスポンスを返す
+func (c *AuthController) ForgotPassword(ctx *gin.Context) {
+	var input dtos.ForgotPasswordInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	if err := c.service.ForgotPassword(input.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send password reset email"})
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+// パスワード再設定用のtokenを使用してパスワードを変更
+func (c *AuthController) ResetPassword(ctx *gin.Context) {
+	var input dtos.ResetPasswordInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	if err := c.service.ResetPassword(input.Token, input.NewPassword); err != nil {
+		if err.Error() == "invalid password reset token" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reset password"})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// ?token=のメール認証用のtokenを使用してemailを認証済みにする(認証メールのリンク先)
+func (c *AuthController) VerifyEmail(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if token == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	if err := c.service.VerifyEmail(token); err != nil {
+		if err.Error() == "invalid email verification token" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify email"})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// ログイン中のユーザーに認証メールを再送信
+func (c *AuthController) ResendVerificationEmail(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	if err := c.service.ResendVerificationEmail(userId); err != nil {
+		switch err.Error() {
+		case "email is already verified":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case "verification email was sent recently":
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case "user not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send verification email"})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/gin-gonic/gin"
+)
+
+type IFeedController interface {
+	GetTimeline(ctx *gin.Context)
+}
+
+type FeedController struct {
+	service services.IFeedService
+}
+
+func NewFeedController(service services.IFeedService) IFeedController {
+	return &FeedController{service: service}
+}
+
+func (c *FeedController) GetTimeline(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tweets, err := c.service.GetTimeline(userId, page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get timeline"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewTweetListResponse(tweets))
+}
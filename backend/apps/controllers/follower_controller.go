@@ -39,11 +39,16 @@ func (c *FollowerController) Follow(ctx *gin.Context) {
 
 	follower, err := c.service.Follow(followerId, followerInput.FolloweeID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to follow"})
+		switch err.Error() {
+		case "cannot follow yourself":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to follow"})
+		}
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, follower)
+	ctx.JSON(http.StatusCreated, dtos.NewFollowerResponse(follower))
 }
 
 func (c *FollowerController) GetFollows(ctx *gin.Context) {
@@ -53,7 +58,13 @@ func (c *FollowerController) GetFollows(ctx *gin.Context) {
 		return
 	}
 
-	followers, err := c.service.GetFollows(followerId)
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	followers, err := c.service.GetFollows(followerId, page)
 	if err != nil {
 		if err.Error() == "followers not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "follows not found"})
@@ -64,7 +75,7 @@ func (c *FollowerController) GetFollows(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, followers)
+	ctx.JSON(http.StatusOK, dtos.NewFollowerListResponse(followers))
 }
 
 func (c *FollowerController) GetFollowers(ctx *gin.Context) {
@@ -74,7 +85,13 @@ func (c *FollowerController) GetFollowers(ctx *gin.Context) {
 		return
 	}
 
-	followers, err := c.service.GetFollowers(followeeId)
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	followers, err := c.service.GetFollowers(followeeId, page)
 	if err != nil {
 		if err.Error() == "followers not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -85,7 +102,7 @@ func (c *FollowerController) GetFollowers(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, followers)
+	ctx.JSON(http.StatusOK, dtos.NewFollowerListResponse(followers))
 }
 
 func (c *FollowerController) DeleteFollower(ctx *gin.Context) {
@@ -134,5 +151,5 @@ func (c *FollowerController) GetFollower(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, follower)
+	ctx.JSON(http.StatusOK, dtos.NewFollowerResponse(follower))
 }
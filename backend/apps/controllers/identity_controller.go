@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/gin-gonic/gin"
+)
+
+type IIdentityController interface {
+	GetIdentities(ctx *gin.Context)
+	LinkIdentity(ctx *gin.Context)
+	UnlinkIdentity(ctx *gin.Context)
+}
+
+type IdentityController struct {
+	service services.IIdentityService
+}
+
+func NewIdentityController(service services.IIdentityService) IIdentityController {
+	return &IdentityController{service: service}
+}
+
+// ログイン中のユーザーが紐づけたプロバイダーのアカウントを取得
+func (c *IdentityController) GetIdentities(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	identities, err := c.service.GetIdentities(userId)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get identities"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": identities})
+}
+
+// /oauth/:provider/login?action=linkのcallbackで返されたticketのプロバイダーのアカウントをログイン中のユーザーに紐づける
+func (c *IdentityController) LinkIdentity(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	var input dtos.LinkIdentityInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	identity, err := c.service.LinkIdentity(userId, input.Ticket)
+	if err != nil {
+		switch err.Error() {
+		case "invalid oauth ticket":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "identity is linked to another user", "provider is already linked":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to link identity"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": identity})
+}
+
+// providerのアカウントの紐づけを解除する
+// パスワードが設定されておらず、最後の1つの場合は解除できない
+func (c *IdentityController) UnlinkIdentity(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	if err := c.service.UnlinkIdentity(userId, ctx.Param("provider")); err != nil {
+		switch err.Error() {
+		case "user identity not found", "user not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user identity not found"})
+		case "cannot unlink the last login method":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlink identity"})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
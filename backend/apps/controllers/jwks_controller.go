@@ -0,0 +1,16 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// アクセストークンの検証用公開鍵をJWKS形式で返す
+// 他のサービスは署名鍵を持たずにkidで公開鍵を選んでトークンを検証できる
+func GetJWKS(ctx *gin.Context) {
+	// 鍵のローテーションが反映されるようにキャッシュ時間は短くする
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, auth.AccessTokenKeySet().JWKS())
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ILikeController interface {
+	Like(ctx *gin.Context)
+	Unlike(ctx *gin.Context)
+	GetLikers(ctx *gin.Context)
+	GetUserLikes(ctx *gin.Context)
+}
+
+type LikeController struct {
+	service services.ILikeService
+}
+
+func NewLikeController(service services.ILikeService) ILikeController {
+	return &LikeController{service: service}
+}
+
+func (c *LikeController) Like(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	tweetId := getIdFromReq(ctx, "id")
+	if tweetId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet id"})
+		return
+	}
+
+	like, err := c.service.Like(userId, tweetId)
+	if err != nil {
+		switch err.Error() {
+		case "tweet not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "already liked":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to like tweet"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dtos.NewLikeResponse(like))
+}
+
+func (c *LikeController) Unlike(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	tweetId := getIdFromReq(ctx, "id")
+	if tweetId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet id"})
+		return
+	}
+
+	if err := c.service.Unlike(userId, tweetId); err != nil {
+		if err.Error() == "like not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlike tweet"})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (c *LikeController) GetLikers(ctx *gin.Context) {
+	tweetId := getIdFromReq(ctx, "id")
+	if tweetId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet id"})
+		return
+	}
+
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	likes, err := c.service.GetLikers(tweetId, page)
+	if err != nil {
+		if err.Error() == "tweet not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get likes"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewLikeListResponse(likes))
+}
+
+func (c *LikeController) GetUserLikes(ctx *gin.Context) {
+	userId := getIdFromReq(ctx, "id")
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	likes, err := c.service.GetUserLikes(userId, page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user likes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewLikeListResponse(likes))
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartのヘッダーなどファイル以外の部分に許容するサイズ
+const multipartOverhead int64 = 1 << 20
+
+type IMediaController interface {
+	UploadMedia(ctx *gin.Context)
+}
+
+type MediaController struct {
+	service services.IMediaService
+}
+
+func NewMediaController(service services.IMediaService) IMediaController {
+	return &MediaController{service: service}
+}
+
+// multipart/form-dataのfileフィールドの画像/動画をアップロード
+// 返されたidをtweet作成時のmedia_idsに指定して添付する
+func (c *MediaController) UploadMedia(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	// 最大サイズの動画を超えるリクエストは読み込まない
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, services.MaxVideoSize+multipartOverhead)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+	defer file.Close()
+
+	media, err := c.service.UploadMedia(userId, file, fileHeader.Size)
+	if err != nil {
+		switch err.Error() {
+		case "unsupported media type":
+			ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		case "media is too large":
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case "invalid image", "image dimensions are too large":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload media"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": media})
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	utils "github.com/daiki-kim/tweet-app/backend/pkg"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/oauth"
+)
+
+const (
+	// 認可リクエストの状態を保存するcookie
+	// /oauth/:provider/callbackでのみ使用するのでpathを/oauthに限定する
+	oauthStateCookieName = "oauth_state"
+	oauthStateCookiePath = "/oauth"
+)
+
+type IOAuthController interface {
+	Login(ctx *gin.Context)
+	Callback(ctx *gin.Context)
+}
+
+type OAuthController struct {
+	registry    *oauth.Registry
+	authService services.IAuthService
+}
+
+func NewOAuthController(registry *oauth.Registry, authService services.IAuthService) IOAuthController {
+	return &OAuthController{
+		registry:    registry,
+		authService: authService,
+	}
+}
+
+// プロバイダーの認可画面へリダイレクト
+// action(login、signup、link)はcallbackの処理に使用する
+// login、signupは紐づけられたユーザーがいればログインし、いなければサインアップ用のticketを返す
+// linkはログイン中のユーザーに紐づけるためのticketを返す(POST /api/v1/me/identitiesで紐づける)
+func (c *OAuthController) Login(ctx *gin.Context) {
+	provider, ok := c.registry.Get(ctx.Param("provider"))
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "provider not found"})
+		return
+	}
+
+	action := ctx.DefaultQuery("action", "login")
+	if action != "signup" && action != "login" && action != "link" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid action"})
+		return
+	}
+
+	state, err := utils.GenerateRandomString(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate state"})
+		return
+	}
+	nonce, err := utils.GenerateRandomString(32)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate nonce"})
+		return
+	}
+	stateClaim := &auth.OAuthStateClaim{
+		Provider: provider.Name(),
+		Action:   action,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: oauth2.GenerateVerifier(),
+	}
+
+	authCodeURL, err := provider.AuthCodeURL(ctx, stateClaim.State, stateClaim.Nonce, stateClaim.Verifier)
+	if err != nil {
+		log.Println("failed to create auth code url: ", err)
+		ctx.JSON(http.StatusBadGateway, gin.H{"error": "failed to connect to provider"})
+		return
+	}
+
+	// 認可リクエストの状態を署名してcookieに保存(サーバーには保存しない)
+	// callbackでstateと比較して、このブラウザで開始した認可リクエストであることを確認する(CSRF対策)
+	stateToken, err := stateClaim.Generate()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sign state"})
+		return
+	}
+	setOAuthStateCookie(ctx, stateToken, int(auth.OAuthStateExpiration.Seconds()))
+
+	ctx.Redirect(http.StatusFound, authCodeURL)
+}
+
+// プロバイダーからのリダイレクト先
+// stateを確認してcodeをユーザー情報に交換し、紐づけられたユーザーのtoken(2段階認証が有効な場合はmfa_token)を返す
+// 紐づけられたユーザーがいない場合とaction=linkの場合はプロバイダーのアカウントのticketを返す
+func (c *OAuthController) Callback(ctx *gin.Context) {
+	provider, ok := c.registry.Get(ctx.Param("provider"))
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "provider not found"})
+		return
+	}
+
+	// 認可リクエストの状態は一度しか使えないようにcookieを削除する
+	stateToken, _ := ctx.Cookie(oauthStateCookieName)
+	setOAuthStateCookie(ctx, "", -1)
+
+	if stateToken == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "oauth state not found"})
+		return
+	}
+	stateClaim, err := auth.ValidateOAuthState(stateToken)
+	if err != nil {
+		log.Println("failed to validate oauth state: ", err)
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "oauth state not found"})
+		return
+	}
+	// stateがcookieと一致するか確認(CSRF対策)
+	if stateClaim.Provider != provider.Name() || subtle.ConstantTimeCompare([]byte(ctx.Query("state")), []byte(stateClaim.State)) != 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "state does not match"})
+		return
+	}
+
+	// ユーザーが認可を拒否した場合など
+	if ctx.Query("error") != "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "authorization failed: " + ctx.Query("error")})
+		return
+	}
+	code := ctx.Query("code")
+	if code == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "code is empty"})
+		return
+	}
+
+	userInfo, err := provider.Exchange(ctx, code, stateClaim.Nonce, stateClaim.Verifier)
+	if err != nil {
+		log.Println("failed to exchange code: ", err)
+		switch {
+		case errors.Is(err, oauth.ErrInvalidIDToken):
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid id token"})
+		case errors.Is(err, oauth.ErrNoEmail):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusBadGateway, gin.H{"error": "failed to get user info from provider"})
+		}
+		return
+	}
+	// プロバイダーで認証されていないemailではサインアップ、ログインできない
+	if !userInfo.EmailVerified {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "email is not verified by provider"})
+		return
+	}
+
+	if stateClaim.Action == "link" {
+		c.respondTicket(ctx, userInfo, auth.OAuthTicketActionLink)
+		return
+	}
+
+	// プロバイダーのアカウントが紐づけられたユーザーでログイン
+	loginResponse, err := c.authService.LoginUsingOAuth(userInfo.Provider, userInfo.Subject, userInfo.Email)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			// ユーザーがいない場合はサインアップ用のticketを返す
+			c.respondTicket(ctx, userInfo, auth.OAuthTicketActionSignup)
+		case "identity is not linked":
+			// 同じemailのユーザーはいるがプロバイダーのアカウントが紐づけられていない(パスワードでログインしてから紐づける)
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case "account is suspended":
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to login using OAuth"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginResponse)
+}
+
+// プロバイダーのアカウントを署名したticketを返す
+// ticketはaction(サインアップまたは紐づけ)にのみ使用できる
+func (c *OAuthController) respondTicket(ctx *gin.Context, userInfo *oauth.UserInfo, action string) {
+	ticket, err := (&auth.OAuthTicketClaim{
+		Action:          action,
+		Provider:        userInfo.Provider,
+		ProviderSubject: userInfo.Subject,
+		Email:           userInfo.Email,
+		Name:            userInfo.Name,
+	}).Generate()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sign oauth ticket"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.OAuthTicketResponse{
+		SignupRequired: action == auth.OAuthTicketActionSignup,
+		Ticket:         ticket,
+		Provider:       userInfo.Provider,
+		Email:          userInfo.Email,
+		Name:           userInfo.Name,
+	})
+}
+
+// 認可リクエストの状態のcookieを設定(maxAge<0の場合は削除)
+// プロバイダーからのリダイレクト(別サイトからのGET)でも送信されるようにSameSite=Laxにする
+// HTTPSの場合はSecureを付ける
+func setOAuthStateCookie(ctx *gin.Context, value string, maxAge int) {
+	secure := ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https"
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oauthStateCookieName, value, maxAge, oauthStateCookiePath, "", secure, true)
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ISearchController interface {
+	SearchTweets(ctx *gin.Context)
+}
+
+type SearchController struct {
+	service services.ISearchService
+}
+
+func NewSearchController(service services.ISearchService) ISearchController {
+	return &SearchController{service: service}
+}
+
+// ?q=検索クエリ&limit=&cursor=
+func (c *SearchController) SearchTweets(ctx *gin.Context) {
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tweets, err := c.service.SearchTweets(ctx.Query("q"), page)
+	if err != nil {
+		switch err.Error() {
+		case "search query is empty", "invalid from filter", "invalid since date", "invalid until date", "invalid has filter", "too many search terms":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search tweets"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewTweetListResponse(tweets))
+}
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ITrendController interface {
+	GetTrends(ctx *gin.Context)
+}
+
+type TrendController struct {
+	service services.ITrendService
+}
+
+func NewTrendController(service services.ITrendService) ITrendController {
+	return &TrendController{service: service}
+}
+
+// ?window=1h|24h|7d(省略時は24h)&limit=(省略時は10)
+func (c *TrendController) GetTrends(ctx *gin.Context) {
+	window := ctx.DefaultQuery("window", services.DefaultTrendWindow)
+
+	limit, err := getTrendLimitFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trends, err := c.service.GetTrends(window, limit)
+	if err != nil {
+		if err.Error() == "invalid window" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get trends"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"window": window, "data": trends})
+}
+
+// requestの?limit=からtrendの件数を取得(最大services.MaxTrendLimit)
+func getTrendLimitFromReq(ctx *gin.Context) (int, error) {
+	limitString := ctx.Query("limit")
+	if limitString == "" {
+		return services.DefaultTrendLimit, nil
+	}
+
+	limit, err := strconv.Atoi(limitString)
+	if err != nil || limit < 1 {
+		return 0, errors.New("invalid limit")
+	}
+
+	return min(limit, services.MaxTrendLimit), nil
+}
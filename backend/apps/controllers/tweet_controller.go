@@ -1,12 +1,16 @@
 package controllers
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
 	"github.com/daiki-kim/tweet-app/backend/apps/services"
 	utils "github.com/daiki-kim/tweet-app/backend/pkg"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,6 +20,13 @@ type ITweetController interface {
 	GetUserTweets(ctx *gin.Context)
 	UpdateTweet(ctx *gin.Context)
 	DeleteTweet(ctx *gin.Context)
+	ReplyTweet(ctx *gin.Context)
+	GetThread(ctx *gin.Context)
+	Retweet(ctx *gin.Context)
+	Unretweet(ctx *gin.Context)
+	QuoteTweet(ctx *gin.Context)
+	GetHashtagTweets(ctx *gin.Context)
+	GetMentions(ctx *gin.Context)
 }
 
 type TweetController struct {
@@ -36,25 +47,41 @@ func (c *TweetController) CreateTweet(ctx *gin.Context) {
 	var input dtos.TweetInput
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
 	}
 
-	tweet, err := c.service.CreateTweet(userId, input.Type, input.Content)
+	tweet, err := c.service.CreateTweet(userId, input.Type, input.Content, input.MediaIDs)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create tweet"})
+		switch err.Error() {
+		case "media not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "media is already attached":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case "media does not match tweet type":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create tweet"})
+		}
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"data": tweet})
+	ctx.JSON(http.StatusCreated, gin.H{"data": dtos.NewTweetResponse(tweet)})
 }
 
 func (c *TweetController) GetTweet(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
 	tweetId := getIdFromReq(ctx, "id")
 	if tweetId == 0 {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet id"})
 		return
 	}
 
-	tweet, err := c.service.GetTweet(tweetId)
+	tweet, err := c.service.GetTweet(tweetId, userId)
 	if err != nil {
 		if err.Error() == "tweet not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "tweet not found"})
@@ -65,7 +92,7 @@ func (c *TweetController) GetTweet(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, tweet)
+	ctx.JSON(http.StatusOK, dtos.NewTweetResponse(tweet))
 }
 
 func (c *TweetController) GetUserTweets(ctx *gin.Context) {
@@ -75,7 +102,13 @@ func (c *TweetController) GetUserTweets(ctx *gin.Context) {
 		return
 	}
 
-	tweets, err := c.service.GetUserTweets(userId)
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tweets, err := c.service.GetUserTweets(userId, page)
 	if err != nil {
 		if err.Error() == "user not found" {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -86,7 +119,7 @@ func (c *TweetController) GetUserTweets(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, tweets)
+	ctx.JSON(http.StatusOK, dtos.NewTweetListResponse(tweets))
 }
 
 func (c *TweetController) UpdateTweet(ctx *gin.Context) {
@@ -113,13 +146,17 @@ func (c *TweetController) UpdateTweet(ctx *gin.Context) {
 		if err.Error() == "this tweet is not yours" {
 			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
+		} else if err.Error() == "retweet cannot be updated" || err.Error() == "type of quote cannot be changed" ||
+			err.Error() == "type of tweet with media cannot be changed" || err.Error() == "media does not match tweet type" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update tweet"})
 			return
 		}
 	}
 
-	ctx.JSON(http.StatusOK, tweet)
+	ctx.JSON(http.StatusOK, dtos.NewTweetResponse(tweet))
 }
 
 func (c *TweetController) DeleteTweet(ctx *gin.Context) {
@@ -144,12 +181,215 @@ func (c *TweetController) DeleteTweet(ctx *gin.Context) {
 			return
 		} else {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete tweet"})
+			return
+		}
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (c *TweetController) ReplyTweet(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	tweetId := getIdFromReq(ctx, "id")
+	if tweetId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet id"})
+		return
+	}
+
+	var input dtos.TweetInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	tweet, err := c.service.ReplyTweet(userId, tweetId, input.Type, input.Content)
+	if err != nil {
+		if err.Error() == "tweet not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else if err.Error() == "media does not match tweet type" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reply tweet"})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": dtos.NewTweetResponse(tweet)})
+}
+
+func (c *TweetController) GetThread(ctx *gin.Context) {
+	tweetId := getIdFromReq(ctx, "id")
+	if tweetId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet id"})
+		return
+	}
+
+	depth, err := getDepthFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	thread, err := c.service.GetThread(tweetId, depth, page)
+	if err != nil {
+		if err.Error() == "tweet not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get thread"})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, thread)
+}
+
+func (c *TweetController) Retweet(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	tweetId := getIdFromReq(ctx, "id")
+	if tweetId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet id"})
+		return
+	}
+
+	tweet, err := c.service.Retweet(userId, tweetId)
+	if err != nil {
+		if err.Error() == "tweet not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else if err.Error() == "already retweeted" {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to retweet"})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": dtos.NewTweetResponse(tweet)})
+}
+
+func (c *TweetController) Unretweet(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	tweetId := getIdFromReq(ctx, "id")
+	if tweetId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet id"})
+		return
+	}
+
+	if err := c.service.Unretweet(userId, tweetId); err != nil {
+		if err.Error() == "retweet not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unretweet"})
+			return
 		}
 	}
 
 	ctx.Status(http.StatusOK)
 }
 
+func (c *TweetController) QuoteTweet(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	tweetId := getIdFromReq(ctx, "id")
+	if tweetId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet id"})
+		return
+	}
+
+	var input dtos.QuoteTweetInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	tweet, err := c.service.QuoteTweet(userId, tweetId, input.Content)
+	if err != nil {
+		if err.Error() == "tweet not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to quote tweet"})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": dtos.NewTweetResponse(tweet)})
+}
+
+func (c *TweetController) GetHashtagTweets(ctx *gin.Context) {
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tweets, err := c.service.GetHashtagTweets(ctx.Param("tag"), page)
+	if err != nil {
+		if err.Error() == "invalid hashtag" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get hashtag tweets"})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewTweetListResponse(tweets))
+}
+
+// ログイン中のユーザーへのmentionを含むtweetを新しい順に取得
+func (c *TweetController) GetMentions(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tweets, err := c.service.GetMentionTweets(userId, page)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get mentions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dtos.NewTweetListResponse(tweets))
+}
+
 // contextからstringのuser_idを取得してuintで返す
 func getUserIdFromCtx(ctx *gin.Context) uint {
 	userIdString, exist := ctx.Get("user_id")
@@ -160,6 +400,15 @@ func getUserIdFromCtx(ctx *gin.Context) uint {
 	return userId
 }
 
+// requestのAuthorizationヘッダーからBearerトークンを取得(ない場合は空文字を返す)
+func getBearerTokenFromReq(ctx *gin.Context) string {
+	token, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
 // requestからstringのidを取得してuintで返す
 func getIdFromReq(ctx *gin.Context, param string) uint {
 	idString := ctx.Param(param)
@@ -168,3 +417,23 @@ func getIdFromReq(ctx *gin.Context, param string) uint {
 	log.Println("[getIdFromReq] id: ", id)
 	return id
 }
+
+// requestの?limit=&cursor=からページングの条件を取得
+func getPageFromReq(ctx *gin.Context) (*pagination.Page, error) {
+	return pagination.NewPage(ctx.Query("limit"), ctx.Query("cursor"))
+}
+
+// requestの?depth=からスレッドの返信ツリーの深さを取得(省略時はservices.DefaultThreadDepth)
+func getDepthFromReq(ctx *gin.Context) (int, error) {
+	depthString := ctx.Query("depth")
+	if depthString == "" {
+		return services.DefaultThreadDepth, nil
+	}
+
+	depth, err := strconv.Atoi(depthString)
+	if err != nil || depth < 1 {
+		return 0, errors.New("invalid depth")
+	}
+
+	return min(depth, services.MaxThreadDepth), nil
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ITwoFactorController interface {
+	EnrollTOTP(ctx *gin.Context)
+	ConfirmTOTP(ctx *gin.Context)
+	DisableTOTP(ctx *gin.Context)
+}
+
+type TwoFactorController struct {
+	service services.ITwoFactorService
+}
+
+func NewTwoFactorController(service services.ITwoFactorService) ITwoFactorController {
+	return &TwoFactorController{service: service}
+}
+
+// ログイン中のユーザーのTOTPのsecretを発行して、認証アプリに登録するotpauth:// URIを返す
+func (c *TwoFactorController) EnrollTOTP(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	enrollment, err := c.service.EnrollTOTP(userId)
+	if err != nil {
+		switch err.Error() {
+		case "two-factor authentication is already enabled":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case "user not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enroll totp"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": enrollment})
+}
+
+// 認証アプリの最初のコードを確認して2段階認証を有効にし、リカバリーコードを返す
+func (c *TwoFactorController) ConfirmTOTP(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	var input dtos.TwoFactorCodeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	recoveryCodes, err := c.service.ConfirmTOTP(userId, input.Code)
+	if err != nil {
+		switch err.Error() {
+		case "invalid code", "two-factor authentication is not enrolled":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "two-factor authentication is already enabled":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to confirm totp"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": dtos.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}})
+}
+
+// TOTPのコードまたはリカバリーコードを確認して2段階認証を無効にする
+func (c *TwoFactorController) DisableTOTP(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	var input dtos.TwoFactorCodeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	if err := c.service.DisableTOTP(userId, input.Code); err != nil {
+		switch err.Error() {
+		case "invalid code", "two-factor authentication is not enabled":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to disable totp"})
+		}
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
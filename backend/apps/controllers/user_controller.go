@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/gin-gonic/gin"
+)
+
+type IUserController interface {
+	GetUser(ctx *gin.Context)
+	GetUserByUsername(ctx *gin.Context)
+	SearchUsers(ctx *gin.Context)
+	GetMe(ctx *gin.Context)
+	UpdateMe(ctx *gin.Context)
+}
+
+type UserController struct {
+	service services.IUserService
+}
+
+func NewUserController(service services.IUserService) IUserController {
+	return &UserController{service: service}
+}
+
+// idのユーザーの公開プロフィールを取得
+func (c *UserController) GetUser(ctx *gin.Context) {
+	userId := getIdFromReq(ctx, "id")
+	if userId == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	profile, err := c.service.GetUserProfile(userId)
+	if err != nil {
+		if err.Error() == "user not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": profile})
+}
+
+// usernameのユーザーの公開プロフィールを取得
+func (c *UserController) GetUserByUsername(ctx *gin.Context) {
+	profile, err := c.service.GetUserProfileByUsername(ctx.Param("username"))
+	if err != nil {
+		if err.Error() == "user not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": profile})
+}
+
+// ?q=検索クエリ&limit=&cursor=
+func (c *UserController) SearchUsers(ctx *gin.Context) {
+	page, err := getPageFromReq(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profiles, err := c.service.SearchUsers(ctx.Query("q"), page)
+	if err != nil {
+		switch err.Error() {
+		case "search query is empty", "search query is too long":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search users"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profiles)
+}
+
+// ログイン中のユーザー本人のプロフィールをemail、生年月日を含めて取得
+func (c *UserController) GetMe(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	user, err := c.service.GetMe(userId)
+	if err != nil {
+		if err.Error() == "user not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": user})
+}
+
+// ログイン中のユーザーのプロフィールを更新
+func (c *UserController) UpdateMe(ctx *gin.Context) {
+	userId := getUserIdFromCtx(ctx)
+	if userId == 0 {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user id"})
+		return
+	}
+
+	var input dtos.UpdateProfileInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid input data"})
+		return
+	}
+
+	user, err := c.service.UpdateMe(userId, &input)
+	if err != nil {
+		switch err.Error() {
+		case "invalid name", "invalid username", "bio is too long", "location is too long", "invalid website", "website is too long",
+			"media is already attached", "profile image must be an image":
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "user not found", "media not found":
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "username is already taken":
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update profile"})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": user})
+}
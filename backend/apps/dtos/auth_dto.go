@@ -1,23 +1,57 @@
 package dtos
 
+// Ticket: OAuthのcallbackで返されたticket(プロバイダーで認証済みのemailを含む)
+// Name: 空の場合はプロバイダーの名前を使用する
 type OAuthSignupInput struct {
-	Name  string `json:"name" binding:"required"`
-	Email string `json:"email" binding:"required"`
-	Dob   string `json:"dob" binding:"required"`
+	Ticket   string `json:"ticket" binding:"required"`
+	Name     string `json:"name"`
+	Username string `json:"username" binding:"required"`
+	Dob      string `json:"dob" binding:"required"`
+}
+
+// OAuthのcallbackでログインしなかった場合(ユーザーが存在しない場合とaction=linkの場合)のレスポンス
+// ticketとusername、生年月日をPOST /api/v1/signup/oauthで送信してサインアップ、
+// またはticketをPOST /api/v1/me/identitiesで送信してログイン中のユーザーに紐づける
+type OAuthTicketResponse struct {
+	SignupRequired bool   `json:"signup_required,omitempty"`
+	Ticket         string `json:"ticket"`
+	Provider       string `json:"provider"`
+	Email          string `json:"email"`
+	Name           string `json:"name"`
 }
 
 type SignupInput struct {
 	Name     string `json:"name" binding:"required"`
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"min=8"`
+	Username string `json:"username" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"min=8,max=72"`
 	Dob      string `json:"dob" binding:"required"`
 }
 
-type OAuthLoginInput struct {
-	Email string `json:"email" binding:"required"`
-}
-
 type LoginInput struct {
 	Email    string `json:"email" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
+
+type RefreshTokenInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type ChangePasswordInput struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"min=8,max=72"`
+}
+
+type ForgotPasswordInput struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPasswordInput struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"min=8,max=72"`
+}
+
+type LoginTwoFactorInput struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
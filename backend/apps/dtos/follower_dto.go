@@ -1,5 +1,38 @@
 package dtos
 
+import (
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+)
+
 type FollowerInput struct {
 	FolloweeID uint `json:"followee_id" binding:"required"`
 }
+
+// follower APIのレスポンス
+// Follower, FolloweeはPublicUserとして返す
+type FollowerResponse struct {
+	ID         uint        `json:"id"`
+	FollowerID uint        `json:"follower_id"`
+	FolloweeID uint        `json:"followee_id"`
+	CreatedAt  time.Time   `json:"created_at"`
+	Follower   *PublicUser `json:"follower"`
+	Followee   *PublicUser `json:"followee"`
+}
+
+func NewFollowerResponse(follower *models.Follower) *FollowerResponse {
+	return &FollowerResponse{
+		ID:         follower.ID,
+		FollowerID: follower.FollowerID,
+		FolloweeID: follower.FolloweeID,
+		CreatedAt:  follower.CreatedAt,
+		Follower:   NewPublicUser(follower.Follower),
+		Followee:   NewPublicUser(follower.Followee),
+	}
+}
+
+func NewFollowerListResponse(followers *pagination.List[*models.Follower]) *pagination.List[*FollowerResponse] {
+	return pagination.Map(followers, NewFollowerResponse)
+}
@@ -0,0 +1,6 @@
+package dtos
+
+// OAuthのcallback(action=link)で返されたticket
+type LinkIdentityInput struct {
+	Ticket string `json:"ticket" binding:"required"`
+}
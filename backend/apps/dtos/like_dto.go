@@ -0,0 +1,34 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+)
+
+// like APIのレスポンス
+// likeしたユーザーはPublicUser、likeされたTweetはTweetResponseとして返す
+type LikeResponse struct {
+	ID        uint           `json:"id"`
+	UserID    uint           `json:"user_id"`
+	TweetID   uint           `json:"tweet_id"`
+	CreatedAt time.Time      `json:"created_at"`
+	User      *PublicUser    `json:"user"`
+	Tweet     *TweetResponse `json:"tweet"`
+}
+
+func NewLikeResponse(like *models.Like) *LikeResponse {
+	return &LikeResponse{
+		ID:        like.ID,
+		UserID:    like.UserID,
+		TweetID:   like.TweetID,
+		CreatedAt: like.CreatedAt,
+		User:      NewPublicUser(like.User),
+		Tweet:     NewTweetResponse(like.Tweet),
+	}
+}
+
+func NewLikeListResponse(likes *pagination.List[*models.Like]) *pagination.List[*LikeResponse] {
+	return pagination.Map(likes, NewLikeResponse)
+}
@@ -0,0 +1,12 @@
+package dtos
+
+// GET /trends のレスポンスの1件
+// Count: 期間内の出現数、PreviousCount: 直前の同じ長さの期間の出現数
+// Score: 出現数の伸び(velocity)、この値の大きい順に並べる
+type Trend struct {
+	Term          string  `json:"term"`
+	Hashtag       bool    `json:"hashtag"`
+	Count         int64   `json:"count"`
+	PreviousCount int64   `json:"previous_count"`
+	Score         float64 `json:"score"`
+}
@@ -1,11 +1,103 @@
 package dtos
 
+import (
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+)
+
 type TweetInput struct {
-	Type    string `json:"type" binding:"required,oneof=text image video"`
-	Content string `json:"content" binding:"required,min=1,max=140"`
+	Type     string `json:"type" binding:"required,oneof=text image video"`
+	Content  string `json:"content" binding:"required,min=1,max=140"`
+	MediaIDs []uint `json:"media_ids" binding:"omitempty,max=4"` // POST /media でアップロードした画像/動画のid
 }
 
 type UpdateTweetInput struct {
 	Type    string `json:"type" binding:"omitempty,oneof=text image video"`
 	Content string `json:"content" binding:"omitempty,min=1,max=140"`
 }
+
+type QuoteTweetInput struct {
+	Content string `json:"content" binding:"required,min=1,max=140"`
+}
+
+// tweet APIのレスポンス
+// 投稿者(retweet/quoteの元のTweetの投稿者を含む)はPublicUserとして返す
+type TweetResponse struct {
+	ID               uint                   `json:"id"`
+	UserID           uint                   `json:"user_id"`
+	Type             models.TweetType       `json:"type"`
+	Content          string                 `json:"content"`
+	CreatedAt        time.Time              `json:"created_at"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+	InReplyToTweetID *uint                  `json:"in_reply_to_tweet_id"`
+	ConversationID   *uint                  `json:"conversation_id"`
+	DeletedAt        *time.Time             `json:"deleted_at"` // 削除されていない場合はnull
+	RetweetedTweetID *uint                  `json:"retweeted_tweet_id"`
+	QuotedTweetID    *uint                  `json:"quoted_tweet_id"`
+	LikeCount        *int64                 `json:"like_count,omitempty"`
+	LikedByMe        *bool                  `json:"liked_by_me,omitempty"`
+	RetweetCount     *int64                 `json:"retweet_count,omitempty"`
+	QuoteCount       *int64                 `json:"quote_count,omitempty"`
+	User             *PublicUser            `json:"user"`
+	RetweetedTweet   *TweetResponse         `json:"retweeted_tweet,omitempty"`
+	QuotedTweet      *TweetResponse         `json:"quoted_tweet,omitempty"`
+	Media            []*models.Media        `json:"media,omitempty"`
+	Hashtags         []*models.Hashtag      `json:"hashtags,omitempty"`
+	Mentions         []*models.TweetMention `json:"mentions,omitempty"`
+}
+
+// tweetがnilの場合はnilを返す(Preloadしていないrelationはnullのままにする)
+func NewTweetResponse(tweet *models.Tweet) *TweetResponse {
+	if tweet == nil {
+		return nil
+	}
+
+	tweetResponse := &TweetResponse{
+		ID:               tweet.ID,
+		UserID:           tweet.UserID,
+		Type:             tweet.Type,
+		Content:          tweet.Content,
+		CreatedAt:        tweet.CreatedAt,
+		UpdatedAt:        tweet.UpdatedAt,
+		InReplyToTweetID: tweet.InReplyToTweetID,
+		ConversationID:   tweet.ConversationID,
+		RetweetedTweetID: tweet.RetweetedTweetID,
+		QuotedTweetID:    tweet.QuotedTweetID,
+		LikeCount:        tweet.LikeCount,
+		LikedByMe:        tweet.LikedByMe,
+		RetweetCount:     tweet.RetweetCount,
+		QuoteCount:       tweet.QuoteCount,
+		User:             NewPublicUser(tweet.User),
+		RetweetedTweet:   NewTweetResponse(tweet.RetweetedTweet),
+		QuotedTweet:      NewTweetResponse(tweet.QuotedTweet),
+		Media:            tweet.Media,
+		Hashtags:         tweet.Hashtags,
+		Mentions:         tweet.Mentions,
+	}
+	if tweet.DeletedAt.Valid {
+		tweetResponse.DeletedAt = &tweet.DeletedAt.Time
+	}
+
+	return tweetResponse
+}
+
+func NewTweetListResponse(tweets *pagination.List[*models.Tweet]) *pagination.List[*TweetResponse] {
+	return pagination.Map(tweets, NewTweetResponse)
+}
+
+// GET /tweet/:id/thread のレスポンス
+// Tweet: idのtweetのconversationの最初のtweet(root)
+// Path: rootの次からidのtweetまでの返信(idがrootの場合は空)、返信ツリーの深さに関係なくidのtweetを表示するため
+// Replies: rootへの直接の返信(ページング)とその返信ツリー
+type TweetThread struct {
+	Tweet   *TweetResponse                     `json:"tweet"`
+	Path    []*TweetResponse                   `json:"path"`
+	Replies *pagination.List[*TweetThreadNode] `json:"replies"`
+}
+
+type TweetThreadNode struct {
+	Tweet   *TweetResponse     `json:"tweet"`
+	Replies []*TweetThreadNode `json:"replies"`
+}
@@ -0,0 +1,11 @@
+package dtos
+
+// TOTPのコード(数字6桁)またはリカバリーコード
+type TwoFactorCodeInput struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// 2段階認証の有効化時に1度だけ返すリカバリーコード
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
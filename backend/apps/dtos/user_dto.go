@@ -0,0 +1,115 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+)
+
+// PATCH /me の入力
+// 指定した項目のみ更新する、空文字列を指定するとbio、場所、websiteを削除できる
+// Username: サインアップ時と同じ形式のみ、他のユーザーが使用しているusernameには変更できない
+// AvatarMediaID, HeaderMediaID: POST /media でアップロードした画像のid、0を指定すると削除する
+type UpdateProfileInput struct {
+	Name          *string `json:"name"`
+	Username      *string `json:"username"`
+	Bio           *string `json:"bio"`
+	Location      *string `json:"location"`
+	Website       *string `json:"website"`
+	AvatarMediaID *uint   `json:"avatar_media_id"`
+	HeaderMediaID *uint   `json:"header_media_id"`
+}
+
+// ユーザーをレスポンスに含める場合は以下のviewのいずれかを使用する
+// PublicUser: 他のユーザーに公開する項目のみ(follower一覧、like一覧、tweetの投稿者など)
+// SelfUser: 本人にのみ返す項目を含む(email、emailの認証状態、生年月日)
+// AdminUser: 管理者向けの項目を含む(role、アカウントの停止状態)
+// どのviewにもパスワードのハッシュは含めない
+type PublicUser struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Username  string    `json:"username"`
+	Bio       string    `json:"bio"`
+	Location  string    `json:"location"`
+	Website   string    `json:"website"`
+	AvatarURL string    `json:"avatar_url"` // Avatar, HeaderをPreloadしていない場合は空
+	HeaderURL string    `json:"header_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SelfUser struct {
+	PublicUser
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Dob           time.Time `json:"dob"`
+}
+
+type AdminUser struct {
+	SelfUser
+	Role        string     `json:"role"`
+	SuspendedAt *time.Time `json:"suspended_at"` // 停止されていない場合はnull
+}
+
+// userがnilの場合はnilを返す(Preloadしていないrelationはnullのままにする)
+func NewPublicUser(user *models.User) *PublicUser {
+	if user == nil {
+		return nil
+	}
+
+	publicUser := &PublicUser{
+		ID:        user.ID,
+		Name:      user.Name,
+		Username:  user.Username,
+		Bio:       user.Bio,
+		Location:  user.Location,
+		Website:   user.Website,
+		CreatedAt: user.CreatedAt,
+	}
+	if user.Avatar != nil {
+		publicUser.AvatarURL = user.Avatar.URL
+	}
+	if user.Header != nil {
+		publicUser.HeaderURL = user.Header.URL
+	}
+
+	return publicUser
+}
+
+func NewSelfUser(user *models.User) *SelfUser {
+	if user == nil {
+		return nil
+	}
+
+	return &SelfUser{
+		PublicUser:    *NewPublicUser(user),
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified(),
+		Dob:           user.Dob,
+	}
+}
+
+func NewAdminUser(user *models.User) *AdminUser {
+	if user == nil {
+		return nil
+	}
+
+	return &AdminUser{
+		SelfUser:    *NewSelfUser(user),
+		Role:        user.Role,
+		SuspendedAt: user.SuspendedAt,
+	}
+}
+
+// 他のユーザーに公開するプロフィール
+// PublicUserにfollower数、follow数、tweet数を加えたもの
+type UserProfile struct {
+	PublicUser
+	FollowerCount  int64 `json:"follower_count"`
+	FollowingCount int64 `json:"following_count"`
+	TweetCount     int64 `json:"tweet_count"`
+}
+
+// userから公開する項目のみを取り出してプロフィールを作成(件数は呼び出し側で設定する)
+func NewUserProfile(user *models.User) *UserProfile {
+	return &UserProfile{PublicUser: *NewPublicUser(user)}
+}
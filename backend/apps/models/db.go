@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/daiki-kim/tweet-app/backend/configs"
 	"gorm.io/driver/mysql"
@@ -26,6 +27,24 @@ func GetModels() []interface{} {
 		&User{},
 		&Tweet{},
 		&Follower{},
+		&Feed{},
+		&FeedTweet{},
+		&Like{},
+		&Media{},
+		&Hashtag{},
+		&TweetHashtag{},
+		&TweetMention{},
+		&TrendBucket{},
+		&RefreshToken{},
+		&RevokedToken{},
+		&UserTokenRevocation{},
+		&PasswordResetToken{},
+		&EmailVerificationToken{},
+		&UserTOTP{},
+		&RecoveryCode{},
+		&LoginAttempt{},
+		&LoginLockout{},
+		&UserIdentity{},
 	}
 }
 
@@ -50,10 +69,10 @@ func NewDatabaseFactory(instance int) (db *gorm.DB, err error) {
 		// 	configs.Config.DBName,
 		// )
 		log.Println(dsn)
-		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 
 	case InstanceSQLite:
-		db, err = gorm.Open(sqlite.Open(configs.Config.DBName), &gorm.Config{})
+		db, err = gorm.Open(sqlite.Open(SQLiteDSN(configs.Config.DBName)), &gorm.Config{TranslateError: true})
 
 	default:
 		return nil, errInvalidSQLDatabaseInstance
@@ -62,6 +81,20 @@ func NewDatabaseFactory(instance int) (db *gorm.DB, err error) {
 	return db, err
 }
 
+// sqlite dsn with foreign keys enabled
+// sqlite ignores foreign keys (and ON DELETE CASCADE) unless enabled on every connection
+// empty name (temporary database) is returned as it is because the driver cannot parse options without a file name
+func SQLiteDSN(name string) string {
+	if name == "" {
+		return name
+	}
+	separator := "?"
+	if strings.Contains(name, "?") {
+		separator = "&"
+	}
+	return name + separator + "_foreign_keys=1"
+}
+
 // initialize database
 func SetDatabase(instance int) (err error) {
 	db, err := NewDatabaseFactory(instance)
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// メール認証用のtoken
+// tokenそのものはメールで送信し、DBにはhash(auth.HashOneTimeToken)のみを保存する
+// Email: tokenを送信したemail、認証時にユーザーのemailが変わっていた場合は使用できない
+type EmailVerificationToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Email     string     `gorm:"type:varchar(255);not null" json:"email"`
+	TokenHash string     `gorm:"type:varchar(64);not null;unique" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
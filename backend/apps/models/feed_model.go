@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ユーザーごとに1つだけ持つタイムライン
+type Feed struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;unique" json:"user_id"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Feedに配信されたTweet
+// TweetService.CreateTweetでフォロワーのFeedに書き込まれる(fan-out-on-write)
+type FeedTweet struct {
+	ID      uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	TweetID uint `gorm:"not null;uniqueIndex:idx_feed_tweets_feed_tweet,priority:2" json:"tweet_id"`
+	FeedID  uint `gorm:"not null;uniqueIndex:idx_feed_tweets_feed_tweet,priority:1" json:"feed_id"` // 同じTweetは1つのFeedに1回だけ配信する
+
+	// relations
+	Tweet *Tweet `gorm:"foreignKey:TweetID;references:ID" json:"tweet"`
+	Feed  *Feed  `gorm:"foreignKey:FeedID;references:ID" json:"feed"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// tweetの内容から抽出したhashtag
+// Name: pkg/hashtagで正規化した名前(先頭の#を含まない)
+type Hashtag struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"type:varchar(100);not null;unique" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// tweetとhashtagの中間テーブル
+// TweetService.CreateTweet/UpdateTweetでtweetの内容と同期される
+type TweetHashtag struct {
+	TweetID   uint `gorm:"primaryKey;autoIncrement:false" json:"tweet_id"`
+	HashtagID uint `gorm:"primaryKey;autoIncrement:false;index" json:"hashtag_id"`
+}
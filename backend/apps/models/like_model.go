@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Like struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_likes_user_tweet" json:"user_id"`  // likeした人
+	TweetID   uint      `gorm:"not null;uniqueIndex:idx_likes_user_tweet" json:"tweet_id"` // likeされたtweet
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// relations
+	// User, Tweet情報をLikeと一緒に取得したい場合はPreloadを使用する
+	User  *User  `gorm:"foreignKey:UserID;references:ID" json:"user"`
+	Tweet *Tweet `gorm:"foreignKey:TweetID;references:ID" json:"tweet"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ログインの失敗回数(アカウント単位、IPアドレス単位)
+// Identifier: "account:<email>"または"ip:<IPアドレス>"
+// LockedUntil: 失敗回数が上限に達した場合にこの日時までログインできない(ロック時にFailuresは0に戻す)
+type LoginAttempt struct {
+	Identifier   string     `gorm:"type:varchar(255);primaryKey" json:"identifier"`
+	Failures     int        `gorm:"not null;default:0" json:"failures"`
+	LastFailedAt time.Time  `gorm:"not null;index" json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
+
+// ログインのロックの監査記録
+// IPAddress: ロックされた時のリクエストのIPアドレス
+type LoginLockout struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Identifier  string    `gorm:"type:varchar(255);not null;index" json:"identifier"`
+	IPAddress   string    `gorm:"type:varchar(45);not null" json:"ip_address"`
+	Failures    int       `gorm:"not null" json:"failures"`
+	LockedUntil time.Time `gorm:"not null" json:"locked_until"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
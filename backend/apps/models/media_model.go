@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// tweetに添付する画像/動画
+// アップロード時はTweetIDがnilで、tweetの作成時に添付される
+// Type: image or video(TweetTypeのImage/Videoと同じ値)
+type Media struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       uint      `gorm:"not null;index" json:"user_id"` // アップロードした人
+	TweetID      *uint     `gorm:"index" json:"tweet_id"`         // 添付されたtweet
+	Type         TweetType `gorm:"type:enum('image', 'video');not null" json:"type"`
+	ContentType  string    `gorm:"type:varchar(100);not null" json:"content_type"`
+	Size         int64     `gorm:"not null" json:"size"`
+	StorageKey   string    `gorm:"type:varchar(255);not null" json:"-"`
+	URL          string    `gorm:"type:varchar(1024);not null" json:"url"`
+	ThumbnailKey string    `gorm:"type:varchar(255);not null;default:''" json:"-"`
+	ThumbnailURL string    `gorm:"type:varchar(1024);not null;default:''" json:"thumbnail_url,omitempty"` // 画像のみ
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Media) TableName() string {
+	return "media"
+}
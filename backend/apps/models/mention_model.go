@@ -0,0 +1,13 @@
+package models
+
+// tweetの内容に含まれる@mention
+// Username: 内容に書かれたままのusername、Start/End: 内容の中での"@username"の位置(文字単位、Endは含まない)
+// 同じユーザーへのmentionが複数ある場合はそれぞれ保存する
+type TweetMention struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement" json:"-"`
+	TweetID  uint   `gorm:"not null;index" json:"-"`
+	UserID   uint   `gorm:"not null;index" json:"user_id"`
+	Username string `gorm:"type:varchar(15);not null" json:"username"`
+	Start    int    `gorm:"not null" json:"start"`
+	End      int    `gorm:"not null" json:"end"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// パスワード再設定用のtoken
+// tokenそのものはメールで送信し、DBにはhash(auth.HashOneTimeToken)のみを保存する
+// 1回使用する(UsedAtが設定される)か有効期限を過ぎると使用できない
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenHash string     `gorm:"type:varchar(64);not null;unique" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
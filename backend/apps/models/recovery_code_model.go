@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// 2段階認証のリカバリーコード
+// 認証アプリを使用できない場合にTOTPのコードの代わりに1回だけ使用できる
+// コードそのものは2段階認証の有効化時に1度だけ表示し、DBにはhash(auth.HashOneTimeToken)のみを保存する
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null;unique" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// 発行したrefresh tokenの状態
+// 同じログインから発行(rotate)されたrefresh tokenは同じFamilyIDを持つ
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	TokenID   string     `gorm:"type:varchar(36);not null;unique" json:"token_id"` // refresh tokenのjti
+	FamilyID  string     `gorm:"type:varchar(36);not null;index" json:"family_id"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`    // rotateされた日時
+	RevokedAt *time.Time `json:"revoked_at"` // logoutや再利用検知で失効した日時
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// relations
+	User *User `gorm:"foreignKey:UserID;references:ID" json:"user"`
+}
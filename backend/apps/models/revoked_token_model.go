@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// 失効させたアクセストークン(jti単位)
+// ExpiresAtを過ぎたらトークン自体が無効になるので削除してよい
+type RevokedToken struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TokenID   string    `gorm:"type:varchar(36);not null;unique" json:"token_id"` // アクセストークンのjti
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ユーザー単位のアクセストークンの失効
+// RevokedBefore以前に発行されたユーザーのアクセストークンを全て無効にする
+type UserTokenRevocation struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID        uint      `gorm:"not null;unique" json:"user_id"`
+	RevokedBefore time.Time `gorm:"not null" json:"revoked_before"`
+	ExpiresAt     time.Time `gorm:"not null;index" json:"expires_at"` // RevokedBefore以前に発行されたトークンが全て期限切れになる日時
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// 時間枠(slot)ごとのtrendの単語の出現数
+// Term: pkg/trend.Tokenizeで抽出した単語(hashtagは#付き)
+// SlotStart: services.TrendSlotDurationで区切った時間枠の開始時刻(UTC)
+type TrendBucket struct {
+	Term      string    `gorm:"type:varchar(128);primaryKey" json:"term"`
+	SlotStart time.Time `gorm:"primaryKey;index" json:"slot_start"`
+	Count     int64     `gorm:"not null;default:0" json:"count"`
+}
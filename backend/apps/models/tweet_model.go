@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // define tweet type
@@ -17,6 +19,10 @@ const (
 	Text  TweetType = "text"
 	Image TweetType = "image"
 	Video TweetType = "video"
+
+	// retweet and quote are created only by TweetService.Retweet/QuoteTweet, so Str2TweetType does not accept them
+	Retweet TweetType = "retweet"
+	Quote   TweetType = "quote"
 )
 
 // convert the string to the enum to be used in the database
@@ -33,6 +39,11 @@ func Str2TweetType(tweetTypeString string) (TweetType, error) {
 	}
 }
 
+// image and video tweets must have attached media of the same type
+func (t TweetType) HasMedia() bool {
+	return t == Image || t == Video
+}
+
 // convert the enum from the database to the custom type
 func (t *TweetType) Scan(value interface{}) error {
 	switch v := value.(type) {
@@ -57,22 +68,61 @@ func (t *TweetType) Scan(value interface{}) error {
 // convert the custom type to the enum
 func (t TweetType) Value() (driver.Value, error) {
 	switch t {
-	case Text, Image, Video:
+	case Text, Image, Video, Retweet, Quote:
 		return string(t), nil
 	default:
 		return nil, fmt.Errorf("unsupported tweet type: %s", t)
 	}
 }
 
+// レスポンスにTweetを含める場合はdtosのTweetResponseを使用する(投稿者をPublicUserとして返すため)
 type Tweet struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID    uint      `gorm:"not null" json:"user_id"`
-	Type      TweetType `gorm:"type:enum('text', 'image', 'video');not null" json:"type"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tweets_user_retweet" json:"user_id"`
+	Type      TweetType `gorm:"type:enum('text', 'image', 'video', 'retweet', 'quote');not null" json:"type"`
 	Content   string    `gorm:"type:varchar(140);not null" json:"content"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
 
+	// reply情報(返信でないTweetはどちらもnil)
+	// ConversationID: 返信をたどった先の最初のTweet(root)のid
+	InReplyToTweetID *uint `json:"in_reply_to_tweet_id"`
+	ConversationID   *uint `gorm:"index" json:"conversation_id"`
+
+	// 削除されたTweetはスレッドが壊れないように内容を消した墓標(tombstone)として残す
+	// 通常のクエリでは取得されず、スレッドの取得時のみUnscopedで取得する
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+
+	// retweet/quote情報
+	// RetweetedTweetID: retweet(Type=retweet)の元のTweet、1人のユーザーが同じTweetをretweetできるのは1回のみ
+	// QuotedTweetID: quote(Type=quote)で引用したTweet
+	RetweetedTweetID *uint `gorm:"uniqueIndex:idx_tweets_user_retweet" json:"retweeted_tweet_id"`
+	QuotedTweetID    *uint `gorm:"index" json:"quoted_tweet_id"`
+
+	// like情報(DBには保存せず、TweetService.GetTweetで設定する)
+	LikeCount *int64 `gorm:"-" json:"like_count,omitempty"`
+	LikedByMe *bool  `gorm:"-" json:"liked_by_me,omitempty"`
+
+	// retweet/quote数(DBには保存せず、TweetServiceとFeedServiceで設定する)
+	RetweetCount *int64 `gorm:"-" json:"retweet_count,omitempty"`
+	QuoteCount   *int64 `gorm:"-" json:"quote_count,omitempty"`
+
 	// relations
 	// User情報をTweetと一緒に取得したい場合はPreload("User")を使用する
 	User *User `gorm:"foreignKey:UserID;references:ID" json:"user"`
+	// retweet/quoteの元のTweet(元の投稿者はRetweetedTweet.User/QuotedTweet.User)
+	RetweetedTweet *Tweet `gorm:"foreignKey:RetweetedTweetID;references:ID" json:"retweeted_tweet,omitempty"`
+	QuotedTweet    *Tweet `gorm:"foreignKey:QuotedTweetID;references:ID" json:"quoted_tweet,omitempty"`
+	// 添付された画像/動画
+	Media []*Media `gorm:"foreignKey:TweetID;references:ID" json:"media,omitempty"`
+	// 内容に含まれるhashtag(tweet_hashtagsはTweetRepositoryで内容と同期する)
+	Hashtags []*Hashtag `gorm:"many2many:tweet_hashtags" json:"hashtags,omitempty"`
+	// 内容に含まれる@mention(存在するユーザーへのmentionのみ、tweet_mentionsはTweetRepositoryで内容と同期する)
+	Mentions []*TweetMention `gorm:"foreignKey:TweetID;references:ID" json:"mentions,omitempty"`
+}
+
+// userIdのユーザーが更新、削除できるtweetか(投稿した本人のみ)
+// 他のユーザーのtweetの削除は/adminからPermissionDeleteTweetsを持つユーザーのみ行える
+func (t *Tweet) IsOwnedBy(userId uint) bool {
+	return t.UserID == userId
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ユーザーに紐づけた外部プロバイダー(Google、GitHub、OIDC)のアカウント
+// プロバイダーのアカウントは(Provider, Subject)で識別し、プロバイダー側でemailが変わってもログインできる
+// 1人のユーザーが紐づけられるアカウントは1つのプロバイダーにつき1つまで
+// Email: 紐づけた時(または最後にログインした時)のプロバイダーのemail、表示用
+type UserIdentity struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"-"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_identities_user_id_provider" json:"-"`
+	Provider  string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject;uniqueIndex:idx_user_identities_user_id_provider" json:"provider"`
+	Subject   string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"`
+	Email     string    `gorm:"type:varchar(255);not null" json:"email"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -2,11 +2,37 @@ package models
 
 import "time"
 
+// パスワード、email、生年月日はJSONに含めない
+// レスポンスにユーザーを含める場合はdtosのPublicUser/SelfUser/AdminUserを使用する
 type User struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
-	Email     string    `gorm:"type:varchar(255);unique;not null" json:"email"`
-	Password  string    `gorm:"type:varchar(255)" json:"password"`
-	Dob       time.Time `gorm:"type:date;omitempty" json:"dob"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name            string     `gorm:"type:varchar(255);not null" json:"name"`           // 表示名
+	Username        string     `gorm:"type:varchar(15);not null;unique" json:"username"` // @mentionで使うhandle、大文字小文字を区別せずunique
+	Email           string     `gorm:"type:varchar(255);unique;not null" json:"-"`
+	Password        string     `gorm:"type:varchar(255)" json:"-"`
+	Role            string     `gorm:"type:varchar(20);not null;default:'user'" json:"-"` // auth.RoleUser、auth.RoleModerator、auth.RoleAdminのいずれか
+	Dob             time.Time  `gorm:"type:date;omitempty" json:"-"`
+	EmailVerifiedAt *time.Time `json:"-"` // メール認証が済んだ日時、未認証の場合はnil
+	SuspendedAt     *time.Time `json:"-"` // アカウントが停止された日時、停止されていない場合はnil
+	Bio             string     `gorm:"type:varchar(160);not null;default:''" json:"bio"`
+	Location        string     `gorm:"type:varchar(30);not null;default:''" json:"location"`
+	Website         string     `gorm:"type:varchar(100);not null;default:''" json:"website"`
+	AvatarMediaID   *uint      `json:"avatar_media_id"` // プロフィール画像(POST /media でアップロードした画像)
+	HeaderMediaID   *uint      `json:"header_media_id"` // ヘッダー画像(POST /media でアップロードした画像)
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// relations
+	// プロフィール画像、ヘッダー画像のURLが必要な場合はPreload("Avatar"), Preload("Header")を使用する
+	Avatar *Media `gorm:"foreignKey:AvatarMediaID;references:ID" json:"-"`
+	Header *Media `gorm:"foreignKey:HeaderMediaID;references:ID" json:"-"`
+}
+
+// メール認証が済んでいるか
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// アカウントが停止されているか
+func (u *User) Suspended() bool {
+	return u.SuspendedAt != nil
 }
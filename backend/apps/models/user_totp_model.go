@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ユーザーのTOTP(RFC 6238)による2段階認証の設定
+// 登録(enroll)時にSecretを発行し、認証アプリの最初のコードで確認されるとConfirmedAtが設定されて有効になる
+// LastUsedStep: 最後に使用したコードのtime step、同じコードの再利用を防ぐ
+type UserTOTP struct {
+	UserID       uint       `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	Secret       string     `gorm:"type:varchar(64);not null" json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// 2段階認証が有効か(登録後に確認済み)
+func (t *UserTOTP) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"gorm.io/gorm"
+)
+
+type IEmailVerificationTokenRepository interface {
+	CreateEmailVerificationToken(verificationToken *models.EmailVerificationToken) error
+	FindEmailVerificationToken(tokenHash string) (*models.EmailVerificationToken, error)
+	MarkEmailVerificationTokenUsed(id uint) error
+	CountEmailVerificationTokensSince(userId uint, since time.Time) (int64, error)
+}
+
+type EmailVerificationTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewEmailVerificationTokenRepository(db *gorm.DB) IEmailVerificationTokenRepository {
+	return &EmailVerificationTokenRepository{DB: db}
+}
+
+func (r *EmailVerificationTokenRepository) CreateEmailVerificationToken(verificationToken *models.EmailVerificationToken) error {
+	result := r.DB.Create(verificationToken)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *EmailVerificationTokenRepository) FindEmailVerificationToken(tokenHash string) (*models.EmailVerificationToken, error) {
+	var verificationToken models.EmailVerificationToken
+	result := r.DB.First(&verificationToken, "token_hash = ?", tokenHash)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("email verification token not found")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &verificationToken, nil
+}
+
+// tokenを使用済みにする
+// 同時に同じtokenが使用された場合に片方だけ成功するよう、未使用の場合のみ更新する
+func (r *EmailVerificationTokenRepository) MarkEmailVerificationTokenUsed(id uint) error {
+	result := r.DB.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("email verification token already used")
+	}
+
+	return nil
+}
+
+// userIdのユーザーにsince以降に発行したtokenの数(認証メールの再送信の制限に使用する)
+func (r *EmailVerificationTokenRepository) CountEmailVerificationTokensSince(userId uint, since time.Time) (int64, error) {
+	var count int64
+	result := r.DB.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ? AND created_at > ?", userId, since).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return count, nil
+}
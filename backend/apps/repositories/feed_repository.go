@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IFeedRepository interface {
+	GetOrCreateFeed(userId uint) (*models.Feed, error)
+	AddTweetToFeeds(tweetId uint, userIds []uint) error
+	AddUserTweetsToFeed(userId, authorId uint, limit int) error
+	RemoveUserTweetsFromFeed(userId, authorId uint) error
+	GetFeedTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error)
+}
+
+type FeedRepository struct {
+	DB *gorm.DB
+}
+
+func NewFeedRepository(db *gorm.DB) IFeedRepository {
+	return &FeedRepository{DB: db}
+}
+
+// userIdのユーザーのFeedを取得、存在しない場合は作成する
+func (r *FeedRepository) GetOrCreateFeed(userId uint) (*models.Feed, error) {
+	return getOrCreateFeed(r.DB, userId)
+}
+
+// tweetIdのTweetをuserIdsの各ユーザーのFeedに追加する
+func (r *FeedRepository) AddTweetToFeeds(tweetId uint, userIds []uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, userId := range userIds {
+			feed, err := getOrCreateFeed(tx, userId)
+			if err != nil {
+				return err
+			}
+
+			feedTweet := &models.FeedTweet{
+				TweetID: tweetId,
+				FeedID:  feed.ID,
+			}
+			// 配信済みのTweetは無視する
+			if result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(feedTweet); result.Error != nil {
+				return result.Error
+			}
+
+			// Feedの更新日時を更新
+			if result := tx.Model(feed).Update("updated_at", time.Now()); result.Error != nil {
+				return result.Error
+			}
+		}
+
+		return nil
+	})
+}
+
+// authorIdのユーザーの最新のTweetを最大limit件userIdのユーザーのFeedに追加する(追加済みのTweetは除く)
+func (r *FeedRepository) AddUserTweetsToFeed(userId, authorId uint, limit int) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		feed, err := getOrCreateFeed(tx, userId)
+		if err != nil {
+			return err
+		}
+
+		var tweetIds []uint
+		result := tx.Model(&models.Tweet{}).
+			Where("user_id = ?", authorId).
+			Where("id NOT IN (?)", tx.Model(&models.FeedTweet{}).Select("tweet_id").Where("feed_id = ?", feed.ID)).
+			Order("id DESC").
+			Limit(limit).
+			Pluck("id", &tweetIds)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(tweetIds) == 0 {
+			return nil
+		}
+
+		feedTweets := make([]*models.FeedTweet, 0, len(tweetIds))
+		for _, tweetId := range tweetIds {
+			feedTweets = append(feedTweets, &models.FeedTweet{TweetID: tweetId, FeedID: feed.ID})
+		}
+		if result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&feedTweets); result.Error != nil {
+			return result.Error
+		}
+
+		// Feedの更新日時を更新
+		return tx.Model(feed).Update("updated_at", time.Now()).Error
+	})
+}
+
+// userIdのユーザーのFeedからauthorIdのユーザーのTweetを全て削除する
+func (r *FeedRepository) RemoveUserTweetsFromFeed(userId, authorId uint) error {
+	result := r.DB.
+		Where("feed_id IN (?)", r.DB.Model(&models.Feed{}).Select("id").Where("user_id = ?", userId)).
+		Where("tweet_id IN (?)", r.DB.Unscoped().Model(&models.Tweet{}).Select("id").Where("user_id = ?", authorId)).
+		Delete(&models.FeedTweet{})
+
+	return result.Error
+}
+
+// userIdのユーザーのFeedに配信されたTweetを新しい順に取得
+func (r *FeedRepository) GetFeedTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	var tweets []*models.Tweet
+
+	result := r.DB.
+		Joins("JOIN feed_tweets ON feed_tweets.tweet_id = tweets.id").
+		Joins("JOIN feeds ON feeds.id = feed_tweets.feed_id").
+		Where("feeds.user_id = ?", userId).
+		Scopes(preloadTweetRelations, page.Scope("tweets")).
+		Find(&tweets)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("tweets not found")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return pagination.NewList(tweets, page, TweetCursor), nil
+}
+
+func getOrCreateFeed(db *gorm.DB, userId uint) (*models.Feed, error) {
+	var feed models.Feed
+
+	result := db.Where(models.Feed{UserID: userId}).FirstOrCreate(&feed)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &feed, nil
+}
@@ -4,14 +4,15 @@ import (
 	"errors"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 	"gorm.io/gorm"
 )
 
 type IFollowerRepository interface {
 	CreateFollower(follower *models.Follower) (*models.Follower, error)
 	GetFollower(id uint) (*models.Follower, error)
-	GetFollowees(followerId uint) ([]*models.Follower, error)
-	GetFollowers(followeeId uint) ([]*models.Follower, error)
+	GetFollowees(followerId uint, page *pagination.Page) (*pagination.List[*models.Follower], error)
+	GetFollowers(followeeId uint, page *pagination.Page) (*pagination.List[*models.Follower], error)
 	DeleteFollower(id uint) error
 }
 
@@ -44,30 +45,30 @@ func (r *FollowerRepository) GetFollower(id uint) (*models.Follower, error) {
 
 // followerIdのユーザーがフォローしているユーザーを取得するためのメソッド
 // followerIdのユーザーがフォローしているユーザーデータを含むFollowerを取得
-func (r *FollowerRepository) GetFollowees(followerId uint) ([]*models.Follower, error) {
+func (r *FollowerRepository) GetFollowees(followerId uint, page *pagination.Page) (*pagination.List[*models.Follower], error) {
 	var followers []*models.Follower
-	result := r.DB.Preload("Followee").Where("follower_id = ?", followerId).Find(&followers)
+	result := r.DB.Preload("Followee").Preload("Followee.Avatar").Where("follower_id = ?", followerId).Scopes(page.Scope("followers")).Find(&followers)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, errors.New("followers not found")
 	} else if result.Error != nil {
 		return nil, result.Error
 	}
 
-	return followers, nil
+	return pagination.NewList(followers, page, followerCursor), nil
 }
 
 // followeeIdのユーザーをフォローしているユーザーを取得するためのメソッド
 // followeeIdのユーザーをフォローしているユーザーデータを含むFollowerを取得
-func (r *FollowerRepository) GetFollowers(followeeId uint) ([]*models.Follower, error) {
+func (r *FollowerRepository) GetFollowers(followeeId uint, page *pagination.Page) (*pagination.List[*models.Follower], error) {
 	var followers []*models.Follower
-	result := r.DB.Preload("Follower").Where("followee_id = ?", followeeId).Find(&followers)
+	result := r.DB.Preload("Follower").Preload("Follower.Avatar").Where("followee_id = ?", followeeId).Scopes(page.Scope("followers")).Find(&followers)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, errors.New("followers not found")
 	} else if result.Error != nil {
 		return nil, result.Error
 	}
 
-	return followers, nil
+	return pagination.NewList(followers, page, followerCursor), nil
 }
 
 func (r *FollowerRepository) DeleteFollower(id uint) error {
@@ -80,3 +81,7 @@ func (r *FollowerRepository) DeleteFollower(id uint) error {
 
 	return nil
 }
+
+func followerCursor(follower *models.Follower) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: follower.CreatedAt, ID: follower.ID}
+}
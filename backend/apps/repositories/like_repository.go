@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type ILikeRepository interface {
+	CreateLike(like *models.Like) (*models.Like, error)
+	DeleteLike(userId, tweetId uint) error
+	GetTweetLikes(tweetId uint, page *pagination.Page) (*pagination.List[*models.Like], error)
+	GetUserLikes(userId uint, page *pagination.Page) (*pagination.List[*models.Like], error)
+	CountLikes(tweetIds []uint) (map[uint]int64, error)
+	GetLikedTweetIds(userId uint, tweetIds []uint) (map[uint]bool, error)
+}
+
+type LikeRepository struct {
+	DB *gorm.DB
+}
+
+func NewLikeRepository(db *gorm.DB) ILikeRepository {
+	return &LikeRepository{DB: db}
+}
+
+func (r *LikeRepository) CreateLike(like *models.Like) (*models.Like, error) {
+	result := r.DB.Create(like)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return nil, errors.New("already liked")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return like, nil
+}
+
+func (r *LikeRepository) DeleteLike(userId, tweetId uint) error {
+	result := r.DB.Delete(&models.Like{}, "user_id = ? AND tweet_id = ?", userId, tweetId)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("like not found")
+	}
+
+	return nil
+}
+
+// tweetIdのtweetをlikeしたユーザーデータを含むLikeを取得
+func (r *LikeRepository) GetTweetLikes(tweetId uint, page *pagination.Page) (*pagination.List[*models.Like], error) {
+	var likes []*models.Like
+	result := r.DB.Preload("User").Preload("User.Avatar").Where("tweet_id = ?", tweetId).Scopes(page.Scope("likes")).Find(&likes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return pagination.NewList(likes, page, likeCursor), nil
+}
+
+// userIdのユーザーがlikeしたtweetデータを含むLikeを取得
+func (r *LikeRepository) GetUserLikes(userId uint, page *pagination.Page) (*pagination.List[*models.Like], error) {
+	var likes []*models.Like
+	result := r.DB.Preload("Tweet").Where("user_id = ?", userId).Scopes(page.Scope("likes")).Find(&likes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return pagination.NewList(likes, page, likeCursor), nil
+}
+
+// tweetIdsの各tweetのlike数を取得
+func (r *LikeRepository) CountLikes(tweetIds []uint) (map[uint]int64, error) {
+	var rows []struct {
+		TweetID uint
+		Count   int64
+	}
+	result := r.DB.Model(&models.Like{}).
+		Select("tweet_id, COUNT(*) AS count").
+		Where("tweet_id IN ?", tweetIds).
+		Group("tweet_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.TweetID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// tweetIdsのうちuserIdのユーザーがlikeしているtweetを取得
+func (r *LikeRepository) GetLikedTweetIds(userId uint, tweetIds []uint) (map[uint]bool, error) {
+	var likedTweetIds []uint
+	result := r.DB.Model(&models.Like{}).
+		Where("user_id = ? AND tweet_id IN ?", userId, tweetIds).
+		Pluck("tweet_id", &likedTweetIds)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	liked := make(map[uint]bool, len(likedTweetIds))
+	for _, tweetId := range likedTweetIds {
+		liked[tweetId] = true
+	}
+
+	return liked, nil
+}
+
+func likeCursor(like *models.Like) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: like.CreatedAt, ID: like.ID}
+}
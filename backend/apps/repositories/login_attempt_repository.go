@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ログインの失敗回数とロックの記録
+// AuthServiceがアカウント単位とIPアドレス単位でログインを制限するために使用する
+type ILoginAttemptRepository interface {
+	FindLoginAttempt(identifier string) (*models.LoginAttempt, error)
+	RecordFailedLogin(identifier string, failedAt time.Time, windowStart time.Time) (*models.LoginAttempt, error)
+	LockLoginAttempt(lockout *models.LoginLockout) error
+	ResetLoginAttempt(identifier string) error
+	FindLoginLockouts(identifier string) ([]*models.LoginLockout, error)
+	PurgeLoginAttempts(before time.Time) (int64, error)
+}
+
+// DBを使用するログインの失敗回数の記録
+type LoginAttemptRepository struct {
+	DB *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) ILoginAttemptRepository {
+	return &LoginAttemptRepository{DB: db}
+}
+
+func (r *LoginAttemptRepository) FindLoginAttempt(identifier string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	result := r.DB.First(&attempt, "identifier = ?", identifier)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("login attempt not found")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &attempt, nil
+}
+
+// 失敗回数を1増やす
+// 最後の失敗がwindowStartより前の場合は1からやり直す
+func (r *LoginAttemptRepository) RecordFailedLogin(identifier string, failedAt time.Time, windowStart time.Time) (*models.LoginAttempt, error) {
+	result := r.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "identifier"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"failures":       gorm.Expr("CASE WHEN login_attempts.last_failed_at < ? THEN 1 ELSE login_attempts.failures + 1 END", windowStart.UTC()),
+			"last_failed_at": failedAt.UTC(),
+		}),
+	}).Create(&models.LoginAttempt{Identifier: identifier, Failures: 1, LastFailedAt: failedAt.UTC()})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return r.FindLoginAttempt(identifier)
+}
+
+// lockout.LockedUntilまでロックして失敗回数を0に戻し、監査記録を保存する
+func (r *LoginAttemptRepository) LockLoginAttempt(lockout *models.LoginLockout) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.LoginAttempt{}).
+			Where("identifier = ?", lockout.Identifier).
+			Updates(map[string]interface{}{"failures": 0, "locked_until": lockout.LockedUntil.UTC()})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result := tx.Create(lockout); result.Error != nil {
+			return result.Error
+		}
+
+		return nil
+	})
+}
+
+// ログインに成功した場合に失敗回数を削除する
+func (r *LoginAttemptRepository) ResetLoginAttempt(identifier string) error {
+	result := r.DB.Where("identifier = ?", identifier).Delete(&models.LoginAttempt{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+// identifierのロックの監査記録を新しい順に取得
+func (r *LoginAttemptRepository) FindLoginLockouts(identifier string) ([]*models.LoginLockout, error) {
+	var lockouts []*models.LoginLockout
+	result := r.DB.Where("identifier = ?", identifier).Order("created_at DESC, id DESC").Find(&lockouts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return lockouts, nil
+}
+
+// 最後の失敗がbeforeより前でロック中でない失敗回数を削除(ロックの監査記録は削除しない)
+func (r *LoginAttemptRepository) PurgeLoginAttempts(before time.Time) (int64, error) {
+	result := r.DB.
+		Where("last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", before.UTC(), before.UTC()).
+		Delete(&models.LoginAttempt{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// メモリ上のログインの失敗回数の記録(テストや単一インスタンスでの利用向け)
+type InMemoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt // identifier -> attempt
+	lockouts []models.LoginLockout
+}
+
+func NewInMemoryLoginAttemptRepository() ILoginAttemptRepository {
+	return &InMemoryLoginAttemptRepository{
+		attempts: map[string]models.LoginAttempt{},
+	}
+}
+
+func (r *InMemoryLoginAttemptRepository) FindLoginAttempt(identifier string) (*models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[identifier]
+	if !ok {
+		return nil, errors.New("login attempt not found")
+	}
+
+	return &attempt, nil
+}
+
+func (r *InMemoryLoginAttemptRepository) RecordFailedLogin(identifier string, failedAt time.Time, windowStart time.Time) (*models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[identifier]
+	if !ok || attempt.LastFailedAt.Before(windowStart) {
+		attempt.Identifier = identifier
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = failedAt
+	r.attempts[identifier] = attempt
+
+	return &attempt, nil
+}
+
+func (r *InMemoryLoginAttemptRepository) LockLoginAttempt(lockout *models.LoginLockout) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempt, ok := r.attempts[lockout.Identifier]; ok {
+		lockedUntil := lockout.LockedUntil
+		attempt.Failures = 0
+		attempt.LockedUntil = &lockedUntil
+		r.attempts[lockout.Identifier] = attempt
+	}
+
+	lockout.ID = uint(len(r.lockouts) + 1)
+	lockout.CreatedAt = time.Now()
+	r.lockouts = append(r.lockouts, *lockout)
+	return nil
+}
+
+func (r *InMemoryLoginAttemptRepository) ResetLoginAttempt(identifier string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, identifier)
+	return nil
+}
+
+func (r *InMemoryLoginAttemptRepository) FindLoginLockouts(identifier string) ([]*models.LoginLockout, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lockouts := []*models.LoginLockout{}
+	for i := range r.lockouts {
+		if r.lockouts[i].Identifier == identifier {
+			lockout := r.lockouts[i]
+			lockouts = append(lockouts, &lockout)
+		}
+	}
+	sort.SliceStable(lockouts, func(i, j int) bool {
+		return lockouts[i].ID > lockouts[j].ID
+	})
+
+	return lockouts, nil
+}
+
+func (r *InMemoryLoginAttemptRepository) PurgeLoginAttempts(before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for identifier, attempt := range r.attempts {
+		if attempt.LastFailedAt.Before(before) && (attempt.LockedUntil == nil || attempt.LockedUntil.Before(before)) {
+			delete(r.attempts, identifier)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// intervalごとに最後の失敗からretentionが経過した失敗回数を削除する
+// 返り値の関数を呼ぶと停止する
+func StartLoginAttemptPurger(repository ILoginAttemptRepository, interval, retention time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				if _, err := repository.PurgeLoginAttempts(now.Add(-retention)); err != nil {
+					log.Println("failed to purge login attempts: ", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
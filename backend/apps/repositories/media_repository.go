@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"gorm.io/gorm"
+)
+
+type IMediaRepository interface {
+	CreateMedia(media *models.Media) (*models.Media, error)
+	GetMediaByIds(mediaIds []uint) ([]*models.Media, error)
+	GetTweetMedia(tweetId uint) ([]*models.Media, error)
+	DeleteMedia(mediaIds []uint) error
+}
+
+type MediaRepository struct {
+	DB *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) IMediaRepository {
+	return &MediaRepository{DB: db}
+}
+
+func (r *MediaRepository) CreateMedia(media *models.Media) (*models.Media, error) {
+	result := r.DB.Create(media)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return media, nil
+}
+
+// mediaIdsの画像/動画をid順に取得(存在しないidは含まれない)
+func (r *MediaRepository) GetMediaByIds(mediaIds []uint) ([]*models.Media, error) {
+	var media []*models.Media
+	result := r.DB.Where("id IN ?", mediaIds).Order("id").Find(&media)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return media, nil
+}
+
+// tweetIdのtweetに添付された画像/動画を取得
+func (r *MediaRepository) GetTweetMedia(tweetId uint) ([]*models.Media, error) {
+	var media []*models.Media
+	result := r.DB.Where("tweet_id = ?", tweetId).Order("id").Find(&media)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return media, nil
+}
+
+func (r *MediaRepository) DeleteMedia(mediaIds []uint) error {
+	if len(mediaIds) == 0 {
+		return nil
+	}
+
+	result := r.DB.Where("id IN ?", mediaIds).Delete(&models.Media{})
+	return result.Error
+}
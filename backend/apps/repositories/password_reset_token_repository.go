@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"gorm.io/gorm"
+)
+
+type IPasswordResetTokenRepository interface {
+	CreatePasswordResetToken(resetToken *models.PasswordResetToken) error
+	FindPasswordResetToken(tokenHash string) (*models.PasswordResetToken, error)
+	MarkPasswordResetTokenUsed(id uint) error
+	InvalidateUserPasswordResetTokens(userId uint) error
+}
+
+type PasswordResetTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) IPasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{DB: db}
+}
+
+func (r *PasswordResetTokenRepository) CreatePasswordResetToken(resetToken *models.PasswordResetToken) error {
+	result := r.DB.Create(resetToken)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *PasswordResetTokenRepository) FindPasswordResetToken(tokenHash string) (*models.PasswordResetToken, error) {
+	var resetToken models.PasswordResetToken
+	result := r.DB.First(&resetToken, "token_hash = ?", tokenHash)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("password reset token not found")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &resetToken, nil
+}
+
+// tokenを使用済みにする
+// 同時に同じtokenが使用された場合に片方だけ成功するよう、未使用の場合のみ更新する
+func (r *PasswordResetTokenRepository) MarkPasswordResetTokenUsed(id uint) error {
+	result := r.DB.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("password reset token already used")
+	}
+
+	return nil
+}
+
+// userIdのユーザーの未使用のtokenを全て使用済みにする(新しいtokenを発行した時、パスワードを変更した時)
+func (r *PasswordResetTokenRepository) InvalidateUserPasswordResetTokens(userId uint) error {
+	result := r.DB.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userId).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"gorm.io/gorm"
+)
+
+type IRefreshTokenRepository interface {
+	CreateRefreshToken(refreshToken *models.RefreshToken) error
+	FindRefreshToken(tokenId string) (*models.RefreshToken, error)
+	MarkRefreshTokenUsed(tokenId string) error
+	RevokeFamily(familyId string) error
+	RevokeUserRefreshTokens(userId uint) error
+}
+
+type RefreshTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewRefreshTokenRepository(db *gorm.DB) IRefreshTokenRepository {
+	return &RefreshTokenRepository{DB: db}
+}
+
+func (r *RefreshTokenRepository) CreateRefreshToken(refreshToken *models.RefreshToken) error {
+	result := r.DB.Create(refreshToken)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepository) FindRefreshToken(tokenId string) (*models.RefreshToken, error) {
+	var refreshToken models.RefreshToken
+	result := r.DB.First(&refreshToken, "token_id = ?", tokenId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("refresh token not found")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &refreshToken, nil
+}
+
+// refresh tokenをrotate済みにする
+// 同時に同じtokenでrotateされた場合に片方だけ成功するよう、未使用の場合のみ更新する
+func (r *RefreshTokenRepository) MarkRefreshTokenUsed(tokenId string) error {
+	result := r.DB.Model(&models.RefreshToken{}).
+		Where("token_id = ? AND used_at IS NULL", tokenId).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("refresh token already used")
+	}
+
+	return nil
+}
+
+// familyIdのrefresh tokenを全て失効させる
+func (r *RefreshTokenRepository) RevokeFamily(familyId string) error {
+	result := r.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyId).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+// userIdのユーザーのrefresh tokenを全て失効させる
+func (r *RefreshTokenRepository) RevokeUserRefreshTokens(userId uint) error {
+	result := r.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userId).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
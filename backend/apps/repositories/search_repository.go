@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/pkg/search"
+	"gorm.io/gorm"
+)
+
+type ISearchRepository interface {
+	SearchTweets(condition *TweetSearchCondition, page *pagination.Page) (*pagination.List[*models.Tweet], error)
+}
+
+// tweetの検索条件(全ての条件を満たすtweetを検索する、空の条件は使用しない)
+// Terms: 内容に含まれる単語/フレーズ(SearchIndexで検索)
+// FromUsername: 投稿者のusername(大文字小文字を区別しない)
+// Since: この日時以降、Until: この日時より前に作成されたtweet
+// Types: tweetの種類(いずれかに一致)、Hashtags: 含まれるhashtag(正規化済み)
+type TweetSearchCondition struct {
+	Terms        []string
+	FromUsername string
+	Since        *time.Time
+	Until        *time.Time
+	Types        []models.TweetType
+	Hashtags     []string
+}
+
+type SearchRepository struct {
+	DB    *gorm.DB
+	Index search.SearchIndex
+}
+
+func NewSearchRepository(db *gorm.DB, index search.SearchIndex) ISearchRepository {
+	return &SearchRepository{DB: db, Index: index}
+}
+
+// conditionに一致するtweetを新しい順に取得
+// retweetは内容を持たないので検索対象にしない
+func (r *SearchRepository) SearchTweets(condition *TweetSearchCondition, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	var tweets []*models.Tweet
+
+	query := r.DB.Where("tweets.type <> ?", models.Retweet).Scopes(r.Index.Match(condition.Terms))
+	if condition.FromUsername != "" {
+		query = query.Where("tweets.user_id IN (?)", r.DB.Model(&models.User{}).Select("id").Where("username = ?", condition.FromUsername))
+	}
+	if condition.Since != nil {
+		query = query.Where("tweets.created_at >= ?", *condition.Since)
+	}
+	if condition.Until != nil {
+		query = query.Where("tweets.created_at < ?", *condition.Until)
+	}
+	if len(condition.Types) > 0 {
+		query = query.Where("tweets.type IN ?", condition.Types)
+	}
+	for _, name := range condition.Hashtags {
+		query = query.Where("tweets.id IN (?)", r.DB.Model(&models.TweetHashtag{}).
+			Select("tweet_hashtags.tweet_id").
+			Joins("JOIN hashtags ON hashtags.id = tweet_hashtags.hashtag_id").
+			Where("hashtags.name = ?", name))
+	}
+
+	result := query.Scopes(preloadTweetRelations, page.Scope("tweets")).Find(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return pagination.NewList(tweets, page, TweetCursor), nil
+}
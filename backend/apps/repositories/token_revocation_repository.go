@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// アクセストークンの失効リスト
+// JwtTokenVerifierがjtiとユーザー単位の失効を確認するために使用する
+type ITokenRevocationRepository interface {
+	RevokeToken(tokenId string, userId uint, expiresAt time.Time) error
+	RevokeUserTokens(userId uint, revokedBefore time.Time, expiresAt time.Time) error
+	IsRevoked(tokenId string, userId uint, issuedAt time.Time) (bool, error)
+	PurgeExpired(now time.Time) (int64, error)
+}
+
+// DBを使用する失効リスト
+type TokenRevocationRepository struct {
+	DB *gorm.DB
+}
+
+func NewTokenRevocationRepository(db *gorm.DB) ITokenRevocationRepository {
+	return &TokenRevocationRepository{DB: db}
+}
+
+func (r *TokenRevocationRepository) RevokeToken(tokenId string, userId uint, expiresAt time.Time) error {
+	revokedToken := &models.RevokedToken{
+		TokenID:   tokenId,
+		UserID:    userId,
+		ExpiresAt: expiresAt.UTC(),
+	}
+
+	// 既に失効済みの場合は何もしない
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(revokedToken)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *TokenRevocationRepository) RevokeUserTokens(userId uint, revokedBefore time.Time, expiresAt time.Time) error {
+	revocation := &models.UserTokenRevocation{
+		UserID:        userId,
+		RevokedBefore: revokedBefore.UTC(),
+		ExpiresAt:     expiresAt.UTC(),
+	}
+
+	// ユーザーごとに1件だけ保持し、既にある場合は日時を更新する
+	result := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revoked_before", "expires_at", "updated_at"}),
+	}).Create(revocation)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *TokenRevocationRepository) IsRevoked(tokenId string, userId uint, issuedAt time.Time) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.RevokedToken{}).Where("token_id = ?", tokenId).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	var revocation models.UserTokenRevocation
+	result = r.DB.First(&revocation, "user_id = ?", userId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return false, nil
+	} else if result.Error != nil {
+		return false, result.Error
+	}
+
+	return !issuedAt.After(revocation.RevokedBefore), nil
+}
+
+// 有効期限を過ぎた失効情報を削除
+func (r *TokenRevocationRepository) PurgeExpired(now time.Time) (int64, error) {
+	var purged int64
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at < ?", now.UTC()).Delete(&models.RevokedToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged += result.RowsAffected
+
+		result = tx.Where("expires_at < ?", now.UTC()).Delete(&models.UserTokenRevocation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged += result.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// メモリ上の失効リスト(テストや単一インスタンスでの利用向け)
+type InMemoryTokenRevocationRepository struct {
+	mu              sync.RWMutex
+	revokedTokens   map[string]time.Time                // jti -> expiresAt
+	userRevocations map[uint]models.UserTokenRevocation // userId -> revocation
+}
+
+func NewInMemoryTokenRevocationRepository() ITokenRevocationRepository {
+	return &InMemoryTokenRevocationRepository{
+		revokedTokens:   map[string]time.Time{},
+		userRevocations: map[uint]models.UserTokenRevocation{},
+	}
+}
+
+func (r *InMemoryTokenRevocationRepository) RevokeToken(tokenId string, userId uint, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokedTokens[tokenId] = expiresAt
+	return nil
+}
+
+func (r *InMemoryTokenRevocationRepository) RevokeUserTokens(userId uint, revokedBefore time.Time, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.userRevocations[userId] = models.UserTokenRevocation{
+		UserID:        userId,
+		RevokedBefore: revokedBefore,
+		ExpiresAt:     expiresAt,
+	}
+	return nil
+}
+
+func (r *InMemoryTokenRevocationRepository) IsRevoked(tokenId string, userId uint, issuedAt time.Time) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.revokedTokens[tokenId]; ok {
+		return true, nil
+	}
+
+	revocation, ok := r.userRevocations[userId]
+	if !ok {
+		return false, nil
+	}
+
+	return !issuedAt.After(revocation.RevokedBefore), nil
+}
+
+func (r *InMemoryTokenRevocationRepository) PurgeExpired(now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for tokenId, expiresAt := range r.revokedTokens {
+		if expiresAt.Before(now) {
+			delete(r.revokedTokens, tokenId)
+			purged++
+		}
+	}
+	for userId, revocation := range r.userRevocations {
+		if revocation.ExpiresAt.Before(now) {
+			delete(r.userRevocations, userId)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// intervalごとに有効期限を過ぎた失効情報を削除する
+// 返り値の関数を呼ぶと停止する
+func StartTokenRevocationPurger(repository ITokenRevocationRepository, interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				if _, err := repository.PurgeExpired(now); err != nil {
+					log.Println("failed to purge expired token revocations: ", err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
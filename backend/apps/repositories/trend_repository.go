@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITrendRepository interface {
+	AddCounts(slotStart time.Time, counts map[string]int64) error
+	SumCounts(from, to time.Time) (map[string]int64, error)
+	DeleteBucketsBefore(before time.Time) (int64, error)
+}
+
+type TrendRepository struct {
+	DB *gorm.DB
+}
+
+func NewTrendRepository(db *gorm.DB) ITrendRepository {
+	return &TrendRepository{DB: db}
+}
+
+// slotStartの時間枠の各単語の出現数にcountsを加算する
+func (r *TrendRepository) AddCounts(slotStart time.Time, counts map[string]int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for term, count := range counts {
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "term"}, {Name: "slot_start"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("trend_buckets.count + ?", count)}),
+			}).Create(&models.TrendBucket{Term: term, SlotStart: slotStart, Count: count})
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+
+		return nil
+	})
+}
+
+// from以上to未満の時間枠の各単語の出現数の合計を取得
+func (r *TrendRepository) SumCounts(from, to time.Time) (map[string]int64, error) {
+	var rows []struct {
+		Term  string
+		Count int64
+	}
+
+	result := r.DB.Model(&models.TrendBucket{}).
+		Select("term, SUM(count) AS count").
+		Where("slot_start >= ? AND slot_start < ?", from, to).
+		Group("term").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Term] = row.Count
+	}
+
+	return counts, nil
+}
+
+// before以前の時間枠を削除して削除した件数を返す
+func (r *TrendRepository) DeleteBucketsBefore(before time.Time) (int64, error) {
+	result := r.DB.Where("slot_start < ?", before).Delete(&models.TrendBucket{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -2,17 +2,28 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ITweetRepository interface {
 	CreateTweet(tweet *models.Tweet) (*models.Tweet, error)
 	GetTweet(id uint) (*models.Tweet, error)
-	GetUserTweets(userId uint) ([]*models.Tweet, error)
+	GetUserTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error)
 	UpdateTweet(updateTweet *models.Tweet) (*models.Tweet, error)
 	DeleteTweet(id uint) error
+	GetReplyPath(id uint) ([]*models.Tweet, error)
+	GetReplies(tweetId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error)
+	GetRepliesOf(tweetIds []uint, limit int) ([]*models.Tweet, error)
+	DeleteRetweet(userId, tweetId uint) error
+	CountRetweets(tweetIds []uint) (map[uint]int64, error)
+	CountQuotes(tweetIds []uint) (map[uint]int64, error)
+	GetHashtagTweets(name string, page *pagination.Page) (*pagination.List[*models.Tweet], error)
+	GetMentionTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error)
 }
 
 type TweetRepository struct {
@@ -23,10 +34,28 @@ func NewTweetRepository(db *gorm.DB) ITweetRepository {
 	return &TweetRepository{DB: db}
 }
 
+// tweetを作成し、tweet.Mediaの画像/動画を添付してtweet.Hashtagsのhashtagとtweet.Mentionsのmentionを登録する
 func (r *TweetRepository) CreateTweet(tweet *models.Tweet) (*models.Tweet, error) {
-	result := r.DB.Create(tweet)
-	if result.Error != nil {
-		return nil, result.Error
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if result := tx.Omit("Media", "Hashtags", "Mentions").Create(tweet); result.Error != nil {
+			return result.Error
+		}
+
+		if err := attachMedia(tx, tweet); err != nil {
+			return err
+		}
+
+		if err := syncHashtags(tx, tweet); err != nil {
+			return err
+		}
+
+		return syncMentions(tx, tweet)
+	})
+	// tweetsのunique制約は(user_id, retweeted_tweet_id)のみ
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, errors.New("already retweeted")
+	} else if err != nil {
+		return nil, err
 	}
 
 	return tweet, nil
@@ -35,7 +64,7 @@ func (r *TweetRepository) CreateTweet(tweet *models.Tweet) (*models.Tweet, error
 func (r *TweetRepository) GetTweet(id uint) (*models.Tweet, error) {
 	var tweet models.Tweet
 
-	result := r.DB.First(&tweet, "id = ?", id)
+	result := r.DB.Scopes(preloadTweetRelations).First(&tweet, "id = ?", id)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, errors.New("tweet not found")
 	} else if result.Error != nil {
@@ -45,35 +74,335 @@ func (r *TweetRepository) GetTweet(id uint) (*models.Tweet, error) {
 	return &tweet, nil
 }
 
-func (r *TweetRepository) GetUserTweets(userId uint) ([]*models.Tweet, error) {
+func (r *TweetRepository) GetUserTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
 	var tweets []*models.Tweet
 
-	result := r.DB.Where("user_id = ?", userId).Find(&tweets)
+	result := r.DB.Where("user_id = ?", userId).Scopes(preloadTweetRelations, page.Scope("tweets")).Find(&tweets)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, errors.New("tweets not found")
 	} else if result.Error != nil {
 		return nil, result.Error
 	}
 
-	return tweets, nil
+	return pagination.NewList(tweets, page, TweetCursor), nil
 }
 
+// tweetを更新してtweet_hashtags、tweet_mentionsをupdateTweet.Hashtags、updateTweet.Mentionsと同期する
 func (r *TweetRepository) UpdateTweet(updateTweet *models.Tweet) (*models.Tweet, error) {
-	result := r.DB.Save(updateTweet)
-	if result.Error != nil {
-		return nil, result.Error
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// preloadした画像/動画、retweet/quoteの元のTweetは保存しない
+		if result := tx.Omit(clause.Associations).Save(updateTweet); result.Error != nil {
+			return result.Error
+		}
+
+		if err := syncHashtags(tx, updateTweet); err != nil {
+			return err
+		}
+
+		return syncMentions(tx, updateTweet)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return updateTweet, nil
 }
 
+// idのtweetの内容を消してtombstoneにする(返信のスレッドが壊れないように行自体は削除しない)
+// 内容と一緒にhashtag、mentionも消す
 func (r *TweetRepository) DeleteTweet(id uint) error {
-	result := r.DB.Delete(&models.Tweet{}, "id = ?", id)
-	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return errors.New("tweet not found")
-	} else if result.Error != nil {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Tweet{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"content":    "",
+			"deleted_at": time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return errors.New("tweet not found")
+		}
+
+		if result := tx.Where("tweet_id = ?", id).Delete(&models.TweetHashtag{}); result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Where("tweet_id = ?", id).Delete(&models.TweetMention{}).Error
+	})
+}
+
+// idのtweetから返信先をたどり、conversationの最初のtweet(root)からidのtweetまでを削除済み(tombstone)を含めて順に取得
+func (r *TweetRepository) GetReplyPath(id uint) ([]*models.Tweet, error) {
+	var tweets []*models.Tweet
+
+	// 返信先は返信より先に作成されているので、idの順に並べるとrootからの順になる
+	result := r.DB.Unscoped().
+		Where(`id IN (
+			WITH RECURSIVE reply_path (id, in_reply_to_tweet_id) AS (
+				SELECT id, in_reply_to_tweet_id FROM tweets WHERE id = ?
+				UNION ALL
+				SELECT tweets.id, tweets.in_reply_to_tweet_id FROM tweets JOIN reply_path ON tweets.id = reply_path.in_reply_to_tweet_id
+			)
+			SELECT id FROM reply_path
+		)`, id).
+		Scopes(preloadThreadRelations).
+		Order("id").
+		Find(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if len(tweets) == 0 {
+		return nil, errors.New("tweet not found")
+	}
+
+	return tweets, nil
+}
+
+// tweetIdのtweetへの返信を削除済み(tombstone)を含めて新しい順に取得
+func (r *TweetRepository) GetReplies(tweetId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	var tweets []*models.Tweet
+
+	result := r.DB.Unscoped().Where("in_reply_to_tweet_id = ?", tweetId).Scopes(preloadThreadRelations, page.Scope("tweets")).Find(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return pagination.NewList(tweets, page, TweetCursor), nil
+}
+
+// tweetIdsの各tweetへの返信を削除済み(tombstone)を含めて新しい順にlimit件ずつ取得
+func (r *TweetRepository) GetRepliesOf(tweetIds []uint, limit int) ([]*models.Tweet, error) {
+	var tweets []*models.Tweet
+
+	// 返信の多いtweetがあっても取得する件数が増えすぎないように、返信先ごとに新しい順の順位をつける
+	rankedReplies := r.DB.Unscoped().
+		Model(&models.Tweet{}).
+		Select("id, ROW_NUMBER() OVER (PARTITION BY in_reply_to_tweet_id ORDER BY created_at DESC, id DESC) AS reply_rank").
+		Where("in_reply_to_tweet_id IN ?", tweetIds)
+
+	result := r.DB.Unscoped().
+		Where("id IN (?)", r.DB.Table("(?) AS ranked_replies", rankedReplies).Select("id").Where("reply_rank <= ?", limit)).
+		Scopes(preloadThreadRelations).
+		Order("created_at DESC").
+		Order("id DESC").
+		Find(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return tweets, nil
+}
+
+// userIdのユーザーのtweetIdのtweetのretweetを削除(retweetはtombstoneを残さずに削除する)
+func (r *TweetRepository) DeleteRetweet(userId, tweetId uint) error {
+	result := r.DB.Unscoped().
+		Where("user_id = ? AND retweeted_tweet_id = ?", userId, tweetId).
+		Delete(&models.Tweet{})
+	if result.Error != nil {
 		return result.Error
+	} else if result.RowsAffected == 0 {
+		return errors.New("retweet not found")
 	}
 
 	return nil
 }
+
+// tweetIdsの各tweetのretweet数を取得
+func (r *TweetRepository) CountRetweets(tweetIds []uint) (map[uint]int64, error) {
+	return r.countByColumn("retweeted_tweet_id", tweetIds)
+}
+
+// tweetIdsの各tweetのquote数を取得
+func (r *TweetRepository) CountQuotes(tweetIds []uint) (map[uint]int64, error) {
+	return r.countByColumn("quoted_tweet_id", tweetIds)
+}
+
+// nameのhashtagを含むtweetを新しい順に取得
+func (r *TweetRepository) GetHashtagTweets(name string, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	var tweets []*models.Tweet
+
+	result := r.DB.
+		Joins("JOIN tweet_hashtags ON tweet_hashtags.tweet_id = tweets.id").
+		Joins("JOIN hashtags ON hashtags.id = tweet_hashtags.hashtag_id").
+		Where("hashtags.name = ?", name).
+		Scopes(preloadTweetRelations, page.Scope("tweets")).
+		Find(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return pagination.NewList(tweets, page, TweetCursor), nil
+}
+
+// userIdのユーザーへのmentionを含むtweetを新しい順に取得
+// 同じtweetで複数回mentionされていても1件として取得する
+func (r *TweetRepository) GetMentionTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	var tweets []*models.Tweet
+
+	result := r.DB.
+		Where("tweets.id IN (?)", r.DB.Model(&models.TweetMention{}).Select("tweet_id").Where("user_id = ?", userId)).
+		Scopes(preloadTweetRelations, page.Scope("tweets")).
+		Find(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return pagination.NewList(tweets, page, TweetCursor), nil
+}
+
+func (r *TweetRepository) countByColumn(column string, tweetIds []uint) (map[uint]int64, error) {
+	var rows []struct {
+		TweetID uint
+		Count   int64
+	}
+
+	result := r.DB.Model(&models.Tweet{}).
+		Select(column+" AS tweet_id, COUNT(*) AS count").
+		Where(column+" IN ?", tweetIds).
+		Group(column).
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[uint]int64, len(tweetIds))
+	for _, row := range rows {
+		counts[row.TweetID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// まだどのtweetにも添付されていないtweet.Mediaをtweetに添付する
+// 他のtweetに先に添付された画像/動画が含まれる場合はtweetの作成ごと取り消す
+func attachMedia(tx *gorm.DB, tweet *models.Tweet) error {
+	if len(tweet.Media) == 0 {
+		return nil
+	}
+
+	mediaIds := make([]uint, 0, len(tweet.Media))
+	for _, media := range tweet.Media {
+		mediaIds = append(mediaIds, media.ID)
+	}
+
+	result := tx.Model(&models.Media{}).
+		Where("id IN ? AND tweet_id IS NULL", mediaIds).
+		Update("tweet_id", tweet.ID)
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected != int64(len(mediaIds)) {
+		return errors.New("media is already attached")
+	}
+
+	for _, media := range tweet.Media {
+		media.TweetID = &tweet.ID
+	}
+
+	return nil
+}
+
+// tweet_hashtagsをtweet.Hashtagsと同じ組み合わせにする
+// まだ登録されていないhashtagは作成し、tweet.Hashtagsを登録済みのhashtagに置き換える
+func syncHashtags(tx *gorm.DB, tweet *models.Tweet) error {
+	if result := tx.Where("tweet_id = ?", tweet.ID).Delete(&models.TweetHashtag{}); result.Error != nil {
+		return result.Error
+	}
+	if len(tweet.Hashtags) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(tweet.Hashtags))
+	newHashtags := make([]*models.Hashtag, 0, len(tweet.Hashtags))
+	for _, hashtag := range tweet.Hashtags {
+		names = append(names, hashtag.Name)
+		newHashtags = append(newHashtags, &models.Hashtag{Name: hashtag.Name})
+	}
+
+	// 他のtweetで登録済みのhashtagはそのまま使う
+	if result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&newHashtags); result.Error != nil {
+		return result.Error
+	}
+
+	var hashtags []*models.Hashtag
+	if result := tx.Where("name IN ?", names).Find(&hashtags); result.Error != nil {
+		return result.Error
+	}
+
+	hashtagsByName := make(map[string]*models.Hashtag, len(hashtags))
+	for _, hashtag := range hashtags {
+		hashtagsByName[hashtag.Name] = hashtag
+	}
+
+	tweetHashtags := make([]*models.TweetHashtag, 0, len(names))
+	tweet.Hashtags = make([]*models.Hashtag, 0, len(names))
+	for _, name := range names {
+		hashtag := hashtagsByName[name]
+		tweetHashtags = append(tweetHashtags, &models.TweetHashtag{TweetID: tweet.ID, HashtagID: hashtag.ID})
+		tweet.Hashtags = append(tweet.Hashtags, hashtag)
+	}
+
+	return tx.Create(&tweetHashtags).Error
+}
+
+// tweet_mentionsをtweet.Mentionsと同じ内容にする
+func syncMentions(tx *gorm.DB, tweet *models.Tweet) error {
+	if result := tx.Where("tweet_id = ?", tweet.ID).Delete(&models.TweetMention{}); result.Error != nil {
+		return result.Error
+	}
+	if len(tweet.Mentions) == 0 {
+		return nil
+	}
+
+	for _, mention := range tweet.Mentions {
+		mention.ID = 0
+		mention.TweetID = tweet.ID
+	}
+
+	return tx.Create(&tweet.Mentions).Error
+}
+
+// 添付された画像/動画、hashtag、mention、retweet/quoteの元のTweetと元の投稿者を一緒に取得するscope
+// 元のTweetが削除されていてもtombstoneとして表示し、投稿者はidと名前、usernameのみ取得する
+func preloadTweetRelations(db *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+
+	return db.
+		Scopes(preloadMedia).
+		Preload("Hashtags").
+		Preload("Mentions", orderMentions).
+		Preload("RetweetedTweet", unscoped).
+		Preload("RetweetedTweet.User", selectPublicUser).
+		Preload("RetweetedTweet.Media", orderMedia).
+		Preload("RetweetedTweet.Mentions", orderMentions).
+		Preload("QuotedTweet", unscoped).
+		Preload("QuotedTweet.User", selectPublicUser).
+		Preload("QuotedTweet.Media", orderMedia).
+		Preload("QuotedTweet.Mentions", orderMentions)
+}
+
+// preloadTweetRelationsに加えてtweetの投稿者も一緒に取得するscope(スレッドのtweet用)
+func preloadThreadRelations(db *gorm.DB) *gorm.DB {
+	return db.Scopes(preloadTweetRelations).Preload("User", selectPublicUser)
+}
+
+// 投稿者はidと名前、usernameのみ取得する
+func selectPublicUser(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "name", "username")
+}
+
+// 添付された画像/動画を添付順に一緒に取得するscope
+func preloadMedia(db *gorm.DB) *gorm.DB {
+	return db.Preload("Media", orderMedia)
+}
+
+func orderMedia(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+// mentionを内容に現れる順に並べる
+func orderMentions(db *gorm.DB) *gorm.DB {
+	return db.Order("start")
+}
+
+// tweetの(created_at, id)からページングのcursorを作成
+func TweetCursor(tweet *models.Tweet) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: tweet.CreatedAt, ID: tweet.ID}
+}
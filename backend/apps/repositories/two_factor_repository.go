@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ITwoFactorRepository interface {
+	FindUserTOTP(userId uint) (*models.UserTOTP, error)
+	SaveUserTOTP(userTOTP *models.UserTOTP) error
+	EnableUserTOTP(userId uint, step int64, recoveryCodeHashes []string) error
+	UpdateTOTPLastUsedStep(userId uint, step int64) error
+	UseRecoveryCode(userId uint, codeHash string) error
+	DeleteUserTOTP(userId uint) error
+}
+
+type TwoFactorRepository struct {
+	DB *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) ITwoFactorRepository {
+	return &TwoFactorRepository{DB: db}
+}
+
+func (r *TwoFactorRepository) FindUserTOTP(userId uint) (*models.UserTOTP, error) {
+	var userTOTP models.UserTOTP
+	result := r.DB.First(&userTOTP, "user_id = ?", userId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("totp not found")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &userTOTP, nil
+}
+
+// TOTPの設定を保存する(確認前に登録し直した場合は置き換える)
+func (r *TwoFactorRepository) SaveUserTOTP(userTOTP *models.UserTOTP) error {
+	result := r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "confirmed_at", "last_used_step", "created_at"}),
+	}).Create(userTOTP)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+// 確認に使用したコードのstepを記録して2段階認証を有効にし、リカバリーコードを置き換える
+// 既に有効になっている場合は"totp already enabled"を返す
+func (r *TwoFactorRepository) EnableUserTOTP(userId uint, step int64, recoveryCodeHashes []string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserTOTP{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userId).
+			Updates(map[string]interface{}{"confirmed_at": time.Now(), "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("totp already enabled")
+		}
+
+		if result := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}); result.Error != nil {
+			return result.Error
+		}
+
+		recoveryCodes := make([]*models.RecoveryCode, len(recoveryCodeHashes))
+		for i, codeHash := range recoveryCodeHashes {
+			recoveryCodes[i] = &models.RecoveryCode{UserID: userId, CodeHash: codeHash}
+		}
+		if result := tx.Create(&recoveryCodes); result.Error != nil {
+			return result.Error
+		}
+
+		return nil
+	})
+}
+
+// 使用したコードのstepを記録する
+// 同じコード(またはそれより前のコード)が使用済みの場合は"totp code already used"を返す
+func (r *TwoFactorRepository) UpdateTOTPLastUsedStep(userId uint, step int64) error {
+	result := r.DB.Model(&models.UserTOTP{}).
+		Where("user_id = ? AND last_used_step < ?", userId, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("totp code already used")
+	}
+
+	return nil
+}
+
+// リカバリーコードを使用済みにする
+// 存在しないか使用済みの場合は"recovery code not found"を返す
+func (r *TwoFactorRepository) UseRecoveryCode(userId uint, codeHash string) error {
+	result := r.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("recovery code not found")
+	}
+
+	return nil
+}
+
+// TOTPの設定とリカバリーコードを削除して2段階認証を無効にする
+func (r *TwoFactorRepository) DeleteUserTOTP(userId uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if result := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}); result.Error != nil {
+			return result.Error
+		}
+
+		if result := tx.Where("user_id = ?", userId).Delete(&models.UserTOTP{}); result.Error != nil {
+			return result.Error
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IUserIdentityRepository interface {
+	CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error
+	CreateUserIdentity(identity *models.UserIdentity) error
+	FindUserIdentity(provider, subject string) (*models.UserIdentity, error)
+	FindUserIdentitiesByUserId(userId uint) ([]*models.UserIdentity, error)
+	UpdateUserIdentityEmail(id uint, email string) error
+	DeleteUserIdentity(userId uint, provider string) error
+}
+
+type UserIdentityRepository struct {
+	DB *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) IUserIdentityRepository {
+	return &UserIdentityRepository{DB: db}
+}
+
+// ユーザーとプロバイダーのアカウントの紐づけを同時に作成する(OAuthでのサインアップ)
+// どちらかの作成に失敗した場合はユーザーも作成しない
+// 同じemailまたはusernameのユーザーが同時に作成された場合は"user already exists"を返す
+func (r *UserIdentityRepository) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if result := tx.Create(user); result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				return errors.New("user already exists")
+			}
+			return result.Error
+		}
+
+		identity.UserID = user.ID
+		if result := tx.Create(identity); result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+				return errors.New("identity already linked")
+			}
+			return result.Error
+		}
+
+		return nil
+	})
+}
+
+// プロバイダーのアカウントをユーザーに紐づける
+// アカウントが紐づけ済みの場合、ユーザーが同じプロバイダーのアカウントを紐づけ済みの場合は"identity already linked"を返す
+func (r *UserIdentityRepository) CreateUserIdentity(identity *models.UserIdentity) error {
+	result := r.DB.Create(identity)
+	if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+		return errors.New("identity already linked")
+	} else if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+func (r *UserIdentityRepository) FindUserIdentity(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	result := r.DB.First(&identity, "provider = ? AND subject = ?", provider, subject)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("user identity not found")
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &identity, nil
+}
+
+// ユーザーが紐づけたプロバイダーのアカウントを紐づけた順に取得
+func (r *UserIdentityRepository) FindUserIdentitiesByUserId(userId uint) ([]*models.UserIdentity, error) {
+	var identities []*models.UserIdentity
+	result := r.DB.Where("user_id = ?", userId).Order("created_at, id").Find(&identities)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return identities, nil
+}
+
+func (r *UserIdentityRepository) UpdateUserIdentityEmail(id uint, email string) error {
+	result := r.DB.Model(&models.UserIdentity{}).Where("id = ?", id).Update("email", email)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	return nil
+}
+
+// プロバイダーのアカウントの紐づけを解除する
+// 紐づけていない場合は"user identity not found"を返す
+// パスワードが設定されておらず、他にプロバイダーのアカウントもない場合はログインできなくなるので"cannot unlink the last login method"を返す
+// 同時に別のプロバイダーの解除が行われても最後の1つが残るように、ユーザーの行をロックしてから確認する
+func (r *UserIdentityRepository) DeleteUserIdentity(userId uint, provider string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "password").First(&user, userId)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return errors.New("user not found")
+		} else if result.Error != nil {
+			return result.Error
+		}
+
+		var identityCount int64
+		if result := tx.Model(&models.UserIdentity{}).Where("user_id = ?", userId).Count(&identityCount); result.Error != nil {
+			return result.Error
+		}
+
+		var identity models.UserIdentity
+		result = tx.First(&identity, "user_id = ? AND provider = ?", userId, provider)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return errors.New("user identity not found")
+		} else if result.Error != nil {
+			return result.Error
+		}
+
+		if user.Password == "" && identityCount <= 1 {
+			return errors.New("cannot unlink the last login method")
+		}
+
+		if result := tx.Delete(&identity); result.Error != nil {
+			return result.Error
+		}
+
+		return nil
+	})
+}
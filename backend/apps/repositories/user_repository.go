@@ -3,14 +3,34 @@ package repositories
 import (
 	"errors"
 	"log"
+	"time"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/pkg/search"
 	"gorm.io/gorm"
 )
 
 type IUserRepository interface {
 	CreateUser(user *models.User) error
 	FindUserByEmail(email string) (*models.User, error)
+	FindUserByUsername(username string) (*models.User, error)
+	FindUsersByUsernames(usernames []string) ([]*models.User, error)
+	FindUserById(id uint) (*models.User, error)
+	SearchUsers(q string, page *pagination.Page) (*pagination.List[*models.User], error)
+	FindUsers(page *pagination.Page) (*pagination.List[*models.User], error)
+	CountUserStats(userIds []uint) (map[uint]*UserStats, error)
+	UpdateUserProfile(user *models.User) (*models.User, error)
+	UpdatePassword(userId uint, hashedPassword string) error
+	MarkEmailVerified(userId uint, email string) error
+	UpdateSuspendedAt(userId uint, suspendedAt *time.Time) error
+}
+
+// ユーザーのフォロワー数、フォロー数、tweet数(削除済みのtweetは含まない)
+type UserStats struct {
+	FollowerCount  int64
+	FollowingCount int64
+	TweetCount     int64
 }
 
 type UserRepository struct {
@@ -46,3 +66,203 @@ func (r *UserRepository) FindUserByEmail(email string) (*models.User, error) {
 
 	return user, nil
 }
+
+// usernameは大文字小文字を区別せずに検索する(usernameカラムのcollationで比較)
+func (r *UserRepository) FindUserByUsername(username string) (*models.User, error) {
+	user := &models.User{}
+	result := r.db.Scopes(preloadProfileImages).First(user, "username = ?", username)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("user not found")
+	}
+
+	if result.Error != nil {
+		log.Println("failed to find user: ", result.Error)
+		return nil, result.Error
+	}
+
+	return user, nil
+}
+
+// usernamesのユーザーをまとめて取得、存在しないusernameは無視する
+// mentionの解決に使用するので、パスワードなどは取得しない
+func (r *UserRepository) FindUsersByUsernames(usernames []string) ([]*models.User, error) {
+	var users []*models.User
+	result := r.db.Select("id", "name", "username").Where("username IN ?", usernames).Find(&users)
+	if result.Error != nil {
+		log.Println("failed to find users: ", result.Error)
+		return nil, result.Error
+	}
+
+	return users, nil
+}
+
+// プロフィール画像、ヘッダー画像も一緒に取得する
+func (r *UserRepository) FindUserById(id uint) (*models.User, error) {
+	user := &models.User{}
+	result := r.db.Scopes(preloadProfileImages).First(user, "id = ?", id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.New("user not found")
+	}
+
+	if result.Error != nil {
+		log.Println("failed to find user: ", result.Error)
+		return nil, result.Error
+	}
+
+	return user, nil
+}
+
+// usernameがqで始まる、または名前にqを含むユーザーを新しい順に取得(大文字小文字を区別しない)
+func (r *UserRepository) SearchUsers(q string, page *pagination.Page) (*pagination.List[*models.User], error) {
+	var users []*models.User
+
+	escaped := search.EscapeLike(q)
+	result := r.db.
+		Scopes(preloadProfileImages).
+		Where("username LIKE ? ESCAPE '!' OR name LIKE ? ESCAPE '!'", escaped+"%", "%"+escaped+"%").
+		Scopes(page.Scope("users")).
+		Find(&users)
+	if result.Error != nil {
+		log.Println("failed to search users: ", result.Error)
+		return nil, result.Error
+	}
+
+	return pagination.NewList(users, page, UserCursor), nil
+}
+
+// 全てのユーザーを新しい順に取得(管理者向けのユーザー一覧)
+func (r *UserRepository) FindUsers(page *pagination.Page) (*pagination.List[*models.User], error) {
+	var users []*models.User
+
+	result := r.db.
+		Scopes(preloadProfileImages).
+		Scopes(page.Scope("users")).
+		Find(&users)
+	if result.Error != nil {
+		log.Println("failed to find users: ", result.Error)
+		return nil, result.Error
+	}
+
+	return pagination.NewList(users, page, UserCursor), nil
+}
+
+// userIdsの各ユーザーのフォロワー数、フォロー数、tweet数を取得
+func (r *UserRepository) CountUserStats(userIds []uint) (map[uint]*UserStats, error) {
+	stats := make(map[uint]*UserStats, len(userIds))
+	for _, userId := range userIds {
+		stats[userId] = &UserStats{}
+	}
+
+	followerCounts, err := r.countByUser(&models.Follower{}, "followee_id", userIds)
+	if err != nil {
+		return nil, err
+	}
+	followingCounts, err := r.countByUser(&models.Follower{}, "follower_id", userIds)
+	if err != nil {
+		return nil, err
+	}
+	tweetCounts, err := r.countByUser(&models.Tweet{}, "user_id", userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, userId := range userIds {
+		stats[userId].FollowerCount = followerCounts[userId]
+		stats[userId].FollowingCount = followingCounts[userId]
+		stats[userId].TweetCount = tweetCounts[userId]
+	}
+
+	return stats, nil
+}
+
+func (r *UserRepository) countByUser(model interface{}, column string, userIds []uint) (map[uint]int64, error) {
+	var rows []struct {
+		UserID uint
+		Count  int64
+	}
+
+	result := r.db.Model(model).
+		Select(column+" AS user_id, COUNT(*) AS count").
+		Where(column+" IN ?", userIds).
+		Group(column).
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[uint]int64, len(userIds))
+	for _, row := range rows {
+		counts[row.UserID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// userのプロフィールの項目(表示名、username、bio、場所、website、プロフィール画像、ヘッダー画像)を更新
+// 空文字列、nilでも削除として保存されるように項目をSelectで指定する
+// 更新後のuserをプロフィール画像、ヘッダー画像と一緒に取得し直して返す
+func (r *UserRepository) UpdateUserProfile(user *models.User) (*models.User, error) {
+	result := r.db.Model(user).
+		Select("name", "username", "bio", "location", "website", "avatar_media_id", "header_media_id").
+		Updates(user)
+	if result.Error != nil {
+		log.Println("failed to update user profile: ", result.Error)
+		return nil, result.Error
+	}
+
+	return r.FindUserById(user.ID)
+}
+
+// hashedPasswordはbcryptでハッシュ化したパスワード
+func (r *UserRepository) UpdatePassword(userId uint, hashedPassword string) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", userId).Update("password", hashedPassword)
+	if result.Error != nil {
+		log.Println("failed to update password: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// userIdのユーザーのemailを認証済みにする
+// 認証メールの送信後にemailが変更されていた場合は更新しない
+func (r *UserRepository) MarkEmailVerified(userId uint, email string) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND email = ?", userId, email).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		log.Println("failed to mark email verified: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// userIdのユーザーのアカウントを停止した日時を更新(nilの場合は停止を解除する)
+func (r *UserRepository) UpdateSuspendedAt(userId uint, suspendedAt *time.Time) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", userId).Update("suspended_at", suspendedAt)
+	if result.Error != nil {
+		log.Println("failed to update suspended_at: ", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func preloadProfileImages(db *gorm.DB) *gorm.DB {
+	return db.Preload("Avatar").Preload("Header")
+}
+
+// userの(created_at, id)からページングのcursorを作成
+func UserCursor(user *models.User) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+)
+
+// /adminからの管理操作
+// 操作の権限(auth.Permission*)はmiddlewares.RequirePermissionで確認するので、ここでは対象のユーザーとの関係のみ確認する
+type IAdminService interface {
+	GetUsers(page *pagination.Page) (*pagination.List[*dtos.AdminUser], error)
+	SuspendUser(actorId, userId uint) (*dtos.AdminUser, error)
+	UnsuspendUser(actorId, userId uint) (*dtos.AdminUser, error)
+	DeleteTweet(actorId, id uint) error
+}
+
+type AdminService struct {
+	userRepository  repositories.IUserRepository
+	tweetRepository repositories.ITweetRepository
+	authService     IAuthService
+	tweetService    ITweetService
+}
+
+func NewAdminService(userRepository repositories.IUserRepository, tweetRepository repositories.ITweetRepository, authService IAuthService, tweetService ITweetService) IAdminService {
+	return &AdminService{
+		userRepository:  userRepository,
+		tweetRepository: tweetRepository,
+		authService:     authService,
+		tweetService:    tweetService,
+	}
+}
+
+// 全てのユーザーをemail、role、停止状態を含めて新しい順に取得
+func (s *AdminService) GetUsers(page *pagination.Page) (*pagination.List[*dtos.AdminUser], error) {
+	users, err := s.userRepository.FindUsers(page)
+	if err != nil {
+		return nil, err
+	}
+
+	adminUsers := make([]*dtos.AdminUser, 0, len(users.Items))
+	for _, user := range users.Items {
+		adminUsers = append(adminUsers, dtos.NewAdminUser(user))
+	}
+
+	return &pagination.List[*dtos.AdminUser]{Items: adminUsers, NextCursor: users.NextCursor}, nil
+}
+
+// actorIdのユーザーがuserIdのアカウントを停止する
+// 停止したアカウントはログインできず、発行済みのトークンも全て失効させる
+// 自分より弱いroleのユーザーのみ停止でき(自分自身、同じroleのユーザーは停止できない)、それ以外は"cannot moderate this user"を返す
+// 既に停止されている場合もトークンの失効は行う(前回の失効に失敗した場合のやり直しのため)
+func (s *AdminService) SuspendUser(actorId, userId uint) (*dtos.AdminUser, error) {
+	user, err := s.findModeratableUser(actorId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.Suspended() {
+		now := time.Now()
+		if err := s.userRepository.UpdateSuspendedAt(userId, &now); err != nil {
+			return nil, err
+		}
+		user.SuspendedAt = &now
+	}
+
+	if err := s.authService.LogoutAll(userId); err != nil {
+		return nil, err
+	}
+
+	return dtos.NewAdminUser(user), nil
+}
+
+// actorIdのユーザーがuserIdのアカウントの停止を解除する(停止と同じく自分より弱いroleのユーザーのみ)
+// 失効させたトークンは戻らないので、ユーザーは再度ログインする
+func (s *AdminService) UnsuspendUser(actorId, userId uint) (*dtos.AdminUser, error) {
+	user, err := s.findModeratableUser(actorId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Suspended() {
+		if err := s.userRepository.UpdateSuspendedAt(userId, nil); err != nil {
+			return nil, err
+		}
+		user.SuspendedAt = nil
+	}
+
+	return dtos.NewAdminUser(user), nil
+}
+
+// actorIdのユーザーがidのtweetを削除する
+// 停止と同じく自分より弱いroleのユーザーのtweetのみ削除でき、それ以外は"cannot moderate this user"を返す
+// (自分のtweetは通常の削除を使用する)
+func (s *AdminService) DeleteTweet(actorId, id uint) error {
+	tweet, err := s.tweetRepository.GetTweet(id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.findModeratableUser(actorId, tweet.UserID); err != nil {
+		return err
+	}
+
+	return s.tweetService.DeleteAnyTweet(id)
+}
+
+// userIdのユーザーを取得し、actorIdのユーザーが管理できるか確認する
+// roleはトークンではなくDBの現在のものを使用する
+func (s *AdminService) findModeratableUser(actorId, userId uint) (*models.User, error) {
+	user, err := s.userRepository.FindUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := s.userRepository.FindUserById(actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !auth.Outranks(actor.Role, user.Role) {
+		return nil, errors.New("cannot moderate this user")
+	}
+
+	return user, nil
+}
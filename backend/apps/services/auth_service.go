@@ -1,35 +1,99 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
+	"net/url"
 	"time"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
 	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/configs"
 	utils "github.com/daiki-kim/tweet-app/backend/pkg"
 	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/mailer"
+	"github.com/daiki-kim/tweet-app/backend/pkg/mention"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type IAuthService interface {
-	SignupUsingOAuth(name, email, dobString string) error
-	Signup(name, email, dobString, password string) error
-	LoginUsingOAuth(email string) (*LoginResponse, error)
-	Login(email, password string) (*LoginResponse, error)
+	SignupUsingOAuth(ticket, name, username, dobString string) (*LoginResponse, error)
+	Signup(name, username, email, dobString, password string) error
+	LoginUsingOAuth(provider, subject, email string) (*LoginResponse, error)
+	Login(email, password, ipAddress string) (*LoginResponse, error)
+	LoginWithTwoFactor(mfaToken, code, ipAddress string) (*LoginResponse, error)
+	RefreshToken(refreshToken string) (*LoginResponse, error)
+	Logout(refreshToken, accessToken string) error
+	LogoutAll(userId uint) error
+	ChangePassword(userId uint, currentPassword, newPassword string) (*LoginResponse, error)
+	ForgotPassword(email string) error
+	ResetPassword(token, newPassword string) error
+	VerifyEmail(token string) error
+	ResendVerificationEmail(userId uint) error
 }
 
+const (
+	// パスワード再設定用のtokenの有効期限
+	PasswordResetTokenExpiration = time.Hour
+
+	// メール認証用のtokenの有効期限
+	EmailVerificationTokenExpiration = 24 * time.Hour
+
+	// 認証メールの再送信の制限
+	// 前回の送信からEmailVerificationResendIntervalが経過するまでは再送信できず、1時間にEmailVerificationResendLimit回まで送信できる
+	EmailVerificationResendInterval = time.Minute
+	EmailVerificationResendLimit    = 5
+)
+
+// 存在しないemailでのログインでもパスワードの比較を行い、応答時間からemailの存在を推測されないようにするためのハッシュ
+const dummyPasswordHash = "$2a$10$J3hikKK9H3VP2aRjtZWQYOe0rz/bkPGjKcYBxn3y.Ku0PB.HbsK3G"
+
 type AuthService struct {
-	repository repositories.IUserRepository
+	repository                       repositories.IUserRepository
+	refreshTokenRepository           repositories.IRefreshTokenRepository
+	tokenRevocationRepository        repositories.ITokenRevocationRepository
+	passwordResetTokenRepository     repositories.IPasswordResetTokenRepository
+	emailVerificationTokenRepository repositories.IEmailVerificationTokenRepository
+	userIdentityRepository           repositories.IUserIdentityRepository
+	twoFactorService                 ITwoFactorService
+	loginAttemptService              ILoginAttemptService
+	mailer                           mailer.Mailer
 }
 
-func NewAuthService(repository repositories.IUserRepository) IAuthService {
-	return &AuthService{repository: repository}
+func NewAuthService(
+	repository repositories.IUserRepository,
+	refreshTokenRepository repositories.IRefreshTokenRepository,
+	tokenRevocationRepository repositories.ITokenRevocationRepository,
+	passwordResetTokenRepository repositories.IPasswordResetTokenRepository,
+	emailVerificationTokenRepository repositories.IEmailVerificationTokenRepository,
+	userIdentityRepository repositories.IUserIdentityRepository,
+	twoFactorService ITwoFactorService,
+	loginAttemptService ILoginAttemptService,
+	mailer mailer.Mailer,
+) IAuthService {
+	return &AuthService{
+		repository:                       repository,
+		refreshTokenRepository:           refreshTokenRepository,
+		tokenRevocationRepository:        tokenRevocationRepository,
+		passwordResetTokenRepository:     passwordResetTokenRepository,
+		emailVerificationTokenRepository: emailVerificationTokenRepository,
+		userIdentityRepository:           userIdentityRepository,
+		twoFactorService:                 twoFactorService,
+		loginAttemptService:              loginAttemptService,
+		mailer:                           mailer,
+	}
 }
 
+// 2段階認証が有効なユーザーのログインではトークンの代わりにMFARequiredとMFATokenを返す
+// MFATokenとTOTPのコード(またはリカバリーコード)をLoginWithTwoFactorで交換してトークンを発行する
 type LoginResponse struct {
-	Token        string `json:"token"`
-	RefreshToken string `json:"refresh_token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
 }
 
 // string型をTime型に変換
@@ -38,7 +102,11 @@ func Str2time(t string) (time.Time, error) {
 }
 
 // OAuth、Normal共通で利用するユーザーモデルを準備
-func PrepareBaseUserModel(name, email, dobString string) (*models.User, error) {
+func PrepareBaseUserModel(name, username, email, dobString string) (*models.User, error) {
+	if !mention.ValidUsername(username) {
+		return nil, errors.New("invalid username")
+	}
+
 	dob, err := Str2time(dobString)
 	if err != nil {
 		log.Println("failed to convert string to time: ", err)
@@ -46,28 +114,65 @@ func PrepareBaseUserModel(name, email, dobString string) (*models.User, error) {
 	}
 
 	user := &models.User{
-		Name:  name,
-		Email: email,
-		Dob:   dob,
+		Name:     name,
+		Username: username,
+		Email:    email,
+		Dob:      dob,
 	}
 
 	return user, nil
 }
 
-// OAuthでGoogleからのユーザーデータを使用してサインアップ
-func (s *AuthService) SignupUsingOAuth(name, email, dobString string) error {
-	user, err := PrepareBaseUserModel(name, email, dobString)
+// OAuthのcallbackで発行したticket(プロバイダーで認証済みのアカウント)を使用してサインアップし、tokenを発行
+// usernameと生年月日はプロバイダーから取得できないのでユーザーが入力する(nameは空の場合にプロバイダーの名前を使用する)
+// emailはプロバイダーで認証済みのもののみticketを発行するので、認証済みとして作成する
+// プロバイダーのアカウント(provider, subject)をユーザーに紐づけて作成し、以降のログインはemailではなく紐づけで行う
+func (s *AuthService) SignupUsingOAuth(ticket, name, username, dobString string) (*LoginResponse, error) {
+	claims, err := auth.ValidateOAuthTicket(ticket, auth.OAuthTicketActionSignup)
+	if err != nil {
+		log.Println("failed to validate oauth ticket: ", err)
+		return nil, errors.New("invalid oauth ticket")
+	}
+	if name == "" {
+		name = claims.Name
+	}
+
+	user, err := PrepareBaseUserModel(name, username, claims.Email, dobString)
 	if err != nil {
 		log.Println("failed to prepare user model: ", err)
-		return err
+		return nil, err
 	}
 
-	return s.repository.CreateUser(user)
+	if _, err := s.repository.FindUserByUsername(user.Username); err == nil {
+		return nil, errors.New("username is already taken")
+	} else if err.Error() != "user not found" {
+		return nil, err
+	}
+	// 同じemailのユーザーがいる場合はそのユーザーでログインしてから紐づける
+	if _, err := s.repository.FindUserByEmail(user.Email); err == nil {
+		return nil, errors.New("email is already taken")
+	} else if err.Error() != "user not found" {
+		return nil, err
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.userIdentityRepository.CreateUserWithIdentity(user, &models.UserIdentity{
+		Provider: claims.Provider,
+		Subject:  claims.ProviderSubject,
+		Email:    claims.Email,
+	}); err != nil {
+		return nil, err
+	}
+
+	// 作成したばかりのユーザーは2段階認証が有効になっていないのでそのままtokenを発行する
+	return s.issueTokens(user)
 }
 
 // ユーザー入力情報を使用するNormalのサインアップ
 // パスワードが必要
-func (s *AuthService) Signup(name, email, dobString, password string) error {
+// ユーザーはemail未認証の状態で作成し、emailに認証メールを送信する
+func (s *AuthService) Signup(name, username, email, dobString, password string) error {
 	// パスワードをハッシュ化
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -75,66 +180,506 @@ func (s *AuthService) Signup(name, email, dobString, password string) error {
 		return err
 	}
 
-	user, err := PrepareBaseUserModel(name, email, dobString)
+	user, err := PrepareBaseUserModel(name, username, email, dobString)
 	if err != nil {
 		log.Println("failed to prepare user model: ", err)
 		return err
 	}
 
 	user.Password = string(hashedPassword)
+	if err := s.createUser(user); err != nil {
+		return err
+	}
+
+	// 送信に失敗してもサインアップは成功しているので、エラーにはせずに再送信してもらう
+	if err := s.sendVerificationEmail(user); err != nil {
+		log.Println("failed to send verification email: ", err)
+	}
+
+	return nil
+}
+
+// usernameが使用済みでないか確認してからユーザーを作成
+// usernameは大文字小文字を区別せずにuniqueなので、"Gopher"がいる場合は"gopher"も使えない
+func (s *AuthService) createUser(user *models.User) error {
+	if _, err := s.repository.FindUserByUsername(user.Username); err == nil {
+		return errors.New("username is already taken")
+	} else if err.Error() != "user not found" {
+		return err
+	}
+
 	return s.repository.CreateUser(user)
 }
 
 // OAuthからのログイン
-// googleから取得したemailを使用してtokenを発行
-func (s *AuthService) LoginUsingOAuth(email string) (*LoginResponse, error) {
+// プロバイダーのアカウント(provider, subject)が紐づけられたユーザーのtokenを発行
+// プロバイダーでemailが変わっていた場合は紐づけのemailを更新する
+// 紐づけがない場合はパスワードのないユーザー(紐づけの導入前にOAuthでサインアップしたユーザー)のみemailで紐づけてログインする
+// パスワードのあるユーザーはemailが一致しても"identity is not linked"を返す(パスワードでログインしてから紐づける)
+func (s *AuthService) LoginUsingOAuth(provider, subject, email string) (*LoginResponse, error) {
+	identity, err := s.userIdentityRepository.FindUserIdentity(provider, subject)
+	if err == nil {
+		if identity.Email != email {
+			if err := s.userIdentityRepository.UpdateUserIdentityEmail(identity.ID, email); err != nil {
+				return nil, err
+			}
+		}
+		user, err := s.repository.FindUserById(identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return s.login(user)
+	}
+	if err.Error() != "user identity not found" {
+		return nil, err
+	}
+
 	// emailからユーザーモデルを取得
 	user, err := s.repository.FindUserByEmail(email)
 	if err != nil {
 		return nil, err
 	}
+	if user.Password != "" {
+		return nil, errors.New("identity is not linked")
+	}
+	identities, err := s.userIdentityRepository.FindUserIdentitiesByUserId(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) > 0 {
+		return nil, errors.New("identity is not linked")
+	}
 
-	userIdString := utils.Uint2String(user.ID)
+	if err := s.userIdentityRepository.CreateUserIdentity(&models.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	}); err != nil {
+		return nil, err
+	}
 
-	// Claim構造体のポインタを生成して、トークンを発行
-	claim := auth.NewClaim(userIdString)
-	token, err := claim.GenerateToken()
+	return s.login(user)
+}
+
+// Normalログイン
+// ユーザーが入力したemailとpasswordを使用してtokenを発行
+// emailが存在しない場合とパスワードが違う場合は区別せずに"invalid email or password"を返す
+// 失敗が続いたアカウント、IPアドレスからのログインは"too many login attempts"を返す
+func (s *AuthService) Login(email, password, ipAddress string) (*LoginResponse, error) {
+	if err := s.loginAttemptService.CheckLogin(email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	// emailからユーザーモデルを取得
+	user, err := s.repository.FindUserByEmail(email)
+	if err != nil && err.Error() != "user not found" {
+		return nil, err
+	}
+
+	// ハッシュ化されたパスワードと入力されたパスワードを比較
+	// ユーザーが存在しない場合もダミーのハッシュと比較して応答時間を揃える
+	passwordHash := dummyPasswordHash
+	if user != nil {
+		passwordHash = user.Password
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)); err != nil || user == nil {
+		if err := s.loginAttemptService.RecordLoginFailure(email, ipAddress); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("invalid email or password")
+	}
+
+	// 失敗回数のリセットはログインが完了した時(2段階認証が有効な場合はコードの確認後)に行う
+	return s.login(user)
+}
+
+// Login、LoginUsingOAuthで返されたmfaトークンとTOTPのコード(またはリカバリーコード)を確認してトークンを発行
+// コードの失敗はパスワードの失敗と同じくアカウントとIPアドレスの失敗回数に数える
+func (s *AuthService) LoginWithTwoFactor(mfaToken, code, ipAddress string) (*LoginResponse, error) {
+	claim, err := auth.ValidateMFAToken(mfaToken)
+	if err != nil {
+		log.Println("failed to validate mfa token: ", err)
+		return nil, errors.New("invalid mfa token")
+	}
+	userId := utils.String2Uint(claim.UserId)
+
+	user, err := s.repository.FindUserById(userId)
 	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errors.New("invalid mfa token")
+		}
 		return nil, err
 	}
 
-	// Claim構造体のポインタを生成して、リフレッシュトークンを発行
-	refreshTokenClaim := auth.NewClaim(userIdString)
-	refreshToken, err := refreshTokenClaim.GenerateRefreshToken()
+	if err := s.loginAttemptService.CheckLogin(user.Email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorService.VerifyCode(userId, code); err != nil {
+		switch err.Error() {
+		case "two-factor authentication is not enabled":
+			return nil, errors.New("invalid mfa token")
+		case "invalid code":
+			if err := s.loginAttemptService.RecordLoginFailure(user.Email, ipAddress); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.loginAttemptService.RecordLoginSuccess(user.Email); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user)
+}
+
+// 認証済みのユーザーのログイン
+// 2段階認証が有効な場合はトークンを発行せずにmfaトークンを返す
+// (パスワードの確認だけで失敗回数をリセットすると、パスワードを知っていればコードを無制限に試せるため、
+// 失敗回数はトークンを発行する時にのみリセットする)
+// 停止されたアカウントは"account is suspended"を返す(パスワード、OAuthの確認後に返すので、停止されていることは本人にしか分からない)
+func (s *AuthService) login(user *models.User) (*LoginResponse, error) {
+	if user.Suspended() {
+		return nil, errors.New("account is suspended")
+	}
+
+	enabled, err := s.twoFactorService.IsEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		if err := s.loginAttemptService.RecordLoginSuccess(user.Email); err != nil {
+			return nil, err
+		}
+		return s.issueTokens(user)
+	}
+
+	mfaToken, err := auth.NewClaim(utils.Uint2String(user.ID)).GenerateMFAToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
+}
+
+// リフレッシュトークンを使用してトークンを再発行
+// 使用したリフレッシュトークンは無効になり、同じfamilyの新しいリフレッシュトークンを発行する(rotation)
+// rotate済みのリフレッシュトークンが再利用された場合は漏洩とみなしてfamily全体を失効させる
+func (s *AuthService) RefreshToken(refreshToken string) (*LoginResponse, error) {
+	claim, storedToken, err := s.findValidRefreshToken(refreshToken)
 	if err != nil {
 		return nil, err
 	}
 
+	// rotate済みのリフレッシュトークンの再利用を検知
+	if err := s.refreshTokenRepository.MarkRefreshTokenUsed(storedToken.TokenID); err != nil {
+		if err.Error() != "refresh token already used" {
+			return nil, err
+		}
+		if err := s.refreshTokenRepository.RevokeFamily(storedToken.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token reuse detected")
+	}
+
+	// roleが変更されていても反映されるように、現在のユーザーのroleでトークンを発行する
+	// 停止されたアカウントのリフレッシュトークンは停止時に失効させているが、念のためここでも確認する
+	user, err := s.repository.FindUserById(storedToken.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, err
+	}
+	if user.Suspended() {
+		return nil, errors.New("account is suspended")
+	}
+
+	// Claim構造体のポインタを生成してトークンを発行
+	tokenClaim := auth.NewClaim(claim.UserId)
+	tokenClaim.SetRole(user.Role)
+	token, err := tokenClaim.GenerateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// 同じfamilyのリフレッシュトークンを発行(有効期限は最初のログイン時のものを引き継ぐ)
+	rotatedRefreshToken, err := claim.UpdateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.saveRefreshToken(storedToken.UserID, claim); err != nil {
+		return nil, err
+	}
+
 	loginResponse := &LoginResponse{
 		Token:        token,
-		RefreshToken: refreshToken,
+		RefreshToken: rotatedRefreshToken,
 	}
 	return loginResponse, nil
 }
 
-// Normalログイン
-// ユーザーが入力したemailとpasswordを使用してtokenを発行
-func (s *AuthService) Login(email, password string) (*LoginResponse, error) {
-	// emailからユーザーモデルを取得
-	user, err := s.repository.FindUserByEmail(email)
+// リフレッシュトークンのfamilyを失効させてログアウト
+// accessTokenが渡された場合は、有効期限までの間も使用できないようにjtiで失効させる
+func (s *AuthService) Logout(refreshToken, accessToken string) error {
+	_, storedToken, err := s.findValidRefreshToken(refreshToken)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	userIdString := utils.Uint2String(user.ID)
+	if err := s.refreshTokenRepository.RevokeFamily(storedToken.FamilyID); err != nil {
+		return err
+	}
 
-	// ハッシュ化されたパスワードと入力されたパスワードを比較
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	if accessToken == "" {
+		return nil
+	}
+
+	// 検証できないアクセストークンは既に使用できないので失効させる必要はない
+	// 他のユーザーのアクセストークンは失効させない
+	accessTokenClaim, err := auth.ValidateAccessToken(accessToken)
+	if err != nil || accessTokenClaim.Subject != auth.Subject || utils.String2Uint(accessTokenClaim.UserId) != storedToken.UserID {
+		return nil
+	}
+
+	return s.tokenRevocationRepository.RevokeToken(accessTokenClaim.ID, storedToken.UserID, accessTokenClaim.ExpiresAt.Time)
+}
+
+// userIdのユーザーの全てのセッションを失効させる
+// 現在までに発行したアクセストークンとリフレッシュトークンが全て使用できなくなる
+func (s *AuthService) LogoutAll(userId uint) error {
+	return s.revokeUserSessions(userId)
+}
+
+// userIdのユーザーの現在までに発行したアクセストークンとリフレッシュトークンを全て失効させる
+// JWTのiatは秒単位なので、この秒より前に発行されたアクセストークンを失効させる
+// (直後のログイン、ChangePasswordなどで同じ秒に発行する新しいトークンまで失効させないため)
+func (s *AuthService) revokeUserSessions(userId uint) error {
+	revokedBefore := time.Now().Truncate(time.Second).Add(-time.Nanosecond)
+	if err := s.tokenRevocationRepository.RevokeUserTokens(userId, revokedBefore, revokedBefore.Add(auth.TokenExpiration)); err != nil {
+		return err
+	}
+
+	return s.refreshTokenRepository.RevokeUserRefreshTokens(userId)
+}
+
+// 現在のパスワードを確認してからパスワードを変更
+// 他のセッションは全て失効させ、このリクエストのユーザーには新しいトークンを発行する
+func (s *AuthService) ChangePassword(userId uint, currentPassword, newPassword string) (*LoginResponse, error) {
+	user, err := s.repository.FindUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
 		return nil, errors.New("invalid password")
 	}
+	if currentPassword == newPassword {
+		return nil, errors.New("new password must be different from the current password")
+	}
+
+	if err := s.updatePassword(userId, newPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.revokeUserSessions(userId); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user)
+}
+
+// パスワード再設定用のtokenを発行してメールで送信
+// 登録されているemailかどうかを知られないよう、ユーザーが存在しない場合もエラーにしない
+func (s *AuthService) ForgotPassword(email string) error {
+	user, err := s.repository.FindUserByEmail(email)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil
+		}
+		return err
+	}
+
+	// 以前に発行したtokenは使用できなくする
+	if err := s.passwordResetTokenRepository.InvalidateUserPasswordResetTokens(user.ID); err != nil {
+		return err
+	}
+
+	token, tokenHash, err := auth.GenerateOneTimeToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwordResetTokenRepository.CreatePasswordResetToken(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(PasswordResetTokenExpiration),
+	}); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(context.Background(), &mailer.Message{
+		To:      user.Email,
+		Subject: "パスワードの再設定",
+		Body: fmt.Sprintf(
+			"%sさん\n\n以下のリンクからパスワードを再設定してください。リンクの有効期限は%d分です。\n%s\n\nこのメールに心当たりがない場合は無視してください。\n",
+			user.Name, int(PasswordResetTokenExpiration.Minutes()), passwordResetURL(token),
+		),
+	})
+}
+
+// パスワード再設定用のtokenを使用してパスワードを変更
+// tokenは1回のみ使用でき、変更後は全てのセッションを失効させる
+func (s *AuthService) ResetPassword(token, newPassword string) error {
+	resetToken, err := s.passwordResetTokenRepository.FindPasswordResetToken(auth.HashOneTimeToken(token))
+	if err != nil {
+		if err.Error() == "password reset token not found" {
+			return errors.New("invalid password reset token")
+		}
+		return err
+	}
+
+	if resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) {
+		return errors.New("invalid password reset token")
+	}
+	if err := s.passwordResetTokenRepository.MarkPasswordResetTokenUsed(resetToken.ID); err != nil {
+		if err.Error() == "password reset token already used" {
+			return errors.New("invalid password reset token")
+		}
+		return err
+	}
+
+	if err := s.updatePassword(resetToken.UserID, newPassword); err != nil {
+		return err
+	}
+
+	return s.LogoutAll(resetToken.UserID)
+}
+
+// メール認証用のtokenを使用してemailを認証済みにする
+// tokenは1回のみ使用でき、送信後にemailが変更されていた場合は使用できない
+func (s *AuthService) VerifyEmail(token string) error {
+	verificationToken, err := s.emailVerificationTokenRepository.FindEmailVerificationToken(auth.HashOneTimeToken(token))
+	if err != nil {
+		if err.Error() == "email verification token not found" {
+			return errors.New("invalid email verification token")
+		}
+		return err
+	}
+
+	if verificationToken.UsedAt != nil || time.Now().After(verificationToken.ExpiresAt) {
+		return errors.New("invalid email verification token")
+	}
+	if err := s.emailVerificationTokenRepository.MarkEmailVerificationTokenUsed(verificationToken.ID); err != nil {
+		if err.Error() == "email verification token already used" {
+			return errors.New("invalid email verification token")
+		}
+		return err
+	}
+
+	if err := s.repository.MarkEmailVerified(verificationToken.UserID, verificationToken.Email); err != nil {
+		if err.Error() == "user not found" {
+			return errors.New("invalid email verification token")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// userIdのユーザーに認証メールを再送信する
+// 認証済みの場合と、送信回数の制限(EmailVerificationResendInterval、EmailVerificationResendLimit)を超えた場合はエラー
+func (s *AuthService) ResendVerificationEmail(userId uint) error {
+	user, err := s.repository.FindUserById(userId)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified() {
+		return errors.New("email is already verified")
+	}
+
+	now := time.Now()
+	recentCount, err := s.emailVerificationTokenRepository.CountEmailVerificationTokensSince(userId, now.Add(-EmailVerificationResendInterval))
+	if err != nil {
+		return err
+	}
+	hourlyCount, err := s.emailVerificationTokenRepository.CountEmailVerificationTokensSince(userId, now.Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+	if recentCount > 0 || hourlyCount >= EmailVerificationResendLimit {
+		return errors.New("verification email was sent recently")
+	}
+
+	return s.sendVerificationEmail(user)
+}
+
+// メール認証用のtokenを発行してuserのemailに送信
+func (s *AuthService) sendVerificationEmail(user *models.User) error {
+	token, tokenHash, err := auth.GenerateOneTimeToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.emailVerificationTokenRepository.CreateEmailVerificationToken(&models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(EmailVerificationTokenExpiration),
+	}); err != nil {
+		return err
+	}
+
+	return s.mailer.Send(context.Background(), &mailer.Message{
+		To:      user.Email,
+		Subject: "メールアドレスの確認",
+		Body: fmt.Sprintf(
+			"%sさん\n\n以下のリンクからメールアドレスを確認してください。リンクの有効期限は%d時間です。\n%s\n\nこのメールに心当たりがない場合は無視してください。\n",
+			user.Name, int(EmailVerificationTokenExpiration.Hours()), emailVerificationURL(token),
+		),
+	})
+}
+
+// パスワードをハッシュ化して保存し、未使用のパスワード再設定用のtokenを使用できなくする
+func (s *AuthService) updatePassword(userId uint, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("failed to hash password: ", err)
+		return err
+	}
+
+	if err := s.repository.UpdatePassword(userId, string(hashedPassword)); err != nil {
+		return err
+	}
+
+	return s.passwordResetTokenRepository.InvalidateUserPasswordResetTokens(userId)
+}
+
+// パスワード再設定画面のURL(PASSWORD_RESET_URL)にtokenを付けたもの
+func passwordResetURL(token string) string {
+	return configs.GetEnvDefault("PASSWORD_RESET_URL", "http://localhost:8001/password/reset") + "?token=" + url.QueryEscape(token)
+}
+
+// メール認証用のURL(EMAIL_VERIFICATION_URL)にtokenを付けたもの
+func emailVerificationURL(token string) string {
+	return configs.GetEnvDefault("EMAIL_VERIFICATION_URL", "http://localhost:8080/api/v1/verify-email") + "?token=" + url.QueryEscape(token)
+}
+
+// userのトークンと新しいfamilyのリフレッシュトークンを発行
+// アクセストークンにはuserのroleと権限を含める
+func (s *AuthService) issueTokens(user *models.User) (*LoginResponse, error) {
+	if user.Suspended() {
+		return nil, errors.New("account is suspended")
+	}
+	userId := user.ID
+	userIdString := utils.Uint2String(userId)
 
 	// Claim構造体のポインタを生成してトークンを発行
 	claim := auth.NewClaim(userIdString)
+	claim.SetRole(user.Role)
 	token, err := claim.GenerateToken()
 	if err != nil {
 		return nil, err
@@ -142,14 +687,53 @@ func (s *AuthService) Login(email, password string) (*LoginResponse, error) {
 
 	// Claim構造体のポインタを生成してリフレッシュトークンを発行
 	refreshTokenClaim := auth.NewClaim(userIdString)
+	refreshTokenClaim.FamilyId = uuid.New().String()
 	refreshToken, err := refreshTokenClaim.GenerateRefreshToken()
 	if err != nil {
 		return nil, err
 	}
 
+	if err := s.saveRefreshToken(userId, refreshTokenClaim); err != nil {
+		return nil, err
+	}
+
 	loginResponse := &LoginResponse{
 		Token:        token,
 		RefreshToken: refreshToken,
 	}
 	return loginResponse, nil
 }
+
+// 発行したリフレッシュトークンを保存
+func (s *AuthService) saveRefreshToken(userId uint, claim *auth.CustomClaim) error {
+	return s.refreshTokenRepository.CreateRefreshToken(&models.RefreshToken{
+		UserID:    userId,
+		TokenID:   claim.ID,
+		FamilyID:  claim.FamilyId,
+		ExpiresAt: claim.ExpiresAt.Time,
+	})
+}
+
+// リフレッシュトークンを検証して保存されているリフレッシュトークンを取得
+// 署名が不正、期限切れ、未発行、失効済みの場合は"invalid refresh token"を返す
+func (s *AuthService) findValidRefreshToken(refreshToken string) (*auth.CustomClaim, *models.RefreshToken, error) {
+	claim, err := auth.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		log.Println("failed to validate refresh token: ", err)
+		return nil, nil, errors.New("invalid refresh token")
+	}
+
+	storedToken, err := s.refreshTokenRepository.FindRefreshToken(claim.ID)
+	if err != nil {
+		if err.Error() == "refresh token not found" {
+			return nil, nil, errors.New("invalid refresh token")
+		}
+		return nil, nil, err
+	}
+
+	if storedToken.RevokedAt != nil || utils.Uint2String(storedToken.UserID) != claim.UserId {
+		return nil, nil, errors.New("invalid refresh token")
+	}
+
+	return claim, storedToken, nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+)
+
+type IFeedService interface {
+	DistributeTweet(tweet *models.Tweet) error
+	AddFolloweeTweets(userId, followeeId uint) error
+	RemoveFolloweeTweets(userId, followeeId uint) error
+	GetTimeline(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error)
+}
+
+// フォローした時にFeedに追加するフォロー先の過去のTweetの数
+const FolloweeBackfillLimit = 100
+
+type FeedService struct {
+	repository         repositories.IFeedRepository
+	followerRepository repositories.IFollowerRepository
+	tweetRepository    repositories.ITweetRepository
+}
+
+func NewFeedService(
+	repository repositories.IFeedRepository,
+	followerRepository repositories.IFollowerRepository,
+	tweetRepository repositories.ITweetRepository,
+) IFeedService {
+	return &FeedService{repository: repository, followerRepository: followerRepository, tweetRepository: tweetRepository}
+}
+
+// 作成されたTweetを投稿者本人とそのフォロワー全員のFeedに配信する
+func (s *FeedService) DistributeTweet(tweet *models.Tweet) error {
+	userIds := []uint{tweet.UserID}
+
+	// フォロワーを全ページ分取得
+	page := &pagination.Page{Limit: pagination.MaxLimit}
+	for {
+		followers, err := s.followerRepository.GetFollowers(tweet.UserID, page)
+		if err != nil {
+			return err
+		}
+
+		for _, follower := range followers.Items {
+			// 投稿者本人は既に含まれているので除く
+			if follower.FollowerID == tweet.UserID {
+				continue
+			}
+			userIds = append(userIds, follower.FollowerID)
+		}
+
+		if followers.NextCursor == nil {
+			break
+		}
+		page = &pagination.Page{Limit: pagination.MaxLimit, Cursor: followers.NextCursor}
+	}
+
+	return s.repository.AddTweetToFeeds(tweet.ID, userIds)
+}
+
+// userIdのユーザーがfolloweeIdのユーザーをフォローした時に、followeeIdのユーザーの最新のTweetをFeedに追加する
+func (s *FeedService) AddFolloweeTweets(userId, followeeId uint) error {
+	return s.repository.AddUserTweetsToFeed(userId, followeeId, FolloweeBackfillLimit)
+}
+
+// userIdのユーザーがfolloweeIdのユーザーのフォローを解除した時に、followeeIdのユーザーのTweetをFeedから削除する
+func (s *FeedService) RemoveFolloweeTweets(userId, followeeId uint) error {
+	return s.repository.RemoveUserTweetsFromFeed(userId, followeeId)
+}
+
+// userIdのユーザーのタイムラインを取得
+// Feedには自分とフォローしているユーザーのTweetのみが入っている(フォロー時に追加、フォロー解除時に削除される)
+// retweet/quoteは元のTweetと元の投稿者を含めて返す
+func (s *FeedService) GetTimeline(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	tweets, err := s.repository.GetFeedTweets(userId, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setShareCounts(s.tweetRepository, tweets.Items); err != nil {
+		return nil, err
+	}
+
+	return tweets, nil
+}
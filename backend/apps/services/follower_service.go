@@ -2,46 +2,64 @@ package services
 
 import (
 	"errors"
+	"log"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
 	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 )
 
 type IFollowerService interface {
 	Follow(followerId, followeeId uint) (*models.Follower, error)
 	GetFollower(id uint) (*models.Follower, error)
-	GetFollows(followerId uint) ([]*models.Follower, error)
-	GetFollowers(followeeId uint) ([]*models.Follower, error)
+	GetFollows(followerId uint, page *pagination.Page) (*pagination.List[*models.Follower], error)
+	GetFollowers(followeeId uint, page *pagination.Page) (*pagination.List[*models.Follower], error)
 	DeleteFollower(id uint, user_id uint) error
 }
 
 type FollowerService struct {
-	repository repositories.IFollowerRepository
+	repository  repositories.IFollowerRepository
+	feedService IFeedService
 }
 
-func NewFollowerService(repository repositories.IFollowerRepository) IFollowerService {
-	return &FollowerService{repository: repository}
+func NewFollowerService(repository repositories.IFollowerRepository, feedService IFeedService) IFollowerService {
+	return &FollowerService{repository: repository, feedService: feedService}
 }
 
 func (s *FollowerService) Follow(followerId, followeeId uint) (*models.Follower, error) {
+	// 自分自身はフォローできない
+	if followerId == followeeId {
+		return nil, errors.New("cannot follow yourself")
+	}
+
 	follower := &models.Follower{
 		FollowerID: followerId,
 		FolloweeID: followeeId,
 	}
 
-	return s.repository.CreateFollower(follower)
+	createdFollower, err := s.repository.CreateFollower(follower)
+	if err != nil {
+		return nil, err
+	}
+
+	// フォロー先の過去のTweetをFeedに追加(追加に失敗してもフォロー自体は成功とする)
+	if err := s.feedService.AddFolloweeTweets(followerId, followeeId); err != nil {
+		log.Println("failed to add followee tweets to feed: ", err)
+	}
+
+	return createdFollower, nil
 }
 
 func (s *FollowerService) GetFollower(id uint) (*models.Follower, error) {
 	return s.repository.GetFollower(id)
 }
 
-func (s *FollowerService) GetFollows(followerId uint) ([]*models.Follower, error) {
-	return s.repository.GetFollowees(followerId)
+func (s *FollowerService) GetFollows(followerId uint, page *pagination.Page) (*pagination.List[*models.Follower], error) {
+	return s.repository.GetFollowees(followerId, page)
 }
 
-func (s *FollowerService) GetFollowers(followeeId uint) ([]*models.Follower, error) {
-	return s.repository.GetFollowers(followeeId)
+func (s *FollowerService) GetFollowers(followeeId uint, page *pagination.Page) (*pagination.List[*models.Follower], error) {
+	return s.repository.GetFollowers(followeeId, page)
 }
 
 func (s *FollowerService) DeleteFollower(id uint, user_id uint) error {
@@ -50,9 +68,15 @@ func (s *FollowerService) DeleteFollower(id uint, user_id uint) error {
 		return err
 	}
 
-	if follower.FollowerID != user_id {
+	if !follower.IsOwnedBy(user_id) {
 		return errors.New("you don't have permission to delete this follower")
 	}
 
+	// フォロー解除したユーザーのTweetがタイムラインに残らないように、先にFeedから削除する
+	// (削除に失敗した場合はフォローを残し、再度フォロー解除できるようにする)
+	if err := s.feedService.RemoveFolloweeTweets(follower.FollowerID, follower.FolloweeID); err != nil {
+		return err
+	}
+
 	return s.repository.DeleteFollower(id)
 }
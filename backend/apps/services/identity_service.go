@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+)
+
+type IIdentityService interface {
+	GetIdentities(userId uint) ([]*models.UserIdentity, error)
+	LinkIdentity(userId uint, ticket string) (*models.UserIdentity, error)
+	UnlinkIdentity(userId uint, provider string) error
+}
+
+type IdentityService struct {
+	repository repositories.IUserIdentityRepository
+}
+
+func NewIdentityService(repository repositories.IUserIdentityRepository) IIdentityService {
+	return &IdentityService{repository: repository}
+}
+
+// ログイン中のユーザーが紐づけたプロバイダーのアカウントを取得
+func (s *IdentityService) GetIdentities(userId uint) ([]*models.UserIdentity, error) {
+	return s.repository.FindUserIdentitiesByUserId(userId)
+}
+
+// OAuthのcallback(action=link)で発行したticketのプロバイダーのアカウントをログイン中のユーザーに紐づける
+// 他のユーザーに紐づけ済みのアカウントは"identity is linked to another user"、
+// 同じプロバイダーの別のアカウントを紐づけ済みの場合は"provider is already linked"を返す
+// 既にこのユーザーに紐づけ済みのアカウントの場合はそのまま返す
+func (s *IdentityService) LinkIdentity(userId uint, ticket string) (*models.UserIdentity, error) {
+	claims, err := auth.ValidateOAuthTicket(ticket, auth.OAuthTicketActionLink)
+	if err != nil {
+		log.Println("failed to validate oauth ticket: ", err)
+		return nil, errors.New("invalid oauth ticket")
+	}
+
+	identity, err := s.repository.FindUserIdentity(claims.Provider, claims.ProviderSubject)
+	if err == nil {
+		if identity.UserID != userId {
+			return nil, errors.New("identity is linked to another user")
+		}
+		return identity, nil
+	}
+	if err.Error() != "user identity not found" {
+		return nil, err
+	}
+
+	identities, err := s.repository.FindUserIdentitiesByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, linked := range identities {
+		if linked.Provider == claims.Provider {
+			return nil, errors.New("provider is already linked")
+		}
+	}
+
+	identity = &models.UserIdentity{
+		UserID:   userId,
+		Provider: claims.Provider,
+		Subject:  claims.ProviderSubject,
+		Email:    claims.Email,
+	}
+	if err := s.repository.CreateUserIdentity(identity); err != nil {
+		// 確認してから作成するまでに同時に紐づけられた場合
+		if err.Error() == "identity already linked" {
+			return nil, errors.New("provider is already linked")
+		}
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+// プロバイダーのアカウントの紐づけを解除する
+// パスワードが設定されておらず、他にプロバイダーのアカウントもない場合は"cannot unlink the last login method"を返す
+func (s *IdentityService) UnlinkIdentity(userId uint, provider string) error {
+	return s.repository.DeleteUserIdentity(userId, provider)
+}
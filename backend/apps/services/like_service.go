@@ -0,0 +1,53 @@
+package services
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+)
+
+type ILikeService interface {
+	Like(userId, tweetId uint) (*models.Like, error)
+	Unlike(userId, tweetId uint) error
+	GetLikers(tweetId uint, page *pagination.Page) (*pagination.List[*models.Like], error)
+	GetUserLikes(userId uint, page *pagination.Page) (*pagination.List[*models.Like], error)
+}
+
+type LikeService struct {
+	repository      repositories.ILikeRepository
+	tweetRepository repositories.ITweetRepository
+}
+
+func NewLikeService(repository repositories.ILikeRepository, tweetRepository repositories.ITweetRepository) ILikeService {
+	return &LikeService{repository: repository, tweetRepository: tweetRepository}
+}
+
+func (s *LikeService) Like(userId, tweetId uint) (*models.Like, error) {
+	// 存在しないtweetへのlikeは"tweet not found"を返す
+	if _, err := s.tweetRepository.GetTweet(tweetId); err != nil {
+		return nil, err
+	}
+
+	like := &models.Like{
+		UserID:  userId,
+		TweetID: tweetId,
+	}
+
+	return s.repository.CreateLike(like)
+}
+
+func (s *LikeService) Unlike(userId, tweetId uint) error {
+	return s.repository.DeleteLike(userId, tweetId)
+}
+
+func (s *LikeService) GetLikers(tweetId uint, page *pagination.Page) (*pagination.List[*models.Like], error) {
+	if _, err := s.tweetRepository.GetTweet(tweetId); err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetTweetLikes(tweetId, page)
+}
+
+func (s *LikeService) GetUserLikes(userId uint, page *pagination.Page) (*pagination.List[*models.Like], error) {
+	return s.repository.GetUserLikes(userId, page)
+}
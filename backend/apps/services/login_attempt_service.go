@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+)
+
+// ログインの試行をアカウント単位とIPアドレス単位で制限する
+// 失敗が続くと次のログインまでの待ち時間を指数的に延ばし、上限に達した場合は一定時間ロックする
+type ILoginAttemptService interface {
+	CheckLogin(email, ipAddress string) error
+	RecordLoginFailure(email, ipAddress string) error
+	RecordLoginSuccess(email string) error
+}
+
+// ログインの失敗に対する制限
+// FreeAttempts: 待ち時間なしで失敗できる回数
+// BaseDelay、MaxDelay: FreeAttemptsを超えた失敗ごとにBaseDelayから2倍ずつ延ばす待ち時間とその上限
+// MaxFailures、LockoutDuration: 失敗回数がMaxFailuresに達した場合にLockoutDurationの間ロックする
+// FailureWindow: 最後の失敗からFailureWindowが経過した場合は失敗回数を1からやり直す
+type LoginAttemptPolicy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	MaxFailures     int
+	LockoutDuration time.Duration
+	FailureWindow   time.Duration
+}
+
+var (
+	// アカウント単位の制限
+	AccountLoginAttemptPolicy = LoginAttemptPolicy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        30 * time.Second,
+		MaxFailures:     10,
+		LockoutDuration: 15 * time.Minute,
+		FailureWindow:   time.Hour,
+	}
+
+	// IPアドレス単位の制限(NATなどで複数のユーザーが同じIPアドレスを使う場合があるので緩くする)
+	IPLoginAttemptPolicy = LoginAttemptPolicy{
+		FreeAttempts:    10,
+		BaseDelay:       time.Second,
+		MaxDelay:        30 * time.Second,
+		MaxFailures:     100,
+		LockoutDuration: 15 * time.Minute,
+		FailureWindow:   time.Hour,
+	}
+)
+
+// failures回失敗した後に次のログインまで待つ時間
+func (p LoginAttemptPolicy) Delay(failures int) time.Duration {
+	exceeded := failures - p.FreeAttempts
+	if exceeded <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < exceeded; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return delay
+}
+
+type LoginAttemptService struct {
+	repository    repositories.ILoginAttemptRepository
+	clock         clock.Clock
+	accountPolicy LoginAttemptPolicy
+	ipPolicy      LoginAttemptPolicy
+}
+
+func NewLoginAttemptService(repository repositories.ILoginAttemptRepository, clock clock.Clock) ILoginAttemptService {
+	return &LoginAttemptService{
+		repository:    repository,
+		clock:         clock,
+		accountPolicy: AccountLoginAttemptPolicy,
+		ipPolicy:      IPLoginAttemptPolicy,
+	}
+}
+
+// アカウントまたはIPアドレスがロック中か待ち時間中の場合は"too many login attempts"を返す
+// パスワードを確認する前に呼ぶ
+func (s *LoginAttemptService) CheckLogin(email, ipAddress string) error {
+	if err := s.check(accountLoginIdentifier(email), s.accountPolicy); err != nil {
+		return err
+	}
+	if ipAddress == "" {
+		return nil
+	}
+
+	return s.check(ipLoginIdentifier(ipAddress), s.ipPolicy)
+}
+
+// アカウントとIPアドレスの失敗回数を増やし、上限に達した場合はロックする
+func (s *LoginAttemptService) RecordLoginFailure(email, ipAddress string) error {
+	if err := s.recordFailure(accountLoginIdentifier(email), ipAddress, s.accountPolicy); err != nil {
+		return err
+	}
+	if ipAddress == "" {
+		return nil
+	}
+
+	return s.recordFailure(ipLoginIdentifier(ipAddress), ipAddress, s.ipPolicy)
+}
+
+// ログインに成功した場合はアカウントの失敗回数を消す
+// IPアドレスの失敗回数は他のアカウントへの試行を含むので消さない
+func (s *LoginAttemptService) RecordLoginSuccess(email string) error {
+	return s.repository.ResetLoginAttempt(accountLoginIdentifier(email))
+}
+
+func (s *LoginAttemptService) check(identifier string, policy LoginAttemptPolicy) error {
+	attempt, err := s.repository.FindLoginAttempt(identifier)
+	if err != nil {
+		if err.Error() == "login attempt not found" {
+			return nil
+		}
+		return err
+	}
+
+	now := s.clock.Now()
+	if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		return errors.New("too many login attempts")
+	}
+	if now.Before(attempt.LastFailedAt.Add(policy.Delay(attempt.Failures))) {
+		return errors.New("too many login attempts")
+	}
+
+	return nil
+}
+
+func (s *LoginAttemptService) recordFailure(identifier, ipAddress string, policy LoginAttemptPolicy) error {
+	now := s.clock.Now()
+	attempt, err := s.repository.RecordFailedLogin(identifier, now, now.Add(-policy.FailureWindow))
+	if err != nil {
+		return err
+	}
+	if attempt.Failures < policy.MaxFailures {
+		return nil
+	}
+
+	return s.repository.LockLoginAttempt(&models.LoginLockout{
+		Identifier:  identifier,
+		IPAddress:   ipAddress,
+		Failures:    attempt.Failures,
+		LockedUntil: now.Add(policy.LockoutDuration),
+	})
+}
+
+// emailは大文字小文字と前後の空白を区別しない
+func accountLoginIdentifier(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipLoginIdentifier(ipAddress string) string {
+	return "ip:" + ipAddress
+}
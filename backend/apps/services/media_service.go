@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/media"
+	"github.com/google/uuid"
+)
+
+type IMediaService interface {
+	UploadMedia(userId uint, file io.Reader, size int64) (*models.Media, error)
+	GetAttachableMedia(userId uint, tweetType models.TweetType, mediaIds []uint) ([]*models.Media, error)
+	GetProfileImage(userId, mediaId uint) (*models.Media, error)
+	DeleteTweetMedia(tweetId uint) error
+}
+
+// アップロードできる画像/動画の最大サイズと1つのtweetに添付できる画像の数
+const (
+	MaxImageSize   int64 = 5 << 20
+	MaxVideoSize   int64 = 100 << 20
+	MaxTweetImages       = 4
+)
+
+// アップロードできるファイルの形式
+// Content-Typeはクライアントの申告ではなくファイルの先頭のバイト列から判定する
+type mediaFormat struct {
+	Type      models.TweetType
+	Extension string
+	MaxSize   int64
+}
+
+var mediaFormats = map[string]mediaFormat{
+	"image/jpeg": {Type: models.Image, Extension: ".jpg", MaxSize: MaxImageSize},
+	"image/png":  {Type: models.Image, Extension: ".png", MaxSize: MaxImageSize},
+	"image/gif":  {Type: models.Image, Extension: ".gif", MaxSize: MaxImageSize},
+	"video/mp4":  {Type: models.Video, Extension: ".mp4", MaxSize: MaxVideoSize},
+	"video/webm": {Type: models.Video, Extension: ".webm", MaxSize: MaxVideoSize},
+}
+
+type MediaService struct {
+	repository repositories.IMediaRepository
+	store      media.MediaStore
+}
+
+func NewMediaService(repository repositories.IMediaRepository, store media.MediaStore) IMediaService {
+	return &MediaService{repository: repository, store: store}
+}
+
+// 画像/動画をstoreに保存してまだtweetに添付されていないMediaを作成
+// 画像の場合はサムネイルも作成する
+func (s *MediaService) UploadMedia(userId uint, file io.Reader, size int64) (*models.Media, error) {
+	// 先頭の512バイトからContent-Typeを判定(http.DetectContentTypeが参照するのは先頭512バイトまで)
+	header := make([]byte, 512)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	header = header[:n]
+
+	contentType := http.DetectContentType(header)
+	format, ok := mediaFormats[contentType]
+	if !ok {
+		return nil, errors.New("unsupported media type")
+	}
+	if size > format.MaxSize {
+		return nil, errors.New("media is too large")
+	}
+
+	ctx := context.Background()
+	name := uuid.NewString()
+	uploadedMedia := &models.Media{
+		UserID:      userId,
+		Type:        format.Type,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  fmt.Sprintf("%ss/%s%s", format.Type, name, format.Extension),
+	}
+
+	var body io.Reader = io.MultiReader(bytes.NewReader(header), file)
+	if format.Type == models.Image {
+		// サムネイルの作成のために画像全体を読み込む(画像はMaxImageSize以下なのでメモリに収まる)
+		data, err := io.ReadAll(io.LimitReader(body, MaxImageSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > MaxImageSize {
+			return nil, errors.New("media is too large")
+		}
+		uploadedMedia.Size = int64(len(data))
+
+		thumbnail, err := media.GenerateThumbnail(bytes.NewReader(data))
+		if errors.Is(err, media.ErrImageTooLarge) {
+			return nil, err
+		} else if err != nil {
+			return nil, errors.New("invalid image")
+		}
+
+		uploadedMedia.ThumbnailKey = fmt.Sprintf("thumbnails/%s.jpg", name)
+		if err := s.store.Put(ctx, uploadedMedia.ThumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), media.ThumbnailContentType); err != nil {
+			return nil, err
+		}
+		uploadedMedia.ThumbnailURL = s.store.URL(uploadedMedia.ThumbnailKey)
+
+		body = bytes.NewReader(data)
+	}
+
+	if err := s.store.Put(ctx, uploadedMedia.StorageKey, body, uploadedMedia.Size, contentType); err != nil {
+		s.deleteFiles(uploadedMedia)
+		return nil, err
+	}
+	uploadedMedia.URL = s.store.URL(uploadedMedia.StorageKey)
+
+	createdMedia, err := s.repository.CreateMedia(uploadedMedia)
+	if err != nil {
+		s.deleteFiles(uploadedMedia)
+		return nil, err
+	}
+
+	return createdMedia, nil
+}
+
+// userIdのユーザーがtweetTypeのtweetに添付できるmediaIdsの画像/動画を取得
+// 自分がアップロードしてまだ添付していないもののみ添付できる
+// image: 画像をMaxTweetImages枚まで、video: 動画を1本のみ、それ以外のtweetには添付できない
+func (s *MediaService) GetAttachableMedia(userId uint, tweetType models.TweetType, mediaIds []uint) ([]*models.Media, error) {
+	if len(mediaIds) == 0 {
+		return nil, nil
+	}
+
+	switch {
+	case tweetType == models.Image && len(mediaIds) <= MaxTweetImages:
+	case tweetType == models.Video && len(mediaIds) == 1:
+	default:
+		return nil, errors.New("media does not match tweet type")
+	}
+
+	attachableMedia, err := s.repository.GetMediaByIds(mediaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	// 重複したidは1つとして扱う
+	uniqueIds := make(map[uint]bool, len(mediaIds))
+	for _, mediaId := range mediaIds {
+		uniqueIds[mediaId] = true
+	}
+	if len(attachableMedia) != len(uniqueIds) {
+		return nil, errors.New("media not found")
+	}
+
+	for _, m := range attachableMedia {
+		if m.UserID != userId {
+			return nil, errors.New("media not found")
+		}
+		if m.TweetID != nil {
+			return nil, errors.New("media is already attached")
+		}
+		if m.Type != tweetType {
+			return nil, errors.New("media does not match tweet type")
+		}
+	}
+
+	return attachableMedia, nil
+}
+
+// userIdのユーザーがプロフィール画像、ヘッダー画像に使用できるmediaIdの画像を取得
+// 自分がアップロードしてtweetに添付していない画像のみ使用できる
+func (s *MediaService) GetProfileImage(userId, mediaId uint) (*models.Media, error) {
+	profileMedia, err := s.repository.GetMediaByIds([]uint{mediaId})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(profileMedia) == 0 || profileMedia[0].UserID != userId {
+		return nil, errors.New("media not found")
+	}
+	if profileMedia[0].TweetID != nil {
+		return nil, errors.New("media is already attached")
+	}
+	if profileMedia[0].Type != models.Image {
+		return nil, errors.New("profile image must be an image")
+	}
+
+	return profileMedia[0], nil
+}
+
+// tweetIdのtweetに添付された画像/動画をstoreとDBから削除
+// storeからの削除に失敗してもDBからは削除する(ファイルは参照されなくなるだけ)
+func (s *MediaService) DeleteTweetMedia(tweetId uint) error {
+	tweetMedia, err := s.repository.GetTweetMedia(tweetId)
+	if err != nil {
+		return err
+	}
+
+	mediaIds := make([]uint, 0, len(tweetMedia))
+	for _, m := range tweetMedia {
+		s.deleteFiles(m)
+		mediaIds = append(mediaIds, m.ID)
+	}
+
+	return s.repository.DeleteMedia(mediaIds)
+}
+
+func (s *MediaService) deleteFiles(m *models.Media) {
+	for _, key := range []string{m.StorageKey, m.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.store.Delete(context.Background(), key); err != nil {
+			log.Println("failed to delete media file: ", key, err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/pkg/search"
+)
+
+type ISearchService interface {
+	SearchTweets(q string, page *pagination.Page) (*pagination.List[*models.Tweet], error)
+}
+
+type SearchService struct {
+	repository      repositories.ISearchRepository
+	tweetRepository repositories.ITweetRepository
+}
+
+func NewSearchService(repository repositories.ISearchRepository, tweetRepository repositories.ITweetRepository) ISearchService {
+	return &SearchService{
+		repository:      repository,
+		tweetRepository: tweetRepository,
+	}
+}
+
+// qの検索クエリに一致するtweetを新しい順に取得
+// qの書式はsearch.Parseを参照(例: `golang "全文検索" from:gopher since:2024-09-01 has:image #go`)
+func (s *SearchService) SearchTweets(q string, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	query, err := search.Parse(q)
+	if err != nil {
+		return nil, err
+	}
+
+	// has:image/has:videoをtweetの種類に変換
+	var tweetTypes []models.TweetType
+	for _, has := range query.Has {
+		tweetType, err := models.Str2TweetType(has)
+		if err != nil || tweetType == models.Text {
+			return nil, search.ErrInvalidHas
+		}
+		tweetTypes = append(tweetTypes, tweetType)
+	}
+
+	tweets, err := s.repository.SearchTweets(&repositories.TweetSearchCondition{
+		Terms:        query.Terms,
+		FromUsername: query.From,
+		Since:        query.Since,
+		Until:        query.Until,
+		Types:        tweetTypes,
+		Hashtags:     query.Hashtags,
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setShareCounts(s.tweetRepository, tweets.Items); err != nil {
+		return nil, err
+	}
+
+	return tweets, nil
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+	"github.com/daiki-kim/tweet-app/backend/pkg/trend"
+)
+
+type ITrendAggregator interface {
+	Record(tweet *models.Tweet)
+	Flush() error
+	Purge() error
+}
+
+type ITrendService interface {
+	GetTrends(window string, limit int) ([]*dtos.Trend, error)
+}
+
+const (
+	// 単語の出現数を集計する時間枠の長さ
+	TrendSlotDuration = 5 * time.Minute
+	// 最も長い期間(7d)とその直前の期間の分だけ集計を残す
+	TrendRetention = 14 * 24 * time.Hour
+
+	// 期間内の出現数がこれより少ない単語はtrendにしない
+	MinTrendCount = 3
+
+	DefaultTrendWindow = "24h"
+	DefaultTrendLimit  = 10
+	MaxTrendLimit      = 50
+)
+
+// trendを集計する期間
+var TrendWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// 作成されたtweetの単語を時間枠ごとにメモリ上で数え、Flushでまとめてrepositoryに保存する
+// Recordはtweetの作成のたびに呼ばれるのでDBには書き込まない
+type TrendAggregator struct {
+	repository repositories.ITrendRepository
+	clock      clock.Clock
+
+	mu      sync.Mutex
+	pending map[time.Time]map[string]int64
+}
+
+func NewTrendAggregator(repository repositories.ITrendRepository, clock clock.Clock) ITrendAggregator {
+	return &TrendAggregator{repository: repository, clock: clock, pending: map[time.Time]map[string]int64{}}
+}
+
+// tweetの内容の単語を現在の時間枠で数える(同じtweetの同じ単語は1回のみ)
+func (a *TrendAggregator) Record(tweet *models.Tweet) {
+	terms := trend.Tokenize(tweet.Content)
+	if len(terms) == 0 {
+		return
+	}
+
+	slotStart := a.clock.Now().UTC().Truncate(TrendSlotDuration)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	counts, ok := a.pending[slotStart]
+	if !ok {
+		counts = map[string]int64{}
+		a.pending[slotStart] = counts
+	}
+	for _, term := range terms {
+		counts[term]++
+	}
+}
+
+// メモリ上で数えた出現数をrepositoryに保存する
+// 保存に失敗した時間枠は次のFlushで再度保存する
+func (a *TrendAggregator) Flush() error {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = map[time.Time]map[string]int64{}
+	a.mu.Unlock()
+
+	var errs []error
+	for slotStart, counts := range pending {
+		if err := a.repository.AddCounts(slotStart, counts); err != nil {
+			errs = append(errs, err)
+			a.restore(slotStart, counts)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// TrendRetentionより古い時間枠を削除する
+func (a *TrendAggregator) Purge() error {
+	_, err := a.repository.DeleteBucketsBefore(a.clock.Now().UTC().Add(-TrendRetention))
+	return err
+}
+
+func (a *TrendAggregator) restore(slotStart time.Time, counts map[string]int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pendingCounts, ok := a.pending[slotStart]
+	if !ok {
+		pendingCounts = map[string]int64{}
+		a.pending[slotStart] = pendingCounts
+	}
+	for term, count := range counts {
+		pendingCounts[term] += count
+	}
+}
+
+// flushIntervalごとに出現数を保存し、purgeIntervalごとに古い時間枠を削除する
+// 返り値の関数を呼ぶと残りの出現数を保存して停止する
+func StartTrendAggregator(aggregator ITrendAggregator, flushInterval, purgeInterval time.Duration) (stop func()) {
+	flushTicker := time.NewTicker(flushInterval)
+	purgeTicker := time.NewTicker(purgeInterval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-flushTicker.C:
+				if err := aggregator.Flush(); err != nil {
+					log.Println("failed to flush trend counts: ", err)
+				}
+			case <-purgeTicker.C:
+				if err := aggregator.Purge(); err != nil {
+					log.Println("failed to purge trend buckets: ", err)
+				}
+			case <-done:
+				flushTicker.Stop()
+				purgeTicker.Stop()
+				if err := aggregator.Flush(); err != nil {
+					log.Println("failed to flush trend counts: ", err)
+				}
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+type TrendService struct {
+	repository repositories.ITrendRepository
+	clock      clock.Clock
+}
+
+func NewTrendService(repository repositories.ITrendRepository, clock clock.Clock) ITrendService {
+	return &TrendService{repository: repository, clock: clock}
+}
+
+// windowの期間(1h, 24h, 7d)のtrendを伸び(velocity)の大きい順にlimit件取得
+// 期間内の出現数と直前の同じ長さの期間の出現数を比べるので、常に多く使われる単語は上位にならない
+func (s *TrendService) GetTrends(window string, limit int) ([]*dtos.Trend, error) {
+	duration, ok := TrendWindows[window]
+	if !ok {
+		return nil, errors.New("invalid window")
+	}
+
+	// 現在の時間枠は途中なので含めて集計する
+	to := s.clock.Now().UTC().Truncate(TrendSlotDuration).Add(TrendSlotDuration)
+	from := to.Add(-duration)
+
+	counts, err := s.repository.SumCounts(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	previousCounts, err := s.repository.SumCounts(from.Add(-duration), from)
+	if err != nil {
+		return nil, err
+	}
+
+	trends := make([]*dtos.Trend, 0, len(counts))
+	for term, count := range counts {
+		if count < MinTrendCount {
+			continue
+		}
+
+		previousCount := previousCounts[term]
+		score := velocity(count, previousCount)
+		if score <= 0 {
+			continue
+		}
+
+		trends = append(trends, &dtos.Trend{
+			Term:          term,
+			Hashtag:       strings.HasPrefix(term, "#"),
+			Count:         count,
+			PreviousCount: previousCount,
+			Score:         score,
+		})
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Score != trends[j].Score {
+			return trends[i].Score > trends[j].Score
+		}
+		if trends[i].Count != trends[j].Count {
+			return trends[i].Count > trends[j].Count
+		}
+		return trends[i].Term < trends[j].Term
+	})
+
+	if len(trends) > limit {
+		trends = trends[:limit]
+	}
+
+	return trends, nil
+}
+
+// 直前の期間からの出現数の伸び
+// 直前の期間の出現数を期待値とした増加量を期待値の平方根で割る(ポアソン分布のzスコアに近い値)
+// 同じくらい使われ続けている単語は0に近く、急に使われ始めた単語ほど大きくなる
+func velocity(count, previousCount int64) float64 {
+	return float64(count-previousCount) / math.Sqrt(float64(previousCount)+1)
+}
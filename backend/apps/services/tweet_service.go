@@ -2,55 +2,253 @@ package services
 
 import (
 	"errors"
+	"log"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
 	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/hashtag"
+	"github.com/daiki-kim/tweet-app/backend/pkg/mention"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 )
 
 type ITweetService interface {
-	CreateTweet(userId uint, tweetTypeString string, content string) (*models.Tweet, error)
-	GetTweet(id uint) (*models.Tweet, error)
-	GetUserTweets(userId uint) ([]*models.Tweet, error)
+	CreateTweet(userId uint, tweetTypeString string, content string, mediaIds []uint) (*models.Tweet, error)
+	GetTweet(id, userId uint) (*models.Tweet, error)
+	GetUserTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error)
 	UpdateTweet(id, userId uint, inputTweet *dtos.UpdateTweetInput) (*models.Tweet, error)
 	DeleteTweet(id, userId uint) error
+	DeleteAnyTweet(id uint) error
+	ReplyTweet(userId, tweetId uint, tweetTypeString string, content string) (*models.Tweet, error)
+	GetThread(id uint, depth int, page *pagination.Page) (*dtos.TweetThread, error)
+	Retweet(userId, tweetId uint) (*models.Tweet, error)
+	Unretweet(userId, tweetId uint) error
+	QuoteTweet(userId, tweetId uint, content string) (*models.Tweet, error)
+	GetHashtagTweets(tag string, page *pagination.Page) (*pagination.List[*models.Tweet], error)
+	GetMentionTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error)
 }
 
+// スレッドで取得する返信ツリーの深さ
+const (
+	DefaultThreadDepth = 3
+	MaxThreadDepth     = 10
+)
+
+// スレッドの2階層目以降で1つのtweetごとに取得する返信の数
+const ThreadRepliesPerTweet = 10
+
 type TweetService struct {
-	repository repositories.ITweetRepository
+	repository      repositories.ITweetRepository
+	likeRepository  repositories.ILikeRepository
+	userRepository  repositories.IUserRepository
+	feedService     IFeedService
+	mediaService    IMediaService
+	trendAggregator ITrendAggregator
 }
 
-func NewTweetService(repository repositories.ITweetRepository) ITweetService {
-	return &TweetService{repository: repository}
+func NewTweetService(
+	repository repositories.ITweetRepository,
+	likeRepository repositories.ILikeRepository,
+	userRepository repositories.IUserRepository,
+	feedService IFeedService,
+	mediaService IMediaService,
+	trendAggregator ITrendAggregator,
+) ITweetService {
+	return &TweetService{
+		repository:      repository,
+		likeRepository:  likeRepository,
+		userRepository:  userRepository,
+		feedService:     feedService,
+		mediaService:    mediaService,
+		trendAggregator: trendAggregator,
+	}
 }
 
-func (s *TweetService) CreateTweet(userId uint, tweetTypeString string, content string) (*models.Tweet, error) {
-	var (
-		tweetType models.TweetType
-		err       error
-	)
-
+// mediaIdsの画像/動画(UploadMediaでアップロード済みのもの)を添付したtweetを作成
+// 画像/動画のtweetは種類の合う画像/動画の添付が必要
+func (s *TweetService) CreateTweet(userId uint, tweetTypeString string, content string, mediaIds []uint) (*models.Tweet, error) {
 	// stringで受け取ったtweetTypeStringをenumに変換
-	tweetType, err = models.Str2TweetType(tweetTypeString)
+	tweetType, err := models.Str2TweetType(tweetTypeString)
 	if err != nil {
 		return nil, err
 	}
 
+	if tweetType.HasMedia() && len(mediaIds) == 0 {
+		return nil, errors.New("media does not match tweet type")
+	}
+
 	tweet := &models.Tweet{
 		UserID:  userId,
 		Type:    tweetType,
 		Content: content,
 	}
 
-	return s.repository.CreateTweet(tweet)
+	if len(mediaIds) > 0 {
+		tweet.Media, err = s.mediaService.GetAttachableMedia(userId, tweetType, mediaIds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.publishTweet(tweet)
+}
+
+// tweetIdのtweetへの返信を作成
+// 返信先と同じconversation(返信先が最初のtweetの場合は返信先のid)に属する
+func (s *TweetService) ReplyTweet(userId, tweetId uint, tweetTypeString string, content string) (*models.Tweet, error) {
+	parentTweet, err := s.repository.GetTweet(tweetId)
+	if err != nil {
+		return nil, err
+	}
+
+	tweetType, err := models.Str2TweetType(tweetTypeString)
+	if err != nil {
+		return nil, err
+	}
+
+	// 返信には画像/動画を添付できない
+	if tweetType.HasMedia() {
+		return nil, errors.New("media does not match tweet type")
+	}
+
+	conversationId := parentTweet.ID
+	if parentTweet.ConversationID != nil {
+		conversationId = *parentTweet.ConversationID
+	}
+
+	return s.publishTweet(&models.Tweet{
+		UserID:           userId,
+		Type:             tweetType,
+		Content:          content,
+		InReplyToTweetID: &parentTweet.ID,
+		ConversationID:   &conversationId,
+	})
+}
+
+// tweetIdのtweetをretweetする(内容を持たず元のtweetを参照するだけのtweetを作成)
+// retweetをretweetした場合は元のtweetをretweetする
+func (s *TweetService) Retweet(userId, tweetId uint) (*models.Tweet, error) {
+	originalTweet, err := s.getOriginalTweet(tweetId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.publishTweet(&models.Tweet{
+		UserID:           userId,
+		Type:             models.Retweet,
+		RetweetedTweetID: &originalTweet.ID,
+	})
+}
+
+// userIdのユーザーのtweetIdのtweetのretweetを取り消す
+// Retweetと同じく、retweetを指定した場合は元のtweetのretweetを取り消す
+func (s *TweetService) Unretweet(userId, tweetId uint) error {
+	originalTweet, err := s.getOriginalTweet(tweetId)
+	if err != nil {
+		// 削除されたtweetのretweetも取り消せるようにtweetIdのまま取り消す
+		if err.Error() == "tweet not found" {
+			return s.repository.DeleteRetweet(userId, tweetId)
+		}
+		return err
+	}
+
+	return s.repository.DeleteRetweet(userId, originalTweet.ID)
+}
+
+// tweetIdのtweetを引用したquote tweetを作成
+func (s *TweetService) QuoteTweet(userId, tweetId uint, content string) (*models.Tweet, error) {
+	originalTweet, err := s.getOriginalTweet(tweetId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.publishTweet(&models.Tweet{
+		UserID:        userId,
+		Type:          models.Quote,
+		Content:       content,
+		QuotedTweetID: &originalTweet.ID,
+	})
+}
+
+// tweetIdのtweetを取得、retweetの場合は元のtweetを取得
+func (s *TweetService) getOriginalTweet(tweetId uint) (*models.Tweet, error) {
+	tweet, err := s.repository.GetTweet(tweetId)
+	if err != nil {
+		return nil, err
+	}
+
+	if tweet.Type == models.Retweet && tweet.RetweetedTweetID != nil {
+		return s.repository.GetTweet(*tweet.RetweetedTweetID)
+	}
+
+	return tweet, nil
 }
 
-func (s *TweetService) GetTweet(id uint) (*models.Tweet, error) {
-	return s.repository.GetTweet(id)
+// tweetを内容のhashtag、mentionと一緒に作成してフォロワーのFeedに配信し、trendの集計に加える
+func (s *TweetService) publishTweet(tweet *models.Tweet) (*models.Tweet, error) {
+	tweet.Hashtags = extractHashtags(tweet.Content)
+
+	mentions, err := s.resolveMentions(tweet.Content)
+	if err != nil {
+		return nil, err
+	}
+	tweet.Mentions = mentions
+
+	createdTweet, err := s.repository.CreateTweet(tweet)
+	if err != nil {
+		return nil, err
+	}
+
+	// フォロワーのFeedに配信(配信に失敗してもTweet自体の作成は成功とする)
+	if err := s.feedService.DistributeTweet(createdTweet); err != nil {
+		log.Println("failed to distribute tweet to feeds: ", err)
+	}
+
+	s.trendAggregator.Record(createdTweet)
+
+	return createdTweet, nil
 }
 
-func (s *TweetService) GetUserTweets(userId uint) ([]*models.Tweet, error) {
-	return s.repository.GetUserTweets(userId)
+// idのtweetをlike数とuserIdのユーザーがlikeしているかどうかを含めて取得
+func (s *TweetService) GetTweet(id, userId uint) (*models.Tweet, error) {
+	tweet, err := s.repository.GetTweet(id)
+	if err != nil {
+		return nil, err
+	}
+
+	likeCounts, err := s.likeRepository.CountLikes([]uint{tweet.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	likedTweetIds, err := s.likeRepository.GetLikedTweetIds(userId, []uint{tweet.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	likeCount := likeCounts[tweet.ID]
+	likedByMe := likedTweetIds[tweet.ID]
+	tweet.LikeCount = &likeCount
+	tweet.LikedByMe = &likedByMe
+
+	if err := setShareCounts(s.repository, []*models.Tweet{tweet}); err != nil {
+		return nil, err
+	}
+
+	return tweet, nil
+}
+
+func (s *TweetService) GetUserTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	tweets, err := s.repository.GetUserTweets(userId, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setShareCounts(s.repository, tweets.Items); err != nil {
+		return nil, err
+	}
+
+	return tweets, nil
 }
 
 func (s *TweetService) UpdateTweet(id, userId uint, inputTweet *dtos.UpdateTweetInput) (*models.Tweet, error) {
@@ -59,20 +257,46 @@ func (s *TweetService) UpdateTweet(id, userId uint, inputTweet *dtos.UpdateTweet
 		return nil, err
 	}
 
-	if updatedTweet.UserID != userId {
+	if !updatedTweet.IsOwnedBy(userId) {
 		return nil, errors.New("this tweet is not yours")
 	}
 
+	if updatedTweet.Type == models.Retweet {
+		return nil, errors.New("retweet cannot be updated")
+	}
+
 	if inputTweet.Type != "" {
+		if updatedTweet.Type == models.Quote {
+			return nil, errors.New("type of quote cannot be changed")
+		}
+
+		// 添付した画像/動画とtweetの種類が合わなくなるため変更できない
+		if len(updatedTweet.Media) > 0 && inputTweet.Type != string(updatedTweet.Type) {
+			return nil, errors.New("type of tweet with media cannot be changed")
+		}
+
 		tweetType, err := models.Str2TweetType(inputTweet.Type)
 		if err != nil {
 			return nil, err
 		}
+
+		// 画像/動画は作成時にのみ添付できるので、添付のないtweetを画像/動画のtweetにはできない
+		if len(updatedTweet.Media) == 0 && tweetType.HasMedia() {
+			return nil, errors.New("media does not match tweet type")
+		}
 		updatedTweet.Type = tweetType
 	}
 
+	// 内容が変わった場合はhashtag、mentionも更新する
 	if inputTweet.Content != "" {
 		updatedTweet.Content = inputTweet.Content
+		updatedTweet.Hashtags = extractHashtags(inputTweet.Content)
+
+		mentions, err := s.resolveMentions(inputTweet.Content)
+		if err != nil {
+			return nil, err
+		}
+		updatedTweet.Mentions = mentions
 	}
 
 	return s.repository.UpdateTweet(updatedTweet)
@@ -84,9 +308,235 @@ func (s *TweetService) DeleteTweet(id, userId uint) error {
 		return err
 	}
 
-	if targetTweet.UserID != userId {
+	if !targetTweet.IsOwnedBy(userId) {
 		return errors.New("this tweet is not yours")
 	}
 
-	return s.repository.DeleteTweet(id)
+	return s.deleteTweet(targetTweet)
+}
+
+// 投稿したユーザーに関係なくidのtweetを削除(/adminからのモデレーション用)
+// 権限の確認はmiddlewares.RequirePermission、対象のユーザーとの関係の確認はAdminService.DeleteTweetで行う
+func (s *TweetService) DeleteAnyTweet(id uint) error {
+	targetTweet, err := s.repository.GetTweet(id)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteTweet(targetTweet)
+}
+
+func (s *TweetService) deleteTweet(targetTweet *models.Tweet) error {
+	id := targetTweet.ID
+
+	// retweetはtombstoneを残さずに取り消す(再度retweetできるように)
+	if targetTweet.Type == models.Retweet && targetTweet.RetweetedTweetID != nil {
+		return s.repository.DeleteRetweet(targetTweet.UserID, *targetTweet.RetweetedTweetID)
+	}
+
+	if err := s.repository.DeleteTweet(id); err != nil {
+		return err
+	}
+
+	// tombstoneには画像/動画を残さない(削除に失敗してもTweet自体の削除は成功とする)
+	if len(targetTweet.Media) > 0 {
+		if err := s.mediaService.DeleteTweetMedia(id); err != nil {
+			log.Println("failed to delete tweet media: ", err)
+		}
+	}
+
+	return nil
+}
+
+// idのtweetのconversationの最初のtweet(root)からidのtweetまでの返信と、rootの返信ツリーを取得
+// 直接の返信はページングし、その先の返信はdepthの深さまでThreadRepliesPerTweet件ずつ取得する
+// 削除されたtweetはtombstoneとして含めるので、途中のtweetが削除されてもスレッドは壊れない
+func (s *TweetService) GetThread(id uint, depth int, page *pagination.Page) (*dtos.TweetThread, error) {
+	if depth < 1 {
+		depth = DefaultThreadDepth
+	}
+	depth = min(depth, MaxThreadDepth)
+
+	path, err := s.repository.GetReplyPath(id)
+	if err != nil {
+		return nil, err
+	}
+	rootTweet := path[0]
+
+	replies, err := s.repository.GetReplies(rootTweet.ID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	// 直接の返信から1階層ずつ返信を取得してツリーを作成
+	nodes := make([]*dtos.TweetThreadNode, 0, len(replies.Items))
+	for _, reply := range replies.Items {
+		nodes = append(nodes, &dtos.TweetThreadNode{Tweet: dtos.NewTweetResponse(reply), Replies: []*dtos.TweetThreadNode{}})
+	}
+
+	parentNodes := nodes
+	for level := 1; level < depth && len(parentNodes) > 0; level++ {
+		parentIds := make([]uint, 0, len(parentNodes))
+		parentNodesById := make(map[uint]*dtos.TweetThreadNode, len(parentNodes))
+		for _, node := range parentNodes {
+			parentIds = append(parentIds, node.Tweet.ID)
+			parentNodesById[node.Tweet.ID] = node
+		}
+
+		childTweets, err := s.repository.GetRepliesOf(parentIds, ThreadRepliesPerTweet)
+		if err != nil {
+			return nil, err
+		}
+
+		var childNodes []*dtos.TweetThreadNode
+		for _, childTweet := range childTweets {
+			childNode := &dtos.TweetThreadNode{Tweet: dtos.NewTweetResponse(childTweet), Replies: []*dtos.TweetThreadNode{}}
+			parentNode := parentNodesById[*childTweet.InReplyToTweetID]
+			parentNode.Replies = append(parentNode.Replies, childNode)
+			childNodes = append(childNodes, childNode)
+		}
+		parentNodes = childNodes
+	}
+
+	pathTweets := make([]*dtos.TweetResponse, 0, len(path)-1)
+	for _, tweet := range path[1:] {
+		pathTweets = append(pathTweets, dtos.NewTweetResponse(tweet))
+	}
+
+	return &dtos.TweetThread{
+		Tweet:   dtos.NewTweetResponse(rootTweet),
+		Path:    pathTweets,
+		Replies: &pagination.List[*dtos.TweetThreadNode]{Items: nodes, NextCursor: replies.NextCursor},
+	}, nil
+}
+
+// tagのhashtagを含むtweetを新しい順に取得
+// tagは#の有無や全角/半角、大文字/小文字を区別しない
+func (s *TweetService) GetHashtagTweets(tag string, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	name, ok := hashtag.Normalize(tag)
+	if !ok {
+		return nil, errors.New("invalid hashtag")
+	}
+
+	tweets, err := s.repository.GetHashtagTweets(name, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setShareCounts(s.repository, tweets.Items); err != nil {
+		return nil, err
+	}
+
+	return tweets, nil
+}
+
+// userIdのユーザーへのmentionを含むtweetを新しい順に取得
+func (s *TweetService) GetMentionTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	tweets, err := s.repository.GetMentionTweets(userId, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setShareCounts(s.repository, tweets.Items); err != nil {
+		return nil, err
+	}
+
+	return tweets, nil
+}
+
+// contentに含まれるmentionを抽出してmentionされたユーザーと紐付ける
+// usernameは大文字小文字を区別せず、存在しないユーザーへのmentionは無視する
+func (s *TweetService) resolveMentions(content string) ([]*models.TweetMention, error) {
+	extracted := mention.Extract(content)
+	if len(extracted) == 0 {
+		return nil, nil
+	}
+
+	var usernames []string
+	seen := map[string]bool{}
+	for _, m := range extracted {
+		username := mention.Normalize(m.Username)
+		if !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+
+	users, err := s.userRepository.FindUsersByUsernames(usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	usersByUsername := make(map[string]*models.User, len(users))
+	for _, user := range users {
+		usersByUsername[mention.Normalize(user.Username)] = user
+	}
+
+	var mentions []*models.TweetMention
+	for _, m := range extracted {
+		user, ok := usersByUsername[mention.Normalize(m.Username)]
+		if !ok {
+			continue
+		}
+		mentions = append(mentions, &models.TweetMention{
+			UserID:   user.ID,
+			Username: m.Username,
+			Start:    m.Start,
+			End:      m.End,
+		})
+	}
+
+	return mentions, nil
+}
+
+// contentに含まれるhashtagを抽出
+func extractHashtags(content string) []*models.Hashtag {
+	var hashtags []*models.Hashtag
+	for _, name := range hashtag.Extract(content) {
+		hashtags = append(hashtags, &models.Hashtag{Name: name})
+	}
+
+	return hashtags
+}
+
+// tweetsのretweet数とquote数を設定
+// retweet/quoteの場合は一緒に取得した元のtweetにも設定する
+func setShareCounts(repository repositories.ITweetRepository, tweets []*models.Tweet) error {
+	var targetTweets []*models.Tweet
+	for _, tweet := range tweets {
+		targetTweets = append(targetTweets, tweet)
+		if tweet.RetweetedTweet != nil {
+			targetTweets = append(targetTweets, tweet.RetweetedTweet)
+		}
+		if tweet.QuotedTweet != nil {
+			targetTweets = append(targetTweets, tweet.QuotedTweet)
+		}
+	}
+	if len(targetTweets) == 0 {
+		return nil
+	}
+
+	tweetIds := make([]uint, 0, len(targetTweets))
+	for _, tweet := range targetTweets {
+		tweetIds = append(tweetIds, tweet.ID)
+	}
+
+	retweetCounts, err := repository.CountRetweets(tweetIds)
+	if err != nil {
+		return err
+	}
+
+	quoteCounts, err := repository.CountQuotes(tweetIds)
+	if err != nil {
+		return err
+	}
+
+	for _, tweet := range targetTweets {
+		retweetCount := retweetCounts[tweet.ID]
+		quoteCount := quoteCounts[tweet.ID]
+		tweet.RetweetCount = &retweetCount
+		tweet.QuoteCount = &quoteCount
+	}
+
+	return nil
 }
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/configs"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/totp"
+)
+
+type ITwoFactorService interface {
+	EnrollTOTP(userId uint) (*TOTPEnrollment, error)
+	ConfirmTOTP(userId uint, code string) ([]string, error)
+	DisableTOTP(userId uint, code string) error
+	IsEnabled(userId uint) (bool, error)
+	VerifyCode(userId uint, code string) error
+}
+
+// TOTPの登録時に返すsecretと認証アプリに登録するotpauth:// URI
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TwoFactorService struct {
+	userRepository      repositories.IUserRepository
+	twoFactorRepository repositories.ITwoFactorRepository
+}
+
+func NewTwoFactorService(userRepository repositories.IUserRepository, twoFactorRepository repositories.ITwoFactorRepository) ITwoFactorService {
+	return &TwoFactorService{
+		userRepository:      userRepository,
+		twoFactorRepository: twoFactorRepository,
+	}
+}
+
+// TOTPのコードの形式(数字6桁)、それ以外はリカバリーコードとして扱う
+var totpCodePattern = regexp.MustCompile(`^\d{6}$`)
+
+// TOTPのsecretを発行して登録する
+// ConfirmTOTPで最初のコードを確認するまでは2段階認証は有効にならない
+// 確認前に再度登録した場合はsecretを発行し直す
+func (s *TwoFactorService) EnrollTOTP(userId uint) (*TOTPEnrollment, error) {
+	user, err := s.userRepository.FindUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := s.IsEnabled(userId)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepository.SaveUserTOTP(&models.UserTOTP{UserID: userId, Secret: secret, CreatedAt: time.Now()}); err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(configs.GetEnvDefault("TOTP_ISSUER", "tweet-app"), user.Email, secret),
+	}, nil
+}
+
+// 認証アプリの最初のコードを確認して2段階認証を有効にする
+// リカバリーコードを発行して返す(コードそのものを返すのはこの時だけ)
+func (s *TwoFactorService) ConfirmTOTP(userId uint, code string) ([]string, error) {
+	userTOTP, err := s.twoFactorRepository.FindUserTOTP(userId)
+	if err != nil {
+		if err.Error() == "totp not found" {
+			return nil, errors.New("two-factor authentication is not enrolled")
+		}
+		return nil, err
+	}
+	if userTOTP.Enabled() {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	step, ok := totp.Validate(userTOTP.Secret, code, time.Now())
+	if !ok {
+		return nil, errors.New("invalid code")
+	}
+
+	recoveryCodes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodeHashes := make([]string, len(recoveryCodes))
+	for i, recoveryCode := range recoveryCodes {
+		recoveryCodeHashes[i] = auth.HashOneTimeToken(totp.NormalizeRecoveryCode(recoveryCode))
+	}
+
+	if err := s.twoFactorRepository.EnableUserTOTP(userId, step, recoveryCodeHashes); err != nil {
+		if err.Error() == "totp already enabled" {
+			return nil, errors.New("two-factor authentication is already enabled")
+		}
+		return nil, err
+	}
+
+	return recoveryCodes, nil
+}
+
+// TOTPのコードまたはリカバリーコードを確認して2段階認証を無効にする
+func (s *TwoFactorService) DisableTOTP(userId uint, code string) error {
+	if err := s.VerifyCode(userId, code); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepository.DeleteUserTOTP(userId)
+}
+
+// userIdのユーザーの2段階認証が有効か
+func (s *TwoFactorService) IsEnabled(userId uint) (bool, error) {
+	userTOTP, err := s.twoFactorRepository.FindUserTOTP(userId)
+	if err != nil {
+		if err.Error() == "totp not found" {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return userTOTP.Enabled(), nil
+}
+
+// TOTPのコード(数字6桁)またはリカバリーコードを確認する
+// 使用済みのTOTPのコードとリカバリーコードは使用できない
+func (s *TwoFactorService) VerifyCode(userId uint, code string) error {
+	userTOTP, err := s.twoFactorRepository.FindUserTOTP(userId)
+	if err != nil && err.Error() != "totp not found" {
+		return err
+	}
+	if userTOTP == nil || !userTOTP.Enabled() {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	code = strings.TrimSpace(code)
+	if totpCodePattern.MatchString(code) {
+		step, ok := totp.Validate(userTOTP.Secret, code, time.Now())
+		if !ok || step <= userTOTP.LastUsedStep {
+			return errors.New("invalid code")
+		}
+		if err := s.twoFactorRepository.UpdateTOTPLastUsedStep(userId, step); err != nil {
+			if err.Error() == "totp code already used" {
+				return errors.New("invalid code")
+			}
+			return err
+		}
+		return nil
+	}
+
+	codeHash := auth.HashOneTimeToken(totp.NormalizeRecoveryCode(code))
+	if err := s.twoFactorRepository.UseRecoveryCode(userId, codeHash); err != nil {
+		if err.Error() == "recovery code not found" {
+			return errors.New("invalid code")
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/mention"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+)
+
+// ユーザー検索のクエリの最大文字数
+const MaxUserSearchQueryLength = 50
+
+// プロフィールの各項目の最大文字数
+const (
+	MaxNameLength     = 50
+	MaxBioLength      = 160
+	MaxLocationLength = 30
+	MaxWebsiteLength  = 100
+)
+
+type IUserService interface {
+	GetUserProfile(id uint) (*dtos.UserProfile, error)
+	GetUserProfileByUsername(username string) (*dtos.UserProfile, error)
+	SearchUsers(q string, page *pagination.Page) (*pagination.List[*dtos.UserProfile], error)
+	GetMe(userId uint) (*dtos.SelfUser, error)
+	UpdateMe(userId uint, input *dtos.UpdateProfileInput) (*dtos.SelfUser, error)
+}
+
+type UserService struct {
+	repository   repositories.IUserRepository
+	mediaService IMediaService
+}
+
+func NewUserService(repository repositories.IUserRepository, mediaService IMediaService) IUserService {
+	return &UserService{repository: repository, mediaService: mediaService}
+}
+
+// idのユーザーの公開プロフィールを取得
+func (s *UserService) GetUserProfile(id uint) (*dtos.UserProfile, error) {
+	user, err := s.repository.FindUserById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getUserProfile(user)
+}
+
+// usernameのユーザーの公開プロフィールを取得(先頭の@と大文字小文字は区別しない)
+func (s *UserService) GetUserProfileByUsername(username string) (*dtos.UserProfile, error) {
+	username = strings.TrimLeft(username, "@")
+	if !mention.ValidUsername(username) {
+		return nil, errors.New("user not found")
+	}
+
+	user, err := s.repository.FindUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getUserProfile(user)
+}
+
+// usernameがqで始まる、または名前にqを含むユーザーの公開プロフィールを新しい順に取得
+func (s *UserService) SearchUsers(q string, page *pagination.Page) (*pagination.List[*dtos.UserProfile], error) {
+	q = strings.TrimLeft(strings.TrimSpace(q), "@")
+	if q == "" {
+		return nil, errors.New("search query is empty")
+	}
+	if len([]rune(q)) > MaxUserSearchQueryLength {
+		return nil, errors.New("search query is too long")
+	}
+
+	users, err := s.repository.SearchUsers(q, page)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.newUserProfiles(users.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pagination.List[*dtos.UserProfile]{Items: profiles, NextCursor: users.NextCursor}, nil
+}
+
+// userIdのユーザー本人のプロフィールをemail、生年月日を含めて取得
+func (s *UserService) GetMe(userId uint) (*dtos.SelfUser, error) {
+	user, err := s.repository.FindUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return dtos.NewSelfUser(user), nil
+}
+
+// userIdのユーザーのプロフィールのうちinputで指定された項目のみを更新
+func (s *UserService) UpdateMe(userId uint, input *dtos.UpdateProfileInput) (*dtos.SelfUser, error) {
+	user, err := s.repository.FindUserById(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" || len([]rune(name)) > MaxNameLength {
+			return nil, errors.New("invalid name")
+		}
+		user.Name = name
+	}
+	if input.Username != nil {
+		if err := s.validateNewUsername(userId, *input.Username); err != nil {
+			return nil, err
+		}
+		user.Username = *input.Username
+	}
+	if input.Bio != nil {
+		bio := strings.TrimSpace(*input.Bio)
+		if len([]rune(bio)) > MaxBioLength {
+			return nil, errors.New("bio is too long")
+		}
+		user.Bio = bio
+	}
+	if input.Location != nil {
+		location := strings.TrimSpace(*input.Location)
+		if len([]rune(location)) > MaxLocationLength {
+			return nil, errors.New("location is too long")
+		}
+		user.Location = location
+	}
+	if input.Website != nil {
+		website, err := validateWebsite(*input.Website)
+		if err != nil {
+			return nil, err
+		}
+		user.Website = website
+	}
+	if input.AvatarMediaID != nil {
+		if user.AvatarMediaID, err = s.getProfileImageId(userId, *input.AvatarMediaID); err != nil {
+			return nil, err
+		}
+	}
+	if input.HeaderMediaID != nil {
+		if user.HeaderMediaID, err = s.getProfileImageId(userId, *input.HeaderMediaID); err != nil {
+			return nil, err
+		}
+	}
+
+	updatedUser, err := s.repository.UpdateUserProfile(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return dtos.NewSelfUser(updatedUser), nil
+}
+
+// usernameはサインアップ時と同じ形式のみ
+// 大文字小文字を区別せずにuniqueなので、自分以外のユーザーが使用している場合は変更できない(自分のusernameの大文字小文字は変更できる)
+func (s *UserService) validateNewUsername(userId uint, username string) error {
+	if !mention.ValidUsername(username) {
+		return errors.New("invalid username")
+	}
+
+	if user, err := s.repository.FindUserByUsername(username); err == nil {
+		if user.ID != userId {
+			return errors.New("username is already taken")
+		}
+	} else if err.Error() != "user not found" {
+		return err
+	}
+
+	return nil
+}
+
+// mediaIdが0の場合はプロフィール画像を削除する(nilを返す)
+func (s *UserService) getProfileImageId(userId, mediaId uint) (*uint, error) {
+	if mediaId == 0 {
+		return nil, nil
+	}
+
+	profileImage, err := s.mediaService.GetProfileImage(userId, mediaId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profileImage.ID, nil
+}
+
+// websiteはhttpまたはhttpsのURLのみ、空文字列の場合は削除する
+func validateWebsite(website string) (string, error) {
+	website = strings.TrimSpace(website)
+	if website == "" {
+		return "", nil
+	}
+	if len(website) > MaxWebsiteLength {
+		return "", errors.New("website is too long")
+	}
+
+	parsed, err := url.ParseRequestURI(website)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errors.New("invalid website")
+	}
+
+	return website, nil
+}
+
+func (s *UserService) getUserProfile(user *models.User) (*dtos.UserProfile, error) {
+	profiles, err := s.newUserProfiles([]*models.User{user})
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles[0], nil
+}
+
+// usersの公開プロフィールをフォロワー数、フォロー数、tweet数を含めて作成
+func (s *UserService) newUserProfiles(users []*models.User) ([]*dtos.UserProfile, error) {
+	profiles := make([]*dtos.UserProfile, 0, len(users))
+	if len(users) == 0 {
+		return profiles, nil
+	}
+
+	userIds := make([]uint, 0, len(users))
+	for _, user := range users {
+		userIds = append(userIds, user.ID)
+	}
+
+	stats, err := s.repository.CountUserStats(userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		profile := dtos.NewUserProfile(user)
+		if userStats, ok := stats[user.ID]; ok {
+			profile.FollowerCount = userStats.FollowerCount
+			profile.FollowingCount = userStats.FollowingCount
+			profile.TweetCount = userStats.TweetCount
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
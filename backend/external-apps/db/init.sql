@@ -1,56 +1,4 @@
 CREATE DATABASE IF NOT EXISTS tweet_app_database;
 
-USE tweet_app_database;
-
-CREATE TABLE users (
-    id INT PRIMARY KEY AUTO_INCREMENT,
-    name VARCHAR(255) NOT NULL,
-    email VARCHAR(255) UNIQUE NOT NULL,
-    password VARCHAR(255),
-    dob DATE,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-);
-
-CREATE TABLE followers (
-    id INT PRIMARY KEY AUTO_INCREMENT,
-    follower_id INT NOT NULL,
-    followee_id int NOT NULL,
-    FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
-    FOREIGN KEY (followee_id) REFERENCES users(id) ON DELETE CASCADE,
-    UNIQUE (follower_id, followee_id)
-);
-
-CREATE TABLE tweets (
-    id INT PRIMARY KEY AUTO_INCREMENT,
-    user_id INT NOT NULL,
-    type ENUM('text', 'image', 'video') NOT NULL,
-    content VARCHAR(140) NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-);
-
-CREATE TABLE feeds (
-    id INT PRIMARY KEY AUTO_INCREMENT,
-    user_id INT NOT NULL UNIQUE, -- users can only have one feed
-    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-);
-
-CREATE TABLE likes (
-    id INT PRIMARY KEY AUTO_INCREMENT,
-    user_id INT NOT NULL,
-    tweet_id INT NOT NULL,
-    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (user_id) REFERENCES users(id),
-    FOREIGN KEY (tweet_id) REFERENCES tweets(id),
-    UNIQUE (user_id, tweet_id)
-);
-
-CREATE TABLE feed_tweets (
-    id INT PRIMARY KEY AUTO_INCREMENT,
-    tweet_id INT NOT NULL,
-    feed_id INT NOT NULL,
-    FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE,
-    FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
-);
+-- tables are created by versioned migrations in backend/migrations
+-- run `go run . migrate up` (or `tweet-app migrate up`) after the database is created
@@ -1,22 +1,88 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
 	"github.com/daiki-kim/tweet-app/backend/configs"
+	"github.com/daiki-kim/tweet-app/backend/migrations"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
 	"github.com/daiki-kim/tweet-app/backend/routes"
 )
 
+// 終了時に処理中のリクエストを待つ時間
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	configs.InitializeConfig()
-	err := models.SetDatabase(configs.Config.DBInstance)
+	err := auth.LoadAccessTokenKeySet()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	err = models.SetDatabase(configs.Config.DBInstance)
 	if err != nil {
 		log.Fatal(err.Error())
 	}
 
 	db := models.DB
-	r := routes.SetupRouter(db)
 
-	r.Run()
+	// `tweet-app migrate up|down|status` でマイグレーションを実行する
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = migrations.RunCommand(db, os.Args[2:], os.Stdout)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
+	// サーバーはデフォルトではマイグレーションを適用しない(`tweet-app migrate up` を先に実行する)
+	// MIGRATE_ON_START=trueの場合は起動時に未適用のマイグレーションを全て適用する
+	if configs.GetEnvDefault("MIGRATE_ON_START", "false") == "true" {
+		err = migrations.Up(db)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+
+	r, stop := routes.SetupRouter(db)
+	defer stop()
+
+	// PORTが未設定の場合は8080で起動する(gin.Engine.Runと同じ)
+	server := &http.Server{
+		Addr:    ":" + configs.GetEnvDefault("PORT", "8080"),
+		Handler: r,
+	}
+
+	// SIGINT/SIGTERMを受け取ったら処理中のリクエストを待ってから終了する
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelShutdown()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Println("failed to shutdown server: ", err)
+		}
+	}()
+
+	log.Println("listening on ", server.Addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Println(err.Error())
+		return
+	}
+
+	// 処理中のリクエストが終わってからバックグラウンドの処理を停止する(defer stop)
+	<-shutdownDone
 }
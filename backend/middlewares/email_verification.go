@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	utils "github.com/daiki-kim/tweet-app/backend/pkg"
+	"github.com/gin-gonic/gin"
+)
+
+// rejects requests from users whose email is not verified yet
+// must be used after JwtTokenVerifier which sets user_id to context
+// when required is false every request is passed (see EMAIL_VERIFICATION_REQUIRED_TO_POST)
+func RequireVerifiedEmail(userRepository repositories.IUserRepository, required bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !required {
+			ctx.Next()
+			return
+		}
+
+		userId := utils.String2Uint(ctx.GetString("user_id"))
+		user, err := userRepository.FindUserById(userId)
+		if err != nil {
+			if err.Error() == "user not found" {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check email verification"})
+			return
+		}
+
+		if !user.EmailVerified() {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "email is not verified"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
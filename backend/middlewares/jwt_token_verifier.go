@@ -4,11 +4,13 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	utils "github.com/daiki-kim/tweet-app/backend/pkg"
 	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
 	"github.com/gin-gonic/gin"
 )
 
-func JwtTokenVerifier() gin.HandlerFunc {
+func JwtTokenVerifier(revocationRepository repositories.ITokenRevocationRepository) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// get header
 		authorizationHeader := ctx.Request.Header.Get("Authorization")
@@ -32,15 +34,34 @@ func JwtTokenVerifier() gin.HandlerFunc {
 		}
 
 		// Validate token
-		// TODO: serch about how to use verify key 2024-08-16
-		claims, err := auth.ValidateToken(tokenString, auth.TokenVerifyKey)
+		claims, err := auth.ValidateAccessToken(tokenString)
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
 
+		// refresh token cannot be used as access token
+		if claims.Subject != auth.Subject {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			return
+		}
+
+		// check token is not revoked by jti or by user
+		revoked, err := revocationRepository.IsRevoked(claims.ID, utils.String2Uint(claims.UserId), claims.IssuedAt.Time)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check token revocation"})
+			return
+		}
+		if revoked {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			return
+		}
+
 		// set email to context
 		ctx.Set("user_id", claims.UserId)
+		// set role and permissions to context (see RequirePermission)
+		ctx.Set("role", claims.Role)
+		ctx.Set("permissions", claims.Permissions)
 
 		ctx.Next()
 	}
@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rejects requests from users without the permission (see auth.RolePermissions)
+// must be used after JwtTokenVerifier which sets permissions of the access token to context
+// permissions are read from the token, so a role change takes effect when the access token is refreshed
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !slices.Contains(ctx.GetStringSlice("permissions"), permission) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission denied"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
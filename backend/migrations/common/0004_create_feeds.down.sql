@@ -0,0 +1,2 @@
+DROP TABLE feed_tweets;
+DROP TABLE feeds;
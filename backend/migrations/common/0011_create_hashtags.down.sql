@@ -0,0 +1,3 @@
+DROP TABLE tweet_hashtags;
+
+DROP TABLE hashtags;
@@ -0,0 +1,10 @@
+-- usage counts of trend terms per time slot
+-- term: hashtag with "#" or normalized word, slot_start: start of the time slot in UTC
+CREATE TABLE trend_buckets (
+    term VARCHAR(128) NOT NULL,
+    slot_start DATETIME NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (term, slot_start)
+);
+
+CREATE INDEX idx_trend_buckets_slot_start ON trend_buckets (slot_start);
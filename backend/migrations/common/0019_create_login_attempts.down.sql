@@ -0,0 +1,3 @@
+DROP TABLE login_lockouts;
+
+DROP TABLE login_attempts;
@@ -0,0 +1 @@
+-- removed feed tweets cannot be restored (tweets of unfollowed users are not shown in the timeline)
@@ -0,0 +1,13 @@
+-- feeds keep only tweets of the owner and the users the owner follows
+-- (tweets of unfollowed users were filtered when reading the timeline before, and are removed from feeds on unfollow now)
+DELETE FROM feed_tweets
+WHERE EXISTS (
+    SELECT 1 FROM feeds
+    JOIN tweets ON tweets.id = feed_tweets.tweet_id
+    WHERE feeds.id = feed_tweets.feed_id
+    AND tweets.user_id <> feeds.user_id
+    AND NOT EXISTS (
+        SELECT 1 FROM followers
+        WHERE followers.follower_id = feeds.user_id AND followers.followee_id = tweets.user_id
+    )
+);
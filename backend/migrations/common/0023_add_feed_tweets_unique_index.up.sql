@@ -0,0 +1,10 @@
+-- a tweet is delivered to a feed only once
+-- duplicates delivered before the index (self follow, backfill racing fan-out) are removed keeping the oldest row
+DELETE FROM feed_tweets
+WHERE id NOT IN (
+    SELECT id FROM (
+        SELECT MIN(id) AS id FROM feed_tweets GROUP BY feed_id, tweet_id
+    ) AS kept_feed_tweets
+);
+
+CREATE UNIQUE INDEX idx_feed_tweets_feed_tweet ON feed_tweets (feed_id, tweet_id);
@@ -0,0 +1,120 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/migrate"
+	"gorm.io/gorm"
+)
+
+// 同じバージョン番号のマイグレーションをMySQLとSQLiteの方言ごとに用意する
+// mysql/{version}_{name}.up.sql, sqlite/{version}_{name}.up.sql
+// 両方の方言で同じSQLになるファイルはcommon/に1つだけ置く(方言ごとのディレクトリに同じファイルがあるとエラー)
+//
+//go:embed common/*.sql mysql/*.sql sqlite/*.sql
+var FS embed.FS
+
+// 共通のマイグレーションとdbの方言のマイグレーションを読み込んだMigratorを作成
+func NewMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+	dialect, err := migrate.Dialect(db)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := Load(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(db, migrations), nil
+}
+
+// 共通のマイグレーションと方言のマイグレーションを合わせて読み込む
+func Load(dialect string) ([]*migrate.Migration, error) {
+	return migrate.Load(FS, "common", dialect)
+}
+
+// 未適用のマイグレーションを全て適用する
+func Up(db *gorm.DB) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	_, err = migrator.Up(0)
+	return err
+}
+
+// `migrate up [n]`, `migrate down [n]`, `migrate status` サブコマンドを実行する
+// up: nを省略すると未適用のマイグレーションを全て適用する
+// down: nを省略すると最後に適用したマイグレーションを1つ戻す
+func RunCommand(db *gorm.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up [n] | down [n] | status")
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		steps, err := getSteps(args, 0)
+		if err != nil {
+			return err
+		}
+		applied, err := migrator.Up(steps)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+
+	case "down":
+		steps, err := getSteps(args, 1)
+		if err != nil {
+			return err
+		}
+		rolledBack, err := migrator.Down(steps)
+		for _, migration := range rolledBack {
+			fmt.Fprintf(out, "rolled back %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Dirty {
+				state = "dirty"
+			} else if status.Applied {
+				state = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+}
+
+func getSteps(args []string, defaultSteps int) (int, error) {
+	if len(args) < 2 {
+		return defaultSteps, nil
+	}
+
+	steps, err := strconv.Atoi(args[1])
+	if err != nil || steps < 1 {
+		return 0, errors.New("steps must be a positive number")
+	}
+
+	return steps, nil
+}
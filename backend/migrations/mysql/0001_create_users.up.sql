@@ -0,0 +1,8 @@
+CREATE TABLE users (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    password VARCHAR(255),
+    dob DATE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,15 @@
+-- users can only have one feed
+CREATE TABLE feeds (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL UNIQUE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE feed_tweets (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    tweet_id INT NOT NULL,
+    feed_id INT NOT NULL,
+    FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE,
+    FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+);
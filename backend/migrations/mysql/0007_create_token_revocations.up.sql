@@ -0,0 +1,20 @@
+-- token_id: jti of access token
+CREATE TABLE revoked_tokens (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    token_id VARCHAR(36) NOT NULL UNIQUE,
+    user_id INT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX (expires_at)
+);
+
+-- access tokens of the user issued before revoked_before are revoked
+CREATE TABLE user_token_revocations (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL UNIQUE,
+    revoked_before TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX (expires_at)
+);
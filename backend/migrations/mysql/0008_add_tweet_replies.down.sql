@@ -0,0 +1,6 @@
+ALTER TABLE tweets
+    DROP FOREIGN KEY fk_tweets_in_reply_to_tweet,
+    DROP INDEX idx_tweets_conversation_id,
+    DROP COLUMN in_reply_to_tweet_id,
+    DROP COLUMN conversation_id,
+    DROP COLUMN deleted_at;
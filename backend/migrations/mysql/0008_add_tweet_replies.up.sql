@@ -0,0 +1,8 @@
+-- conversation_id: id of the first tweet of the reply chain
+-- deleted_at: deleted tweets are kept as tombstones so that threads do not break
+ALTER TABLE tweets
+    ADD COLUMN in_reply_to_tweet_id INT NULL,
+    ADD COLUMN conversation_id INT NULL,
+    ADD COLUMN deleted_at TIMESTAMP NULL,
+    ADD CONSTRAINT fk_tweets_in_reply_to_tweet FOREIGN KEY (in_reply_to_tweet_id) REFERENCES tweets(id) ON DELETE SET NULL,
+    ADD INDEX idx_tweets_conversation_id (conversation_id);
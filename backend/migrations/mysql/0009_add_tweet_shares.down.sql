@@ -0,0 +1,10 @@
+DELETE FROM tweets WHERE type IN ('retweet', 'quote');
+
+ALTER TABLE tweets
+    DROP FOREIGN KEY fk_tweets_retweeted_tweet,
+    DROP FOREIGN KEY fk_tweets_quoted_tweet,
+    DROP INDEX idx_tweets_user_retweet,
+    DROP INDEX idx_tweets_quoted_tweet_id,
+    DROP COLUMN retweeted_tweet_id,
+    DROP COLUMN quoted_tweet_id,
+    MODIFY COLUMN type ENUM('text', 'image', 'video') NOT NULL;
@@ -0,0 +1,10 @@
+-- retweeted_tweet_id: original tweet of retweet (a user can retweet the same tweet only once)
+-- quoted_tweet_id: original tweet of quote tweet
+ALTER TABLE tweets
+    MODIFY COLUMN type ENUM('text', 'image', 'video', 'retweet', 'quote') NOT NULL,
+    ADD COLUMN retweeted_tweet_id INT NULL,
+    ADD COLUMN quoted_tweet_id INT NULL,
+    ADD CONSTRAINT fk_tweets_retweeted_tweet FOREIGN KEY (retweeted_tweet_id) REFERENCES tweets(id) ON DELETE CASCADE,
+    ADD CONSTRAINT fk_tweets_quoted_tweet FOREIGN KEY (quoted_tweet_id) REFERENCES tweets(id) ON DELETE SET NULL,
+    ADD UNIQUE INDEX idx_tweets_user_retweet (user_id, retweeted_tweet_id),
+    ADD INDEX idx_tweets_quoted_tweet_id (quoted_tweet_id);
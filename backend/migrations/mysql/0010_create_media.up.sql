@@ -0,0 +1,19 @@
+-- images and videos attached to tweets
+-- tweet_id is NULL until the uploaded media is attached to a tweet
+CREATE TABLE media (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    tweet_id INT NULL,
+    type ENUM('image', 'video') NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    url VARCHAR(1024) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL DEFAULT '',
+    thumbnail_url VARCHAR(1024) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE,
+    INDEX idx_media_user_id (user_id),
+    INDEX idx_media_tweet_id (tweet_id)
+);
@@ -0,0 +1,15 @@
+-- name: normalized hashtag without leading "#" (NFKC and lower case)
+CREATE TABLE hashtags (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE tweet_hashtags (
+    tweet_id INT NOT NULL,
+    hashtag_id INT NOT NULL,
+    PRIMARY KEY (tweet_id, hashtag_id),
+    FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE,
+    FOREIGN KEY (hashtag_id) REFERENCES hashtags(id) ON DELETE CASCADE,
+    INDEX idx_tweet_hashtags_hashtag_id (hashtag_id)
+);
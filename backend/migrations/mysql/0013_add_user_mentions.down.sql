@@ -0,0 +1,5 @@
+DROP TABLE tweet_mentions;
+
+ALTER TABLE users
+    DROP INDEX idx_users_username,
+    DROP COLUMN username;
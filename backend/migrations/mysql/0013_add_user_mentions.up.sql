@@ -0,0 +1,23 @@
+-- username: handle used for @mentions, unique regardless of case (utf8mb4 default collation is case-insensitive)
+-- existing users get "user{id}" and can change it later with PATCH /me
+ALTER TABLE users ADD COLUMN username VARCHAR(15) NULL AFTER name;
+
+UPDATE users SET username = CONCAT('user', id);
+
+ALTER TABLE users
+    MODIFY COLUMN username VARCHAR(15) NOT NULL,
+    ADD UNIQUE INDEX idx_users_username (username);
+
+-- start, end: offsets of "@username" in the tweet content in characters (end is exclusive)
+CREATE TABLE tweet_mentions (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    tweet_id INT NOT NULL,
+    user_id INT NOT NULL,
+    username VARCHAR(15) NOT NULL,
+    start INT NOT NULL,
+    end INT NOT NULL,
+    FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX idx_tweet_mentions_tweet_id (tweet_id),
+    INDEX idx_tweet_mentions_user_id (user_id)
+);
@@ -0,0 +1 @@
+ALTER TABLE tweets DROP INDEX idx_tweets_content_fulltext;
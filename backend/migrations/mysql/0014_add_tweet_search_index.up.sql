@@ -0,0 +1,3 @@
+-- full text index of tweets.content for GET /search/tweets
+-- ngram parser splits text into ngram_token_size (default 2) characters so that Japanese text can be searched
+ALTER TABLE tweets ADD FULLTEXT INDEX idx_tweets_content_fulltext (content) WITH PARSER ngram;
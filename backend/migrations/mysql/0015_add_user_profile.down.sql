@@ -0,0 +1,10 @@
+ALTER TABLE users
+    DROP FOREIGN KEY fk_users_header_media,
+    DROP FOREIGN KEY fk_users_avatar_media;
+
+ALTER TABLE users
+    DROP COLUMN header_media_id,
+    DROP COLUMN avatar_media_id,
+    DROP COLUMN website,
+    DROP COLUMN location,
+    DROP COLUMN bio;
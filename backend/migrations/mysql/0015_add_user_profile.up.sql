@@ -0,0 +1,10 @@
+-- profile fields editable with PATCH /me
+-- avatar_media_id, header_media_id: images uploaded with POST /media, cleared when the media is deleted
+ALTER TABLE users
+    ADD COLUMN bio VARCHAR(160) NOT NULL DEFAULT '' AFTER dob,
+    ADD COLUMN location VARCHAR(30) NOT NULL DEFAULT '' AFTER bio,
+    ADD COLUMN website VARCHAR(100) NOT NULL DEFAULT '' AFTER location,
+    ADD COLUMN avatar_media_id INT NULL AFTER website,
+    ADD COLUMN header_media_id INT NULL AFTER avatar_media_id,
+    ADD CONSTRAINT fk_users_avatar_media FOREIGN KEY (avatar_media_id) REFERENCES media(id) ON DELETE SET NULL,
+    ADD CONSTRAINT fk_users_header_media FOREIGN KEY (header_media_id) REFERENCES media(id) ON DELETE SET NULL;
@@ -0,0 +1,19 @@
+-- email_verified_at: NULL until the user opens the link in the verification email
+-- existing users are treated as verified
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP NULL AFTER dob;
+
+UPDATE users SET email_verified_at = created_at;
+
+-- token_hash: sha256 of the token sent by email (the token itself is never stored)
+-- email: address the token was sent to, the token is invalid once the user's email changes
+CREATE TABLE email_verification_tokens (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX (user_id, created_at)
+);
@@ -0,0 +1,23 @@
+-- secret: base32 TOTP secret shared with the authenticator app
+-- confirmed_at: NULL until the first code is verified, two-factor authentication is enabled after that
+-- last_used_step: time step of the last accepted code, a code cannot be used twice
+CREATE TABLE user_totps (
+    user_id INT PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- code_hash: sha256 of the recovery code shown once to the user (the code itself is never stored)
+-- used_at: set when the code is consumed, a code can be used only once
+CREATE TABLE recovery_codes (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    used_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    INDEX (user_id)
+);
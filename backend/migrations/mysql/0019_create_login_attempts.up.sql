@@ -0,0 +1,21 @@
+-- identifier: "account:<email>" or "ip:<ip address>"
+-- failures: consecutive failed logins, reset to 0 on successful login and when the identifier is locked
+-- locked_until: logins are rejected until this time after too many failures
+CREATE TABLE login_attempts (
+    identifier VARCHAR(255) PRIMARY KEY,
+    failures INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL,
+    INDEX (last_failed_at)
+);
+
+-- audit log of lockouts, kept after the lockout expires
+CREATE TABLE login_lockouts (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    identifier VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    failures INT NOT NULL,
+    locked_until TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX (identifier)
+);
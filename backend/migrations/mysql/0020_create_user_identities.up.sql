@@ -0,0 +1,14 @@
+-- provider, subject: account of an external provider (subject is the provider's stable user id, email can change)
+-- a user can link one account per provider
+-- email: email at the provider when the account was linked or last used to login, for display only
+CREATE TABLE user_identities (
+    id INT PRIMARY KEY AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE INDEX idx_user_identities_provider_subject (provider, subject),
+    UNIQUE INDEX idx_user_identities_user_id_provider (user_id, provider)
+);
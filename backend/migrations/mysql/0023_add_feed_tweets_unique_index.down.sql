@@ -0,0 +1,4 @@
+-- the foreign key of feed_id needs an index after the unique index is dropped
+ALTER TABLE feed_tweets
+    ADD INDEX idx_feed_tweets_feed_id (feed_id),
+    DROP INDEX idx_feed_tweets_feed_tweet;
@@ -0,0 +1,10 @@
+-- SQLite has no ENUM type, so tweet type is checked by CHECK constraint
+CREATE TABLE tweets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    type VARCHAR(5) NOT NULL CHECK (type IN ('text', 'image', 'video')),
+    content VARCHAR(140) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
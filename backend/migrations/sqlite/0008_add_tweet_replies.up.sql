@@ -0,0 +1,8 @@
+-- conversation_id: id of the first tweet of the reply chain
+-- deleted_at: deleted tweets are kept as tombstones so that threads do not break
+ALTER TABLE tweets ADD COLUMN in_reply_to_tweet_id INTEGER NULL;
+ALTER TABLE tweets ADD COLUMN conversation_id INTEGER NULL;
+ALTER TABLE tweets ADD COLUMN deleted_at DATETIME NULL;
+
+CREATE INDEX idx_tweets_in_reply_to_tweet_id ON tweets (in_reply_to_tweet_id);
+CREATE INDEX idx_tweets_conversation_id ON tweets (conversation_id);
@@ -0,0 +1,29 @@
+-- migrate:foreign_keys off
+-- foreign keys are disabled while rebuilding, so rows referencing retweets and quotes are deleted explicitly
+DELETE FROM likes WHERE tweet_id IN (SELECT id FROM tweets WHERE type IN ('retweet', 'quote'));
+DELETE FROM feed_tweets WHERE tweet_id IN (SELECT id FROM tweets WHERE type IN ('retweet', 'quote'));
+
+-- rebuild tweets table without retweets and quotes
+CREATE TABLE tweets_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    type VARCHAR(5) NOT NULL CHECK (type IN ('text', 'image', 'video')),
+    content VARCHAR(140) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    in_reply_to_tweet_id INTEGER NULL,
+    conversation_id INTEGER NULL,
+    deleted_at DATETIME NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO tweets_old (id, user_id, type, content, created_at, updated_at, in_reply_to_tweet_id, conversation_id, deleted_at)
+SELECT id, user_id, type, content, created_at, updated_at, in_reply_to_tweet_id, conversation_id, deleted_at FROM tweets
+WHERE type NOT IN ('retweet', 'quote');
+
+DROP TABLE tweets;
+
+ALTER TABLE tweets_old RENAME TO tweets;
+
+CREATE INDEX idx_tweets_in_reply_to_tweet_id ON tweets (in_reply_to_tweet_id);
+CREATE INDEX idx_tweets_conversation_id ON tweets (conversation_id);
@@ -0,0 +1,32 @@
+-- migrate:foreign_keys off
+-- SQLite cannot alter CHECK constraint of tweets.type, so the table is rebuilt
+-- retweeted_tweet_id: original tweet of retweet (a user can retweet the same tweet only once)
+-- quoted_tweet_id: original tweet of quote tweet
+CREATE TABLE tweets_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    type VARCHAR(7) NOT NULL CHECK (type IN ('text', 'image', 'video', 'retweet', 'quote')),
+    content VARCHAR(140) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    in_reply_to_tweet_id INTEGER NULL,
+    conversation_id INTEGER NULL,
+    deleted_at DATETIME NULL,
+    retweeted_tweet_id INTEGER NULL,
+    quoted_tweet_id INTEGER NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (retweeted_tweet_id) REFERENCES tweets(id) ON DELETE CASCADE,
+    FOREIGN KEY (quoted_tweet_id) REFERENCES tweets(id) ON DELETE SET NULL
+);
+
+INSERT INTO tweets_new (id, user_id, type, content, created_at, updated_at, in_reply_to_tweet_id, conversation_id, deleted_at)
+SELECT id, user_id, type, content, created_at, updated_at, in_reply_to_tweet_id, conversation_id, deleted_at FROM tweets;
+
+DROP TABLE tweets;
+
+ALTER TABLE tweets_new RENAME TO tweets;
+
+CREATE INDEX idx_tweets_in_reply_to_tweet_id ON tweets (in_reply_to_tweet_id);
+CREATE INDEX idx_tweets_conversation_id ON tweets (conversation_id);
+CREATE UNIQUE INDEX idx_tweets_user_retweet ON tweets (user_id, retweeted_tweet_id);
+CREATE INDEX idx_tweets_quoted_tweet_id ON tweets (quoted_tweet_id);
@@ -0,0 +1,20 @@
+-- images and videos attached to tweets
+-- tweet_id is NULL until the uploaded media is attached to a tweet
+CREATE TABLE media (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    tweet_id INTEGER NULL,
+    type VARCHAR(5) NOT NULL CHECK (type IN ('image', 'video')),
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    url VARCHAR(1024) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL DEFAULT '',
+    thumbnail_url VARCHAR(1024) NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_media_user_id ON media (user_id);
+CREATE INDEX idx_media_tweet_id ON media (tweet_id);
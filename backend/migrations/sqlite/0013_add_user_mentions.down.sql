@@ -0,0 +1,5 @@
+DROP TABLE tweet_mentions;
+
+DROP INDEX idx_users_username;
+
+ALTER TABLE users DROP COLUMN username;
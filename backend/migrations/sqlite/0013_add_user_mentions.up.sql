@@ -0,0 +1,22 @@
+-- username: handle used for @mentions, unique regardless of case (COLLATE NOCASE)
+-- existing users get "user{id}" and can change it later with PATCH /me
+ALTER TABLE users ADD COLUMN username VARCHAR(15) COLLATE NOCASE NOT NULL DEFAULT '';
+
+UPDATE users SET username = 'user' || id;
+
+CREATE UNIQUE INDEX idx_users_username ON users (username);
+
+-- start, end: offsets of "@username" in the tweet content in characters (end is exclusive)
+CREATE TABLE tweet_mentions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tweet_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    username VARCHAR(15) NOT NULL,
+    start INTEGER NOT NULL,
+    end INTEGER NOT NULL,
+    FOREIGN KEY (tweet_id) REFERENCES tweets(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_tweet_mentions_tweet_id ON tweet_mentions (tweet_id);
+CREATE INDEX idx_tweet_mentions_user_id ON tweet_mentions (user_id);
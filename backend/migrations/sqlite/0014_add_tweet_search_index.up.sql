@@ -0,0 +1,3 @@
+-- SQLite has no FULLTEXT index
+-- the FTS5 table tweets_fts and its triggers are created by search.NewSQLiteIndex
+-- because FTS5 is available only when SQLite is built with it (go test -tags sqlite_fts5)
@@ -0,0 +1,5 @@
+ALTER TABLE users DROP COLUMN header_media_id;
+ALTER TABLE users DROP COLUMN avatar_media_id;
+ALTER TABLE users DROP COLUMN website;
+ALTER TABLE users DROP COLUMN location;
+ALTER TABLE users DROP COLUMN bio;
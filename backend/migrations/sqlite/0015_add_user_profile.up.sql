@@ -0,0 +1,7 @@
+-- profile fields editable with PATCH /me
+-- avatar_media_id, header_media_id: images uploaded with POST /media, cleared when the media is deleted
+ALTER TABLE users ADD COLUMN bio VARCHAR(160) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN location VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN website VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN avatar_media_id INTEGER NULL REFERENCES media(id) ON DELETE SET NULL;
+ALTER TABLE users ADD COLUMN header_media_id INTEGER NULL REFERENCES media(id) ON DELETE SET NULL;
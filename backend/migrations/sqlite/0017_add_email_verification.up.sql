@@ -0,0 +1,20 @@
+-- email_verified_at: NULL until the user opens the link in the verification email
+-- existing users are treated as verified
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME NULL;
+
+UPDATE users SET email_verified_at = created_at;
+
+-- token_hash: sha256 of the token sent by email (the token itself is never stored)
+-- email: address the token was sent to, the token is invalid once the user's email changes
+CREATE TABLE email_verification_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_email_verification_tokens_user_id_created_at ON email_verification_tokens (user_id, created_at);
@@ -0,0 +1,24 @@
+-- secret: base32 TOTP secret shared with the authenticator app
+-- confirmed_at: NULL until the first code is verified, two-factor authentication is enabled after that
+-- last_used_step: time step of the last accepted code, a code cannot be used twice
+CREATE TABLE user_totps (
+    user_id INTEGER PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at DATETIME NULL,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- code_hash: sha256 of the recovery code shown once to the user (the code itself is never stored)
+-- used_at: set when the code is consumed, a code can be used only once
+CREATE TABLE recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    used_at DATETIME NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes (user_id);
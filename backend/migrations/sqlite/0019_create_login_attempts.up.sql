@@ -0,0 +1,23 @@
+-- identifier: "account:<email>" or "ip:<ip address>"
+-- failures: consecutive failed logins, reset to 0 on successful login and when the identifier is locked
+-- locked_until: logins are rejected until this time after too many failures
+CREATE TABLE login_attempts (
+    identifier VARCHAR(255) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failed_at DATETIME NOT NULL,
+    locked_until DATETIME NULL
+);
+
+CREATE INDEX idx_login_attempts_last_failed_at ON login_attempts (last_failed_at);
+
+-- audit log of lockouts, kept after the lockout expires
+CREATE TABLE login_lockouts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    identifier VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    failures INTEGER NOT NULL,
+    locked_until DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_lockouts_identifier ON login_lockouts (identifier);
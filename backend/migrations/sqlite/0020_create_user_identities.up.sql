@@ -0,0 +1,15 @@
+-- provider, subject: account of an external provider (subject is the provider's stable user id, email can change)
+-- a user can link one account per provider
+-- email: email at the provider when the account was linked or last used to login, for display only
+CREATE TABLE user_identities (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_user_identities_provider_subject ON user_identities (provider, subject);
+CREATE UNIQUE INDEX idx_user_identities_user_id_provider ON user_identities (user_id, provider);
@@ -0,0 +1,6 @@
+-- role: user, moderator or admin (permissions of each role are defined in pkg/auth)
+-- there is no API to grant roles, promote the first admin with
+--   UPDATE users SET role = 'admin' WHERE id = ?;
+-- suspended_at: NULL unless the account is suspended by a moderator or admin, suspended users cannot login
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN suspended_at DATETIME NULL;
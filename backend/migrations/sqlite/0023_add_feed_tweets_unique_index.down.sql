@@ -0,0 +1 @@
+DROP INDEX idx_feed_tweets_feed_tweet;
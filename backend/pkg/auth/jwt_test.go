@@ -160,3 +160,96 @@ func TestValidateToken_InvalidToken(t *testing.T) {
 		t.Fatal("Expected error due to invalid token, got nil")
 	}
 }
+
+// リフレッシュトークンが検証されるテスト
+func TestValidateRefreshToken_ValidToken(t *testing.T) {
+	// テスト用のリフレッシュトークンを生成
+	testCustomClaim := auth.NewClaim("1")
+	testCustomClaim.FamilyId = "test-family"
+	tokenString, err := testCustomClaim.GenerateRefreshToken()
+	if err != nil {
+		t.Fatalf("Failed to generate test refresh token: %v", err)
+	}
+
+	// リフレッシュトークンを検証
+	claims, err := auth.ValidateRefreshToken(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateRefreshToken returned an error: %v", err)
+	}
+
+	// クレームが正しいか確認
+	if claims.UserId != "1" || claims.FamilyId != "test-family" {
+		t.Errorf("Expected user 1 of test-family, got %v of %v", claims.UserId, claims.FamilyId)
+	}
+}
+
+// アクセストークンはリフレッシュトークンとして検証されないテスト
+func TestValidateRefreshToken_AccessToken(t *testing.T) {
+	// テスト用のアクセストークンを生成
+	tokenString, err := auth.NewClaim("1").GenerateToken()
+	if err != nil {
+		t.Fatalf("Failed to generate test token: %v", err)
+	}
+
+	// リフレッシュトークンとして検証
+	_, err = auth.ValidateRefreshToken(tokenString)
+	if err == nil {
+		t.Fatal("Expected error due to access token, got nil")
+	}
+}
+
+// mfaトークンが検証されるテスト
+func TestValidateMFAToken_ValidToken(t *testing.T) {
+	// テスト用のmfaトークンを生成
+	tokenString, err := auth.NewClaim("1").GenerateMFAToken()
+	if err != nil {
+		t.Fatalf("Failed to generate test mfa token: %v", err)
+	}
+
+	// mfaトークンを検証
+	claims, err := auth.ValidateMFAToken(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateMFAToken returned an error: %v", err)
+	}
+
+	// クレームが正しいか確認
+	if claims.UserId != "1" || claims.Subject != auth.MFATokenSubject {
+		t.Errorf("Expected mfa token of user 1, got %v of %v", claims.Subject, claims.UserId)
+	}
+	if len(claims.Audience) != 1 || claims.Audience[0] != auth.MFATokenAudience {
+		t.Errorf("Expected audience %v, got %v", auth.MFATokenAudience, claims.Audience)
+	}
+	if claims.ExpiresAt.Sub(claims.IssuedAt.Time) != auth.MFATokenExpiration {
+		t.Errorf("Expected expiration %v, got %v", auth.MFATokenExpiration, claims.ExpiresAt.Sub(claims.IssuedAt.Time))
+	}
+}
+
+// アクセストークンはmfaトークンとして検証されないテスト
+func TestValidateMFAToken_AccessToken(t *testing.T) {
+	// テスト用のアクセストークンを生成
+	tokenString, err := auth.NewClaim("1").GenerateToken()
+	if err != nil {
+		t.Fatalf("Failed to generate test token: %v", err)
+	}
+
+	// mfaトークンとして検証
+	_, err = auth.ValidateMFAToken(tokenString)
+	if err == nil {
+		t.Fatal("Expected error due to access token, got nil")
+	}
+}
+
+// mfaトークンはaudienceが違うのでアクセストークンとして検証されないテスト
+func TestValidateAccessToken_MFAToken(t *testing.T) {
+	// テスト用のmfaトークンを生成
+	tokenString, err := auth.NewClaim("1").GenerateMFAToken()
+	if err != nil {
+		t.Fatalf("Failed to generate test mfa token: %v", err)
+	}
+
+	// アクセストークンとして検証
+	_, err = auth.ValidateAccessToken(tokenString)
+	if err == nil {
+		t.Fatal("Expected error due to mfa token, got nil")
+	}
+}
@@ -0,0 +1,444 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/daiki-kim/tweet-app/backend/configs"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signing algorithms supported for access tokens
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+
+	// kid of the shared HS256 secret (HS256 keys are never published in JWKS)
+	hmacKeyId = "hs256"
+)
+
+// key used to verify access tokens
+// Key: []byte for HS256, *rsa.PublicKey for RS256, ed25519.PublicKey for EdDSA
+type VerificationKey struct {
+	KeyId  string
+	Method jwt.SigningMethod
+	Key    interface{}
+}
+
+// set of keys for access tokens
+// signs with one key and verifies with every active key so that keys can be rotated
+// without invalidating tokens signed by the previous key
+type KeySet struct {
+	signingKeyId     string
+	signingMethod    jwt.SigningMethod
+	signingKey       interface{}
+	verificationKeys map[string]*VerificationKey
+}
+
+// JSON Web Key (RFC 7517) of a public verification key
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSON Web Key Set served on /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var accessTokenKeySet atomic.Pointer[KeySet]
+
+func init() {
+	accessTokenKeySet.Store(NewHMACKeySet(tokenSignKey, TokenVerifyKey))
+}
+
+// create key set which signs with HS256 secret
+func NewHMACKeySet(signKey, verifyKey []byte) *KeySet {
+	return &KeySet{
+		signingKeyId:  hmacKeyId,
+		signingMethod: jwt.SigningMethodHS256,
+		signingKey:    signKey,
+		verificationKeys: map[string]*VerificationKey{
+			hmacKeyId: {KeyId: hmacKeyId, Method: jwt.SigningMethodHS256, Key: verifyKey},
+		},
+	}
+}
+
+// create key set which signs with RSA(RS256) or Ed25519(EdDSA) private key
+// publicKeys: previous public keys which are still accepted while rotating keys
+// kid of each key is its JWK thumbprint (RFC 7638)
+func NewKeySet(privateKey crypto.Signer, publicKeys ...crypto.PublicKey) (*KeySet, error) {
+	signingKey, err := newVerificationKey(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	keySet := &KeySet{
+		signingKeyId:     signingKey.KeyId,
+		signingMethod:    signingKey.Method,
+		signingKey:       privateKey,
+		verificationKeys: map[string]*VerificationKey{signingKey.KeyId: signingKey},
+	}
+
+	for _, publicKey := range publicKeys {
+		verificationKey, err := newVerificationKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		keySet.verificationKeys[verificationKey.KeyId] = verificationKey
+	}
+
+	return keySet, nil
+}
+
+// load key set from environment variables
+// TOKEN_SIGN_ALGORITHM: HS256(default), RS256 or EdDSA
+// TOKEN_SIGN_KEY_FILE: PEM private key file used by RS256 and EdDSA
+// TOKEN_VERIFY_KEY_FILES: comma separated PEM public key files still accepted after rotation
+func LoadKeySetFromEnv() (*KeySet, error) {
+	algorithm := configs.GetEnvDefault("TOKEN_SIGN_ALGORITHM", AlgorithmHS256)
+	if algorithm == AlgorithmHS256 {
+		return NewHMACKeySet(
+			[]byte(configs.GetEnvDefault("TOKEN_SIGN_KEY", "secret")),
+			[]byte(configs.GetEnvDefault("TOKEN_VERIFY_KEY", "secret")),
+		), nil
+	}
+	if algorithm != AlgorithmRS256 && algorithm != AlgorithmEdDSA {
+		return nil, fmt.Errorf("unsupported token sign algorithm: %s", algorithm)
+	}
+
+	privateKey, err := LoadPrivateKeyFile(configs.GetEnvDefault("TOKEN_SIGN_KEY_FILE", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKeys []crypto.PublicKey
+	for _, path := range strings.Split(configs.GetEnvDefault("TOKEN_VERIFY_KEY_FILES", ""), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		publicKey, err := LoadPublicKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	keySet, err := NewKeySet(privateKey, publicKeys...)
+	if err != nil {
+		return nil, err
+	}
+	if keySet.signingMethod.Alg() != algorithm {
+		return nil, fmt.Errorf("token sign key is not a %s key", algorithm)
+	}
+
+	return keySet, nil
+}
+
+// load key set from environment variables and use it for access tokens
+func LoadAccessTokenKeySet() error {
+	keySet, err := LoadKeySetFromEnv()
+	if err != nil {
+		return err
+	}
+
+	SetAccessTokenKeySet(keySet)
+	return nil
+}
+
+// replace key set used for access tokens
+func SetAccessTokenKeySet(keySet *KeySet) {
+	accessTokenKeySet.Store(keySet)
+}
+
+// get key set used for access tokens
+func AccessTokenKeySet() *KeySet {
+	return accessTokenKeySet.Load()
+}
+
+// sign claims with signing key and set kid header
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signingMethod, claims)
+	token.Header["kid"] = k.signingKeyId
+
+	return token.SignedString(k.signingKey)
+}
+
+// jwt.Keyfunc which selects verification key by kid header
+// tokens without kid are accepted only by HS256 key set (tokens issued before kid was added)
+func (k *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	keyId, _ := token.Header["kid"].(string)
+	if keyId == "" && k.signingMethod == jwt.SigningMethodHS256 {
+		keyId = hmacKeyId
+	}
+
+	verificationKey, ok := k.verificationKeys[keyId]
+	if !ok {
+		return nil, errors.New("unknown key id")
+	}
+
+	// reject tokens whose alg header does not match the key (algorithm confusion)
+	if token.Method.Alg() != verificationKey.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	return verificationKey.Key, nil
+}
+
+// algorithms of active verification keys
+func (k *KeySet) ValidMethods() []string {
+	var methods []string
+	seen := map[string]bool{}
+	for _, verificationKey := range k.verificationKeys {
+		alg := verificationKey.Method.Alg()
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+
+	return methods
+}
+
+// public keys of key set in JWKS format
+// signing key comes first, HS256 secrets are not included
+func (k *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+
+	if signingKey, ok := k.verificationKeys[k.signingKeyId]; ok {
+		if jwk, ok := signingKey.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	var keyIds []string
+	for keyId := range k.verificationKeys {
+		if keyId != k.signingKeyId {
+			keyIds = append(keyIds, keyId)
+		}
+	}
+	sort.Strings(keyIds)
+	for _, keyId := range keyIds {
+		if jwk, ok := k.verificationKeys[keyId].JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	return jwks
+}
+
+// convert verification key to JWK
+// returns false for HS256 keys which must not be published
+func (v *VerificationKey) JWK() (JWK, bool) {
+	switch key := v.Key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: v.Method.Alg(),
+			Kid: v.KeyId,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: v.Method.Alg(),
+			Kid: v.KeyId,
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// convert JWK to public key
+// supports RSA, EC(P-256, P-384, P-521) and OKP(Ed25519) keys
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", j.Crv)
+		}
+		x, err := decodeJWKInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("invalid EC point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve: %s", j.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type: %s", j.Kty)
+	}
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("invalid JWK parameter")
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
+
+// load RSA(PKCS#1 or PKCS#8) or Ed25519(PKCS#8) private key from PEM file
+func LoadPrivateKeyFile(path string) (crypto.Signer, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case ed25519.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type in %s", path)
+	}
+}
+
+// load RSA or Ed25519 public key from PEM file (PKIX public key or certificate)
+func LoadPublicKeyFile(path string) (crypto.PublicKey, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return certificate.PublicKey, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+}
+
+func readPEMFile(path string) (*pem.Block, error) {
+	if path == "" {
+		return nil, errors.New("key file is not set")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	return block, nil
+}
+
+func newVerificationKey(publicKey crypto.PublicKey) (*VerificationKey, error) {
+	verificationKey := &VerificationKey{Key: publicKey}
+
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		verificationKey.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		verificationKey.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("unsupported public key type")
+	}
+
+	jwk, _ := verificationKey.JWK()
+	verificationKey.KeyId = thumbprint(jwk)
+	return verificationKey, nil
+}
+
+// JWK thumbprint (RFC 7638): SHA-256 of required members in lexicographic order
+func thumbprint(jwk JWK) string {
+	var members []byte
+	if jwk.Kty == "RSA" {
+		members, _ = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N})
+	} else {
+		members, _ = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X})
+	}
+
+	sum := sha256.Sum256(members)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
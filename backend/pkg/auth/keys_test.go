@@ -0,0 +1,277 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// テスト用にアクセストークンの鍵セットを差し替え、テスト終了時に元に戻す
+func useKeySet(t *testing.T, keySet *auth.KeySet) {
+	original := auth.AccessTokenKeySet()
+	auth.SetAccessTokenKeySet(keySet)
+	t.Cleanup(func() { auth.SetAccessTokenKeySet(original) })
+}
+
+// テスト用の秘密鍵と公開鍵のPEMファイルを作成する
+func writeKeyFiles(t *testing.T, privateKey crypto.Signer) (privateKeyFile, publicKeyFile string) {
+	dir := t.TempDir()
+
+	privateDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal private key: %v", err)
+	}
+	publicDer, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+
+	privateKeyFile = filepath.Join(dir, "private.pem")
+	publicKeyFile = filepath.Join(dir, "public.pem")
+	if err := os.WriteFile(privateKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDer}), 0600); err != nil {
+		t.Fatalf("failed to write private key: %v", err)
+	}
+	if err := os.WriteFile(publicKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer}), 0600); err != nil {
+		t.Fatalf("failed to write public key: %v", err)
+	}
+
+	return privateKeyFile, publicKeyFile
+}
+
+// RS256の鍵で署名したトークンがkid付きで検証されるテスト
+func TestValidateAccessToken_RS256(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	privateKeyFile, _ := writeKeyFiles(t, rsaKey)
+
+	// 環境変数から鍵セットを読み込む
+	t.Setenv("TOKEN_SIGN_ALGORITHM", auth.AlgorithmRS256)
+	t.Setenv("TOKEN_SIGN_KEY_FILE", privateKeyFile)
+	t.Setenv("TOKEN_VERIFY_KEY_FILES", "")
+	keySet, err := auth.LoadKeySetFromEnv()
+	if err != nil {
+		t.Fatalf("LoadKeySetFromEnv returned an error: %v", err)
+	}
+	useKeySet(t, keySet)
+
+	// トークンを生成して検証
+	tokenString, err := auth.NewClaim("1").GenerateToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	claims, err := auth.ValidateAccessToken(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateAccessToken returned an error: %v", err)
+	}
+	if claims.UserId != "1" {
+		t.Errorf("Expected user 1, got %v", claims.UserId)
+	}
+
+	// ヘッダーのkidがJWKSの公開鍵と一致するか確認
+	parsedToken, _, err := jwt.NewParser().ParseUnverified(tokenString, &auth.CustomClaim{})
+	if err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	jwks := keySet.JWKS()
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("Expected 1 key in jwks, got %d", len(jwks.Keys))
+	}
+	if parsedToken.Header["kid"] != jwks.Keys[0].Kid || parsedToken.Header["alg"] != "RS256" {
+		t.Errorf("Expected kid %v with RS256, got %v with %v", jwks.Keys[0].Kid, parsedToken.Header["kid"], parsedToken.Header["alg"])
+	}
+	if jwks.Keys[0].Kty != "RSA" || jwks.Keys[0].E != "AQAB" {
+		t.Errorf("unexpected jwk: %+v", jwks.Keys[0])
+	}
+}
+
+// 鍵をローテーションしても以前の鍵で署名したトークンが検証されるテスト
+func TestValidateAccessToken_KeyRotation(t *testing.T) {
+	_, oldKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	_, oldPublicKeyFile := writeKeyFiles(t, oldKey)
+	newPrivateKeyFile, _ := writeKeyFiles(t, newKey)
+
+	// 古い鍵で署名したトークン
+	oldKeySet, err := auth.NewKeySet(oldKey)
+	if err != nil {
+		t.Fatalf("NewKeySet returned an error: %v", err)
+	}
+	useKeySet(t, oldKeySet)
+	oldTokenString, err := auth.NewClaim("1").GenerateToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	// 関係のない鍵で署名したトークン
+	otherKeySet, err := auth.NewKeySet(otherKey)
+	if err != nil {
+		t.Fatalf("NewKeySet returned an error: %v", err)
+	}
+	useKeySet(t, otherKeySet)
+	otherTokenString, err := auth.NewClaim("1").GenerateToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	// 新しい鍵で署名し、古い公開鍵も検証に使う
+	t.Setenv("TOKEN_SIGN_ALGORITHM", auth.AlgorithmEdDSA)
+	t.Setenv("TOKEN_SIGN_KEY_FILE", newPrivateKeyFile)
+	t.Setenv("TOKEN_VERIFY_KEY_FILES", oldPublicKeyFile)
+	if err := auth.LoadAccessTokenKeySet(); err != nil {
+		t.Fatalf("LoadAccessTokenKeySet returned an error: %v", err)
+	}
+
+	newTokenString, err := auth.NewClaim("2").GenerateToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	if _, err := auth.ValidateAccessToken(newTokenString); err != nil {
+		t.Errorf("Expected new token to be valid, got %v", err)
+	}
+	if _, err := auth.ValidateAccessToken(oldTokenString); err != nil {
+		t.Errorf("Expected old token to be valid, got %v", err)
+	}
+	if _, err := auth.ValidateAccessToken(otherTokenString); err == nil {
+		t.Error("Expected error due to unknown key id, got nil")
+	}
+
+	// JWKSには新旧両方の公開鍵が含まれ、署名鍵が先頭になる
+	jwks := auth.AccessTokenKeySet().JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("Expected 2 keys in jwks, got %d", len(jwks.Keys))
+	}
+	if jwks.Keys[0].Kty != "OKP" || jwks.Keys[0].Crv != "Ed25519" || jwks.Keys[0].Alg != "EdDSA" {
+		t.Errorf("unexpected jwk: %+v", jwks.Keys[0])
+	}
+}
+
+// 署名アルゴリズムと鍵の種類が一致しない場合はエラーになるテスト
+func TestLoadKeySetFromEnv_AlgorithmMismatch(t *testing.T) {
+	_, ed25519Key, _ := ed25519.GenerateKey(rand.Reader)
+	privateKeyFile, _ := writeKeyFiles(t, ed25519Key)
+
+	t.Setenv("TOKEN_SIGN_ALGORITHM", auth.AlgorithmRS256)
+	t.Setenv("TOKEN_SIGN_KEY_FILE", privateKeyFile)
+	t.Setenv("TOKEN_VERIFY_KEY_FILES", "")
+	if _, err := auth.LoadKeySetFromEnv(); err == nil {
+		t.Fatal("Expected error due to algorithm mismatch, got nil")
+	}
+}
+
+// HS256の鍵セットでは公開鍵が公開されず、kidなしの既存トークンも検証されるテスト
+func TestValidateAccessToken_HS256(t *testing.T) {
+	useKeySet(t, auth.NewHMACKeySet(testSignKey, testVerifyKey))
+
+	// kidなしのトークン
+	tokenString, err := generateTestToken("1", testSignKey)
+	if err != nil {
+		t.Fatalf("Failed to generate test token: %v", err)
+	}
+	if _, err := auth.ValidateAccessToken(tokenString); err != nil {
+		t.Errorf("ValidateAccessToken returned an error: %v", err)
+	}
+
+	if jwks := auth.AccessTokenKeySet().JWKS(); len(jwks.Keys) != 0 {
+		t.Errorf("Expected no keys in jwks, got %d", len(jwks.Keys))
+	}
+}
+
+// HS256の鍵セットでRS256のトークンが拒否されるテスト(アルゴリズム混同)
+func TestValidateAccessToken_UnexpectedAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	rsaKeySet, err := auth.NewKeySet(rsaKey)
+	if err != nil {
+		t.Fatalf("NewKeySet returned an error: %v", err)
+	}
+	useKeySet(t, rsaKeySet)
+	tokenString, err := auth.NewClaim("1").GenerateToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	auth.SetAccessTokenKeySet(auth.NewHMACKeySet(testSignKey, testVerifyKey))
+	if _, err := auth.ValidateAccessToken(tokenString); err == nil {
+		t.Fatal("Expected error due to unexpected algorithm, got nil")
+	}
+}
+
+// JWKS形式の公開鍵から元の公開鍵に戻せるかのテスト
+func TestJWKPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	edPublicKey, _, _ := ed25519.GenerateKey(rand.Reader)
+	keySet, err := auth.NewKeySet(rsaKey, edPublicKey)
+	if err != nil {
+		t.Fatalf("NewKeySet returned an error: %v", err)
+	}
+
+	for _, jwk := range keySet.JWKS().Keys {
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			t.Fatalf("PublicKey returned an error for %s: %v", jwk.Kty, err)
+		}
+		switch publicKey := publicKey.(type) {
+		case *rsa.PublicKey:
+			if !publicKey.Equal(&rsaKey.PublicKey) {
+				t.Errorf("Expected the original rsa key")
+			}
+		case ed25519.PublicKey:
+			if !publicKey.Equal(edPublicKey) {
+				t.Errorf("Expected the original ed25519 key")
+			}
+		default:
+			t.Errorf("unexpected key type %T", publicKey)
+		}
+	}
+
+	// EC鍵(JWKSの公開はしないが、外部のIDプロバイダーの検証で使用する)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ec key: %v", err)
+	}
+	publicKey, err := auth.JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, 32))),
+		Y:   base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, 32))),
+	}.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey returned an error for EC: %v", err)
+	}
+	if !ecKey.PublicKey.Equal(publicKey) {
+		t.Errorf("Expected the original ec key")
+	}
+
+	// 不正な鍵
+	invalidKeys := []auth.JWK{
+		{Kty: "oct"},
+		{Kty: "RSA", N: "", E: "AQAB"},
+		{Kty: "EC", Crv: "P-256", X: "AQ", Y: "AQ"},
+		{Kty: "OKP", Crv: "Ed25519", X: "AQ"},
+	}
+	for _, jwk := range invalidKeys {
+		if _, err := jwk.PublicKey(); err == nil {
+			t.Errorf("Expected an error for %+v", jwk)
+		}
+	}
+}
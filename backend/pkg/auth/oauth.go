@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// oauth token constants
+// these tokens are signed with the access token key set, and cannot be used as access tokens because of their subjects and audiences
+const (
+	OAuthStateSubject     = "OAuthState"
+	OAuthTicketSubject    = "OAuthTicket"
+	OAuthStateAudience    = Audience + "/oauth/state"
+	OAuthTicketAudience   = Audience + "/oauth/ticket"
+	OAuthStateExpiration  = time.Minute * time.Duration(10)
+	OAuthTicketExpiration = time.Minute * time.Duration(15)
+)
+
+// actions of oauth ticket
+// a ticket can only be used for the action it was issued for (a link ticket cannot be used to sign up)
+const (
+	OAuthTicketActionSignup = "signup"
+	OAuthTicketActionLink   = "link"
+)
+
+// authorization request kept by the browser (in a cookie) until the provider redirects back to the callback
+// Verifier: PKCE code_verifier, Nonce: nonce of the ID token
+type OAuthStateClaim struct {
+	Provider string `json:"provider"`
+	Action   string `json:"action"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// provider account verified on the callback
+// the client exchanges it for a new user (signup) or links it to the logged in user (link)
+// Action: OAuthTicketActionSignup or OAuthTicketActionLink
+// ProviderSubject: stable user id at the provider (sub of the ID token)
+type OAuthTicketClaim struct {
+	Action          string `json:"action"`
+	Provider        string `json:"provider"`
+	ProviderSubject string `json:"provider_sub"`
+	Email           string `json:"email"`
+	Name            string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// generate signed oauth state token
+func (c *OAuthStateClaim) Generate() (string, error) {
+	c.RegisteredClaims = newOAuthRegisteredClaims(OAuthStateSubject, OAuthStateAudience, OAuthStateExpiration)
+	return AccessTokenKeySet().Sign(c)
+}
+
+// generate signed oauth ticket
+func (c *OAuthTicketClaim) Generate() (string, error) {
+	c.RegisteredClaims = newOAuthRegisteredClaims(OAuthTicketSubject, OAuthTicketAudience, OAuthTicketExpiration)
+	return AccessTokenKeySet().Sign(c)
+}
+
+// verify oauth state token
+// state token must be signed with access token key set and have oauth state subject and audience
+func ValidateOAuthState(token string) (*OAuthStateClaim, error) {
+	claims := &OAuthStateClaim{}
+	if err := parseOAuthToken(token, claims, OAuthStateAudience); err != nil {
+		return nil, err
+	}
+
+	if claims.Subject != OAuthStateSubject {
+		return nil, errors.New("token is not an oauth state")
+	}
+
+	return claims, nil
+}
+
+// verify oauth ticket for action
+// ticket must be signed with access token key set, have oauth ticket subject and audience and be issued for the action
+func ValidateOAuthTicket(token string, action string) (*OAuthTicketClaim, error) {
+	claims := &OAuthTicketClaim{}
+	if err := parseOAuthToken(token, claims, OAuthTicketAudience); err != nil {
+		return nil, err
+	}
+
+	if claims.Subject != OAuthTicketSubject || claims.Provider == "" || claims.ProviderSubject == "" {
+		return nil, errors.New("token is not an oauth ticket")
+	}
+	if claims.Action != action {
+		return nil, errors.New("oauth ticket is not issued for " + action)
+	}
+
+	return claims, nil
+}
+
+func newOAuthRegisteredClaims(subject, audience string, expiration time.Duration) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Issuer:    Issuer,
+		Subject:   subject,
+		Audience:  []string{audience},
+		IssuedAt:  jwt.NewNumericDate(time.Now().Local()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Local().Add(expiration)),
+		ID:        uuid.New().String(),
+	}
+}
+
+func parseOAuthToken(token string, claims jwt.Claims, audience string) error {
+	keySet := AccessTokenKeySet()
+	_, err := jwt.ParseWithClaims(token, claims, keySet.Keyfunc,
+		jwt.WithValidMethods(keySet.ValidMethods()),
+		jwt.WithIssuer(Issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+
+	return err
+}
@@ -0,0 +1,88 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+)
+
+// 署名したoauth stateを検証できるテスト
+func TestOAuthStateRoundTrip(t *testing.T) {
+	token, err := (&auth.OAuthStateClaim{Provider: "google", Action: "login", State: "state", Nonce: "nonce", Verifier: "verifier"}).Generate()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	claims, err := auth.ValidateOAuthState(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claims.Provider != "google" || claims.Action != "login" || claims.State != "state" || claims.Nonce != "nonce" || claims.Verifier != "verifier" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+// 署名したoauth ticketを検証できるテスト
+func TestOAuthTicketRoundTrip(t *testing.T) {
+	token, err := (&auth.OAuthTicketClaim{Action: auth.OAuthTicketActionSignup, Provider: "github", ProviderSubject: "42", Email: "gopher@example.com", Name: "gopher"}).Generate()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	claims, err := auth.ValidateOAuthTicket(token, auth.OAuthTicketActionSignup)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claims.Provider != "github" || claims.ProviderSubject != "42" || claims.Email != "gopher@example.com" || claims.Name != "gopher" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
+
+// 用途の違うトークンはoauth state、oauth ticketとして受け付けないテスト
+func TestOAuthTokensRejectOtherTokens(t *testing.T) {
+	accessToken, _ := auth.NewClaim("1").GenerateToken()
+	state, _ := (&auth.OAuthStateClaim{Provider: "google", State: "state"}).Generate()
+	ticket, _ := (&auth.OAuthTicketClaim{Action: auth.OAuthTicketActionSignup, Provider: "google", ProviderSubject: "1"}).Generate()
+
+	if _, err := auth.ValidateOAuthState(accessToken); err == nil {
+		t.Errorf("expected access token to be rejected as oauth state")
+	}
+	if _, err := auth.ValidateOAuthState(ticket); err == nil {
+		t.Errorf("expected oauth ticket to be rejected as oauth state")
+	}
+	if _, err := auth.ValidateOAuthTicket(state, auth.OAuthTicketActionSignup); err == nil {
+		t.Errorf("expected oauth state to be rejected as oauth ticket")
+	}
+	if _, err := auth.ValidateOAuthTicket(accessToken, auth.OAuthTicketActionSignup); err == nil {
+		t.Errorf("expected access token to be rejected as oauth ticket")
+	}
+	// oauth state、oauth ticketはaudienceが違うのでアクセストークンとして検証されない
+	if _, err := auth.ValidateAccessToken(state); err == nil {
+		t.Errorf("expected oauth state to be rejected as access token")
+	}
+	if _, err := auth.ValidateAccessToken(ticket); err == nil {
+		t.Errorf("expected oauth ticket to be rejected as access token")
+	}
+	if _, err := auth.ValidateMFAToken(ticket); err == nil {
+		t.Errorf("expected oauth ticket to be rejected as mfa token")
+	}
+	if _, err := auth.ValidateOAuthTicket(ticket+"x", auth.OAuthTicketActionSignup); err == nil {
+		t.Errorf("expected tampered oauth ticket to be rejected")
+	}
+}
+
+// ticketは発行されたaction以外には使用できないテスト
+func TestOAuthTicketRejectsOtherAction(t *testing.T) {
+	linkTicket, _ := (&auth.OAuthTicketClaim{Action: auth.OAuthTicketActionLink, Provider: "google", ProviderSubject: "1"}).Generate()
+	signupTicket, _ := (&auth.OAuthTicketClaim{Action: auth.OAuthTicketActionSignup, Provider: "google", ProviderSubject: "1"}).Generate()
+
+	if _, err := auth.ValidateOAuthTicket(linkTicket, auth.OAuthTicketActionSignup); err == nil {
+		t.Errorf("expected link ticket to be rejected for signup")
+	}
+	if _, err := auth.ValidateOAuthTicket(signupTicket, auth.OAuthTicketActionLink); err == nil {
+		t.Errorf("expected signup ticket to be rejected for link")
+	}
+	if _, err := auth.ValidateOAuthTicket(linkTicket, auth.OAuthTicketActionLink); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
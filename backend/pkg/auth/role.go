@@ -0,0 +1,59 @@
+package auth
+
+import "slices"
+
+// user roles stored in users.role
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// permissions carried in access tokens and checked by middlewares.RequirePermission
+const (
+	PermissionReadUsers    = "users:read"    // list users with their email and suspension state
+	PermissionSuspendUsers = "users:suspend" // suspend and unsuspend accounts
+	PermissionDeleteTweets = "tweets:delete" // delete tweets of any user
+)
+
+// permissions granted to each role
+// RoleUser has no extra permissions, ownership of tweets and followers is checked by the services
+var rolePermissions = map[string][]string{
+	RoleModerator: {PermissionSuspendUsers, PermissionDeleteTweets},
+	RoleAdmin:     {PermissionReadUsers, PermissionSuspendUsers, PermissionDeleteTweets},
+}
+
+// rank of each role, used to decide who can moderate whom
+var roleRanks = map[string]int{
+	RoleUser:      0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+// permissions of the role (unknown and empty roles have no permissions)
+func RolePermissions(role string) []string {
+	return slices.Clone(rolePermissions[role])
+}
+
+// whether a user with role can moderate (e.g. suspend) a user with target role
+// only users with a higher role can moderate, so nobody can suspend themselves or their peers
+func Outranks(role, target string) bool {
+	rank, ok := roleRanks[role]
+	if !ok {
+		return false
+	}
+
+	return rank > roleRanks[target]
+}
+
+// set role and its permissions to the claim
+// only access tokens carry them, refresh tokens read the current role from the database when rotated
+func (c *CustomClaim) SetRole(role string) {
+	c.Role = role
+	c.Permissions = RolePermissions(role)
+}
+
+// whether the claim has the permission
+func (c *CustomClaim) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
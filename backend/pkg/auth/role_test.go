@@ -0,0 +1,73 @@
+package auth_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+)
+
+// roleの権限がアクセストークンに含まれ、検証後も取り出せるテスト
+func TestAccessTokenCarriesRolePermissions(t *testing.T) {
+	claim := auth.NewClaim("1")
+	claim.SetRole(auth.RoleModerator)
+	token, err := claim.GenerateToken()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	parsed, err := auth.ValidateAccessToken(token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if parsed.Role != auth.RoleModerator {
+		t.Errorf("expected role %q, got %q", auth.RoleModerator, parsed.Role)
+	}
+	if !parsed.HasPermission(auth.PermissionDeleteTweets) || !parsed.HasPermission(auth.PermissionSuspendUsers) {
+		t.Errorf("expected moderator permissions, got %v", parsed.Permissions)
+	}
+	// ユーザー一覧(emailを含む)は管理者のみ
+	if parsed.HasPermission(auth.PermissionReadUsers) {
+		t.Errorf("expected moderator not to have %q", auth.PermissionReadUsers)
+	}
+}
+
+// 一般ユーザー、不明なroleには権限がないテスト
+func TestRolePermissionsOfUser(t *testing.T) {
+	for _, role := range []string{auth.RoleUser, "", "superuser"} {
+		if permissions := auth.RolePermissions(role); len(permissions) != 0 {
+			t.Errorf("expected no permissions for role %q, got %v", role, permissions)
+		}
+	}
+
+	// 返された権限を変更してもroleの権限は変わらない
+	permissions := auth.RolePermissions(auth.RoleAdmin)
+	permissions[0] = "changed"
+	if slices.Contains(auth.RolePermissions(auth.RoleAdmin), "changed") {
+		t.Errorf("expected role permissions not to be changed")
+	}
+}
+
+// 自分より弱いroleのユーザーのみ管理できるテスト
+func TestOutranks(t *testing.T) {
+	testCases := []struct {
+		role     string
+		target   string
+		expected bool
+	}{
+		{auth.RoleAdmin, auth.RoleModerator, true},
+		{auth.RoleAdmin, auth.RoleUser, true},
+		{auth.RoleModerator, auth.RoleUser, true},
+		{auth.RoleModerator, auth.RoleModerator, false},
+		{auth.RoleModerator, auth.RoleAdmin, false},
+		{auth.RoleAdmin, auth.RoleAdmin, false},
+		{auth.RoleUser, auth.RoleUser, false},
+		{"superuser", auth.RoleUser, false},
+	}
+
+	for _, testCase := range testCases {
+		if actual := auth.Outranks(testCase.role, testCase.target); actual != testCase.expected {
+			t.Errorf("Outranks(%q, %q): expected %v, got %v", testCase.role, testCase.target, testCase.expected, actual)
+		}
+	}
+}
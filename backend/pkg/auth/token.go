@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// number of random bytes of one-time tokens (password reset, email verification)
+const OneTimeTokenBytes = 32
+
+// generate a random one-time token sent to the user and its hash stored in the database
+// only the hash is stored so that a leaked database cannot be used to reset passwords
+func GenerateOneTimeToken() (token string, tokenHash string, err error) {
+	b := make([]byte, OneTimeTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashOneTimeToken(token), nil
+}
+
+// sha256 hex of the token
+// the token has enough entropy, so a fast hash without salt is enough to look it up
+func HashOneTimeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,29 @@
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+)
+
+// 毎回異なるtokenが作成され、hashがHashOneTimeTokenと一致するか確認
+func TestGenerateOneTimeToken(t *testing.T) {
+	token, tokenHash, err := auth.GenerateOneTimeToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	if len(token) != 43 || len(tokenHash) != 64 {
+		t.Errorf("unexpected token length: %d, %d", len(token), len(tokenHash))
+	}
+	if tokenHash != auth.HashOneTimeToken(token) {
+		t.Errorf("hash does not match token")
+	}
+	if token == tokenHash {
+		t.Errorf("token must not be stored as is")
+	}
+
+	other, _, err := auth.GenerateOneTimeToken()
+	if err != nil || other == token {
+		t.Errorf("expected different token, got %s, %v", other, err)
+	}
+}
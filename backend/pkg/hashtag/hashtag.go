@@ -0,0 +1,83 @@
+package hashtag
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// max length of a hashtag in characters (hashtags.name is varchar(100))
+const MaxLength = 100
+
+// Extract hashtags from tweet content
+// a hashtag starts with "#" or fullwidth "＃" at the beginning or after a non-word character
+// (so "a#b" and "&#39;" are not hashtags) and continues while the characters are letters,
+// marks, digits, "_" or "・" in any script (like: #golang, #東京, #ポケモン・GO)
+// hashtags are normalized with Normalize, hashtags of only digits or longer than MaxLength are ignored
+// returns unique hashtags in the order of appearance
+func Extract(content string) []string {
+	var hashtags []string
+	seen := map[string]bool{}
+
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		if !isHashMark(runes[i]) || (i > 0 && (isHashtagChar(runes[i-1]) || runes[i-1] == '&')) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isHashtagChar(runes[end]) {
+			end++
+		}
+		// "##tag" and "#tag#tag" are not hashtags
+		if end < len(runes) && isHashMark(runes[end]) {
+			i = end
+			continue
+		}
+
+		if name, ok := Normalize(string(runes[i+1 : end])); ok && !seen[name] {
+			seen[name] = true
+			hashtags = append(hashtags, name)
+		}
+		i = end - 1
+	}
+
+	return hashtags
+}
+
+// Normalize hashtag name so that the same hashtag in different forms is stored once
+// NFKC (fullwidth "ＧＯ" and halfwidth "ｶﾀｶﾅ" to "GO" and "カタカナ") and lower case, leading "#" is removed
+// returns false if the name is not a valid hashtag
+func Normalize(name string) (string, bool) {
+	name = strings.TrimLeftFunc(name, isHashMark)
+	name = strings.ToLower(norm.NFKC.String(name))
+
+	if name == "" || utf8.RuneCountInString(name) > MaxLength {
+		return "", false
+	}
+
+	onlyDigits := true
+	for _, r := range name {
+		if !isHashtagChar(r) {
+			return "", false
+		}
+		if !unicode.IsDigit(r) {
+			onlyDigits = false
+		}
+	}
+	if onlyDigits {
+		return "", false
+	}
+
+	return name, true
+}
+
+func isHashMark(r rune) bool {
+	return r == '#' || r == '＃'
+}
+
+func isHashtagChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_' || r == '・'
+}
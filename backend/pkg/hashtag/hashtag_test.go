@@ -0,0 +1,69 @@
+package hashtag_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/hashtag"
+)
+
+// 日本語を含むhashtagを抽出して正規化できるか確認
+func TestExtract(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected []string
+	}{
+		{content: "hello #golang and #Gin!", expected: []string{"golang", "gin"}},
+		{content: "今日は #東京 で #ラーメン を食べた", expected: []string{"東京", "ラーメン"}},
+		{content: "#ポケモン・GO 楽しい", expected: []string{"ポケモン・go"}},
+		// 全角の＃と英数字、半角カナはNFKCで正規化する
+		{content: "＃ＧＯ言語 #ｶﾀｶﾅ", expected: []string{"go言語", "カタカナ"}},
+		// 同じhashtagは1つにまとめる
+		{content: "#Go #go #GO", expected: []string{"go"}},
+		// 単語の途中や数字のみ、HTMLの文字参照はhashtagではない
+		{content: "a#b #123 &#39; ##double #a#b", expected: nil},
+		{content: "#1位 #tag_with_underscore.", expected: []string{"1位", "tag_with_underscore"}},
+		{content: "(#括弧)「#鍵括弧」", expected: []string{"括弧", "鍵括弧"}},
+		{content: "no hashtags", expected: nil},
+	}
+
+	for _, c := range cases {
+		if hashtags := hashtag.Extract(c.content); !reflect.DeepEqual(c.expected, hashtags) {
+			t.Errorf("Extract(%q) = %q, expected %q", c.content, hashtags, c.expected)
+		}
+	}
+}
+
+// MaxLengthより長いhashtagは無視する
+func TestExtractTooLongHashtag(t *testing.T) {
+	content := "#" + strings.Repeat("あ", hashtag.MaxLength+1) + " #" + strings.Repeat("あ", hashtag.MaxLength)
+	hashtags := hashtag.Extract(content)
+
+	if len(hashtags) != 1 || hashtags[0] != strings.Repeat("あ", hashtag.MaxLength) {
+		t.Errorf("unexpected hashtags: %q", hashtags)
+	}
+}
+
+// URLのパラメータで受け取ったhashtagを正規化できるか確認
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{name: "Golang", expected: "golang", ok: true},
+		{name: "#東京", expected: "東京", ok: true},
+		{name: "ＧＯ", expected: "go", ok: true},
+		{name: "", ok: false},
+		{name: "123", ok: false},
+		{name: "with space", ok: false},
+	}
+
+	for _, c := range cases {
+		name, ok := hashtag.Normalize(c.name)
+		if name != c.expected || ok != c.ok {
+			t.Errorf("Normalize(%q) = %q, %v, expected %q, %v", c.name, name, ok, c.expected, c.ok)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package mailer
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// mailer which only writes mails to the log and keeps them in memory
+// used in development and tests, the body is logged as is (including reset links)
+type LogMailer struct {
+	mu       sync.Mutex
+	messages []*Message
+}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	if err := validateMessage(msg); err != nil {
+		return err
+	}
+
+	log.Printf("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+
+	return nil
+}
+
+// mails sent so far in order
+func (m *LogMailer) Messages() []*Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*Message{}, m.messages...)
+}
@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/daiki-kim/tweet-app/backend/configs"
+)
+
+// mailer kinds of MAILER
+const (
+	MailerLog  = "log"
+	MailerSMTP = "smtp"
+)
+
+var ErrInvalidMessage = errors.New("invalid mail message")
+
+// plain text mail sent to one recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// sends mails like password reset links to users
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// create mailer from environment variables
+// MAILER: log(default) or smtp
+// smtp: SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD, MAIL_FROM
+func NewMailerFromEnv() (Mailer, error) {
+	switch kind := configs.GetEnvDefault("MAILER", MailerLog); kind {
+	case MailerLog:
+		return NewLogMailer(), nil
+	case MailerSMTP:
+		port, err := strconv.Atoi(configs.GetEnvDefault("SMTP_PORT", "587"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+		}
+		return NewSMTPMailer(SMTPConfig{
+			Host:     configs.GetEnvDefault("SMTP_HOST", "localhost"),
+			Port:     port,
+			Username: configs.GetEnvDefault("SMTP_USERNAME", ""),
+			Password: configs.GetEnvDefault("SMTP_PASSWORD", ""),
+			From:     configs.GetEnvDefault("MAIL_FROM", "no-reply@localhost"),
+		})
+	default:
+		return nil, fmt.Errorf("unsupported mailer: %s", kind)
+	}
+}
+
+// recipient must be a single address and subject must not contain line breaks
+// so that they cannot inject other headers
+func validateMessage(msg *Message) error {
+	if msg == nil || strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return ErrInvalidMessage
+	}
+
+	address, err := mail.ParseAddress(msg.To)
+	if err != nil || address.Address != msg.To {
+		return ErrInvalidMessage
+	}
+
+	return nil
+}
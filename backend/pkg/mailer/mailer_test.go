@@ -0,0 +1,198 @@
+package mailer_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"mime"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/mailer"
+)
+
+// 送信したメールがログに書かれて記録されるか確認
+func TestLogMailer(t *testing.T) {
+	logMailer := mailer.NewLogMailer()
+
+	msg := &mailer.Message{To: "gopher@example.com", Subject: "hello", Body: "body"}
+	if err := logMailer.Send(context.Background(), msg); err != nil {
+		t.Fatalf("failed to send mail: %v", err)
+	}
+
+	messages := logMailer.Messages()
+	if len(messages) != 1 || messages[0] != msg {
+		t.Errorf("unexpected messages: %v", messages)
+	}
+}
+
+// 改行を含む宛先や件名、複数の宛先はヘッダーの挿入を防ぐためにエラーにする
+func TestSendInvalidMessage(t *testing.T) {
+	cases := []*mailer.Message{
+		{To: "gopher@example.com\r\nBcc: evil@example.com", Subject: "hello"},
+		{To: "gopher@example.com", Subject: "hello\r\nBcc: evil@example.com"},
+		{To: "gopher@example.com, evil@example.com", Subject: "hello"},
+		{To: "Gopher <gopher@example.com>", Subject: "hello"},
+		{To: "not an address", Subject: "hello"},
+		nil,
+	}
+
+	logMailer := mailer.NewLogMailer()
+	for _, msg := range cases {
+		if err := logMailer.Send(context.Background(), msg); !errors.Is(err, mailer.ErrInvalidMessage) {
+			t.Errorf("expected ErrInvalidMessage for %v, got %v", msg, err)
+		}
+	}
+	if len(logMailer.Messages()) != 0 {
+		t.Errorf("invalid messages must not be sent")
+	}
+}
+
+// MAILERの値でmailerを切り替える
+func TestNewMailerFromEnv(t *testing.T) {
+	t.Setenv("MAILER", "log")
+	if m, err := mailer.NewMailerFromEnv(); err != nil {
+		t.Errorf("failed to create log mailer: %v", err)
+	} else if _, ok := m.(*mailer.LogMailer); !ok {
+		t.Errorf("expected log mailer, got %T", m)
+	}
+
+	t.Setenv("MAILER", "smtp")
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_PORT", "587")
+	t.Setenv("MAIL_FROM", "no-reply@example.com")
+	if m, err := mailer.NewMailerFromEnv(); err != nil {
+		t.Errorf("failed to create smtp mailer: %v", err)
+	} else if _, ok := m.(*mailer.SMTPMailer); !ok {
+		t.Errorf("expected smtp mailer, got %T", m)
+	}
+
+	t.Setenv("SMTP_PORT", "port")
+	if _, err := mailer.NewMailerFromEnv(); err == nil {
+		t.Errorf("expected error for invalid port")
+	}
+
+	t.Setenv("MAILER", "unknown")
+	if _, err := mailer.NewMailerFromEnv(); err == nil {
+		t.Errorf("expected error for unknown mailer")
+	}
+}
+
+// テスト用のSMTPサーバーにメールを送信して内容を確認
+func TestSMTPMailer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go serveSMTP(listener, received)
+
+	addr := listener.Addr().(*net.TCPAddr)
+	smtpMailer, err := mailer.NewSMTPMailer(mailer.SMTPConfig{
+		Host: "127.0.0.1",
+		Port: addr.Port,
+		From: "Tweet App <no-reply@example.com>",
+	})
+	if err != nil {
+		t.Fatalf("failed to create smtp mailer: %v", err)
+	}
+
+	body := strings.Repeat("パスワードを再設定してください ", 10)
+	err = smtpMailer.Send(context.Background(), &mailer.Message{To: "gopher@example.com", Subject: "パスワードの再設定", Body: body})
+	if err != nil {
+		t.Fatalf("failed to send mail: %v", err)
+	}
+
+	data := <-received
+	parsed, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to parse mail: %v", err)
+	}
+	if to := parsed.Header.Get("To"); to != "gopher@example.com" {
+		t.Errorf("unexpected to: %s", to)
+	}
+	if from := parsed.Header.Get("From"); from != `"Tweet App" <no-reply@example.com>` {
+		t.Errorf("unexpected from: %s", from)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "パスワードの再設定" {
+		t.Errorf("unexpected subject: %s, %v", subject, err)
+	}
+
+	encoded := new(strings.Builder)
+	lines := bufio.NewScanner(parsed.Body)
+	for lines.Scan() {
+		if len(lines.Text()) > 76 {
+			t.Errorf("body line is too long: %d", len(lines.Text()))
+		}
+		encoded.WriteString(lines.Text())
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil || string(decoded) != body {
+		t.Errorf("unexpected body: %q, %v", decoded, err)
+	}
+}
+
+// SMTPサーバーの設定が不正な場合はエラー
+func TestNewSMTPMailerInvalidConfig(t *testing.T) {
+	if _, err := mailer.NewSMTPMailer(mailer.SMTPConfig{Port: 25, From: "no-reply@example.com"}); err == nil {
+		t.Errorf("expected error for empty host")
+	}
+	if _, err := mailer.NewSMTPMailer(mailer.SMTPConfig{Host: "localhost", Port: 25, From: "invalid"}); err == nil {
+		t.Errorf("expected error for invalid from address")
+	}
+}
+
+// 1通のメールを受け取るだけの最小限のSMTPサーバー
+func serveSMTP(listener net.Listener, received chan<- string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM"), strings.HasPrefix(command, "RCPT TO"):
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			received <- data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 " + strconv.Quote(command) + " not implemented")
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// media store which saves files in a local directory
+// the files are served by the router under BaseURL (see routes.SetupRouter)
+type LocalMediaStore struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewLocalMediaStore(dir, baseURL string) (*LocalMediaStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &LocalMediaStore{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (s *LocalMediaStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, body); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalMediaStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (s *LocalMediaStore) URL(key string) string {
+	return s.BaseURL + "/" + key
+}
+
+// file path of key, rejects keys which escape the directory
+func (s *LocalMediaStore) path(key string) (string, error) {
+	if !filepath.IsLocal(key) {
+		return "", errors.New("invalid media key")
+	}
+
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,48 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/daiki-kim/tweet-app/backend/configs"
+)
+
+// storage kinds of MEDIA_STORE
+const (
+	StoreLocal = "local"
+	StoreS3    = "s3"
+)
+
+// storage for uploaded media files
+// key: path of the file in the store (like: images/0b0c....jpg)
+type MediaStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// create media store from environment variables
+// MEDIA_STORE: local(default) or s3
+// local: MEDIA_LOCAL_DIR, MEDIA_BASE_URL
+// s3: MEDIA_S3_ENDPOINT, MEDIA_S3_REGION, MEDIA_S3_BUCKET, MEDIA_S3_ACCESS_KEY, MEDIA_S3_SECRET_KEY, MEDIA_S3_PUBLIC_URL
+func NewMediaStoreFromEnv() (MediaStore, error) {
+	switch kind := configs.GetEnvDefault("MEDIA_STORE", StoreLocal); kind {
+	case StoreLocal:
+		return NewLocalMediaStore(
+			configs.GetEnvDefault("MEDIA_LOCAL_DIR", "uploads"),
+			configs.GetEnvDefault("MEDIA_BASE_URL", "http://localhost:8080/media"),
+		)
+	case StoreS3:
+		return NewS3MediaStore(S3Config{
+			Endpoint:  configs.GetEnvDefault("MEDIA_S3_ENDPOINT", "http://localhost:9000"),
+			Region:    configs.GetEnvDefault("MEDIA_S3_REGION", "us-east-1"),
+			Bucket:    configs.GetEnvDefault("MEDIA_S3_BUCKET", "tweet-app-media"),
+			AccessKey: configs.GetEnvDefault("MEDIA_S3_ACCESS_KEY", ""),
+			SecretKey: configs.GetEnvDefault("MEDIA_S3_SECRET_KEY", ""),
+			PublicURL: configs.GetEnvDefault("MEDIA_S3_PUBLIC_URL", ""),
+		})
+	default:
+		return nil, fmt.Errorf("unsupported media store: %s", kind)
+	}
+}
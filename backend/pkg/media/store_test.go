@@ -0,0 +1,154 @@
+package media_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/media"
+)
+
+// ローカルのディレクトリに保存、URLの作成、削除ができるか確認
+func TestLocalMediaStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := media.NewLocalMediaStore(dir, "http://localhost:8080/media/")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := store.Put(ctx, "images/a.jpg", strings.NewReader("data"), 4, "image/jpeg"); err != nil {
+		t.Fatalf("failed to put file: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "images", "a.jpg"))
+	if err != nil || string(data) != "data" {
+		t.Fatalf("unexpected file content: %q, %v", data, err)
+	}
+	if url := store.URL("images/a.jpg"); url != "http://localhost:8080/media/images/a.jpg" {
+		t.Errorf("unexpected url: %s", url)
+	}
+
+	if err := store.Delete(ctx, "images/a.jpg"); err != nil {
+		t.Fatalf("failed to delete file: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "images", "a.jpg")); !os.IsNotExist(err) {
+		t.Errorf("file still exists: %v", err)
+	}
+
+	// 存在しないファイルの削除はエラーにしない
+	if err := store.Delete(ctx, "images/a.jpg"); err != nil {
+		t.Errorf("failed to delete missing file: %v", err)
+	}
+}
+
+// ディレクトリの外を指すkeyは拒否する
+func TestLocalMediaStoreRejectsEscapingKey(t *testing.T) {
+	store, err := media.NewLocalMediaStore(t.TempDir(), "http://localhost:8080/media")
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	for _, key := range []string{"../a.jpg", "/etc/passwd", "images/../../a.jpg"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("data"), 4, "image/jpeg"); err == nil {
+			t.Errorf("key %s was accepted", key)
+		}
+	}
+}
+
+// S3互換ストレージへのリクエストがpath-styleでSigV4の署名付きで送られるか確認
+func TestS3MediaStore(t *testing.T) {
+	var requests []*http.Request
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, string(body))
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	store, err := media.NewS3MediaStore(media.S3Config{
+		Endpoint:  server.URL,
+		Region:    "us-east-1",
+		Bucket:    "tweet-app-media",
+		AccessKey: "minioadmin",
+		SecretKey: "minioadmin",
+	})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := store.Put(ctx, "images/a b.jpg", strings.NewReader("data"), 4, "image/jpeg"); err != nil {
+		t.Fatalf("failed to put object: %v", err)
+	}
+	if err := store.Delete(ctx, "images/a b.jpg"); err != nil {
+		t.Fatalf("failed to delete object: %v", err)
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("unexpected number of requests: %d", len(requests))
+	}
+
+	put := requests[0]
+	if put.Method != http.MethodPut || put.URL.EscapedPath() != "/tweet-app-media/images/a%20b.jpg" {
+		t.Errorf("unexpected put request: %s %s", put.Method, put.URL.EscapedPath())
+	}
+	if bodies[0] != "data" || put.Header.Get("Content-Type") != "image/jpeg" {
+		t.Errorf("unexpected put body: %q %s", bodies[0], put.Header.Get("Content-Type"))
+	}
+	if put.Header.Get("X-Amz-Content-Sha256") != "UNSIGNED-PAYLOAD" {
+		t.Errorf("unexpected payload hash: %s", put.Header.Get("X-Amz-Content-Sha256"))
+	}
+
+	authorization := regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=minioadmin/\d{8}/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}$`)
+	for _, r := range requests {
+		if !authorization.MatchString(r.Header.Get("Authorization")) {
+			t.Errorf("unexpected authorization header: %s", r.Header.Get("Authorization"))
+		}
+	}
+
+	if requests[1].Method != http.MethodDelete {
+		t.Errorf("unexpected delete request: %s", requests[1].Method)
+	}
+
+	if url := store.URL("images/a b.jpg"); url != server.URL+"/tweet-app-media/images/a%20b.jpg" {
+		t.Errorf("unexpected url: %s", url)
+	}
+}
+
+// S3互換ストレージがエラーを返した場合はエラーにする
+func TestS3MediaStoreError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	store, err := media.NewS3MediaStore(media.S3Config{
+		Endpoint:  server.URL,
+		Region:    "us-east-1",
+		Bucket:    "tweet-app-media",
+		AccessKey: "minioadmin",
+		SecretKey: "wrong",
+		PublicURL: "https://cdn.example.com/",
+	})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	if err := store.Put(context.Background(), "images/a.jpg", strings.NewReader("data"), 4, "image/jpeg"); err == nil {
+		t.Error("put succeeded with forbidden response")
+	}
+	if url := store.URL("images/a.jpg"); url != "https://cdn.example.com/images/a.jpg" {
+		t.Errorf("unexpected url: %s", url)
+	}
+}
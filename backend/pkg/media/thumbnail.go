@@ -0,0 +1,67 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
+
+	"golang.org/x/image/draw"
+
+	// register decoders of supported image formats
+	_ "image/gif"
+	_ "image/png"
+)
+
+// thumbnails fit in ThumbnailSize x ThumbnailSize and are encoded as JPEG
+const (
+	ThumbnailSize        = 320
+	ThumbnailContentType = "image/jpeg"
+	thumbnailQuality     = 80
+)
+
+// images are decoded into memory, so the number of pixels is limited before decoding
+// a small compressed file can declare huge dimensions (decompression bomb)
+const MaxImagePixels = 40_000_000
+
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// decode JPEG, PNG or GIF image and create JPEG thumbnail
+// images smaller than ThumbnailSize are not enlarged
+func GenerateThumbnail(r io.Reader) ([]byte, error) {
+	// read the header to check the dimensions, then decode from the same bytes
+	var header bytes.Buffer
+	config, _, err := image.DecodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(io.MultiReader(&header, r))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > ThumbnailSize || height > ThumbnailSize {
+		if width >= height {
+			width, height = ThumbnailSize, max(1, height*ThumbnailSize/bounds.Dx())
+		} else {
+			width, height = max(1, width*ThumbnailSize/bounds.Dy()), ThumbnailSize
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
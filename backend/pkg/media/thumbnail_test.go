@@ -0,0 +1,81 @@
+package media_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/media"
+)
+
+// テスト用のPNG画像を作成
+func encodePNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// 大きい画像は縦横比を保ってThumbnailSizeに収まるJPEGに縮小する
+func TestGenerateThumbnail(t *testing.T) {
+	cases := []struct {
+		width, height                 int
+		expectedWidth, expectedHeight int
+	}{
+		{width: 640, height: 480, expectedWidth: 320, expectedHeight: 240},
+		{width: 400, height: 800, expectedWidth: 160, expectedHeight: 320},
+		// 小さい画像は拡大しない
+		{width: 100, height: 50, expectedWidth: 100, expectedHeight: 50},
+	}
+
+	for _, c := range cases {
+		thumbnail, err := media.GenerateThumbnail(bytes.NewReader(encodePNG(t, c.width, c.height)))
+		if err != nil {
+			t.Fatalf("failed to generate thumbnail: %v", err)
+		}
+
+		config, err := jpeg.DecodeConfig(bytes.NewReader(thumbnail))
+		if err != nil {
+			t.Fatalf("thumbnail is not jpeg: %v", err)
+		}
+		if config.Width != c.expectedWidth || config.Height != c.expectedHeight {
+			t.Errorf("%dx%d: unexpected thumbnail size %dx%d", c.width, c.height, config.Width, config.Height)
+		}
+	}
+}
+
+// 画像として読み込めないデータはエラーにする
+func TestGenerateThumbnailInvalidImage(t *testing.T) {
+	if _, err := media.GenerateThumbnail(strings.NewReader("not an image")); err == nil {
+		t.Error("invalid image was accepted")
+	}
+}
+
+// 縦横のサイズが大きすぎる画像はデコードせずにエラーにする
+func TestGenerateThumbnailTooLargeImage(t *testing.T) {
+	// IHDRの幅と高さを書き換え、ファイルサイズは小さいまま50000x50000の画像とする
+	data := encodePNG(t, 10, 10)
+	ihdr := data[12:29]
+	binary.BigEndian.PutUint32(ihdr[4:8], 50000)
+	binary.BigEndian.PutUint32(ihdr[8:12], 50000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(ihdr))
+
+	if _, err := media.GenerateThumbnail(bytes.NewReader(data)); !errors.Is(err, media.ErrImageTooLarge) {
+		t.Errorf("expected ErrImageTooLarge, got %v", err)
+	}
+}
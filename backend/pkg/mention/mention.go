@@ -0,0 +1,74 @@
+package mention
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// max length of a username (users.username is varchar(15))
+const MaxUsernameLength = 15
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,15}$`)
+
+// mention of a user in tweet content
+// Start and End are offsets in characters (unicode code points, not bytes) of "@username",
+// End is exclusive (so content[Start:End] in runes is "@username")
+type Mention struct {
+	Username string
+	Start    int
+	End      int
+}
+
+// ValidUsername reports whether the username can be used as a handle
+// a username is 1 to 15 characters of ASCII letters, digits and "_" (like: gopher_42)
+func ValidUsername(username string) bool {
+	return usernamePattern.MatchString(username)
+}
+
+// Normalize username for case-insensitive comparison, leading "@" is removed
+func Normalize(username string) string {
+	return strings.ToLower(strings.TrimLeftFunc(username, isAtMark))
+}
+
+// Extract mentions from tweet content
+// a mention starts with "@" or fullwidth "＠" at the beginning or after a character that is not
+// a username character (so the domain of "me@example.com" is not a mention) and is followed by a username
+// "@" followed by more than MaxUsernameLength username characters or followed by another "@" is ignored
+// returns all mentions in the order of appearance (the same user may be mentioned more than once)
+func Extract(content string) []Mention {
+	var mentions []Mention
+
+	runes := []rune(content)
+	for i := 0; i < len(runes); i++ {
+		if !isAtMark(runes[i]) || (i > 0 && (isUsernameChar(runes[i-1]) || isAtMark(runes[i-1]))) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isUsernameChar(runes[end]) {
+			end++
+		}
+		// "@@user", "@user@example.com" and too long usernames are not mentions
+		if end < len(runes) && isAtMark(runes[end]) {
+			i = end
+			continue
+		}
+
+		username := string(runes[i+1 : end])
+		if ValidUsername(username) {
+			mentions = append(mentions, Mention{Username: username, Start: i, End: end})
+		}
+		i = end - 1
+	}
+
+	return mentions
+}
+
+func isAtMark(r rune) bool {
+	return r == '@' || r == '＠'
+}
+
+func isUsernameChar(r rune) bool {
+	return r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
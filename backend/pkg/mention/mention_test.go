@@ -0,0 +1,61 @@
+package mention_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/mention"
+)
+
+// mentionとその位置(文字単位)を抽出できるか確認
+func TestExtract(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected []mention.Mention
+	}{
+		{content: "hello @gopher!", expected: []mention.Mention{{Username: "gopher", Start: 6, End: 13}}},
+		// 位置はbyteではなく文字単位
+		{content: "こんにちは@Gopher さん", expected: []mention.Mention{{Username: "Gopher", Start: 5, End: 12}}},
+		// 全角の＠も使える、同じユーザーへのmentionも全て返す
+		{content: "＠a @b_1 @a", expected: []mention.Mention{{Username: "a", Start: 0, End: 2}, {Username: "b_1", Start: 3, End: 7}, {Username: "a", Start: 8, End: 10}}},
+		// メールアドレスや@@、16文字以上のusernameはmentionではない
+		{content: "me@example.com @@double @user@example.com @abcdefghijklmnop", expected: nil},
+		{content: "(@paren).", expected: []mention.Mention{{Username: "paren", Start: 1, End: 7}}},
+		{content: "@ alone", expected: nil},
+	}
+
+	for _, c := range cases {
+		if mentions := mention.Extract(c.content); !reflect.DeepEqual(c.expected, mentions) {
+			t.Errorf("Extract(%q) = %v, expected %v", c.content, mentions, c.expected)
+		}
+	}
+}
+
+// usernameとして使える文字列か確認
+func TestValidUsername(t *testing.T) {
+	cases := []struct {
+		username string
+		expected bool
+	}{
+		{username: "gopher_42", expected: true},
+		{username: "A", expected: true},
+		{username: "abcdefghijklmno", expected: true},
+		{username: "abcdefghijklmnop", expected: false},
+		{username: "", expected: false},
+		{username: "with-hyphen", expected: false},
+		{username: "日本語", expected: false},
+	}
+
+	for _, c := range cases {
+		if valid := mention.ValidUsername(c.username); valid != c.expected {
+			t.Errorf("ValidUsername(%q) = %v, expected %v", c.username, valid, c.expected)
+		}
+	}
+}
+
+// 大文字小文字を区別せずに比較できるように正規化する
+func TestNormalize(t *testing.T) {
+	if username := mention.Normalize("@GoPher"); username != "gopher" {
+		t.Errorf("unexpected username: %q", username)
+	}
+}
@@ -0,0 +1,365 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migration file name: {version}_{name}.up.sql / {version}_{name}.down.sql (like: 0001_create_users.up.sql)
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// directive line of sqlite migrations that rebuild tables referenced by foreign keys
+// the migration runs with foreign keys disabled (dropping the old table must not cascade to other tables)
+// and fails if any foreign key is broken after it, following https://www.sqlite.org/lang_altertable.html#otheralter
+const ForeignKeysOffDirective = "-- migrate:foreign_keys off"
+
+// numbered migration with up and down sql
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// applied migration recorded in schema_migrations table
+// Dirty: migration has started but not finished (needs manual fix before running migrations again)
+type SchemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Dirty     bool      `gorm:"not null;default:false" json:"dirty"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+// migration status shown by `migrate status`
+type Status struct {
+	Version   uint
+	Name      string
+	Applied   bool
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []*Migration
+}
+
+// load migrations from dirs of fsys
+// files of a version can be split across dirs (like: shared sql in "common", dialect specific sql in "mysql"),
+// but the same up or down file must not exist in more than one dir
+// every version must have both up and down file
+func Load(fsys fs.FS, dirs ...string) ([]*Migration, error) {
+	migrationsByVersion := map[uint]*Migration{}
+	for _, dir := range dirs {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			matches := fileNamePattern.FindStringSubmatch(entry.Name())
+			if matches == nil {
+				return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+			}
+			version, err := strconv.ParseUint(matches[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+
+			migration, ok := migrationsByVersion[uint(version)]
+			if !ok {
+				migration = &Migration{Version: uint(version), Name: matches[2]}
+				migrationsByVersion[uint(version)] = migration
+			} else if migration.Name != matches[2] {
+				return nil, fmt.Errorf("migration version %d has different names: %s, %s", version, migration.Name, matches[2])
+			}
+
+			sql := &migration.Down
+			if matches[3] == "up" {
+				sql = &migration.Up
+			}
+			if *sql != "" {
+				return nil, fmt.Errorf("duplicate migration file: %s", entry.Name())
+			}
+			*sql = string(data)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(migrationsByVersion))
+	for _, migration := range migrationsByVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration version %d must have both up and down file", migration.Version)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+func New(db *gorm.DB, migrations []*Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// apply pending migrations in version order
+// steps: number of migrations to apply (0 applies all pending migrations)
+func (m *Migrator) Up(steps int) ([]*Migration, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for _, migration := range m.migrations {
+		if steps > 0 && len(done) >= steps {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := m.run(migration, migration.Up, true); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// roll back applied migrations in reverse version order
+// steps: number of migrations to roll back (0 rolls back all applied migrations)
+func (m *Migrator) Down(steps int) ([]*Migration, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []*Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if steps > 0 && len(done) >= steps {
+			break
+		}
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		if err := m.run(migration, migration.Down, false); err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// status of all known migrations in version order
+func (m *Migrator) Status() ([]*Status, error) {
+	schemaMigrations, err := m.schemaMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := &Status{Version: migration.Version, Name: migration.Name}
+		if schemaMigration, ok := schemaMigrations[migration.Version]; ok {
+			status.Applied = true
+			status.Dirty = schemaMigration.Dirty
+			status.AppliedAt = &schemaMigration.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// get applied versions, returns error if any migration is dirty
+func (m *Migrator) appliedVersions() (map[uint]*SchemaMigration, error) {
+	schemaMigrations, err := m.schemaMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, schemaMigration := range schemaMigrations {
+		if schemaMigration.Dirty {
+			return nil, fmt.Errorf("database is dirty at version %d, fix the schema and the schema_migrations row manually", schemaMigration.Version)
+		}
+	}
+
+	return schemaMigrations, nil
+}
+
+// get rows of schema_migrations table by version, creates the table if not exists
+func (m *Migrator) schemaMigrations() (map[uint]*SchemaMigration, error) {
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var schemaMigrations []*SchemaMigration
+	if result := m.db.Find(&schemaMigrations); result.Error != nil {
+		return nil, result.Error
+	}
+
+	schemaMigrationsByVersion := map[uint]*SchemaMigration{}
+	for _, schemaMigration := range schemaMigrations {
+		schemaMigrationsByVersion[schemaMigration.Version] = schemaMigration
+	}
+
+	return schemaMigrationsByVersion, nil
+}
+
+// run up or down sql of migration
+// the migration is marked dirty while running because MySQL commits DDL implicitly and
+// a failed migration cannot be rolled back by the transaction
+func (m *Migrator) run(migration *Migration, sql string, up bool) error {
+	schemaMigration := &SchemaMigration{
+		Version:   migration.Version,
+		Name:      migration.Name,
+		Dirty:     true,
+		AppliedAt: time.Now(),
+	}
+	if result := m.db.Save(schemaMigration); result.Error != nil {
+		return result.Error
+	}
+
+	var err error
+	if hasDirective(sql, ForeignKeysOffDirective) {
+		err = m.runWithoutForeignKeys(migration, sql)
+	} else {
+		err = m.db.Transaction(func(tx *gorm.DB) error {
+			return execStatements(tx, migration, sql)
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	if !up {
+		return m.db.Delete(schemaMigration).Error
+	}
+	return m.db.Model(schemaMigration).Update("dirty", false).Error
+}
+
+// run sql in a transaction on a single connection with foreign keys disabled (sqlite only)
+// PRAGMA foreign_keys is a no-op inside a transaction, so it is switched outside of the transaction
+func (m *Migrator) runWithoutForeignKeys(migration *Migration, sql string) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		var foreignKeys int
+		if result := conn.Raw("PRAGMA foreign_keys").Scan(&foreignKeys); result.Error != nil {
+			return result.Error
+		}
+		if result := conn.Exec("PRAGMA foreign_keys = OFF"); result.Error != nil {
+			return result.Error
+		}
+
+		err := conn.Transaction(func(tx *gorm.DB) error {
+			if err := execStatements(tx, migration, sql); err != nil {
+				return err
+			}
+			return checkForeignKeys(tx, migration)
+		})
+
+		if result := conn.Exec(fmt.Sprintf("PRAGMA foreign_keys = %d", foreignKeys)); result.Error != nil && err == nil {
+			err = result.Error
+		}
+		return err
+	})
+}
+
+func execStatements(tx *gorm.DB, migration *Migration, sql string) error {
+	for _, statement := range splitStatements(sql) {
+		if result := tx.Exec(statement); result.Error != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, result.Error)
+		}
+	}
+	return nil
+}
+
+// returns error if PRAGMA foreign_key_check reports any row referencing a missing parent
+func checkForeignKeys(tx *gorm.DB, migration *Migration) error {
+	rows, err := tx.Raw("PRAGMA foreign_key_check").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table string
+		var rowId sql.NullInt64
+		var parent string
+		var foreignKeyId int
+		if err := rows.Scan(&table, &rowId, &parent, &foreignKeyId); err != nil {
+			return err
+		}
+		return fmt.Errorf("migration %d_%s failed: foreign key violation in %s referencing %s", migration.Version, migration.Name, table, parent)
+	}
+	return rows.Err()
+}
+
+// directive is a whole line of sql file
+func hasDirective(sql string, directive string) bool {
+	for _, line := range strings.Split(sql, "\n") {
+		if strings.TrimSpace(line) == directive {
+			return true
+		}
+	}
+	return false
+}
+
+// split sql file into statements
+// statements end with ";" at the end of line, lines starting with "--" are comments
+func splitStatements(sql string) []string {
+	var statements []string
+	var builder strings.Builder
+
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		builder.WriteString(line)
+		builder.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(builder.String()))
+			builder.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(builder.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
+
+// dialect name of db (mysql or sqlite)
+func Dialect(db *gorm.DB) (string, error) {
+	name := db.Dialector.Name()
+	if name != "mysql" && name != "sqlite" {
+		return "", errors.New("unsupported dialect: " + name)
+	}
+
+	return name, nil
+}
@@ -0,0 +1,121 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+const gitHubAPIURL = "https://api.github.com"
+
+type GitHubConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// default: read:user user:email
+	Scopes []string
+	// default: github.Endpoint and https://api.github.com (override for GitHub Enterprise or tests)
+	Endpoint   oauth2.Endpoint
+	APIURL     string
+	HTTPClient *http.Client
+}
+
+// GitHub OAuth app
+// GitHub does not issue ID tokens, so the user is read from the REST API with the access token
+type GitHubProvider struct {
+	config GitHubConfig
+	client *http.Client
+}
+
+type gitHubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type gitHubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func NewGitHubProvider(config GitHubConfig) *GitHubProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"read:user", "user:email"}
+	}
+	if config.Endpoint.AuthURL == "" {
+		config.Endpoint = github.Endpoint
+	}
+	if config.APIURL == "" {
+		config.APIURL = gitHubAPIURL
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+
+	return &GitHubProvider{config: config, client: httpClientOrDefault(config.HTTPClient)}
+}
+
+func (p *GitHubProvider) Name() string {
+	return p.config.Name
+}
+
+// nonce is not used because GitHub does not issue ID tokens
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	return p.oauth2Config().AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// exchange authorization code and read the user and its primary verified email
+func (p *GitHubProvider) Exchange(ctx context.Context, code, nonce, verifier string) (*UserInfo, error) {
+	token, err := p.oauth2Config().Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	var user gitHubUser
+	if err := getJSON(ctx, p.client, p.config.APIURL+"/user", token.AccessToken, &user); err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.ID == 0 {
+		return nil, errors.New("github user has no id")
+	}
+	var emails []gitHubEmail
+	if err := getJSON(ctx, p.client, p.config.APIURL+"/user/emails", token.AccessToken, &emails); err != nil {
+		return nil, fmt.Errorf("failed to get emails: %w", err)
+	}
+
+	userInfo := &UserInfo{
+		Provider: p.config.Name,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+	}
+	if userInfo.Name == "" {
+		userInfo.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			userInfo.Email = email.Email
+			userInfo.EmailVerified = email.Verified
+		}
+	}
+	if userInfo.Email == "" {
+		return nil, ErrNoEmail
+	}
+
+	return userInfo, nil
+}
+
+func (p *GitHubProvider) oauth2Config() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Scopes:       p.config.Scopes,
+		Endpoint:     p.config.Endpoint,
+	}
+}
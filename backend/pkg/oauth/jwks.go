@@ -0,0 +1,129 @@
+package oauth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keys are fetched again at most once per interval when a token has an unknown kid,
+// so that tokens with random kids cannot make us flood the provider
+const jwksRefreshInterval = time.Minute
+
+// public keys of a provider fetched from its jwks_uri
+// keys are cached and refreshed when a token is signed by an unknown key (key rotation)
+type RemoteKeySet struct {
+	url    string
+	client *http.Client
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func NewRemoteKeySet(url string, client *http.Client, clock clock.Clock) *RemoteKeySet {
+	return &RemoteKeySet{url: url, client: httpClientOrDefault(client), clock: clock}
+}
+
+// jwt.Keyfunc which selects the provider's key by kid header
+// tokens without kid are accepted only when the provider has exactly one key
+func (s *RemoteKeySet) Keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if key, ok := s.lookup(kid); ok {
+			return key, nil
+		}
+		if !s.fetchedAt.IsZero() && s.clock.Now().Sub(s.fetchedAt) < jwksRefreshInterval {
+			return nil, fmt.Errorf("unknown key id: %s", kid)
+		}
+		if err := s.fetch(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok := s.lookup(kid); ok {
+			return key, nil
+		}
+
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+}
+
+func (s *RemoteKeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" {
+		if len(s.keys) != 1 {
+			return nil, false
+		}
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// keys which are not for signature or cannot be parsed are skipped
+func (s *RemoteKeySet) fetch(ctx context.Context) error {
+	s.fetchedAt = s.clock.Now()
+
+	var jwks auth.JWKS
+	if err := getJSON(ctx, s.client, s.url, "", &jwks); err != nil {
+		return fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("jwks has no usable keys")
+	}
+
+	s.keys = keys
+	return nil
+}
+
+// GET url and decode JSON response
+// accessToken is sent in Authorization header when it is not empty
+func getJSON(ctx context.Context, client *http.Client, url, accessToken string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, url)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
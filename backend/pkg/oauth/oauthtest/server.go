@@ -0,0 +1,348 @@
+// Package oauthtest provides a local OpenID Connect provider for tests.
+//
+// The server implements discovery, JWKS, the authorization endpoint (which approves
+// every request immediately), the token endpoint with PKCE (S256) verification and
+// the userinfo endpoint. ID tokens are signed with an RSA key generated per server.
+package oauthtest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+	"github.com/daiki-kim/tweet-app/backend/pkg/oauth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ClientID     = "test-client"
+	ClientSecret = "test-secret"
+)
+
+// user authenticated by the server
+type User struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// authorization request waiting for the code to be exchanged
+type authRequest struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+}
+
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	user     User
+	key      *rsa.PrivateKey
+	keyId    string
+	codes    map[string]authRequest
+	tokens   map[string]User
+	modifyFn func(claims jwt.MapClaims)
+	signFn   func(claims jwt.MapClaims) string
+	omitUser bool
+}
+
+// start a server which authenticates user
+// close it with Close when the test ends
+func NewServer(user User) *Server {
+	s := &Server{
+		user:   user,
+		codes:  map[string]authRequest{},
+		tokens: map[string]User{},
+	}
+	s.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("/jwks", s.handleJWKS)
+	mux.HandleFunc("/authorize", s.handleAuthorize)
+	mux.HandleFunc("/token", s.handleToken)
+	mux.HandleFunc("/userinfo", s.handleUserinfo)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// OIDC provider configured for the server
+func (s *Server) Provider(name, redirectURL string) *oauth.OIDCProvider {
+	return s.ProviderWithClock(name, redirectURL, clock.New())
+}
+
+// OIDC provider configured for the server which uses clock to verify ID tokens and refresh JWKS
+func (s *Server) ProviderWithClock(name, redirectURL string, clock clock.Clock) *oauth.OIDCProvider {
+	return oauth.NewOIDCProvider(oauth.OIDCConfig{
+		Name:         name,
+		Issuer:       s.Issuer(),
+		ClientID:     ClientID,
+		ClientSecret: ClientSecret,
+		RedirectURL:  redirectURL,
+		HTTPClient:   s.Client(),
+		Clock:        clock,
+	})
+}
+
+// change the user authenticated by following requests
+func (s *Server) SetUser(user User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+}
+
+// modify claims of following ID tokens (e.g. to issue an expired token)
+func (s *Server) ModifyIDToken(modify func(claims jwt.MapClaims)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.modifyFn = modify
+}
+
+// sign following ID tokens with sign instead of the server's key
+// (e.g. with another server's SignIDToken to issue a forged token)
+func (s *Server) SetIDTokenSigner(sign func(claims jwt.MapClaims) string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.signFn = sign
+}
+
+// omit email and name from following ID tokens so that clients have to call userinfo
+func (s *Server) OmitUserClaims(omit bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.omitUser = omit
+}
+
+// replace the signing key with a new one with a different kid
+func (s *Server) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.key = key
+	s.keyId = randomString()
+}
+
+// sign claims with the current key as the provider would do
+func (s *Server) SignIDToken(claims jwt.MapClaims) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sign(claims)
+}
+
+// approve authorization request and return the URL the browser is redirected to
+// authCodeURL is the URL returned by oauth.Provider.AuthCodeURL
+func (s *Server) Authorize(authCodeURL string) (string, error) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Get(authCodeURL)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		return "", fmt.Errorf("authorization failed with status %d", res.StatusCode)
+	}
+
+	return res.Header.Get("Location"), nil
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"userinfo_endpoint":                     s.URL + "/userinfo",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, auth.JWKS{Keys: []auth.JWK{{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: s.keyId,
+		N:   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+	}}})
+}
+
+// approve the request without login and redirect back with code and state
+func (s *Server) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != ClientID || query.Get("response_type") != "code" || query.Get("redirect_uri") == "" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "PKCE is required", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	s.mu.Lock()
+	s.codes[code] = authRequest{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+	}
+	s.mu.Unlock()
+
+	redirectURL, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := redirectURL.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURL.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+}
+
+// exchange code for access token and ID token
+// codes can be used once and code_verifier must match code_challenge
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientId != ClientID || clientSecret != ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code := r.PostForm.Get("code")
+	request, ok := s.codes[code]
+	delete(s.codes, code)
+	if !ok || request.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(challenge[:])), []byte(request.codeChallenge)) != 1 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "code_verifier does not match"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": s.URL,
+		"sub": s.user.Subject,
+		"aud": ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	if request.nonce != "" {
+		claims["nonce"] = request.nonce
+	}
+	if !s.omitUser {
+		claims["email"] = s.user.Email
+		claims["email_verified"] = s.user.EmailVerified
+		claims["name"] = s.user.Name
+	}
+	if s.modifyFn != nil {
+		s.modifyFn(claims)
+	}
+
+	idToken := ""
+	if s.signFn != nil {
+		idToken = s.signFn(claims)
+	} else {
+		idToken = s.sign(claims)
+	}
+
+	accessToken := randomString()
+	s.tokens[accessToken] = s.user
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+// access token is accepted only in Authorization header
+func (s *Server) handleUserinfo(w http.ResponseWriter, r *http.Request) {
+	accessToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mu.Lock()
+	user, found := s.tokens[accessToken]
+	s.mu.Unlock()
+	if !ok || !found {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"sub":            user.Subject,
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+		"name":           user.Name,
+	})
+}
+
+func (s *Server) sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyId
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		panic(err)
+	}
+
+	return signed
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func randomString() string {
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,230 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// signing algorithms accepted for ID tokens ("none" and HMAC are never accepted)
+var idTokenSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type OIDCConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	// default: openid email profile
+	Scopes []string
+	// default: http.Client with timeout
+	HTTPClient *http.Client
+	// default: system clock
+	Clock clock.Clock
+}
+
+// OpenID Connect provider configured by discovery (Google, Okta, Keycloak, ...)
+// the ID token returned from the token endpoint is verified with the provider's JWKS
+type OIDCProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *oidcMetadata
+	keySet   *RemoteKeySet
+}
+
+// provider metadata served on <issuer>/.well-known/openid-configuration
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string      `json:"nonce"`
+	AuthorizedParty string      `json:"azp"`
+	Email           string      `json:"email"`
+	EmailVerified   booleanFlag `json:"email_verified"`
+	Name            string      `json:"name"`
+}
+
+// some providers return email_verified as "true"/"false" string instead of boolean
+type booleanFlag bool
+
+func (b *booleanFlag) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch value := value.(type) {
+	case bool:
+		*b = booleanFlag(value)
+	case string:
+		*b = booleanFlag(value == "true")
+	default:
+		*b = false
+	}
+
+	return nil
+}
+
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	if config.Clock == nil {
+		config.Clock = clock.New()
+	}
+
+	return &OIDCProvider{config: config, client: httpClientOrDefault(config.HTTPClient)}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.config.Name
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauth2Config, err := p.oauth2Config(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return oauth2Config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oauth2.SetAuthURLParam("nonce", nonce)), nil
+}
+
+// exchange authorization code and verify ID token
+// claims missing in the ID token (e.g. email) are read from the userinfo endpoint
+func (p *OIDCProvider) Exchange(ctx context.Context, code, nonce, verifier string) (*UserInfo, error) {
+	oauth2Config, err := p.oauth2Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauth2Config.Exchange(context.WithValue(ctx, oauth2.HTTPClient, p.client), code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+
+	claims, err := p.verifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+	userInfo := &UserInfo{
+		Provider:      p.config.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+	}
+
+	if userInfo.Email == "" && p.metadata.UserinfoEndpoint != "" {
+		var userinfoClaims idTokenClaims
+		if err := getJSON(ctx, p.client, p.metadata.UserinfoEndpoint, token.AccessToken, &userinfoClaims); err != nil {
+			return nil, fmt.Errorf("failed to get userinfo: %w", err)
+		}
+		// userinfo must be of the user authenticated by the ID token
+		if userinfoClaims.Subject != claims.Subject {
+			return nil, errors.New("userinfo subject does not match id token")
+		}
+		userInfo.Email = userinfoClaims.Email
+		userInfo.EmailVerified = bool(userinfoClaims.EmailVerified)
+		if userInfo.Name == "" {
+			userInfo.Name = userinfoClaims.Name
+		}
+	}
+	if userInfo.Email == "" {
+		return nil, ErrNoEmail
+	}
+
+	return userInfo, nil
+}
+
+// verify signature with JWKS, issuer, audience, expiration and nonce of ID token
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, rawIDToken, nonce string) (*idTokenClaims, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(rawIDToken, &claims, p.keySet.Keyfunc(ctx),
+		jwt.WithTimeFunc(p.config.Clock.Now),
+		jwt.WithValidMethods(idTokenSigningMethods),
+		jwt.WithIssuer(p.metadata.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: sub is empty", ErrInvalidIDToken)
+	}
+	// token issued to several clients must be authorized for this client
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("%w: azp does not match client id", ErrInvalidIDToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+
+	return &claims, nil
+}
+
+func (p *OIDCProvider) oauth2Config(ctx context.Context) (*oauth2.Config, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Scopes:       p.config.Scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.metadata.AuthorizationEndpoint,
+			TokenURL: p.metadata.TokenEndpoint,
+		},
+	}, nil
+}
+
+// fetch provider metadata on first use so that the app can start while the provider is unreachable
+func (p *OIDCProvider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return nil
+	}
+
+	issuer := strings.TrimSuffix(p.config.Issuer, "/")
+	var metadata oidcMetadata
+	if err := getJSON(ctx, p.client, issuer+"/.well-known/openid-configuration", "", &metadata); err != nil {
+		return fmt.Errorf("failed to discover %s: %w", issuer, err)
+	}
+	// metadata must be of the configured issuer (OpenID Connect Discovery 1.0 section 4.3)
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuer {
+		return fmt.Errorf("issuer %s does not match %s", metadata.Issuer, issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JwksURI == "" {
+		return fmt.Errorf("incomplete metadata of %s", issuer)
+	}
+
+	p.metadata = &metadata
+	p.keySet = NewRemoteKeySet(metadata.JwksURI, p.client, p.config.Clock)
+	return nil
+}
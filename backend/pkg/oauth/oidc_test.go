@@ -0,0 +1,225 @@
+package oauth_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+	"github.com/daiki-kim/tweet-app/backend/pkg/oauth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/oauth/oauthtest"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const testRedirectURL = "http://localhost:8080/oauth/test/callback"
+
+var testUser = oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: true, Name: "gopher"}
+
+// 認可リクエストを承認してcodeを取得する
+func authorize(t *testing.T, server *oauthtest.Server, provider oauth.Provider, state, nonce, verifier string) string {
+	authCodeURL, err := provider.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL returned an error: %v", err)
+	}
+	location, err := server.Authorize(authCodeURL)
+	if err != nil {
+		t.Fatalf("Authorize returned an error: %v", err)
+	}
+	redirectURL, err := url.Parse(location)
+	if err != nil {
+		t.Fatalf("failed to parse redirect url: %v", err)
+	}
+	if redirectURL.Query().Get("state") != state {
+		t.Fatalf("Expected state %s, got %s", state, redirectURL.Query().Get("state"))
+	}
+
+	return redirectURL.Query().Get("code")
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	server := oauthtest.NewServer(testUser)
+	defer server.Close()
+	provider := server.Provider("test", testRedirectURL)
+
+	// 認可リクエストにPKCEのchallengeとnonceを含める
+	verifier := oauth2.GenerateVerifier()
+	authCodeURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL returned an error: %v", err)
+	}
+	query, _ := url.Parse(authCodeURL)
+	if query.Query().Get("code_challenge_method") != "S256" || query.Query().Get("nonce") != "nonce" {
+		t.Errorf("unexpected auth code url: %s", authCodeURL)
+	}
+	if !strings.HasPrefix(authCodeURL, server.URL+"/authorize?") {
+		t.Errorf("Expected discovered authorization endpoint, got %s", authCodeURL)
+	}
+
+	code := authorize(t, server, provider, "state", "nonce", verifier)
+	userInfo, err := provider.Exchange(context.Background(), code, "nonce", verifier)
+	if err != nil {
+		t.Fatalf("Exchange returned an error: %v", err)
+	}
+	expected := oauth.UserInfo{Provider: "test", Subject: "user-1", Email: "gopher@example.com", EmailVerified: true, Name: "gopher"}
+	if *userInfo != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *userInfo)
+	}
+
+	// codeは一度しか使えない
+	if _, err := provider.Exchange(context.Background(), code, "nonce", verifier); err == nil {
+		t.Errorf("Expected an error for reused code")
+	}
+}
+
+// ID tokenにemailがない場合はuserinfoから取得する
+func TestOIDCProviderUserinfo(t *testing.T) {
+	server := oauthtest.NewServer(testUser)
+	defer server.Close()
+	server.OmitUserClaims(true)
+	provider := server.Provider("test", testRedirectURL)
+
+	verifier := oauth2.GenerateVerifier()
+	code := authorize(t, server, provider, "state", "nonce", verifier)
+	userInfo, err := provider.Exchange(context.Background(), code, "nonce", verifier)
+	if err != nil {
+		t.Fatalf("Exchange returned an error: %v", err)
+	}
+	if userInfo.Email != "gopher@example.com" || !userInfo.EmailVerified || userInfo.Name != "gopher" {
+		t.Errorf("unexpected user info: %+v", userInfo)
+	}
+}
+
+// PKCEのverifierが一致しない場合はcodeを交換できない
+func TestOIDCProviderInvalidVerifier(t *testing.T) {
+	server := oauthtest.NewServer(testUser)
+	defer server.Close()
+	provider := server.Provider("test", testRedirectURL)
+
+	code := authorize(t, server, provider, "state", "nonce", oauth2.GenerateVerifier())
+	if _, err := provider.Exchange(context.Background(), code, "nonce", oauth2.GenerateVerifier()); err == nil {
+		t.Errorf("Expected an error for invalid verifier")
+	}
+}
+
+func TestOIDCProviderInvalidIDToken(t *testing.T) {
+	testCases := []struct {
+		name   string
+		nonce  string
+		modify func(claims jwt.MapClaims)
+	}{
+		{name: "nonce mismatch", nonce: "other"},
+		{name: "audience mismatch", modify: func(claims jwt.MapClaims) { claims["aud"] = "other-client" }},
+		{name: "issuer mismatch", modify: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }},
+		{name: "expired", modify: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() }},
+		{name: "no expiration", modify: func(claims jwt.MapClaims) { delete(claims, "exp") }},
+		{name: "no subject", modify: func(claims jwt.MapClaims) { delete(claims, "sub") }},
+		// 複数のclientに発行されたtokenはazpがこのclientでなければならない
+		{name: "azp mismatch", modify: func(claims jwt.MapClaims) {
+			claims["aud"] = []string{oauthtest.ClientID, "other-client"}
+			claims["azp"] = "other-client"
+		}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := oauthtest.NewServer(testUser)
+			defer server.Close()
+			server.ModifyIDToken(testCase.modify)
+			provider := server.Provider("test", testRedirectURL)
+
+			nonce := testCase.nonce
+			if nonce == "" {
+				nonce = "nonce"
+			}
+			verifier := oauth2.GenerateVerifier()
+			code := authorize(t, server, provider, "state", "nonce", verifier)
+			_, err := provider.Exchange(context.Background(), code, nonce, verifier)
+			if !errors.Is(err, oauth.ErrInvalidIDToken) {
+				t.Errorf("Expected ErrInvalidIDToken, got %v", err)
+			}
+		})
+	}
+}
+
+// 署名が不正なID tokenとalg=noneのID tokenは受け付けない
+func TestOIDCProviderIDTokenSignature(t *testing.T) {
+	otherServer := oauthtest.NewServer(testUser)
+	defer otherServer.Close()
+
+	signers := map[string]func(claims jwt.MapClaims) string{
+		// 別のプロバイダーの鍵で署名したtoken
+		"forged": otherServer.SignIDToken,
+		"none": func(claims jwt.MapClaims) string {
+			unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return unsigned
+		},
+		"hs256": func(claims jwt.MapClaims) string {
+			signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(oauthtest.ClientSecret))
+			return signed
+		},
+	}
+
+	for name, sign := range signers {
+		t.Run(name, func(t *testing.T) {
+			server := oauthtest.NewServer(testUser)
+			defer server.Close()
+			server.SetIDTokenSigner(sign)
+			provider := server.Provider("test", testRedirectURL)
+
+			verifier := oauth2.GenerateVerifier()
+			code := authorize(t, server, provider, "state", "nonce", verifier)
+			_, err := provider.Exchange(context.Background(), code, "nonce", verifier)
+			if !errors.Is(err, oauth.ErrInvalidIDToken) {
+				t.Errorf("Expected ErrInvalidIDToken, got %v", err)
+			}
+		})
+	}
+}
+
+// プロバイダーが鍵をローテーションした場合はJWKSを再取得する
+// 未知のkidによる再取得は1分に1回までに制限する
+func TestOIDCProviderKeyRotation(t *testing.T) {
+	server := oauthtest.NewServer(testUser)
+	defer server.Close()
+	fakeClock := clock.NewFake(time.Now())
+	provider := server.ProviderWithClock("test", testRedirectURL, fakeClock)
+
+	verifier := oauth2.GenerateVerifier()
+	code := authorize(t, server, provider, "state", "nonce", verifier)
+	if _, err := provider.Exchange(context.Background(), code, "nonce", verifier); err != nil {
+		t.Fatalf("Exchange returned an error: %v", err)
+	}
+
+	// 直前に取得したばかりの場合は再取得しない
+	server.RotateKey()
+	code = authorize(t, server, provider, "state", "nonce", verifier)
+	if _, err := provider.Exchange(context.Background(), code, "nonce", verifier); !errors.Is(err, oauth.ErrInvalidIDToken) {
+		t.Fatalf("Expected ErrInvalidIDToken before refresh interval, got %v", err)
+	}
+
+	fakeClock.Advance(time.Minute)
+	code = authorize(t, server, provider, "state", "nonce", verifier)
+	if _, err := provider.Exchange(context.Background(), code, "nonce", verifier); err != nil {
+		t.Fatalf("Exchange after key rotation returned an error: %v", err)
+	}
+}
+
+// discoveryのissuerが設定と一致しない場合は使用しない
+func TestOIDCProviderIssuerMismatch(t *testing.T) {
+	server := oauthtest.NewServer(testUser)
+	defer server.Close()
+
+	provider := oauth.NewOIDCProvider(oauth.OIDCConfig{
+		Name:       "test",
+		Issuer:     server.Issuer() + "/other",
+		ClientID:   oauthtest.ClientID,
+		HTTPClient: server.Client(),
+	})
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", oauth2.GenerateVerifier()); err == nil {
+		t.Errorf("Expected an error for issuer mismatch")
+	}
+}
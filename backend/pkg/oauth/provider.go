@@ -0,0 +1,172 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/configs"
+)
+
+// provider kinds of OAUTH_<NAME>_TYPE
+const (
+	ProviderOIDC   = "oidc"
+	ProviderGitHub = "github"
+
+	// issuer of Google's OpenID Connect
+	GoogleIssuer = "https://accounts.google.com"
+
+	// timeout of requests to providers
+	requestTimeout = 10 * time.Second
+)
+
+var (
+	ErrInvalidIDToken = errors.New("invalid id token")
+	ErrNoEmail        = errors.New("provider did not return an email")
+)
+
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// user authenticated by a provider
+// Subject is unique only within the provider
+type UserInfo struct {
+	Provider      string `json:"provider"`
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// OAuth 2.0 / OpenID Connect identity provider used for social login
+// every authorization request uses PKCE (S256) with verifier,
+// and providers issuing ID tokens bind them to the request with nonce
+type Provider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	Exchange(ctx context.Context, code, nonce, verifier string) (*UserInfo, error)
+}
+
+// providers enabled for login, looked up by name in /oauth/:provider/...
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	registry := &Registry{providers: map[string]Provider{}}
+	for _, provider := range providers {
+		registry.providers[provider.Name()] = provider
+	}
+
+	return registry
+}
+
+func (r *Registry) Get(name string) (Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+// names of registered providers in alphabetical order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// create registry from environment variables
+// OAUTH_PROVIDERS: comma separated provider names (default: google)
+// for each provider <NAME> (upper case of the name):
+//
+//	OAUTH_<NAME>_TYPE: oidc or github (default: github for "github", oidc otherwise)
+//	OAUTH_<NAME>_ISSUER: issuer of OIDC provider, discovered via /.well-known/openid-configuration
+//	                     (default: https://accounts.google.com for "google", required otherwise)
+//	OAUTH_<NAME>_CLIENT_ID, OAUTH_<NAME>_CLIENT_SECRET
+//	OAUTH_<NAME>_REDIRECT_URL (default: http://localhost:8080/oauth/<name>/callback)
+//	OAUTH_<NAME>_SCOPES: space separated scopes (default depends on TYPE)
+//
+// GOOGLE_CLIENT_ID, GOOGLE_CLIENT_SECRET and GOOGLE_REDIRECT_URL are still read for "google"
+func NewRegistryFromEnv() (*Registry, error) {
+	var providers []Provider
+	for _, name := range strings.Split(configs.GetEnvDefault("OAUTH_PROVIDERS", "google"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !providerNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid oauth provider name: %s", name)
+		}
+
+		provider, err := newProviderFromEnv(name)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	return NewRegistry(providers...), nil
+}
+
+func newProviderFromEnv(name string) (Provider, error) {
+	prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+	env := func(key, defVal string) string {
+		return configs.GetEnvDefault(prefix+key, defVal)
+	}
+
+	clientId := env("CLIENT_ID", "")
+	clientSecret := env("CLIENT_SECRET", "")
+	redirectURL := env("REDIRECT_URL", "http://localhost:8080/oauth/"+name+"/callback")
+	issuer := env("ISSUER", "")
+	defaultType := ProviderOIDC
+	switch name {
+	case "google":
+		clientId = env("CLIENT_ID", configs.GetEnvDefault("GOOGLE_CLIENT_ID", ""))
+		clientSecret = env("CLIENT_SECRET", configs.GetEnvDefault("GOOGLE_CLIENT_SECRET", ""))
+		redirectURL = env("REDIRECT_URL", configs.GetEnvDefault("GOOGLE_REDIRECT_URL", redirectURL))
+		issuer = env("ISSUER", GoogleIssuer)
+	case "github":
+		defaultType = ProviderGitHub
+	}
+	scopes := strings.Fields(env("SCOPES", ""))
+
+	switch kind := env("TYPE", defaultType); kind {
+	case ProviderOIDC:
+		if issuer == "" {
+			return nil, fmt.Errorf("%sISSUER is not set", prefix)
+		}
+		return NewOIDCProvider(OIDCConfig{
+			Name:         name,
+			Issuer:       issuer,
+			ClientID:     clientId,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+		}), nil
+	case ProviderGitHub:
+		return NewGitHubProvider(GitHubConfig{
+			Name:         name,
+			ClientID:     clientId,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported oauth provider type: %s", kind)
+	}
+}
+
+// http client used for requests to providers
+func httpClientOrDefault(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+
+	return &http.Client{Timeout: requestTimeout}
+}
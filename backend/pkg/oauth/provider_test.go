@@ -0,0 +1,127 @@
+package oauth_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/oauth"
+	"golang.org/x/oauth2"
+)
+
+// 環境変数から複数のプロバイダーを登録できるかのテスト
+func TestNewRegistryFromEnv(t *testing.T) {
+	t.Setenv("OAUTH_PROVIDERS", "google, github,okta")
+	t.Setenv("GOOGLE_CLIENT_ID", "google-client")
+	t.Setenv("OAUTH_OKTA_ISSUER", "https://example.okta.com")
+
+	registry, err := oauth.NewRegistryFromEnv()
+	if err != nil {
+		t.Fatalf("NewRegistryFromEnv returned an error: %v", err)
+	}
+	if names := registry.Names(); !reflect.DeepEqual(names, []string{"github", "google", "okta"}) {
+		t.Errorf("unexpected providers: %v", names)
+	}
+
+	google, _ := registry.Get("google")
+	if _, ok := google.(*oauth.OIDCProvider); !ok {
+		t.Errorf("Expected google to be an OIDC provider, got %T", google)
+	}
+	github, _ := registry.Get("github")
+	if _, ok := github.(*oauth.GitHubProvider); !ok {
+		t.Errorf("Expected github to be a GitHub provider, got %T", github)
+	}
+	if _, ok := registry.Get("unknown"); ok {
+		t.Errorf("Expected unknown provider not to be registered")
+	}
+}
+
+func TestNewRegistryFromEnvErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		env  map[string]string
+	}{
+		{name: "missing issuer", env: map[string]string{"OAUTH_PROVIDERS": "okta"}},
+		{name: "invalid name", env: map[string]string{"OAUTH_PROVIDERS": "../okta"}},
+		{name: "unsupported type", env: map[string]string{"OAUTH_PROVIDERS": "okta", "OAUTH_OKTA_TYPE": "saml"}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			for key, value := range testCase.env {
+				t.Setenv(key, value)
+			}
+			if _, err := oauth.NewRegistryFromEnv(); err == nil {
+				t.Errorf("Expected an error")
+			}
+		})
+	}
+}
+
+// GitHubのAPIからユーザーとprimaryのemailを取得する
+func TestGitHubProviderExchange(t *testing.T) {
+	verifier := oauth2.GenerateVerifier()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("code") != "code" || r.PostForm.Get("code_verifier") != verifier {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "gho_token", "token_type": "bearer"})
+	})
+	// access tokenはAuthorizationヘッダーでのみ受け付ける
+	authorized := func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer gho_token" && r.URL.RawQuery == ""
+	}
+	mux.HandleFunc("/api/user", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 42, "login": "gopher", "name": ""})
+	})
+	mux.HandleFunc("/api/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"email": "other@example.com", "primary": false, "verified": true},
+			{"email": "gopher@example.com", "primary": true, "verified": true},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider := oauth.NewGitHubProvider(oauth.GitHubConfig{
+		Name:     "github",
+		ClientID: "client",
+		Endpoint: oauth2.Endpoint{AuthURL: server.URL + "/login/oauth/authorize", TokenURL: server.URL + "/login/oauth/access_token"},
+		APIURL:   server.URL + "/api",
+	})
+
+	// 認可リクエストにPKCEのchallengeを含める
+	authCodeURL, err := provider.AuthCodeURL(context.Background(), "state", "nonce", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL returned an error: %v", err)
+	}
+	parsed, _ := url.Parse(authCodeURL)
+	if parsed.Query().Get("code_challenge_method") != "S256" || parsed.Query().Get("state") != "state" {
+		t.Errorf("unexpected auth code url: %s", authCodeURL)
+	}
+
+	userInfo, err := provider.Exchange(context.Background(), "code", "nonce", verifier)
+	if err != nil {
+		t.Fatalf("Exchange returned an error: %v", err)
+	}
+	expected := oauth.UserInfo{Provider: "github", Subject: "42", Email: "gopher@example.com", EmailVerified: true, Name: "gopher"}
+	if *userInfo != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *userInfo)
+	}
+}
@@ -0,0 +1,170 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// pagination constants
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// ページの境界となるレコードの位置
+// (created_at, id)の組で並び順を一意に決める
+type Cursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uint      `json:"id"`
+}
+
+// cursorをクライアントに返すための不透明な文字列に変換
+func (c *Cursor) Encode() (string, error) {
+	// MarshalJSONが再帰的に呼ばれないようにalias型で変換
+	type rawCursor Cursor
+	cursorJSON, err := json.Marshal((*rawCursor)(c))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(cursorJSON), nil
+}
+
+// クライアントから受け取った文字列をcursorに変換
+func DecodeCursor(encoded string) (*Cursor, error) {
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(cursorJSON, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.ID == 0 || cursor.CreatedAt.IsZero() {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// レスポンスではcursorをエンコード済みの文字列として返す
+func (c *Cursor) MarshalJSON() ([]byte, error) {
+	encoded, err := c.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encoded)
+}
+
+// 取得するページ
+// Cursorがnilの場合は先頭のページ
+type Page struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// queryの?limit=&cursor=からPageを作成
+func NewPage(limitString, cursorString string) (*Page, error) {
+	page := &Page{Limit: DefaultLimit}
+
+	if limitString != "" {
+		limit, err := strconv.Atoi(limitString)
+		if err != nil || limit < 1 {
+			return nil, ErrInvalidLimit
+		}
+		page.Limit = min(limit, MaxLimit)
+	}
+
+	if cursorString != "" {
+		cursor, err := DecodeCursor(cursorString)
+		if err != nil {
+			return nil, err
+		}
+		page.Cursor = cursor
+	}
+
+	return page, nil
+}
+
+// 1ページに含めるレコード数
+func (p *Page) GetLimit() int {
+	if p == nil || p.Limit < 1 {
+		return DefaultLimit
+	}
+
+	return min(p.Limit, MaxLimit)
+}
+
+// tableの(created_at, id)の降順でページングするgormのscope
+// 次のページの有無を判定するためにlimit+1件取得する
+func (p *Page) Scope(table string) func(db *gorm.DB) *gorm.DB {
+	createdAtColumn := table + ".created_at"
+	idColumn := table + ".id"
+
+	return func(db *gorm.DB) *gorm.DB {
+		if p != nil && p.Cursor != nil {
+			db = db.Where(
+				fmt.Sprintf("(%s < ? OR (%s = ? AND %s < ?))", createdAtColumn, createdAtColumn, idColumn),
+				p.Cursor.CreatedAt, p.Cursor.CreatedAt, p.Cursor.ID,
+			)
+		}
+
+		return db.
+			Order(createdAtColumn + " DESC").
+			Order(idColumn + " DESC").
+			Limit(p.GetLimit() + 1)
+	}
+}
+
+// ページングされた一覧
+// {"data": [...], "next_cursor": "..."}の形でレスポンスに使用する
+type List[T any] struct {
+	Items      []T     `json:"data"`
+	NextCursor *Cursor `json:"next_cursor"`
+}
+
+// Page.Scopeで取得したitemsからListを作成
+// limitを超えた分があれば次のページのcursorを設定する
+func NewList[T any](items []T, page *Page, cursorOf func(T) Cursor) *List[T] {
+	limit := page.GetLimit()
+	list := &List[T]{Items: items}
+	if list.Items == nil {
+		list.Items = []T{}
+	}
+
+	if len(list.Items) > limit {
+		list.Items = list.Items[:limit]
+		nextCursor := cursorOf(list.Items[limit-1])
+		list.NextCursor = &nextCursor
+	}
+
+	return list
+}
+
+// Listの各itemをfnで変換したListを作成(next_cursorはそのまま引き継ぐ)
+// レスポンス用のDTOに変換する場合に使用する
+func Map[T, U any](list *List[T], fn func(T) U) *List[U] {
+	if list == nil {
+		return nil
+	}
+
+	mapped := &List[U]{Items: make([]U, 0, len(list.Items)), NextCursor: list.NextCursor}
+	for _, item := range list.Items {
+		mapped.Items = append(mapped.Items, fn(item))
+	}
+
+	return mapped
+}
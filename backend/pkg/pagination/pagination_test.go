@@ -0,0 +1,123 @@
+package pagination_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+)
+
+type testItem struct {
+	ID        uint
+	CreatedAt time.Time
+}
+
+func testItemCursor(item *testItem) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: item.CreatedAt, ID: item.ID}
+}
+
+// エンコードしたcursorが元に戻るテスト
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := &pagination.Cursor{
+		CreatedAt: time.Date(2024, 9, 1, 12, 0, 0, 123, time.UTC),
+		ID:        42,
+	}
+
+	encoded, err := cursor.Encode()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	decoded, err := pagination.DecodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID {
+		t.Errorf("expected %v, got %v", cursor, decoded)
+	}
+}
+
+// 不正なcursorのテスト
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, invalid := range []string{"not base64!", "e30", "eyJpZCI6MX0"} {
+		if _, err := pagination.DecodeCursor(invalid); err != pagination.ErrInvalidCursor {
+			t.Errorf("expected ErrInvalidCursor for %q, got %v", invalid, err)
+		}
+	}
+}
+
+// queryからPageを作成するテスト
+func TestNewPage(t *testing.T) {
+	page, err := pagination.NewPage("", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if page.Limit != pagination.DefaultLimit || page.Cursor != nil {
+		t.Errorf("expected default page, got %v", page)
+	}
+
+	page, err = pagination.NewPage("1000", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if page.Limit != pagination.MaxLimit {
+		t.Errorf("expected limit %d, got %d", pagination.MaxLimit, page.Limit)
+	}
+
+	for _, invalid := range []string{"0", "-1", "abc"} {
+		if _, err := pagination.NewPage(invalid, ""); err != pagination.ErrInvalidLimit {
+			t.Errorf("expected ErrInvalidLimit for %q, got %v", invalid, err)
+		}
+	}
+}
+
+// limitを超えた分を切り捨てて次のcursorを設定するテスト
+func TestNewList(t *testing.T) {
+	now := time.Now()
+	items := []*testItem{
+		{ID: 3, CreatedAt: now},
+		{ID: 2, CreatedAt: now.Add(-time.Minute)},
+		{ID: 1, CreatedAt: now.Add(-time.Hour)},
+	}
+
+	list := pagination.NewList(items, &pagination.Page{Limit: 2}, testItemCursor)
+	if len(list.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(list.Items))
+	}
+	if list.NextCursor == nil || list.NextCursor.ID != 2 {
+		t.Errorf("expected next cursor at id 2, got %v", list.NextCursor)
+	}
+
+	list = pagination.NewList(items, &pagination.Page{Limit: 3}, testItemCursor)
+	if len(list.Items) != 3 || list.NextCursor != nil {
+		t.Errorf("expected last page, got %v", list)
+	}
+
+	empty := pagination.NewList[*testItem](nil, nil, testItemCursor)
+	if empty.Items == nil || len(empty.Items) != 0 {
+		t.Errorf("expected empty items, got %v", empty.Items)
+	}
+}
+
+// 変換後のListにnext_cursorが引き継がれるかのテスト
+func TestMap(t *testing.T) {
+	now := time.Now()
+	items := []*testItem{
+		{ID: 2, CreatedAt: now},
+		{ID: 1, CreatedAt: now.Add(-time.Minute)},
+	}
+
+	list := pagination.NewList(items, &pagination.Page{Limit: 1}, testItemCursor)
+	ids := pagination.Map(list, func(item *testItem) uint { return item.ID })
+	if len(ids.Items) != 1 || ids.Items[0] != 2 {
+		t.Errorf("expected [2], got %v", ids.Items)
+	}
+	if ids.NextCursor != list.NextCursor {
+		t.Errorf("expected next cursor to be kept, got %v", ids.NextCursor)
+	}
+
+	empty := pagination.Map(pagination.NewList[*testItem](nil, nil, testItemCursor), func(item *testItem) uint { return item.ID })
+	if empty.Items == nil || len(empty.Items) != 0 {
+		t.Errorf("expected empty items, got %v", empty.Items)
+	}
+}
@@ -0,0 +1,42 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// full text index of a text column of a table
+// Match returns a scope that narrows the rows down to the ones whose column contains all terms
+// (a term may be a phrase with spaces), no terms means no condition
+type SearchIndex interface {
+	Match(terms []string) func(db *gorm.DB) *gorm.DB
+}
+
+// create search index of table.column for the dialect of db
+// mysql: FULLTEXT index with the ngram parser, sqlite: FTS5 with the trigram tokenizer
+func NewSearchIndex(db *gorm.DB, table, column string) (SearchIndex, error) {
+	switch name := db.Dialector.Name(); name {
+	case "mysql":
+		return NewMySQLIndex(table, column), nil
+	case "sqlite":
+		return NewSQLiteIndex(db, table, column)
+	default:
+		return nil, fmt.Errorf("unsupported database for search index: %s", name)
+	}
+}
+
+// quote term as a phrase of MATCH queries ("" in a phrase is a literal ")
+func quotePhrase(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
+
+// escape character of LIKE patterns made by EscapeLike
+// "!" instead of "\" because a backslash is an escape in MySQL string literals but not in SQLite
+const LikeEscape = "!"
+
+// EscapeLike escapes "%", "_" and "!" in term for LIKE ... ESCAPE '!'
+func EscapeLike(term string) string {
+	return strings.NewReplacer(LikeEscape, LikeEscape+LikeEscape, "%", LikeEscape+"%", "_", LikeEscape+"_").Replace(term)
+}
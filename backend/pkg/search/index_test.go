@@ -0,0 +1,60 @@
+package search_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/search"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type tweet struct {
+	ID      uint
+	Content string
+}
+
+// SQLを実行せずに生成だけするDBを準備
+func openDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "search.db")), &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	return db
+}
+
+// MySQLでは全ての単語をフレーズとして必須にしたBOOLEAN MODEのMATCHで検索する
+func TestMySQLIndexMatch(t *testing.T) {
+	index := search.NewMySQLIndex("tweets", "content")
+
+	statement := openDryRunDB(t).Scopes(index.Match([]string{"golang", `full "text"`, "あ"})).Find(&[]tweet{}).Statement
+
+	expectedSQL := "SELECT * FROM `tweets` WHERE tweets.content LIKE ? ESCAPE '!' AND MATCH(tweets.content) AGAINST (? IN BOOLEAN MODE)"
+	if sql := statement.SQL.String(); sql != expectedSQL {
+		t.Errorf("unexpected sql: %s", sql)
+	}
+	// ngram_token_sizeより短い単語はLIKEで検索する
+	expectedVars := []interface{}{"%あ%", `+"golang" +"full text"`}
+	if !reflect.DeepEqual(expectedVars, statement.Vars) {
+		t.Errorf("unexpected vars: %q", statement.Vars)
+	}
+}
+
+// 単語がない場合は条件を追加しない
+func TestMySQLIndexMatchNoTerms(t *testing.T) {
+	index := search.NewMySQLIndex("tweets", "content")
+
+	statement := openDryRunDB(t).Scopes(index.Match(nil)).Find(&[]tweet{}).Statement
+
+	if sql := statement.SQL.String(); sql != "SELECT * FROM `tweets`" {
+		t.Errorf("unexpected sql: %s", sql)
+	}
+}
+
+// LIKEのワイルドカードとエスケープ文字をエスケープする
+func TestEscapeLike(t *testing.T) {
+	if escaped := search.EscapeLike("100%_off!"); escaped != "100!%!_off!!" {
+		t.Errorf("unexpected escaped term: %s", escaped)
+	}
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ngram_token_size of the MySQL server (default 2)
+const ngramTokenSize = 2
+
+// MySQL FULLTEXT index with the ngram parser (the index is created by the migration)
+// ngram splits text into tokens of ngram_token_size characters so that Japanese text without spaces
+// can be searched, terms shorter than ngram_token_size are searched with LIKE
+type MySQLIndex struct {
+	Table  string
+	Column string
+}
+
+func NewMySQLIndex(table, column string) SearchIndex {
+	return &MySQLIndex{Table: table, Column: column}
+}
+
+func (i *MySQLIndex) Match(terms []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		// every term is a required phrase in boolean mode: +"term" +"two words"
+		var phrases []string
+		for _, term := range terms {
+			if utf8.RuneCountInString(term) >= ngramTokenSize {
+				phrases = append(phrases, "+"+quotePhrase(strings.ReplaceAll(term, `"`, "")))
+				continue
+			}
+			db = db.Where(fmt.Sprintf("%s.%s LIKE ? ESCAPE '!'", i.Table, i.Column), "%"+EscapeLike(term)+"%")
+		}
+
+		if len(phrases) == 0 {
+			return db
+		}
+		return db.Where(
+			fmt.Sprintf("MATCH(%s.%s) AGAINST (? IN BOOLEAN MODE)", i.Table, i.Column),
+			strings.Join(phrases, " "),
+		)
+	}
+}
@@ -0,0 +1,156 @@
+package search
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/hashtag"
+	"github.com/daiki-kim/tweet-app/backend/pkg/mention"
+)
+
+// date format of since: and until:
+const DateLayout = "2006-01-02"
+
+var (
+	ErrEmptyQuery   = errors.New("search query is empty")
+	ErrInvalidFrom  = errors.New("invalid from filter")
+	ErrInvalidSince = errors.New("invalid since date")
+	ErrInvalidUntil = errors.New("invalid until date")
+	ErrInvalidHas   = errors.New("invalid has filter")
+	ErrTooManyTerms = errors.New("too many search terms")
+)
+
+// max number of terms, phrases and hashtags in a query
+const MaxTerms = 10
+
+// parsed search query
+// Terms: words and "quoted phrases" that the content must contain (all of them)
+// From: username of the author (without "@")
+// Since: tweets created on or after the date, Until: tweets created before the date (UTC)
+// Has: media kinds of has:image / has:video (lower case, not validated)
+// Hashtags: normalized hashtags of #tag (without "#")
+type Query struct {
+	Terms    []string
+	From     string
+	Since    *time.Time
+	Until    *time.Time
+	Has      []string
+	Hashtags []string
+}
+
+// Parse search query like: `golang "full text" from:gopher since:2024-09-01 until:2024-10-01 has:image #go`
+// operators are case-insensitive, unknown operators (like "foo:bar") are searched as terms
+// an unclosed quote makes the rest of the query a phrase
+func Parse(q string) (*Query, error) {
+	query := &Query{}
+
+	for _, token := range tokenize(q) {
+		if token.phrase {
+			query.Terms = append(query.Terms, token.text)
+			continue
+		}
+
+		key, value, hasOperator := strings.Cut(token.text, ":")
+		switch strings.ToLower(key) {
+		case "from":
+			if !hasOperator {
+				break
+			}
+			username := strings.TrimLeft(value, "@＠")
+			if !mention.ValidUsername(username) {
+				return nil, ErrInvalidFrom
+			}
+			query.From = username
+			continue
+		case "since":
+			if !hasOperator {
+				break
+			}
+			since, err := time.Parse(DateLayout, value)
+			if err != nil {
+				return nil, ErrInvalidSince
+			}
+			query.Since = &since
+			continue
+		case "until":
+			if !hasOperator {
+				break
+			}
+			until, err := time.Parse(DateLayout, value)
+			if err != nil {
+				return nil, ErrInvalidUntil
+			}
+			query.Until = &until
+			continue
+		case "has":
+			if !hasOperator {
+				break
+			}
+			if value == "" {
+				return nil, ErrInvalidHas
+			}
+			query.Has = append(query.Has, strings.ToLower(value))
+			continue
+		}
+
+		if strings.HasPrefix(token.text, "#") || strings.HasPrefix(token.text, "＃") {
+			if name, ok := hashtag.Normalize(token.text); ok {
+				query.Hashtags = append(query.Hashtags, name)
+				continue
+			}
+		}
+
+		query.Terms = append(query.Terms, token.text)
+	}
+
+	if len(query.Terms) == 0 && len(query.Hashtags) == 0 && query.From == "" &&
+		query.Since == nil && query.Until == nil && len(query.Has) == 0 {
+		return nil, ErrEmptyQuery
+	}
+	if len(query.Terms)+len(query.Hashtags) > MaxTerms {
+		return nil, ErrTooManyTerms
+	}
+
+	return query, nil
+}
+
+type token struct {
+	text   string
+	phrase bool
+}
+
+// split query by spaces (including fullwidth space), "quoted phrases" are kept as one token
+func tokenize(q string) []token {
+	var tokens []token
+	var current []rune
+	inPhrase := false
+
+	flush := func(phrase bool) {
+		text := strings.TrimSpace(string(current))
+		if phrase {
+			// spaces in a phrase are normalized to one space
+			text = strings.Join(strings.Fields(text), " ")
+		}
+		if text != "" {
+			tokens = append(tokens, token{text: text, phrase: phrase})
+		}
+		current = current[:0]
+	}
+
+	for _, r := range q {
+		switch {
+		case r == '"' || r == '”' || r == '“':
+			flush(inPhrase)
+			inPhrase = !inPhrase
+		case unicode.IsSpace(r) && !inPhrase:
+			flush(false)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush(inPhrase)
+
+	return tokens
+}
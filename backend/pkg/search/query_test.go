@@ -0,0 +1,65 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/search"
+)
+
+// 演算子、フレーズ、hashtagを含む検索クエリを解析できるか確認
+func TestParse(t *testing.T) {
+	query, err := search.Parse(`golang "full  text" FROM:@Gopher since:2024-09-01 until:2024-10-01 has:Image #Go 東京　ラーメン foo:bar`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	since := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	expected := &search.Query{
+		Terms:    []string{"golang", "full text", "東京", "ラーメン", "foo:bar"},
+		From:     "Gopher",
+		Since:    &since,
+		Until:    &until,
+		Has:      []string{"image"},
+		Hashtags: []string{"go"},
+	}
+	if !reflect.DeepEqual(expected, query) {
+		t.Errorf("Parse() = %+v, expected %+v", query, expected)
+	}
+}
+
+// 閉じていない"はクエリの最後までをフレーズとする
+func TestParseUnclosedPhrase(t *testing.T) {
+	query, err := search.Parse(`"hello world`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual([]string{"hello world"}, query.Terms) {
+		t.Errorf("unexpected terms: %q", query.Terms)
+	}
+}
+
+// 不正な検索クエリはエラーになる
+func TestParseInvalidQuery(t *testing.T) {
+	cases := []struct {
+		q        string
+		expected error
+	}{
+		{q: "", expected: search.ErrEmptyQuery},
+		{q: `  "" `, expected: search.ErrEmptyQuery},
+		{q: "from:not-a-user", expected: search.ErrInvalidFrom},
+		{q: "since:2024/09/01", expected: search.ErrInvalidSince},
+		{q: "until:yesterday", expected: search.ErrInvalidUntil},
+		{q: "has:", expected: search.ErrInvalidHas},
+		{q: "a b c d e f g h i j k", expected: search.ErrTooManyTerms},
+	}
+
+	for _, c := range cases {
+		if _, err := search.Parse(c.q); err != c.expected {
+			t.Errorf("Parse(%q) error = %v, expected %v", c.q, err, c.expected)
+		}
+	}
+}
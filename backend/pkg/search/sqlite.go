@@ -0,0 +1,93 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// min length of terms that the trigram tokenizer can match
+const trigramLength = 3
+
+// SQLite FTS5 index with the trigram tokenizer, used for tests instead of the MySQL FULLTEXT index
+// SQLite must be built with FTS5 (go test -tags sqlite_fts5)
+// the FTS table {table}_fts is an external content table of table.column kept in sync by triggers,
+// it is created by NewSQLiteIndex if it does not exist (table must have an integer primary key "id")
+// trigram matches substrings of 3 or more characters, shorter terms are searched with LIKE
+type SQLiteIndex struct {
+	Table    string
+	Column   string
+	FTSTable string
+}
+
+func NewSQLiteIndex(db *gorm.DB, table, column string) (SearchIndex, error) {
+	index := &SQLiteIndex{Table: table, Column: column, FTSTable: table + "_fts"}
+	if err := index.createTable(db); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+func (i *SQLiteIndex) Match(terms []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		var phrases []string
+		for _, term := range terms {
+			if utf8.RuneCountInString(term) >= trigramLength {
+				phrases = append(phrases, quotePhrase(term))
+				continue
+			}
+			db = db.Where(fmt.Sprintf("%s.%s LIKE ? ESCAPE '!'", i.Table, i.Column), "%"+EscapeLike(term)+"%")
+		}
+
+		if len(phrases) == 0 {
+			return db
+		}
+		return db.Where(
+			fmt.Sprintf("%s.id IN (SELECT rowid FROM %s WHERE %s MATCH ?)", i.Table, i.FTSTable, i.FTSTable),
+			strings.Join(phrases, " AND "),
+		)
+	}
+}
+
+// create the FTS table and the triggers, and index the existing rows
+func (i *SQLiteIndex) createTable(db *gorm.DB) error {
+	if db.Migrator().HasTable(i.FTSTable) {
+		return nil
+	}
+
+	statements := []string{
+		fmt.Sprintf(
+			"CREATE VIRTUAL TABLE %[1]s USING fts5(%[3]s, content='%[2]s', content_rowid='id', tokenize='trigram')",
+			i.FTSTable, i.Table, i.Column,
+		),
+		fmt.Sprintf(
+			"CREATE TRIGGER %[1]s_ai AFTER INSERT ON %[2]s BEGIN "+
+				"INSERT INTO %[1]s(rowid, %[3]s) VALUES (new.id, new.%[3]s); END",
+			i.FTSTable, i.Table, i.Column,
+		),
+		fmt.Sprintf(
+			"CREATE TRIGGER %[1]s_ad AFTER DELETE ON %[2]s BEGIN "+
+				"INSERT INTO %[1]s(%[1]s, rowid, %[3]s) VALUES ('delete', old.id, old.%[3]s); END",
+			i.FTSTable, i.Table, i.Column,
+		),
+		fmt.Sprintf(
+			"CREATE TRIGGER %[1]s_au AFTER UPDATE OF %[3]s ON %[2]s BEGIN "+
+				"INSERT INTO %[1]s(%[1]s, rowid, %[3]s) VALUES ('delete', old.id, old.%[3]s); "+
+				"INSERT INTO %[1]s(rowid, %[3]s) VALUES (new.id, new.%[3]s); END",
+			i.FTSTable, i.Table, i.Column,
+		),
+		fmt.Sprintf("INSERT INTO %[1]s(%[1]s) VALUES ('rebuild')", i.FTSTable),
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range statements {
+			if result := tx.Exec(statement); result.Error != nil {
+				return fmt.Errorf("failed to create search index: %w", result.Error)
+			}
+		}
+		return nil
+	})
+}
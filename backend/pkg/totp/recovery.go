@@ -0,0 +1,39 @@
+package totp
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+// recovery codes which can be used once instead of a TOTP code when the authenticator is lost
+const (
+	RecoveryCodeCount = 10
+
+	// 10 base32 characters (50 bits), shown as "xxxxx-xxxxx"
+	// 7 random bytes are encoded so that every character is fully random
+	recoveryCodeBytes = 7
+	recoveryCodeChars = 10
+)
+
+// generate RecoveryCodeCount random recovery codes
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(secretEncoding.EncodeToString(b))[:recoveryCodeChars]
+		codes[i] = code[:recoveryCodeChars/2] + "-" + code[recoveryCodeChars/2:]
+	}
+
+	return codes, nil
+}
+
+// normalize recovery code entered by the user (case, hyphen and spaces are ignored)
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.Join(strings.Fields(code), "")
+}
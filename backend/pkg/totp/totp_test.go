@@ -0,0 +1,103 @@
+package totp_test
+
+import (
+	"encoding/base32"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/totp"
+)
+
+// RFC 6238 Appendix BのSHA1のテストベクタ(下6桁)
+func TestCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, testCase := range testCases {
+		code, err := totp.Code(secret, time.Unix(testCase.unix, 0))
+		if err != nil {
+			t.Fatalf("failed to generate code: %v", err)
+		}
+		if code != testCase.code {
+			t.Errorf("unexpected code at %d: %s, want %s", testCase.unix, code, testCase.code)
+		}
+	}
+}
+
+// 前後1stepのコードは受け付け、それ以外は拒否する
+func TestValidate(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatalf("failed to generate secret: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	for _, offset := range []time.Duration{-totp.Period, 0, totp.Period} {
+		code, _ := totp.Code(secret, now.Add(offset))
+		step, ok := totp.Validate(secret, code, now)
+		if !ok {
+			t.Errorf("code at offset %s was rejected", offset)
+		}
+		if step != totp.Step(now.Add(offset)) {
+			t.Errorf("unexpected step at offset %s: %d", offset, step)
+		}
+	}
+
+	code, _ := totp.Code(secret, now.Add(-2*totp.Period))
+	if _, ok := totp.Validate(secret, code, now); ok {
+		t.Error("old code was accepted")
+	}
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := totp.Validate(secret, code, now); ok {
+			t.Errorf("invalid code %q was accepted", code)
+		}
+	}
+}
+
+// 認証アプリに登録するotpauth:// URI
+func TestURI(t *testing.T) {
+	uri := totp.URI("tweet-app", "gopher@example.com", "JBSWY3DPEHPK3PXP")
+	if uri != "otpauth://totp/tweet-app:gopher@example.com?algorithm=SHA1&digits=6&issuer=tweet-app&period=30&secret=JBSWY3DPEHPK3PXP" {
+		t.Errorf("unexpected uri: %s", uri)
+	}
+}
+
+// リカバリーコードは重複せず、入力時の大文字、ハイフン、空白は無視する
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := totp.GenerateRecoveryCodes()
+	if err != nil {
+		t.Fatalf("failed to generate recovery codes: %v", err)
+	}
+	if len(codes) != totp.RecoveryCodeCount {
+		t.Fatalf("unexpected number of recovery codes: %d", len(codes))
+	}
+
+	format := regexp.MustCompile(`^[a-z2-7]{5}-[a-z2-7]{5}$`)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if !format.MatchString(code) {
+			t.Errorf("unexpected recovery code format: %s", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicated recovery code: %s", code)
+		}
+		seen[code] = true
+
+		entered := " " + strings.ToUpper(code[:3]) + " " + code[3:] + " "
+		if totp.NormalizeRecoveryCode(entered) != totp.NormalizeRecoveryCode(code) {
+			t.Errorf("recovery code %q was not normalized: %q", entered, totp.NormalizeRecoveryCode(entered))
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package trend
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/hashtag"
+	"golang.org/x/text/unicode/norm"
+)
+
+// terms longer than MaxTermLength characters are ignored
+const MaxTermLength = 100
+
+// minimum length of terms in each script
+const (
+	minWordLength     = 3 // words of space separated scripts (like: go, the)
+	minJapaneseLength = 2 // runs of kanji or katakana (like: 東京, ラーメン)
+)
+
+// common English words which are never trends
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true, "you": true,
+	"all": true, "any": true, "can": true, "had": true, "her": true, "was": true, "one": true,
+	"our": true, "out": true, "has": true, "have": true, "this": true, "that": true, "with": true,
+	"from": true, "they": true, "will": true, "would": true, "there": true, "their": true,
+	"what": true, "about": true, "which": true, "when": true, "were": true, "been": true,
+	"just": true, "like": true, "your": true, "into": true, "than": true, "then": true,
+	"them": true, "some": true, "very": true, "its": true, "his": true, "she": true, "him": true,
+}
+
+// kinds of characters used to split text into terms
+type charClass int
+
+const (
+	classSeparator charClass = iota
+	classWord                // letters of space separated scripts, digits and "_"
+	classKanji
+	classKatakana
+	classHiragana
+)
+
+// Tokenize extracts unique trend terms from tweet content in the order of appearance
+// hashtags are returned with "#" (like: #golang) and the other terms are normalized words:
+//   - space separated scripts: words of at least 3 characters except stop words and numbers
+//   - Japanese: runs of kanji or katakana of at least 2 characters (hiragana runs are mostly
+//     particles and okurigana, so they are not terms)
+//
+// URLs, mentions and hashtags are not split into terms
+func Tokenize(content string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	add := func(term string) {
+		if !seen[term] && utf8.RuneCountInString(term) <= MaxTermLength {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for _, name := range hashtag.Extract(content) {
+		add("#" + name)
+	}
+
+	for _, field := range strings.Fields(norm.NFKC.String(content)) {
+		if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") ||
+			strings.HasPrefix(field, "@") || strings.HasPrefix(field, "#") {
+			continue
+		}
+
+		for _, term := range splitTerms(strings.ToLower(field)) {
+			add(term)
+		}
+	}
+
+	return terms
+}
+
+// split text into runs of the same character class and keep runs which are terms
+func splitTerms(text string) []string {
+	var terms []string
+	var run []rune
+	runClass := classSeparator
+
+	flush := func() {
+		if isTerm(run, runClass) {
+			terms = append(terms, string(run))
+		}
+		run = run[:0]
+	}
+
+	for _, r := range text {
+		class := classOf(r)
+		// "ー" continues katakana (like: ラーメン)
+		if r == 'ー' && runClass == classKatakana {
+			class = classKatakana
+		}
+
+		if class != runClass {
+			flush()
+			runClass = class
+		}
+		if class != classSeparator {
+			run = append(run, r)
+		}
+	}
+	flush()
+
+	return terms
+}
+
+func isTerm(run []rune, class charClass) bool {
+	switch class {
+	case classWord:
+		if len(run) < minWordLength || stopWords[string(run)] {
+			return false
+		}
+		for _, r := range run {
+			if !unicode.IsDigit(r) {
+				return true
+			}
+		}
+		return false
+	case classKanji, classKatakana:
+		return len(run) >= minJapaneseLength
+	default:
+		return false
+	}
+}
+
+func classOf(r rune) charClass {
+	switch {
+	case unicode.Is(unicode.Han, r) || r == '々':
+		return classKanji
+	case unicode.Is(unicode.Katakana, r):
+		return classKatakana
+	case unicode.Is(unicode.Hiragana, r):
+		return classHiragana
+	case unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_':
+		return classWord
+	default:
+		return classSeparator
+	}
+}
@@ -0,0 +1,31 @@
+package trend_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/pkg/trend"
+)
+
+// hashtagと英語/日本語の単語を抽出できるか確認
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		content  string
+		expected []string
+	}{
+		{content: "Learning #Go with the Gin framework", expected: []string{"#go", "learning", "gin", "framework"}},
+		{content: "今日は東京でラーメンを食べた", expected: []string{"今日", "東京", "ラーメン"}},
+		// 全角英数字と半角カナはNFKCで正規化し、同じ単語は1つにまとめる
+		{content: "ＧＯ言語 go言語 ｶﾚｰ", expected: []string{"言語", "カレー"}},
+		{content: "Golang golang GOLANG", expected: []string{"golang"}},
+		// URLとmention、数字のみの単語は含めない
+		{content: "see https://example.com/news @alice 2024 and 123abc", expected: []string{"see", "123abc"}},
+		{content: "a to is", expected: nil},
+	}
+
+	for _, c := range cases {
+		if terms := trend.Tokenize(c.content); !reflect.DeepEqual(c.expected, terms) {
+			t.Errorf("Tokenize(%q) = %q, expected %q", c.content, terms, c.expected)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ctx.ClientIP()で使用するリクエスト元のIPアドレスの決め方を設定する
+// ログインの失敗回数はIPアドレスごとに数えるので、クライアントが自由に設定できるヘッダーは信頼しない
+//   - trustedProxies: X-Forwarded-Forを信頼するプロキシのIPアドレス/CIDR(カンマ区切り)
+//   - trustedPlatform: 信頼するCDN、PaaSのヘッダー(cloudflare|google|flyio、またはヘッダー名)
+//
+// どちらも空の場合はヘッダーを使用せず、接続元のIPアドレスを使用する
+func ConfigureClientIP(r *gin.Engine, trustedProxies, trustedPlatform string) error {
+	var proxies []string
+	for _, proxy := range strings.Split(trustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		return err
+	}
+
+	switch trustedPlatform {
+	case "cloudflare":
+		r.TrustedPlatform = gin.PlatformCloudflare
+	case "google":
+		r.TrustedPlatform = gin.PlatformGoogleAppEngine
+	case "flyio":
+		r.TrustedPlatform = gin.PlatformFlyIO
+	default:
+		r.TrustedPlatform = trustedPlatform
+	}
+
+	return nil
+}
@@ -1,34 +1,145 @@
 package routes
 
 import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
 	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
 	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
 	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/configs"
 	"github.com/daiki-kim/tweet-app/backend/middlewares"
-	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+	"github.com/daiki-kim/tweet-app/backend/pkg/mailer"
+	"github.com/daiki-kim/tweet-app/backend/pkg/media"
+	"github.com/daiki-kim/tweet-app/backend/pkg/oauth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/search"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupRouter(db *gorm.DB) *gin.Engine {
+const (
+	// 失効リストから期限切れのエントリを削除する間隔
+	tokenRevocationPurgeInterval = time.Hour
+
+	// ログインの失敗回数から古いものを削除する間隔と、最後の失敗から削除するまでの期間
+	loginAttemptPurgeInterval = time.Hour
+	loginAttemptRetention     = 24 * time.Hour
+
+	// trendの出現数を保存する間隔と古い集計を削除する間隔
+	trendFlushInterval = 10 * time.Second
+	trendPurgeInterval = time.Hour
+)
+
+// 返り値のstopを呼ぶとバックグラウンドの処理(期限切れの削除、trendの保存)を停止する
+// trendの出現数はstopで最後に保存するので、サーバーの終了時に必ず呼ぶ
+func SetupRouter(db *gorm.DB) (r *gin.Engine, stop func()) {
+	var stops []func()
+	stop = func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
 	userRepository := repositories.NewUserRepository(db)
-	authService := services.NewAuthService(userRepository)
+	refreshTokenRepository := repositories.NewRefreshTokenRepository(db)
+	tokenRevocationRepository := repositories.NewTokenRevocationRepository(db)
+	stops = append(stops, repositories.StartTokenRevocationPurger(tokenRevocationRepository, tokenRevocationPurgeInterval))
+	jwtTokenVerifier := middlewares.JwtTokenVerifier(tokenRevocationRepository)
+	passwordResetTokenRepository := repositories.NewPasswordResetTokenRepository(db)
+	emailVerificationTokenRepository := repositories.NewEmailVerificationTokenRepository(db)
+	mailSender, err := mailer.NewMailerFromEnv()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	twoFactorRepository := repositories.NewTwoFactorRepository(db)
+	twoFactorService := services.NewTwoFactorService(userRepository, twoFactorRepository)
+	twoFactorController := controllers.NewTwoFactorController(twoFactorService)
+	// ログインの失敗回数の保存先(LOGIN_ATTEMPT_STORE=db|memory)
+	var loginAttemptRepository repositories.ILoginAttemptRepository
+	switch store := configs.GetEnvDefault("LOGIN_ATTEMPT_STORE", "db"); store {
+	case "db":
+		loginAttemptRepository = repositories.NewLoginAttemptRepository(db)
+	case "memory":
+		loginAttemptRepository = repositories.NewInMemoryLoginAttemptRepository()
+	default:
+		log.Fatal("invalid LOGIN_ATTEMPT_STORE: ", store)
+	}
+	stops = append(stops, repositories.StartLoginAttemptPurger(loginAttemptRepository, loginAttemptPurgeInterval, loginAttemptRetention))
+	loginAttemptService := services.NewLoginAttemptService(loginAttemptRepository, clock.New())
+	userIdentityRepository := repositories.NewUserIdentityRepository(db)
+	authService := services.NewAuthService(userRepository, refreshTokenRepository, tokenRevocationRepository, passwordResetTokenRepository, emailVerificationTokenRepository, userIdentityRepository, twoFactorService, loginAttemptService, mailSender)
 	authController := controllers.NewAuthController(authService)
+	identityService := services.NewIdentityService(userIdentityRepository)
+	identityController := controllers.NewIdentityController(identityService)
 
-	tweetRepository := repositories.NewTweetRepository(db)
-	tweetService := services.NewTweetService(tweetRepository)
-	tweetController := controllers.NewTweetController(tweetService)
+	oauthRegistry, err := oauth.NewRegistryFromEnv()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	oauthController := controllers.NewOAuthController(oauthRegistry, authService)
+
+	// email未認証のユーザーはログインできるがtweetを投稿できない(EMAIL_VERIFICATION_REQUIRED_TO_POST=falseで無効)
+	emailVerificationRequired, err := strconv.ParseBool(configs.GetEnvDefault("EMAIL_VERIFICATION_REQUIRED_TO_POST", "true"))
+	if err != nil {
+		log.Fatal("invalid EMAIL_VERIFICATION_REQUIRED_TO_POST: ", err.Error())
+	}
+	verifiedEmailRequired := middlewares.RequireVerifiedEmail(userRepository, emailVerificationRequired)
 
 	followerRepository := repositories.NewFollowerRepository(db)
-	followerService := services.NewFollowerService(followerRepository)
+	tweetRepository := repositories.NewTweetRepository(db)
+
+	feedRepository := repositories.NewFeedRepository(db)
+	feedService := services.NewFeedService(feedRepository, followerRepository, tweetRepository)
+	feedController := controllers.NewFeedController(feedService)
+
+	followerService := services.NewFollowerService(followerRepository, feedService)
 	followerController := controllers.NewFollowerController(followerService)
 
-	r := gin.Default()
+	mediaStore, err := media.NewMediaStoreFromEnv()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	mediaRepository := repositories.NewMediaRepository(db)
+	mediaService := services.NewMediaService(mediaRepository, mediaStore)
+	mediaController := controllers.NewMediaController(mediaService)
+
+	userService := services.NewUserService(userRepository, mediaService)
+	userController := controllers.NewUserController(userService)
+
+	trendRepository := repositories.NewTrendRepository(db)
+	trendAggregator := services.NewTrendAggregator(trendRepository, clock.New())
+	stops = append(stops, services.StartTrendAggregator(trendAggregator, trendFlushInterval, trendPurgeInterval))
+	trendService := services.NewTrendService(trendRepository, clock.New())
+	trendController := controllers.NewTrendController(trendService)
+
+	likeRepository := repositories.NewLikeRepository(db)
+	tweetService := services.NewTweetService(tweetRepository, likeRepository, userRepository, feedService, mediaService, trendAggregator)
+	tweetController := controllers.NewTweetController(tweetService)
+
+	adminService := services.NewAdminService(userRepository, tweetRepository, authService, tweetService)
+	adminController := controllers.NewAdminController(adminService)
 
-	// セッションのミドルウェアを設定
-	store := cookie.NewStore([]byte("secret"))
-	r.Use(sessions.Sessions("my_session", store))
+	likeService := services.NewLikeService(likeRepository, tweetRepository)
+	likeController := controllers.NewLikeController(likeService)
+
+	searchIndex, err := search.NewSearchIndex(db, "tweets", "content")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	searchRepository := repositories.NewSearchRepository(db, searchIndex)
+	searchService := services.NewSearchService(searchRepository, tweetRepository)
+	searchController := controllers.NewSearchController(searchService)
+
+	r = gin.Default()
+	// リバースプロキシ、CDNの背後で動かす場合はTRUSTED_PROXIES、TRUSTED_PLATFORMを設定する
+	if err := ConfigureClientIP(r, configs.GetEnvDefault("TRUSTED_PROXIES", ""), configs.GetEnvDefault("TRUSTED_PLATFORM", "")); err != nil {
+		log.Fatal("invalid TRUSTED_PROXIES: ", err.Error())
+	}
 
 	apirouter := r.Group("/api")
 	{
@@ -38,27 +149,67 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 			{
 				signupRouter.GET("/")                                        // TODO: Frontend実装後にUser data作成画面へredirectする 2024-08-15
 				signupRouter.POST("/", authController.Signup)                // User data送信先
-				signupRouter.GET("/oauth")                                   // TODO: Frontend実装後にOAuthからのUser data作成画面へredirectする 2024-08-15
-				signupRouter.POST("/oauth", authController.SignupUsingOAuth) // OAuth経由のUser data送信先
+				signupRouter.POST("/oauth", authController.SignupUsingOAuth) // OAuthのcallbackで返されたticketとusername、dobからサインアップ
 			}
 
 			loginRouter := v1Router.Group("/login")
 			{
-				loginRouter.GET("/")                                      // TODO: User data入力画面へredirect 2024-08-15
-				loginRouter.POST("/", authController.Login)               // User data送信先
-				loginRouter.GET("/oauth", authController.LoginUsingOAuth) // OAuthからのリダイレクト先
+				loginRouter.GET("/")                                    // TODO: User data入力画面へredirect 2024-08-15
+				loginRouter.POST("/", authController.Login)             // User data送信先
+				loginRouter.POST("/2fa", authController.LoginTwoFactor) // 2段階認証が有効な場合にmfa_tokenとコードからトークンを発行
 			}
 
-			tweetRouterWithAuth := v1Router.Group("/tweet", middlewares.JwtTokenVerifier())
+			v1Router.POST("/token/refresh", authController.RefreshToken)             // refresh tokenからtokenを再発行
+			v1Router.POST("/logout", authController.Logout)                          // refresh token(とAuthorizationヘッダーのtoken)を失効させてログアウト
+			v1Router.POST("/logout/all", jwtTokenVerifier, authController.LogoutAll) // ログイン中のユーザーの全てのセッションを失効させる
+
+			passwordRouter := v1Router.Group("/password")
 			{
-				tweetRouterWithAuth.POST("/", tweetController.CreateTweet)               // reqestのbodyの内容のtweetを作成
-				tweetRouterWithAuth.GET("/:id", tweetController.GetTweet)                // idの*tweet{}を取得
-				tweetRouterWithAuth.GET("/user/:user_id", tweetController.GetUserTweets) // user_idのユーザーのtweetリストを取得
-				tweetRouterWithAuth.PUT("/:id", tweetController.UpdateTweet)             // idのtweetを更新
-				tweetRouterWithAuth.DELETE("/:id", tweetController.DeleteTweet)          // idのtweetを削除
+				passwordRouter.POST("/forgot", authController.ForgotPassword) // emailにパスワード再設定用のリンクを送信
+				passwordRouter.POST("/reset", authController.ResetPassword)   // パスワード再設定用のtokenを使用してパスワードを変更
 			}
 
-			followerRouterWithAuth := v1Router.Group("/follower", middlewares.JwtTokenVerifier())
+			v1Router.GET("/verify-email", authController.VerifyEmail)                                       // ?token=のメール認証用のtokenでemailを認証済みにする(認証メールのリンク先)
+			v1Router.POST("/verify-email/resend", jwtTokenVerifier, authController.ResendVerificationEmail) // ログイン中のユーザーに認証メールを再送信
+
+			tweetRouterWithAuth := v1Router.Group("/tweet", jwtTokenVerifier)
+			{
+				tweetRouterWithAuth.POST("/", verifiedEmailRequired, tweetController.CreateTweet)         // reqestのbodyの内容のtweetを作成
+				tweetRouterWithAuth.GET("/:id", tweetController.GetTweet)                                 // idの*tweet{}を取得
+				tweetRouterWithAuth.GET("/user/:user_id", tweetController.GetUserTweets)                  // user_idのユーザーのtweetリストを取得
+				tweetRouterWithAuth.PUT("/:id", tweetController.UpdateTweet)                              // idのtweetを更新
+				tweetRouterWithAuth.DELETE("/:id", tweetController.DeleteTweet)                           // idのtweetを削除(他のユーザーのtweetは/admin/tweets/:idで削除する)
+				tweetRouterWithAuth.POST("/:id/like", likeController.Like)                                // idのtweetをlikeする
+				tweetRouterWithAuth.DELETE("/:id/like", likeController.Unlike)                            // idのtweetのlikeを取り消す
+				tweetRouterWithAuth.GET("/:id/likes", likeController.GetLikers)                           // idのtweetをlikeしたユーザーリストを取得
+				tweetRouterWithAuth.POST("/:id/reply", verifiedEmailRequired, tweetController.ReplyTweet) // idのtweetへの返信を作成
+				tweetRouterWithAuth.GET("/:id/thread", tweetController.GetThread)                         // idのtweetのconversationのrootからidまでの返信とrootの返信ツリーを取得
+				tweetRouterWithAuth.POST("/:id/retweet", verifiedEmailRequired, tweetController.Retweet)  // idのtweetをretweetする
+				tweetRouterWithAuth.DELETE("/:id/retweet", tweetController.Unretweet)                     // idのtweetのretweetを取り消す
+				tweetRouterWithAuth.POST("/:id/quote", verifiedEmailRequired, tweetController.QuoteTweet) // idのtweetを引用したtweetを作成
+			}
+
+			v1Router.POST("/media", jwtTokenVerifier, mediaController.UploadMedia) // 画像/動画をアップロード(tweet作成時にmedia_idsで添付する)
+
+			hashtagRouterWithAuth := v1Router.Group("/hashtag", jwtTokenVerifier)
+			{
+				hashtagRouterWithAuth.GET("/:tag/tweets", tweetController.GetHashtagTweets) // tagのhashtagを含むtweetリストを取得
+			}
+
+			searchRouterWithAuth := v1Router.Group("/search", jwtTokenVerifier)
+			{
+				searchRouterWithAuth.GET("/tweets", searchController.SearchTweets) // ?q=の検索クエリに一致するtweetリストを取得
+				searchRouterWithAuth.GET("/users", userController.SearchUsers)     // ?q=でusernameまたは名前を検索してユーザーの公開プロフィールリストを取得
+			}
+
+			userRouterWithAuth := v1Router.Group("/user", jwtTokenVerifier)
+			{
+				userRouterWithAuth.GET("/:id", userController.GetUser)                             // idのユーザーの公開プロフィールを取得
+				userRouterWithAuth.GET("/by-username/:username", userController.GetUserByUsername) // usernameのユーザーの公開プロフィールを取得
+				userRouterWithAuth.GET("/:id/likes", likeController.GetUserLikes)                  // idのユーザーがlikeしたtweetリストを取得
+			}
+
+			followerRouterWithAuth := v1Router.Group("/follower", jwtTokenVerifier)
 			{
 				followerRouterWithAuth.POST("/", followerController.Follow)                            // reqestのbodyに指定したfolloee_idとfollower_id=user_idのfollowerを作成(フォローする)
 				followerRouterWithAuth.GET("/:id", followerController.GetFollower)                     // idのfollowerを取得
@@ -66,16 +217,65 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 				followerRouterWithAuth.GET("/followers/:followee_id", followerController.GetFollowers) // followee_idのユーザーをフォローしているfollowerリストを取得
 				followerRouterWithAuth.DELETE("/:id", followerController.DeleteFollower)               // idのfollowerを削除
 			}
+
+			v1Router.GET("/timeline", jwtTokenVerifier, feedController.GetTimeline)        // フォローしているユーザーと自分のtweetを新しい順に取得
+			v1Router.GET("/trends", jwtTokenVerifier, trendController.GetTrends)           // 直近1h/24h/7dで伸びているhashtagと単語を取得
+			v1Router.GET("/mentions", jwtTokenVerifier, tweetController.GetMentions)       // ログイン中のユーザーへのmentionを含むtweetを新しい順に取得
+			v1Router.GET("/me", jwtTokenVerifier, userController.GetMe)                    // ログイン中のユーザーのプロフィールをemail、生年月日を含めて取得
+			v1Router.PATCH("/me", jwtTokenVerifier, userController.UpdateMe)               // ログイン中のユーザーのプロフィールを更新
+			v1Router.POST("/me/password", jwtTokenVerifier, authController.ChangePassword) // 現在のパスワードを確認してパスワードを変更
+
+			twoFactorRouterWithAuth := v1Router.Group("/me/2fa/totp", jwtTokenVerifier)
+			{
+				twoFactorRouterWithAuth.POST("", twoFactorController.EnrollTOTP)          // TOTPのsecretを発行してotpauth:// URIを返す
+				twoFactorRouterWithAuth.POST("/confirm", twoFactorController.ConfirmTOTP) // 最初のコードを確認して2段階認証を有効にし、リカバリーコードを返す
+				twoFactorRouterWithAuth.DELETE("", twoFactorController.DisableTOTP)       // コードを確認して2段階認証を無効にする
+			}
+
+			identityRouterWithAuth := v1Router.Group("/me/identities", jwtTokenVerifier)
+			{
+				identityRouterWithAuth.GET("", identityController.GetIdentities)               // 紐づけたプロバイダーのアカウントを取得
+				identityRouterWithAuth.POST("", identityController.LinkIdentity)               // /oauth/:provider/login?action=linkのcallbackで返されたticketのアカウントを紐づける
+				identityRouterWithAuth.DELETE("/:provider", identityController.UnlinkIdentity) // providerの紐づけを解除(最後のログイン方法は解除できない)
+			}
+
+			// 管理API(roleの権限はアクセストークンに含まれ、RequirePermissionで確認する)
+			adminRouterWithAuth := v1Router.Group("/admin", jwtTokenVerifier)
+			{
+				adminRouterWithAuth.GET("/users", middlewares.RequirePermission(auth.PermissionReadUsers), adminController.GetUsers)                           // 全てのユーザーをemail、role、停止状態を含めて取得
+				adminRouterWithAuth.POST("/users/:id/suspension", middlewares.RequirePermission(auth.PermissionSuspendUsers), adminController.SuspendUser)     // idのユーザーのアカウントを停止してトークンを失効させる
+				adminRouterWithAuth.DELETE("/users/:id/suspension", middlewares.RequirePermission(auth.PermissionSuspendUsers), adminController.UnsuspendUser) // idのユーザーのアカウントの停止を解除
+				adminRouterWithAuth.DELETE("/tweets/:id", middlewares.RequirePermission(auth.PermissionDeleteTweets), adminController.DeleteTweet)             // 自分より弱いroleのユーザーのidのtweetを削除
+			}
 		}
 	}
 
+	oauthRouter := r.Group("/oauth/:provider")
+	{
+		oauthRouter.GET("/login", oauthController.Login)       // プロバイダーの認可画面へリダイレクト(?action=signup|login|link)
+		oauthRouter.GET("/callback", oauthController.Callback) // プロバイダーからのリダイレクト先(tokenまたはサインアップ、紐づけ用のticketを返す)
+	}
+
+	// 以前のGoogleログインのURL(登録済みのリダイレクトURLのために残す)
 	{
 		r.GET("/google_login/:action", func(ctx *gin.Context) {
-			action := ctx.Param("action")
-			controllers.GoogleLogin(ctx, action)
+			ctx.Redirect(http.StatusFound, "/oauth/google/login?action="+url.QueryEscape(ctx.Param("action")))
 		})
-		r.GET("/google_callback", controllers.GoogleCallback)
+		r.GET("/google_callback", func(ctx *gin.Context) {
+			ctx.Redirect(http.StatusFound, "/oauth/google/callback?"+ctx.Request.URL.RawQuery)
+		})
+	}
+
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS) // アクセストークン検証用の公開鍵を取得
+
+	// ローカルに保存した画像/動画はMEDIA_BASE_URLのpathで配信する(S3の場合はS3から直接配信する)
+	if localStore, ok := mediaStore.(*media.LocalMediaStore); ok {
+		baseURL, err := url.Parse(localStore.BaseURL)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		r.Static(baseURL.Path, localStore.Dir)
 	}
 
-	return r
+	return r, stop
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
 	"github.com/daiki-kim/tweet-app/backend/configs"
+	"github.com/daiki-kim/tweet-app/backend/migrations"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -14,24 +15,15 @@ type DBSQLiteSuite struct {
 	suite.Suite
 }
 
-// tweetモデルはenum型がSQLiteで対応していないため省略
-func getTestModels() []interface{} {
-	return []interface{}{
-		&models.User{},
-		&models.Follower{},
-	}
-}
-
 // sqliteのテストスイートをセットアップ
 func (suite *DBSQLiteSuite) SetupSuite() {
 	configs.Config.DBName = testDBName
 	err := models.SetDatabase(models.InstanceSQLite)
 	suite.Assert().Nil(err)
 
-	for _, model := range getTestModels() {
-		err := models.DB.AutoMigrate(model)
-		suite.Assert().Nil(err)
-	}
+	// 本番と同じマイグレーションでSQLiteのテーブルを作成
+	err = migrations.Up(models.DB)
+	suite.Assert().Nil(err)
 }
 
 // sqliteのテストスイートをクリーンアップ
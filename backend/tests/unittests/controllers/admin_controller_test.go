@@ -0,0 +1,221 @@
+package controllers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdminGetUsersSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockAdminService, testAdminController := prepareTestAdminController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/admin/users", testAdminController.GetUsers)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/users", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	suspendedAt := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+	mockAdminService.On("GetUsers", mock.Anything).Return(&pagination.List[*dtos.AdminUser]{
+		Items: []*dtos.AdminUser{{
+			SelfUser: dtos.SelfUser{
+				PublicUser: dtos.PublicUser{ID: 1, Name: "gopher", Username: "gopher", CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)},
+				Email:      "gopher@example.com",
+				Dob:        time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			Role:        "user",
+			SuspendedAt: &suspendedAt,
+		}},
+	}, nil)
+
+	// users responseを準備
+	usersResponseJson := `{
+		"data": [{
+			"id": 1,
+			"name": "gopher",
+			"username": "gopher",
+			"bio": "",
+			"location": "",
+			"website": "",
+			"avatar_url": "",
+			"header_url": "",
+			"created_at": "2024-09-01T00:00:00Z",
+			"email": "gopher@example.com",
+			"email_verified": false,
+			"dob": "2020-01-01T00:00:00Z",
+			"role": "user",
+			"suspended_at": "2024-09-02T00:00:00Z"
+		}],
+		"next_cursor": null
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, usersResponseJson, w.Body.String())
+	mockAdminService.AssertExpectations(t)
+}
+
+func TestAdminGetUsersInvalidCursor(t *testing.T) {
+	// モックサービスを準備
+	mockAdminService, testAdminController := prepareTestAdminController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/admin/users", testAdminController.GetUsers)
+
+	// リクエスト実行
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/admin/users?cursor=invalid", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockAdminService.AssertNotCalled(t, "GetUsers", mock.Anything)
+}
+
+func TestSuspendUser(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "success", err: nil, code: http.StatusOK},
+		{name: "user not found", err: errors.New("user not found"), code: http.StatusNotFound},
+		{name: "cannot moderate", err: errors.New("cannot moderate this user"), code: http.StatusForbidden},
+		{name: "internal error", err: errors.New("database error"), code: http.StatusInternalServerError},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックサービスを準備
+			mockAdminService, testAdminController := prepareTestAdminController()
+
+			// ginエンジンの設定
+			r := setupTestRouter()
+			r.POST("/api/v1/admin/users/:id/suspension", func(c *gin.Context) {
+				// テストのために context に user_id を設定
+				c.Set("user_id", "1")
+				testAdminController.SuspendUser(c)
+			})
+
+			// モックサービスを準備
+			if testCase.err != nil {
+				mockAdminService.On("SuspendUser", uint(1), uint(2)).Return(nil, testCase.err)
+			} else {
+				suspendedAt := time.Now()
+				mockAdminService.On("SuspendUser", uint(1), uint(2)).Return(&dtos.AdminUser{Role: "user", SuspendedAt: &suspendedAt}, nil)
+			}
+
+			// リクエスト実行
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/2/suspension", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, testCase.code, w.Code)
+			mockAdminService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUnsuspendUserSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockAdminService, testAdminController := prepareTestAdminController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.DELETE("/api/v1/admin/users/:id/suspension", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testAdminController.UnsuspendUser(c)
+	})
+
+	// モックサービスを準備
+	mockAdminService.On("UnsuspendUser", uint(1), uint(2)).Return(&dtos.AdminUser{Role: "user"}, nil)
+
+	// リクエスト実行
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/users/2/suspension", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"suspended_at":null`)
+	mockAdminService.AssertExpectations(t)
+}
+
+func TestSuspendUserInvalidId(t *testing.T) {
+	// モックサービスを準備
+	mockAdminService, testAdminController := prepareTestAdminController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.POST("/api/v1/admin/users/:id/suspension", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testAdminController.SuspendUser(c)
+	})
+
+	// リクエスト実行
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/admin/users/abc/suspension", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockAdminService.AssertNotCalled(t, "SuspendUser", mock.Anything, mock.Anything)
+}
+
+func TestAdminDeleteTweet(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "success", err: nil, code: http.StatusOK},
+		{name: "tweet not found", err: errors.New("tweet not found"), code: http.StatusNotFound},
+		{name: "cannot moderate", err: errors.New("cannot moderate this user"), code: http.StatusForbidden},
+		{name: "internal error", err: errors.New("database error"), code: http.StatusInternalServerError},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックサービスを準備
+			mockAdminService, testAdminController := prepareTestAdminController()
+
+			// ginエンジンの設定
+			r := setupTestRouter()
+			r.DELETE("/api/v1/admin/tweets/:id", func(c *gin.Context) {
+				// テストのために context に user_id を設定
+				c.Set("user_id", "1")
+				testAdminController.DeleteTweet(c)
+			})
+
+			// モックサービスを準備
+			mockAdminService.On("DeleteTweet", uint(1), uint(3)).Return(testCase.err)
+
+			// リクエスト実行
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/admin/tweets/3", nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, testCase.code, w.Code)
+			mockAdminService.AssertExpectations(t)
+		})
+	}
+}
+
+func prepareTestAdminController() (*mocks.MockAdminService, controllers.IAdminController) {
+	mockAdminService := &mocks.MockAdminService{}
+	testAdminController := controllers.NewAdminController(mockAdminService)
+
+	return mockAdminService, testAdminController
+}
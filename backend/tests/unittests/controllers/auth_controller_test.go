@@ -2,20 +2,20 @@ package controllers_test
 
 import (
 	"bytes"
-	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
 	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
 	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/routes"
 	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
-	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestSignupUsingOAuthSuccess(t *testing.T) {
@@ -24,132 +24,268 @@ func TestSignupUsingOAuthSuccess(t *testing.T) {
 	testAuthController := controllers.NewAuthController(mockAuthService)
 
 	// ginエンジンの設定
-	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := setupTestRouter()
+	r.POST("/api/v1/auth/signup/oauth", testAuthController.SignupUsingOAuth)
 
-	// セッションストアを設定
-	store := cookie.NewStore([]byte("secret"))
-	r.Use(sessions.Sessions("my_session", store))
+	// リクエスト作成
+	// プロバイダーのアカウントとemailはOAuthのcallbackで返されたticketの値を使用する
+	reqBody := []byte(`{"ticket": "test_ticket", "username": "testuser", "dob": "2020-01-01"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/signup/oauth", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
 
-	// OAuthからのユーザーデータを準備
-	userData := dtos.OAuthSignupInput{
-		Name:  "testuser",
-		Email: "test@example.com",
+	// mockAuthServiceのmockメソッドを準備
+	loginResponse := &services.LoginResponse{
+		Token:        "test_token",
+		RefreshToken: "test_refresh_token",
 	}
-	SessionData, _ := json.Marshal(userData)
-
-	// OAuthからのユーザーデータをセッションに保存
-	r.POST("/api/v1/auth/signup/oauth", func(ctx *gin.Context) {
-		session := sessions.Default(ctx)
-		session.Set("user_data", string(SessionData))
-		session.Save()
-		testAuthController.SignupUsingOAuth(ctx)
-	})
+	mockAuthService.On("SignupUsingOAuth", "test_ticket", "", "testuser", "2020-01-01").Return(loginResponse, nil)
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"token": "test_token", "refresh_token": "test_refresh_token"}`, w.Body.String())
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestSignupUsingOAuthErrors(t *testing.T) {
+	testCases := []struct {
+		name       string
+		reqBody    string
+		serviceErr error
+		statusCode int
+	}{
+		{
+			name:       "without ticket",
+			reqBody:    `{"username": "testuser", "dob": "2020-01-01"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid ticket",
+			reqBody:    `{"ticket": "test_ticket", "username": "testuser", "dob": "2020-01-01"}`,
+			serviceErr: errors.New("invalid oauth ticket"),
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "username is already taken",
+			reqBody:    `{"ticket": "test_ticket", "username": "testuser", "dob": "2020-01-01"}`,
+			serviceErr: errors.New("username is already taken"),
+			statusCode: http.StatusConflict,
+		},
+		{
+			// 同じticketで既にサインアップ済み
+			name:       "identity already linked",
+			reqBody:    `{"ticket": "test_ticket", "username": "testuser", "dob": "2020-01-01"}`,
+			serviceErr: errors.New("identity already linked"),
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "email is already taken",
+			reqBody:    `{"ticket": "test_ticket", "username": "testuser", "dob": "2020-01-01"}`,
+			serviceErr: errors.New("email is already taken"),
+			statusCode: http.StatusConflict,
+		},
+		{
+			// 確認してから作成するまでに同じemailのユーザーが作成された
+			name:       "user already exists",
+			reqBody:    `{"ticket": "test_ticket", "username": "testuser", "dob": "2020-01-01"}`,
+			serviceErr: errors.New("user already exists"),
+			statusCode: http.StatusConflict,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockAuthService := &mocks.MockAuthService{}
+			testAuthController := controllers.NewAuthController(mockAuthService)
+
+			r := setupTestRouter()
+			r.POST("/api/v1/auth/signup/oauth", testAuthController.SignupUsingOAuth)
+
+			mockAuthService.On("SignupUsingOAuth", "test_ticket", "", "testuser", "2020-01-01").Return(nil, testCase.serviceErr)
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/signup/oauth", bytes.NewBufferString(testCase.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.statusCode, w.Code)
+		})
+	}
+}
+
+func TestSignupUsernameAlreadyTaken(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.POST("/api/v1/signup", testAuthController.Signup)
 
 	// リクエスト作成
-	reqBody := []byte(`{"dob": "2020-01-01"}`)
-	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/signup/oauth", bytes.NewBuffer(reqBody))
+	reqBody := []byte(`{"name": "testuser", "username": "TestUser", "email": "test@example.com", "password": "testpassword", "dob": "2020-01-01"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/signup", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 
 	// mockAuthServiceのmockメソッドを準備
-	userData.Dob = "2020-01-01"
-	mockAuthService.On("SignupUsingOAuth", userData.Name, userData.Email, userData.Dob).Return(nil)
+	mockAuthService.On("Signup", "testuser", "TestUser", "test@example.com", "2020-01-01", "testpassword").Return(errors.New("username is already taken"))
 
 	// テスト実行
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
 	// レスポンスを検証
-	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"error": "username is already taken"}`, w.Body.String())
 	mockAuthService.AssertExpectations(t)
 }
 
-func TestLoginUsingOAuthSuccess(t *testing.T) {
-	// モックレポジトリを準備
+func TestSignupInvalidEmail(t *testing.T) {
+	// モックサービスを準備
 	mockAuthService := &mocks.MockAuthService{}
 	testAuthController := controllers.NewAuthController(mockAuthService)
 
 	// ginエンジンの設定
-	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := setupTestRouter()
+	r.POST("/api/v1/signup", testAuthController.Signup)
 
-	// セッションストアを設定
-	store := cookie.NewStore([]byte("secret"))
-	r.Use(sessions.Sessions("my_session", store))
+	// emailの形式ではない
+	reqBody := []byte(`{"name": "testuser", "username": "testuser", "email": "not-an-email", "password": "testpassword", "dob": "2020-01-01"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/signup", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
 
-	// OAuthからのユーザーデータを準備
-	userData := dtos.OAuthLoginInput{
-		Email: "test@example.com",
-	}
-	SessionData, _ := json.Marshal(userData)
-
-	// OAuthからのユーザーデータをセッションに保存
-	r.GET("/api/v1/auth/login/oauth", func(ctx *gin.Context) {
-		session := sessions.Default(ctx)
-		session.Set("user_data", string(SessionData))
-		session.Save()
-		testAuthController.LoginUsingOAuth(ctx)
-	})
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
 
-	// login responseを準備
-	loginResponse := &services.LoginResponse{
-		Token:        "test_token",
-		RefreshToken: "test_refresh_token",
-	}
+	// レスポンスを検証
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockAuthService.AssertNotCalled(t, "Signup", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
 
-	// mockAuthServiceのmockメソッドを準備
-	mockAuthService.On("LoginUsingOAuth", userData.Email).Return(loginResponse, nil)
+func TestSignupPasswordTooLong(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.POST("/api/v1/signup", testAuthController.Signup)
+
+	// パスワードが72文字を超えている(パスワードの変更、再設定と同じ上限)
+	reqBody := []byte(`{"name": "testuser", "username": "testuser", "email": "test@example.com", "password": "` + strings.Repeat("a", 73) + `", "dob": "2020-01-01"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/signup", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockAuthService.AssertNotCalled(t, "Signup", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginTwoFactorRequired(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.POST("/api/v1/login", testAuthController.Login)
+
+	// リクエスト作成
+	reqBody := []byte(`{"email": "test@example.com", "password": "testpassword"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// 2段階認証が有効なユーザーの場合はmfaトークンのみを返す
+	mockAuthService.On("Login", "test@example.com", "testpassword", mock.Anything).Return(&services.LoginResponse{
+		MFARequired: true,
+		MFAToken:    "mfa_token",
+	}, nil)
 
 	// テスト実行
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/login/oauth", nil)
 	r.ServeHTTP(w, req)
 
 	// レスポンスを検証
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"mfa_required": true, "mfa_token": "mfa_token"}`, w.Body.String())
 	mockAuthService.AssertExpectations(t)
 }
 
-func TestLoginUsingOAuthNotUserFound(t *testing.T) {
-	// モックレポジトリを準備
+func TestLoginTwoFactorSuccess(t *testing.T) {
+	// モックサービスを準備
 	mockAuthService := &mocks.MockAuthService{}
 	testAuthController := controllers.NewAuthController(mockAuthService)
 
 	// ginエンジンの設定
-	gin.SetMode(gin.TestMode)
-	r := gin.Default()
+	r := setupTestRouter()
+	r.POST("/api/v1/login/2fa", testAuthController.LoginTwoFactor)
 
-	// セッションストアを設定
-	store := cookie.NewStore([]byte("secret"))
-	r.Use(sessions.Sessions("my_session", store))
-
-	// OAuthからのユーザーデータを準備
-	userData := dtos.OAuthLoginInput{
-		Email: "test@example.com",
-	}
-	SessionData, _ := json.Marshal(userData)
-
-	// OAuthからのユーザーデータをセッションに保存
-	r.GET("/api/v1/auth/login/oauth", func(ctx *gin.Context) {
-		session := sessions.Default(ctx)
-		session.Set("user_data", string(SessionData))
-		session.Save()
-		testAuthController.LoginUsingOAuth(ctx)
-	})
+	// リクエスト作成
+	reqBody := []byte(`{"mfa_token": "mfa_token", "code": "123456"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/login/2fa", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
 
 	// mockAuthServiceのmockメソッドを準備
-	mockAuthService.On("LoginUsingOAuth", userData.Email).Return(nil, errors.New("user not found"))
+	mockAuthService.On("LoginWithTwoFactor", "mfa_token", "123456", mock.Anything).Return(&services.LoginResponse{
+		Token:        "test_token",
+		RefreshToken: "test_refresh_token",
+	}, nil)
 
 	// テスト実行
 	w := httptest.NewRecorder()
-	req, _ := http.NewRequest(http.MethodGet, "/api/v1/auth/login/oauth", nil)
 	r.ServeHTTP(w, req)
 
 	// レスポンスを検証
-	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"token": "test_token", "refresh_token": "test_refresh_token"}`, w.Body.String())
 	mockAuthService.AssertExpectations(t)
 }
 
+func TestLoginTwoFactorErrors(t *testing.T) {
+	testCases := []struct {
+		reqBody string
+		err     error
+		code    int
+	}{
+		{`{"mfa_token": "expired_token", "code": "123456"}`, errors.New("invalid mfa token"), http.StatusUnauthorized},
+		{`{"mfa_token": "mfa_token", "code": "000000"}`, errors.New("invalid code"), http.StatusUnauthorized},
+		{`{"mfa_token": "mfa_token", "code": "000000"}`, errors.New("too many login attempts"), http.StatusTooManyRequests},
+		{`{"mfa_token": "mfa_token"}`, nil, http.StatusBadRequest},
+	}
+
+	for _, testCase := range testCases {
+		// モックサービスを準備
+		mockAuthService := &mocks.MockAuthService{}
+		testAuthController := controllers.NewAuthController(mockAuthService)
+
+		// ginエンジンの設定
+		r := setupTestRouter()
+		r.POST("/api/v1/login/2fa", testAuthController.LoginTwoFactor)
+
+		// リクエスト作成
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/login/2fa", bytes.NewBufferString(testCase.reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// mockAuthServiceのmockメソッドを準備
+		mockAuthService.On("LoginWithTwoFactor", mock.Anything, mock.Anything, mock.Anything).Return(nil, testCase.err)
+
+		// テスト実行
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		// レスポンスを検証
+		assert.Equal(t, testCase.code, w.Code)
+	}
+}
+
 func TestLoginSuccess(t *testing.T) {
 	// モックレポジトリを準備
 	mockAuthService := &mocks.MockAuthService{}
@@ -159,10 +295,6 @@ func TestLoginSuccess(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
 
-	// セッションストアを設定
-	store := cookie.NewStore([]byte("secret"))
-	r.Use(sessions.Sessions("my_session", store))
-
 	// テストユーザーデータを準備
 	userData := dtos.LoginInput{
 		Email:    "test@example.com",
@@ -184,7 +316,7 @@ func TestLoginSuccess(t *testing.T) {
 	}
 
 	// mockAuthServiceのmockメソッドを準備
-	mockAuthService.On("Login", userData.Email, userData.Password).Return(loginResponse, nil)
+	mockAuthService.On("Login", userData.Email, userData.Password, mock.Anything).Return(loginResponse, nil)
 
 	// テスト実行
 	w := httptest.NewRecorder()
@@ -204,10 +336,6 @@ func TestLoginUserNotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
 
-	// セッションストアを設定
-	store := cookie.NewStore([]byte("secret"))
-	r.Use(sessions.Sessions("my_session", store))
-
 	// テストユーザーデータを準備
 	wrongUserData := dtos.LoginInput{
 		Email:    "wronguser@example.com",
@@ -222,15 +350,16 @@ func TestLoginUserNotFound(t *testing.T) {
 	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(reqBody))
 	req.Header.Set("Content-Type", "application/json")
 
-	// mockAuthServiceのmockメソッドを準備
-	mockAuthService.On("Login", wrongUserData.Email, wrongUserData.Password).Return(nil, errors.New("user not found"))
+	// mockAuthServiceのmockメソッドを準備(存在しないemailでもパスワードが違う場合と同じエラーを返す)
+	mockAuthService.On("Login", wrongUserData.Email, wrongUserData.Password, mock.Anything).Return(nil, errors.New("invalid email or password"))
 
 	// テスト実行
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
-	// レスポンスを検証
-	assert.Equal(t, http.StatusNotFound, w.Code)
+	// レスポンスを検証(emailの存在がわからないように404ではなく401を返す)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error": "invalid email or password"}`, w.Body.String())
 	mockAuthService.AssertExpectations(t)
 }
 
@@ -243,10 +372,6 @@ func TestLoginUserInvalidPassword(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
 
-	// セッションストアを設定
-	store := cookie.NewStore([]byte("secret"))
-	r.Use(sessions.Sessions("my_session", store))
-
 	// テストユーザーデータを準備
 	wrongUserData := dtos.LoginInput{
 		Email:    "test@example.com",
@@ -262,7 +387,150 @@ func TestLoginUserInvalidPassword(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 
 	// mockAuthServiceのmockメソッドを準備
-	mockAuthService.On("Login", wrongUserData.Email, wrongUserData.Password).Return(nil, errors.New("invalid password"))
+	mockAuthService.On("Login", wrongUserData.Email, wrongUserData.Password, mock.Anything).Return(nil, errors.New("invalid email or password"))
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error": "invalid email or password"}`, w.Body.String())
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestLoginTooManyAttempts(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.POST("/api/v1/auth/login", testAuthController.Login)
+
+	// リクエスト作成(リクエスト元のIPアドレスをサービスに渡す)
+	reqBody := []byte(`{"email": "test@example.com", "password": "testpassword"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.1:12345"
+
+	// mockAuthServiceのmockメソッドを準備
+	mockAuthService.On("Login", "test@example.com", "testpassword", "192.0.2.1").Return(nil, errors.New("too many login attempts"))
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.JSONEq(t, `{"error": "too many login attempts"}`, w.Body.String())
+	mockAuthService.AssertExpectations(t)
+}
+
+// ログインの失敗回数を数えるIPアドレスはクライアントが送ったX-Forwarded-Forで変えられない
+func TestLoginIgnoresSpoofedForwardedFor(t *testing.T) {
+	testCases := []struct {
+		name            string
+		trustedProxies  string
+		trustedPlatform string
+		expectedIP      string
+	}{
+		// プロキシを信頼しない場合は接続元のIPアドレス
+		{name: "no trusted proxies", expectedIP: "192.0.2.1"},
+		// 接続元が信頼するプロキシではない場合
+		{name: "untrusted proxy", trustedProxies: "10.0.0.0/8", expectedIP: "192.0.2.1"},
+		// CDNのヘッダーのみ信頼する場合
+		{name: "trusted platform", trustedPlatform: "cloudflare", expectedIP: "198.51.100.7"},
+		// 接続元が信頼するプロキシの場合はX-Forwarded-Forを使用する
+		{name: "trusted proxy", trustedProxies: "192.0.2.0/24", expectedIP: "203.0.113.5"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックサービスを準備
+			mockAuthService := &mocks.MockAuthService{}
+			testAuthController := controllers.NewAuthController(mockAuthService)
+
+			// ginエンジンの設定(SetupRouterと同じ設定)
+			r := setupTestRouter()
+			assert.NoError(t, routes.ConfigureClientIP(r, testCase.trustedProxies, testCase.trustedPlatform))
+			r.POST("/api/v1/auth/login", testAuthController.Login)
+
+			// リクエスト作成(X-Forwarded-Forを偽装する)
+			reqBody := []byte(`{"email": "test@example.com", "password": "testpassword"}`)
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/auth/login", bytes.NewBuffer(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Forwarded-For", "203.0.113.5")
+			req.Header.Set("CF-Connecting-IP", "198.51.100.7")
+			req.RemoteAddr = "192.0.2.1:12345"
+
+			// mockAuthServiceのmockメソッドを準備
+			mockAuthService.On("Login", "test@example.com", "testpassword", testCase.expectedIP).Return(nil, errors.New("invalid email or password"))
+
+			// テスト実行
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// レスポンスを検証
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			mockAuthService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefreshTokenSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// RefreshToken APIを準備
+	r.POST("/api/v1/token/refresh", testAuthController.RefreshToken)
+
+	// リクエスト作成
+	reqBody := []byte(`{"refresh_token": "test_refresh_token"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/token/refresh", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// login responseを準備
+	loginResponse := &services.LoginResponse{
+		Token:        "new_token",
+		RefreshToken: "new_refresh_token",
+	}
+
+	// mockAuthServiceのmockメソッドを準備
+	mockAuthService.On("RefreshToken", "test_refresh_token").Return(loginResponse, nil)
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"token": "new_token", "refresh_token": "new_refresh_token"}`, w.Body.String())
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestRefreshTokenReuseDetected(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// RefreshToken APIを準備
+	r.POST("/api/v1/token/refresh", testAuthController.RefreshToken)
+
+	// リクエスト作成
+	reqBody := []byte(`{"refresh_token": "used_refresh_token"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/token/refresh", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// mockAuthServiceのmockメソッドを準備
+	mockAuthService.On("RefreshToken", "used_refresh_token").Return(nil, errors.New("refresh token reuse detected"))
 
 	// テスト実行
 	w := httptest.NewRecorder()
@@ -272,3 +540,294 @@ func TestLoginUserInvalidPassword(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 	mockAuthService.AssertExpectations(t)
 }
+
+func TestLogoutSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// Logout APIを準備
+	r.POST("/api/v1/logout", testAuthController.Logout)
+
+	// リクエスト作成
+	reqBody := []byte(`{"refresh_token": "test_refresh_token"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/logout", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test_access_token")
+
+	// mockAuthServiceのmockメソッドを準備
+	mockAuthService.On("Logout", "test_refresh_token", "test_access_token").Return(nil)
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestChangePasswordSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// ChangePassword APIを準備
+	r.POST("/api/v1/me/password", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testAuthController.ChangePassword(c)
+	})
+
+	// リクエスト作成
+	reqBody := []byte(`{"current_password": "oldpassword", "new_password": "newpassword"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/password", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// mockAuthServiceのmockメソッドを準備
+	mockAuthService.On("ChangePassword", uint(1), "oldpassword", "newpassword").Return(&services.LoginResponse{
+		Token:        "new_token",
+		RefreshToken: "new_refresh_token",
+	}, nil)
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// 新しく発行したトークンを返す
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"token": "new_token", "refresh_token": "new_refresh_token"}`, w.Body.String())
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestChangePasswordErrors(t *testing.T) {
+	testCases := []struct {
+		reqBody string
+		err     error
+		code    int
+	}{
+		{`{"current_password": "wrongpassword", "new_password": "newpassword"}`, errors.New("invalid password"), http.StatusUnauthorized},
+		{`{"current_password": "oldpassword", "new_password": "oldpassword"}`, errors.New("new password must be different from the current password"), http.StatusBadRequest},
+		{`{"current_password": "oldpassword", "new_password": "short"}`, nil, http.StatusBadRequest},
+	}
+
+	for _, testCase := range testCases {
+		// モックサービスを準備
+		mockAuthService := &mocks.MockAuthService{}
+		testAuthController := controllers.NewAuthController(mockAuthService)
+
+		// ginエンジンの設定
+		r := setupTestRouter()
+		r.POST("/api/v1/me/password", func(c *gin.Context) {
+			// テストのために context に user_id を設定
+			c.Set("user_id", "1")
+			testAuthController.ChangePassword(c)
+		})
+
+		// リクエスト作成
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/password", bytes.NewBufferString(testCase.reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// mockAuthServiceのmockメソッドを準備
+		mockAuthService.On("ChangePassword", uint(1), mock.Anything, mock.Anything).Return(nil, testCase.err)
+
+		// テスト実行
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		// レスポンスを検証
+		assert.Equal(t, testCase.code, w.Code)
+	}
+}
+
+func TestForgotPasswordSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// ForgotPassword APIを準備
+	r.POST("/api/v1/password/forgot", testAuthController.ForgotPassword)
+
+	// リクエスト作成
+	reqBody := []byte(`{"email": "test@example.com"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/password/forgot", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// mockAuthServiceのmockメソッドを準備
+	mockAuthService.On("ForgotPassword", "test@example.com").Return(nil)
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestResetPasswordSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// ResetPassword APIを準備
+	r.POST("/api/v1/password/reset", testAuthController.ResetPassword)
+
+	// リクエスト作成
+	reqBody := []byte(`{"token": "reset_token", "new_password": "newpassword"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/password/reset", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// mockAuthServiceのmockメソッドを準備
+	mockAuthService.On("ResetPassword", "reset_token", "newpassword").Return(nil)
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestResetPasswordInvalidToken(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// ResetPassword APIを準備
+	r.POST("/api/v1/password/reset", testAuthController.ResetPassword)
+
+	// リクエスト作成
+	reqBody := []byte(`{"token": "used_token", "new_password": "newpassword"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/password/reset", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// mockAuthServiceのmockメソッドを準備
+	mockAuthService.On("ResetPassword", "used_token", "newpassword").Return(errors.New("invalid password reset token"))
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid password reset token"}`, w.Body.String())
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestVerifyEmailSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockAuthService := &mocks.MockAuthService{}
+	testAuthController := controllers.NewAuthController(mockAuthService)
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// VerifyEmail APIを準備
+	r.GET("/api/v1/verify-email", testAuthController.VerifyEmail)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/verify-email?token=verification_token", nil)
+
+	// mockAuthServiceのmockメソッドを準備
+	mockAuthService.On("VerifyEmail", "verification_token").Return(nil)
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockAuthService.AssertExpectations(t)
+}
+
+func TestVerifyEmailErrors(t *testing.T) {
+	testCases := []struct {
+		query string
+		err   error
+		code  int
+	}{
+		{"?token=used_token", errors.New("invalid email verification token"), http.StatusBadRequest},
+		{"", nil, http.StatusBadRequest},
+		{"?token=verification_token", errors.New("db error"), http.StatusInternalServerError},
+	}
+
+	for _, testCase := range testCases {
+		// モックサービスを準備
+		mockAuthService := &mocks.MockAuthService{}
+		testAuthController := controllers.NewAuthController(mockAuthService)
+
+		// ginエンジンの設定
+		r := setupTestRouter()
+		r.GET("/api/v1/verify-email", testAuthController.VerifyEmail)
+
+		// リクエスト作成
+		req, _ := http.NewRequest(http.MethodGet, "/api/v1/verify-email"+testCase.query, nil)
+
+		// mockAuthServiceのmockメソッドを準備
+		mockAuthService.On("VerifyEmail", mock.Anything).Return(testCase.err)
+
+		// テスト実行
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		// レスポンスを検証
+		assert.Equal(t, testCase.code, w.Code)
+	}
+}
+
+func TestResendVerificationEmail(t *testing.T) {
+	testCases := []struct {
+		err  error
+		code int
+	}{
+		{nil, http.StatusAccepted},
+		{errors.New("email is already verified"), http.StatusConflict},
+		{errors.New("verification email was sent recently"), http.StatusTooManyRequests},
+		{errors.New("user not found"), http.StatusNotFound},
+	}
+
+	for _, testCase := range testCases {
+		// モックサービスを準備
+		mockAuthService := &mocks.MockAuthService{}
+		testAuthController := controllers.NewAuthController(mockAuthService)
+
+		// ginエンジンの設定
+		r := setupTestRouter()
+		r.POST("/api/v1/verify-email/resend", func(c *gin.Context) {
+			// テストのために context に user_id を設定
+			c.Set("user_id", "1")
+			testAuthController.ResendVerificationEmail(c)
+		})
+
+		// リクエスト作成
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/verify-email/resend", nil)
+
+		// mockAuthServiceのmockメソッドを準備
+		mockAuthService.On("ResendVerificationEmail", uint(1)).Return(testCase.err)
+
+		// テスト実行
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		// レスポンスを検証
+		assert.Equal(t, testCase.code, w.Code)
+		mockAuthService.AssertExpectations(t)
+	}
+}
@@ -2,12 +2,15 @@ package controllers_test
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -52,6 +55,7 @@ func TestFollowSuccess(t *testing.T) {
 		"id": 1,
 		"follower_id": 1,
 		"followee_id": 2,
+		"created_at": "0001-01-01T00:00:00Z",
 		"follower": null,
 		"followee": null
 	}`
@@ -63,6 +67,36 @@ func TestFollowSuccess(t *testing.T) {
 	mockFollowerService.AssertExpectations(t)
 }
 
+func TestFollowSelf(t *testing.T) {
+	// モックサービスを準備
+	mockFollowerService, testFollowerController := prepareTestController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// Follow APIを準備
+	r.POST("/api/v1/follower", func(c *gin.Context) {
+		// テストのために context に followerId を設定
+		c.Set("user_id", "1")
+		testFollowerController.Follow(c)
+	})
+
+	// 自分自身をフォローするリクエストを作成
+	reqBody := []byte(`{"followee_id": 1}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/follower", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockFollowerService.On("Follow", uint(1), uint(1)).Return(nil, errors.New("cannot follow yourself"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "cannot follow yourself"}`, w.Body.String())
+	mockFollowerService.AssertExpectations(t)
+}
+
 func TestGetFollowerSuccess(t *testing.T) {
 	// モックサービスを準備
 	mockFollowerService, testFollowerController := prepareTestController()
@@ -96,6 +130,7 @@ func TestGetFollowerSuccess(t *testing.T) {
 		"id": 1,
 		"follower_id": 1,
 		"followee_id": 2,
+		"created_at": "0001-01-01T00:00:00Z",
 		"follower": null,
 		"followee": null
 	}`
@@ -142,25 +177,30 @@ func TestGetFollowsSuccess(t *testing.T) {
 	}
 
 	// モックサービスを準備
-	mockFollowerService.On("GetFollows", uint(1)).Return(followerResponse, nil)
+	mockFollowerService.On("GetFollows", uint(1), &pagination.Page{Limit: pagination.DefaultLimit}).Return(&pagination.List[*models.Follower]{Items: followerResponse}, nil)
 
 	// follower responseを準備
-	followerResponseJson := `[
-		{
-			"id": 1,
-			"follower_id": 1,
-			"followee_id": 2,
-			"follower": null,
-			"followee": null
-		},
-		{
-			"id": 2,
-			"follower_id": 1,
-			"followee_id": 3,
-			"follower": null,
-			"followee": null
-		}
-	]`
+	followerResponseJson := `{
+		"data": [
+			{
+				"id": 1,
+				"follower_id": 1,
+				"followee_id": 2,
+				"created_at": "0001-01-01T00:00:00Z",
+				"follower": null,
+				"followee": null
+			},
+			{
+				"id": 2,
+				"follower_id": 1,
+				"followee_id": 3,
+				"created_at": "0001-01-01T00:00:00Z",
+				"follower": null,
+				"followee": null
+			}
+		],
+		"next_cursor": null
+	}`
 
 	// リクエスト実行
 	r.ServeHTTP(w, req)
@@ -204,30 +244,100 @@ func TestGetFollowersSuccess(t *testing.T) {
 	}
 
 	// モックサービスを準備
-	mockFollowerService.On("GetFollowers", uint(3)).Return(followerResponse, nil)
+	mockFollowerService.On("GetFollowers", uint(3), &pagination.Page{Limit: pagination.DefaultLimit}).Return(&pagination.List[*models.Follower]{Items: followerResponse}, nil)
 
 	// follower responseを準備
-	followerResponseJson := `[
-		{
-			"id": 1,
-			"follower_id": 1,
-			"followee_id": 3,
-			"follower": null,
-			"followee": null
-		},
-		{
-			"id": 2,
-			"follower_id": 2,
-			"followee_id": 3,
-			"follower": null,
-			"followee": null
-		}
-	]`
+	followerResponseJson := `{
+		"data": [
+			{
+				"id": 1,
+				"follower_id": 1,
+				"followee_id": 3,
+				"created_at": "0001-01-01T00:00:00Z",
+				"follower": null,
+				"followee": null
+			},
+			{
+				"id": 2,
+				"follower_id": 2,
+				"followee_id": 3,
+				"created_at": "0001-01-01T00:00:00Z",
+				"follower": null,
+				"followee": null
+			}
+		],
+		"next_cursor": null
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, followerResponseJson, w.Body.String())
+	mockFollowerService.AssertExpectations(t)
+}
+
+func TestGetFollowersHidesSensitiveUserFields(t *testing.T) {
+	// モックサービスを準備
+	mockFollowerService, testFollowerController := prepareTestController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// GetFollowers APIを準備
+	r.GET("/api/v1/follower/followers/:followee_id", testFollowerController.GetFollowers)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/follower/followers/2", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// パスワードのハッシュ、email、生年月日を含むユーザーをPreloadしたFollowerを準備
+	follower := &models.User{
+		ID:        1,
+		Name:      "gopher",
+		Username:  "gopher",
+		Email:     "gopher@example.com",
+		Password:  "$2a$10$hashedpassword",
+		Dob:       time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+	followerResponse := []*models.Follower{{ID: 1, FollowerID: 1, FolloweeID: 2, Follower: follower}}
+
+	// モックサービスを準備
+	mockFollowerService.On("GetFollowers", uint(2), &pagination.Page{Limit: pagination.DefaultLimit}).Return(&pagination.List[*models.Follower]{Items: followerResponse}, nil)
+
+	// follower responseを準備
+	followerResponseJson := `{
+		"data": [
+			{
+				"id": 1,
+				"follower_id": 1,
+				"followee_id": 2,
+				"created_at": "0001-01-01T00:00:00Z",
+				"follower": {
+					"id": 1,
+					"name": "gopher",
+					"username": "gopher",
+					"bio": "",
+					"location": "",
+					"website": "",
+					"avatar_url": "",
+					"header_url": "",
+					"created_at": "2024-09-01T00:00:00Z"
+				},
+				"followee": null
+			}
+		],
+		"next_cursor": null
+	}`
 
 	// リクエスト実行
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.JSONEq(t, followerResponseJson, w.Body.String())
+	assert.NotContains(t, w.Body.String(), follower.Password)
+	assert.NotContains(t, w.Body.String(), follower.Email)
 	mockFollowerService.AssertExpectations(t)
 }
 
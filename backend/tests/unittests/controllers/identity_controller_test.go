@@ -0,0 +1,131 @@
+package controllers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// /me/identitiesのルーターを準備
+func prepareTestIdentityRouter() (*mocks.MockIdentityService, *gin.Engine) {
+	mockIdentityService := &mocks.MockIdentityService{}
+	testIdentityController := controllers.NewIdentityController(mockIdentityService)
+
+	r := setupTestRouter()
+	withUser := func(handler gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			// テストのために context に user_id を設定
+			c.Set("user_id", "1")
+			handler(c)
+		}
+	}
+	r.GET("/api/v1/me/identities", withUser(testIdentityController.GetIdentities))
+	r.POST("/api/v1/me/identities", withUser(testIdentityController.LinkIdentity))
+	r.DELETE("/api/v1/me/identities/:provider", withUser(testIdentityController.UnlinkIdentity))
+	return mockIdentityService, r
+}
+
+func TestGetIdentities(t *testing.T) {
+	mockIdentityService, r := prepareTestIdentityRouter()
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockIdentityService.On("GetIdentities", uint(1)).Return([]*models.UserIdentity{
+		{ID: 1, UserID: 1, Provider: "google", Subject: "google-1", Email: "test@example.com", CreatedAt: createdAt},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/me/identities", nil)
+	r.ServeHTTP(w, req)
+
+	// subjectはレスポンスに含めない
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": [{"provider": "google", "email": "test@example.com", "created_at": "2024-01-01T00:00:00Z"}]}`, w.Body.String())
+	mockIdentityService.AssertExpectations(t)
+}
+
+// OAuthのcallback(action=link)で返されたticketのアカウントを紐づける
+func TestLinkIdentitySuccess(t *testing.T) {
+	mockIdentityService, r := prepareTestIdentityRouter()
+
+	mockIdentityService.On("LinkIdentity", uint(1), "test_ticket").Return(&models.UserIdentity{UserID: 1, Provider: "github", Subject: "42", Email: "test@example.com"}, nil)
+
+	reqBody := []byte(`{"ticket": "test_ticket"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/identities", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"data": {"provider": "github", "email": "test@example.com", "created_at": "0001-01-01T00:00:00Z"}}`, w.Body.String())
+	mockIdentityService.AssertExpectations(t)
+}
+
+func TestLinkIdentityErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		reqBody string
+		err     error
+		code    int
+	}{
+		{name: "without ticket", reqBody: `{}`, code: http.StatusBadRequest},
+		{name: "invalid ticket", reqBody: `{"ticket": "test_ticket"}`, err: errors.New("invalid oauth ticket"), code: http.StatusBadRequest},
+		{name: "linked to another user", reqBody: `{"ticket": "test_ticket"}`, err: errors.New("identity is linked to another user"), code: http.StatusConflict},
+		{name: "provider already linked", reqBody: `{"ticket": "test_ticket"}`, err: errors.New("provider is already linked"), code: http.StatusConflict},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockIdentityService, r := prepareTestIdentityRouter()
+			if testCase.err != nil {
+				mockIdentityService.On("LinkIdentity", uint(1), "test_ticket").Return(nil, testCase.err)
+			}
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/identities", bytes.NewBufferString(testCase.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.code, w.Code)
+			if testCase.err == nil {
+				mockIdentityService.AssertNotCalled(t, "LinkIdentity", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	testCases := []struct {
+		name     string
+		provider string
+		err      error
+		code     int
+	}{
+		{name: "success", provider: "github", code: http.StatusOK},
+		{name: "not linked", provider: "okta", err: errors.New("user identity not found"), code: http.StatusNotFound},
+		{name: "last login method", provider: "google", err: errors.New("cannot unlink the last login method"), code: http.StatusConflict},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockIdentityService, r := prepareTestIdentityRouter()
+			mockIdentityService.On("UnlinkIdentity", uint(1), testCase.provider).Return(testCase.err)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/me/identities/"+testCase.provider, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, testCase.code, w.Code)
+			mockIdentityService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package controllers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLikeSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockLikeService, testLikeController := prepareTestLikeController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// Like APIを準備
+	r.POST("/api/v1/tweet/:id/like", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testLikeController.Like(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tweet/2/like", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// Like responseを準備
+	likeResponse := &models.Like{
+		ID:        1,
+		UserID:    1,
+		TweetID:   2,
+		CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// モックサービスを準備
+	mockLikeService.On("Like", uint(1), uint(2)).Return(likeResponse, nil)
+
+	// like responseを準備
+	likeResponseJson := `{
+		"id": 1,
+		"user_id": 1,
+		"tweet_id": 2,
+		"created_at": "2024-09-01T00:00:00Z",
+		"user": null,
+		"tweet": null
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, likeResponseJson, w.Body.String())
+	mockLikeService.AssertExpectations(t)
+}
+
+func TestLikeConflict(t *testing.T) {
+	// モックサービスを準備
+	mockLikeService, testLikeController := prepareTestLikeController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// Like APIを準備
+	r.POST("/api/v1/tweet/:id/like", func(c *gin.Context) {
+		c.Set("user_id", "1")
+		testLikeController.Like(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tweet/2/like", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockLikeService.On("Like", uint(1), uint(2)).Return(nil, errors.New("already liked"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockLikeService.AssertExpectations(t)
+}
+
+func TestUnlikeNotFound(t *testing.T) {
+	// モックサービスを準備
+	mockLikeService, testLikeController := prepareTestLikeController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// Unlike APIを準備
+	r.DELETE("/api/v1/tweet/:id/like", func(c *gin.Context) {
+		c.Set("user_id", "1")
+		testLikeController.Unlike(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/tweet/2/like", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockLikeService.On("Unlike", uint(1), uint(2)).Return(errors.New("like not found"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockLikeService.AssertExpectations(t)
+}
+
+func TestGetLikersHidesSensitiveUserFields(t *testing.T) {
+	// モックサービスを準備
+	mockLikeService, testLikeController := prepareTestLikeController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// GetLikers APIを準備
+	r.GET("/api/v1/tweet/:id/likes", testLikeController.GetLikers)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tweet/2/likes", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// パスワードのハッシュ、email、生年月日を含むユーザーをPreloadしたLikeを準備
+	liker := &models.User{
+		ID:        1,
+		Name:      "gopher",
+		Username:  "gopher",
+		Email:     "gopher@example.com",
+		Password:  "$2a$10$hashedpassword",
+		Dob:       time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+	likes := []*models.Like{{ID: 1, UserID: 1, TweetID: 2, CreatedAt: time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC), User: liker}}
+
+	// モックサービスを準備
+	mockLikeService.On("GetLikers", uint(2), &pagination.Page{Limit: pagination.DefaultLimit}).Return(&pagination.List[*models.Like]{Items: likes}, nil)
+
+	// like responseを準備
+	likeResponseJson := `{
+		"data": [
+			{
+				"id": 1,
+				"user_id": 1,
+				"tweet_id": 2,
+				"created_at": "2024-09-02T00:00:00Z",
+				"user": {
+					"id": 1,
+					"name": "gopher",
+					"username": "gopher",
+					"bio": "",
+					"location": "",
+					"website": "",
+					"avatar_url": "",
+					"header_url": "",
+					"created_at": "2024-09-01T00:00:00Z"
+				},
+				"tweet": null
+			}
+		],
+		"next_cursor": null
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, likeResponseJson, w.Body.String())
+	assert.NotContains(t, w.Body.String(), liker.Password)
+	assert.NotContains(t, w.Body.String(), liker.Email)
+	mockLikeService.AssertExpectations(t)
+}
+
+func prepareTestLikeController() (*mocks.MockLikeService, controllers.ILikeController) {
+	mockLikeService := &mocks.MockLikeService{}
+	testLikeController := controllers.NewLikeController(mockLikeService)
+
+	return mockLikeService, testLikeController
+}
@@ -0,0 +1,154 @@
+package controllers_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUploadMediaSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockMediaService, testMediaController := prepareTestMediaController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// UploadMedia APIを準備
+	r.POST("/api/v1/media", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testMediaController.UploadMedia(c)
+	})
+
+	// リクエスト作成
+	req := newUploadRequest(t, "photo.png", []byte("png data"))
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// media responseを準備
+	mediaResponse := &models.Media{
+		ID:           1,
+		UserID:       1,
+		Type:         models.Image,
+		ContentType:  "image/png",
+		Size:         8,
+		StorageKey:   "images/1.png",
+		URL:          "http://localhost:8080/media/images/1.png",
+		ThumbnailKey: "thumbnails/1.jpg",
+		ThumbnailURL: "http://localhost:8080/media/thumbnails/1.jpg",
+		CreatedAt:    time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// モックサービスを準備
+	mockMediaService.On("UploadMedia", uint(1), mock.Anything, int64(8)).Return(mediaResponse, nil)
+
+	// media responseを準備(storageのkeyは返さない)
+	mediaResponseJson := `{
+		"data": {
+			"id": 1,
+			"user_id": 1,
+			"tweet_id": null,
+			"type": "image",
+			"content_type": "image/png",
+			"size": 8,
+			"url": "http://localhost:8080/media/images/1.png",
+			"thumbnail_url": "http://localhost:8080/media/thumbnails/1.jpg",
+			"created_at": "2024-09-01T00:00:00Z"
+		}
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, mediaResponseJson, w.Body.String())
+	mockMediaService.AssertExpectations(t)
+}
+
+func TestUploadMediaUnsupportedType(t *testing.T) {
+	// モックサービスを準備
+	mockMediaService, testMediaController := prepareTestMediaController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// UploadMedia APIを準備
+	r.POST("/api/v1/media", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testMediaController.UploadMedia(c)
+	})
+
+	// リクエスト作成
+	req := newUploadRequest(t, "page.html", []byte("<html></html>"))
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockMediaService.On("UploadMedia", uint(1), mock.Anything, int64(13)).Return(nil, errors.New("unsupported media type"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.JSONEq(t, `{"error": "unsupported media type"}`, w.Body.String())
+}
+
+func TestUploadMediaWithoutFile(t *testing.T) {
+	// モックサービスを準備
+	mockMediaService, testMediaController := prepareTestMediaController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// UploadMedia APIを準備
+	r.POST("/api/v1/media", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testMediaController.UploadMedia(c)
+	})
+
+	// リクエスト作成(fileフィールドなし)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/media", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockMediaService.AssertNotCalled(t, "UploadMedia")
+}
+
+// fileフィールドにファイルを設定したmultipart/form-dataのリクエストを作成
+func newUploadRequest(t *testing.T, fileName string, data []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	assert.NoError(t, err)
+	_, err = part.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/media", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func prepareTestMediaController() (*mocks.MockMediaService, controllers.IMediaController) {
+	mockMediaService := &mocks.MockMediaService{}
+	testMediaController := controllers.NewMediaController(mockMediaService)
+	return mockMediaService, testMediaController
+}
@@ -0,0 +1,242 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/oauth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/oauth/oauthtest"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testOAuthRedirectURL = "http://localhost:8080/oauth/test/callback"
+
+// fake OIDCサーバーをプロバイダー"test"として登録したルーターを準備
+func prepareTestOAuthRouter(server *oauthtest.Server) (*mocks.MockAuthService, *gin.Engine) {
+	mockAuthService := &mocks.MockAuthService{}
+	testOAuthController := controllers.NewOAuthController(
+		oauth.NewRegistry(server.Provider("test", testOAuthRedirectURL)),
+		mockAuthService,
+	)
+
+	r := setupTestRouter()
+	r.GET("/oauth/:provider/login", testOAuthController.Login)
+	r.GET("/oauth/:provider/callback", testOAuthController.Callback)
+
+	return mockAuthService, r
+}
+
+// cookieを付けてリクエストを実行する
+// 同じ名前のcookieはブラウザと同じく最後にSet-Cookieされたものを使用する
+func serveWithCookies(r *gin.Engine, target string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, target, nil)
+	latest := map[string]*http.Cookie{}
+	for _, c := range cookies {
+		latest[c.Name] = c
+	}
+	for _, c := range latest {
+		req.AddCookie(c)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// loginからプロバイダーで認可してcallbackを実行する
+func serveOAuthCallback(t *testing.T, server *oauthtest.Server, r *gin.Engine, action string) *httptest.ResponseRecorder {
+	w := serveWithCookies(r, "/oauth/test/login?action="+action, nil)
+	assert.Equal(t, http.StatusFound, w.Code)
+	callbackURL, err := server.Authorize(w.Header().Get("Location"))
+	assert.NoError(t, err)
+	callback, _ := url.Parse(callbackURL)
+
+	return serveWithCookies(r, callback.RequestURI(), w.Result().Cookies())
+}
+
+// プロバイダーの認可画面へのリダイレクトから、callbackで紐づけられたユーザーのtokenを返すまで
+func TestOAuthLoginAndCallbackSuccess(t *testing.T) {
+	server := oauthtest.NewServer(oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: true, Name: "gopher"})
+	defer server.Close()
+	mockAuthService, r := prepareTestOAuthRouter(server)
+
+	// 認可画面へリダイレクト(PKCEのchallengeとnonceを含める)
+	w := serveWithCookies(r, "/oauth/test/login", nil)
+	assert.Equal(t, http.StatusFound, w.Code)
+	authCodeURL, _ := url.Parse(w.Header().Get("Location"))
+	assert.Equal(t, server.URL+"/authorize", authCodeURL.Scheme+"://"+authCodeURL.Host+authCodeURL.Path)
+	assert.Equal(t, "S256", authCodeURL.Query().Get("code_challenge_method"))
+	assert.NotEmpty(t, authCodeURL.Query().Get("nonce"))
+	// 認可リクエストの状態はHttpOnlyのcookieに保存する
+	cookies := w.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "oauth_state", cookies[0].Name)
+	assert.Equal(t, "/oauth", cookies[0].Path)
+	assert.True(t, cookies[0].HttpOnly)
+
+	// プロバイダーで認可してcallbackへ
+	loginResponse := &services.LoginResponse{Token: "test_token", RefreshToken: "test_refresh_token"}
+	mockAuthService.On("LoginUsingOAuth", "test", "user-1", "gopher@example.com").Return(loginResponse, nil)
+	callbackURL, err := server.Authorize(authCodeURL.String())
+	assert.NoError(t, err)
+	callback, _ := url.Parse(callbackURL)
+	w = serveWithCookies(r, callback.RequestURI(), cookies)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"token": "test_token", "refresh_token": "test_refresh_token"}`, w.Body.String())
+	callbackCookies := w.Result().Cookies()
+
+	// 同じstateでcallbackを再実行できない(cookieは削除されている)
+	w = serveWithCookies(r, callback.RequestURI(), append(cookies, callbackCookies...))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	// 以前のcookieを再送してもcodeは一度しか交換できない
+	w = serveWithCookies(r, callback.RequestURI(), cookies)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	mockAuthService.AssertNumberOfCalls(t, "LoginUsingOAuth", 1)
+}
+
+// 紐づけられたユーザーがいない場合はサインアップ用のticketを返す
+func TestOAuthCallbackSignupRequired(t *testing.T) {
+	server := oauthtest.NewServer(oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: true, Name: "gopher"})
+	defer server.Close()
+	mockAuthService, r := prepareTestOAuthRouter(server)
+
+	mockAuthService.On("LoginUsingOAuth", "test", "user-1", "gopher@example.com").Return(nil, errors.New("user not found"))
+
+	w := serveOAuthCallback(t, server, r, "signup")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dtos.OAuthTicketResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.SignupRequired)
+	assert.Equal(t, "test", response.Provider)
+	assert.Equal(t, "gopher@example.com", response.Email)
+	assert.Equal(t, "gopher", response.Name)
+
+	// ticketにはプロバイダーで認証されたアカウントが署名されている(サインアップにのみ使用できる)
+	claims, err := auth.ValidateOAuthTicket(response.Ticket, auth.OAuthTicketActionSignup)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", claims.Provider)
+	assert.Equal(t, "user-1", claims.ProviderSubject)
+	assert.Equal(t, "gopher@example.com", claims.Email)
+	assert.Equal(t, "gopher", claims.Name)
+}
+
+// action=linkの場合はログインせずに紐づけ用のticketを返す
+func TestOAuthCallbackLinkTicket(t *testing.T) {
+	server := oauthtest.NewServer(oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: true, Name: "gopher"})
+	defer server.Close()
+	mockAuthService, r := prepareTestOAuthRouter(server)
+
+	w := serveOAuthCallback(t, server, r, "link")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dtos.OAuthTicketResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.SignupRequired)
+	claims, err := auth.ValidateOAuthTicket(response.Ticket, auth.OAuthTicketActionLink)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", claims.ProviderSubject)
+	// 紐づけ用のticketはサインアップに使用できない
+	_, err = auth.ValidateOAuthTicket(response.Ticket, auth.OAuthTicketActionSignup)
+	assert.Error(t, err)
+	mockAuthService.AssertNotCalled(t, "LoginUsingOAuth", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// 同じemailのユーザーにプロバイダーのアカウントが紐づけられていない場合
+func TestOAuthCallbackIdentityNotLinked(t *testing.T) {
+	server := oauthtest.NewServer(oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: true, Name: "gopher"})
+	defer server.Close()
+	mockAuthService, r := prepareTestOAuthRouter(server)
+
+	mockAuthService.On("LoginUsingOAuth", "test", "user-1", "gopher@example.com").Return(nil, errors.New("identity is not linked"))
+
+	w := serveOAuthCallback(t, server, r, "login")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"error": "identity is not linked"}`, w.Body.String())
+}
+
+func TestOAuthCallbackErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		user   oauthtest.User
+		query  func(callback url.Values)
+		code   int
+		errMsg string
+	}{
+		{
+			name:   "state mismatch",
+			user:   oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: true},
+			query:  func(callback url.Values) { callback.Set("state", "forged") },
+			code:   http.StatusBadRequest,
+			errMsg: "state does not match",
+		},
+		{
+			name:   "access denied",
+			user:   oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: true},
+			query:  func(callback url.Values) { callback.Del("code"); callback.Set("error", "access_denied") },
+			code:   http.StatusBadRequest,
+			errMsg: "authorization failed: access_denied",
+		},
+		{
+			name:   "email not verified",
+			user:   oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: false},
+			query:  func(callback url.Values) {},
+			code:   http.StatusForbidden,
+			errMsg: "email is not verified by provider",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			server := oauthtest.NewServer(testCase.user)
+			defer server.Close()
+			_, r := prepareTestOAuthRouter(server)
+
+			w := serveWithCookies(r, "/oauth/test/login", nil)
+			cookies := w.Result().Cookies()
+			callbackURL, err := server.Authorize(w.Header().Get("Location"))
+			assert.NoError(t, err)
+
+			callback, _ := url.Parse(callbackURL)
+			query := callback.Query()
+			testCase.query(query)
+			w = serveWithCookies(r, callback.Path+"?"+query.Encode(), cookies)
+
+			assert.Equal(t, testCase.code, w.Code)
+			assert.JSONEq(t, `{"error": "`+testCase.errMsg+`"}`, w.Body.String())
+		})
+	}
+}
+
+// cookieに認可リクエストの状態がない(loginを経由していない)callbackは受け付けない
+func TestOAuthCallbackWithoutState(t *testing.T) {
+	server := oauthtest.NewServer(oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: true})
+	defer server.Close()
+	_, r := prepareTestOAuthRouter(server)
+
+	w := serveWithCookies(r, "/oauth/test/callback?state=state&code=code", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "oauth state not found"}`, w.Body.String())
+}
+
+func TestOAuthLoginErrors(t *testing.T) {
+	server := oauthtest.NewServer(oauthtest.User{Subject: "user-1", Email: "gopher@example.com", EmailVerified: true})
+	defer server.Close()
+	_, r := prepareTestOAuthRouter(server)
+
+	w := serveWithCookies(r, "/oauth/unknown/login", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveWithCookies(r, "/oauth/test/login?action=delete", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,100 @@
+package controllers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchTweetsSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockSearchService, testSearchController := prepareTestSearchController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/search/tweets", testSearchController.SearchTweets)
+
+	// リクエスト作成
+	q := `"full text" from:gopher`
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/search/tweets?q="+url.QueryEscape(q), nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// search responseを準備
+	createdAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	searchResponse := &pagination.List[*models.Tweet]{
+		Items: []*models.Tweet{{ID: 1, UserID: 1, Type: models.Text, Content: "full text search", CreatedAt: createdAt, UpdatedAt: createdAt}},
+	}
+
+	// モックサービスを準備
+	mockSearchService.On("SearchTweets", q, mock.Anything).Return(searchResponse, nil)
+
+	// search responseを準備
+	searchResponseJson := `{
+		"data": [
+			{
+				"id": 1,
+				"user_id": 1,
+				"type": "text",
+				"content": "full text search",
+				"created_at": "2024-09-01T00:00:00Z",
+				"updated_at": "2024-09-01T00:00:00Z",
+				"in_reply_to_tweet_id": null,
+				"conversation_id": null,
+				"retweeted_tweet_id": null,
+				"quoted_tweet_id": null,
+				"deleted_at": null,
+				"user": null
+			}
+		],
+		"next_cursor": null
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, searchResponseJson, w.Body.String())
+	mockSearchService.AssertExpectations(t)
+}
+
+func TestSearchTweetsInvalidQuery(t *testing.T) {
+	// モックサービスを準備
+	mockSearchService, testSearchController := prepareTestSearchController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/search/tweets", testSearchController.SearchTweets)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/search/tweets?q=has:link", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockSearchService.On("SearchTweets", "has:link", mock.Anything).Return(nil, errors.New("invalid has filter"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid has filter"}`, w.Body.String())
+	mockSearchService.AssertExpectations(t)
+}
+
+func prepareTestSearchController() (*mocks.MockSearchService, controllers.ISearchController) {
+	mockSearchService := &mocks.MockSearchService{}
+	testSearchController := controllers.NewSearchController(mockSearchService)
+
+	return mockSearchService, testSearchController
+}
@@ -0,0 +1,77 @@
+package controllers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTrendsSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockTrendService, testTrendController := prepareTestTrendController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/trends", testTrendController.GetTrends)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/trends?window=1h&limit=100", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備(limitはMaxTrendLimitまで)
+	mockTrendService.On("GetTrends", "1h", services.MaxTrendLimit).Return([]*dtos.Trend{
+		{Term: "#go", Hashtag: true, Count: 10, PreviousCount: 0, Score: 10},
+	}, nil)
+
+	// trends responseを準備
+	trendsResponseJson := `{
+		"window": "1h",
+		"data": [
+			{"term": "#go", "hashtag": true, "count": 10, "previous_count": 0, "score": 10}
+		]
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, trendsResponseJson, w.Body.String())
+	mockTrendService.AssertExpectations(t)
+}
+
+func TestGetTrendsInvalidWindow(t *testing.T) {
+	// モックサービスを準備
+	mockTrendService, testTrendController := prepareTestTrendController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/trends", testTrendController.GetTrends)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/trends?window=30m", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockTrendService.On("GetTrends", "30m", services.DefaultTrendLimit).Return(nil, errors.New("invalid window"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid window"}`, w.Body.String())
+}
+
+func prepareTestTrendController() (*mocks.MockTrendService, controllers.ITrendController) {
+	mockTrendService := &mocks.MockTrendService{}
+	testTrendController := controllers.NewTrendController(mockTrendService)
+	return mockTrendService, testTrendController
+}
@@ -0,0 +1,398 @@
+package controllers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReplyTweetSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockTweetService, testTweetController := prepareTestTweetController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// ReplyTweet APIを準備
+	r.POST("/api/v1/tweet/:id/reply", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testTweetController.ReplyTweet(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tweet/2/reply", bytes.NewBufferString(`{"type": "text", "content": "reply"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// reply responseを準備
+	parentId := uint(2)
+	replyResponse := &models.Tweet{
+		ID:               3,
+		UserID:           1,
+		Type:             models.Text,
+		Content:          "reply",
+		CreatedAt:        time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:        time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		InReplyToTweetID: &parentId,
+		ConversationID:   &parentId,
+	}
+
+	// モックサービスを準備
+	mockTweetService.On("ReplyTweet", uint(1), uint(2), "text", "reply").Return(replyResponse, nil)
+
+	// reply responseを準備
+	replyResponseJson := `{
+		"data": {
+			"id": 3,
+			"user_id": 1,
+			"type": "text",
+			"content": "reply",
+			"created_at": "2024-09-01T00:00:00Z",
+			"updated_at": "2024-09-01T00:00:00Z",
+			"in_reply_to_tweet_id": 2,
+			"conversation_id": 2,
+			"retweeted_tweet_id": null,
+			"quoted_tweet_id": null,
+			"deleted_at": null,
+			"user": null
+		}
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, replyResponseJson, w.Body.String())
+	mockTweetService.AssertExpectations(t)
+}
+
+func TestReplyTweetNotFound(t *testing.T) {
+	// モックサービスを準備
+	mockTweetService, testTweetController := prepareTestTweetController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// ReplyTweet APIを準備
+	r.POST("/api/v1/tweet/:id/reply", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testTweetController.ReplyTweet(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tweet/2/reply", bytes.NewBufferString(`{"type": "text", "content": "reply"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockTweetService.On("ReplyTweet", uint(1), uint(2), "text", "reply").Return(nil, errors.New("tweet not found"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockTweetService.AssertExpectations(t)
+}
+
+func TestGetThreadSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockTweetService, testTweetController := prepareTestTweetController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// GetThread APIを準備
+	r.GET("/api/v1/tweet/:id/thread", testTweetController.GetThread)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tweet/1/thread?depth=2", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// thread responseを準備(削除されたtweetはtombstoneとして返す)
+	rootId := uint(1)
+	createdAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	threadResponse := &dtos.TweetThread{
+		Tweet: &dtos.TweetResponse{ID: 1, UserID: 1, Type: models.Text, Content: "", CreatedAt: createdAt, UpdatedAt: createdAt, DeletedAt: &createdAt},
+		Path:  []*dtos.TweetResponse{},
+		Replies: &pagination.List[*dtos.TweetThreadNode]{
+			Items: []*dtos.TweetThreadNode{
+				{
+					Tweet:   &dtos.TweetResponse{ID: 2, UserID: 2, Type: models.Text, Content: "reply", CreatedAt: createdAt, UpdatedAt: createdAt, InReplyToTweetID: &rootId, ConversationID: &rootId},
+					Replies: []*dtos.TweetThreadNode{},
+				},
+			},
+		},
+	}
+
+	// モックサービスを準備
+	mockTweetService.On("GetThread", uint(1), 2, mock.Anything).Return(threadResponse, nil)
+
+	// thread responseを準備
+	threadResponseJson := `{
+		"tweet": {
+			"id": 1,
+			"user_id": 1,
+			"type": "text",
+			"content": "",
+			"created_at": "2024-09-01T00:00:00Z",
+			"updated_at": "2024-09-01T00:00:00Z",
+			"in_reply_to_tweet_id": null,
+			"conversation_id": null,
+			"retweeted_tweet_id": null,
+			"quoted_tweet_id": null,
+			"deleted_at": "2024-09-01T00:00:00Z",
+			"user": null
+		},
+		"path": [],
+		"replies": {
+			"data": [
+				{
+					"tweet": {
+						"id": 2,
+						"user_id": 2,
+						"type": "text",
+						"content": "reply",
+						"created_at": "2024-09-01T00:00:00Z",
+						"updated_at": "2024-09-01T00:00:00Z",
+						"in_reply_to_tweet_id": 1,
+						"conversation_id": 1,
+						"retweeted_tweet_id": null,
+						"quoted_tweet_id": null,
+						"deleted_at": null,
+						"user": null
+					},
+					"replies": []
+				}
+			],
+			"next_cursor": null
+		}
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, threadResponseJson, w.Body.String())
+	mockTweetService.AssertExpectations(t)
+}
+
+func TestGetThreadInvalidDepth(t *testing.T) {
+	// モックサービスを準備
+	mockTweetService, testTweetController := prepareTestTweetController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// GetThread APIを準備
+	r.GET("/api/v1/tweet/:id/thread", testTweetController.GetThread)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/tweet/1/thread?depth=0", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockTweetService.AssertNotCalled(t, "GetThread")
+}
+
+func TestCreateTweetWithAttachedMedia(t *testing.T) {
+	// モックサービスを準備
+	mockTweetService, testTweetController := prepareTestTweetController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// CreateTweet APIを準備
+	r.POST("/api/v1/tweet", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testTweetController.CreateTweet(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tweet", bytes.NewBufferString(`{"type": "image", "content": "photo", "media_ids": [1]}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockTweetService.On("CreateTweet", uint(1), "image", "photo", []uint{1}).Return(nil, errors.New("media is already attached"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.JSONEq(t, `{"error": "media is already attached"}`, w.Body.String())
+	mockTweetService.AssertExpectations(t)
+}
+
+func TestCreateTweetTooManyMedia(t *testing.T) {
+	// モックサービスを準備
+	mockTweetService, testTweetController := prepareTestTweetController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// CreateTweet APIを準備
+	r.POST("/api/v1/tweet", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testTweetController.CreateTweet(c)
+	})
+
+	// 添付できる画像/動画は4つまで
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/tweet", bytes.NewBufferString(`{"type": "image", "content": "photos", "media_ids": [1, 2, 3, 4, 5]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid input data"}`, w.Body.String())
+	mockTweetService.AssertNotCalled(t, "CreateTweet", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteTweetInternalError(t *testing.T) {
+	// モックサービスを準備
+	mockTweetService, testTweetController := prepareTestTweetController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// DeleteTweet APIを準備
+	r.DELETE("/api/v1/tweet/:id", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testTweetController.DeleteTweet(c)
+	})
+
+	req, _ := http.NewRequest(http.MethodDelete, "/api/v1/tweet/3", nil)
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockTweetService.On("DeleteTweet", uint(3), uint(1)).Return(errors.New("database error"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error": "failed to delete tweet"}`, w.Body.String())
+	mockTweetService.AssertExpectations(t)
+}
+
+func TestGetHashtagTweetsInvalidTag(t *testing.T) {
+	// モックサービスを準備
+	mockTweetService, testTweetController := prepareTestTweetController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// GetHashtagTweets APIを準備
+	r.GET("/api/v1/hashtag/:tag/tweets", testTweetController.GetHashtagTweets)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/hashtag/123/tweets", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockTweetService.On("GetHashtagTweets", "123", mock.Anything).Return(nil, errors.New("invalid hashtag"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error": "invalid hashtag"}`, w.Body.String())
+	mockTweetService.AssertExpectations(t)
+}
+
+func TestGetMentionsSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockTweetService, testTweetController := prepareTestTweetController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+
+	// GetMentions APIを準備
+	r.GET("/api/v1/mentions", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "2")
+		testTweetController.GetMentions(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/mentions?limit=10", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// mention responseを準備
+	createdAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	mentionResponse := &pagination.List[*models.Tweet]{
+		Items: []*models.Tweet{
+			{
+				ID: 1, UserID: 1, Type: models.Text, Content: "hi @gopher", CreatedAt: createdAt, UpdatedAt: createdAt,
+				Mentions: []*models.TweetMention{{ID: 1, TweetID: 1, UserID: 2, Username: "gopher", Start: 3, End: 10}},
+			},
+		},
+	}
+
+	// モックサービスを準備
+	mockTweetService.On("GetMentionTweets", uint(2), &pagination.Page{Limit: 10}).Return(mentionResponse, nil)
+
+	// mention responseを準備
+	mentionResponseJson := `{
+		"data": [
+			{
+				"id": 1,
+				"user_id": 1,
+				"type": "text",
+				"content": "hi @gopher",
+				"created_at": "2024-09-01T00:00:00Z",
+				"updated_at": "2024-09-01T00:00:00Z",
+				"in_reply_to_tweet_id": null,
+				"conversation_id": null,
+				"retweeted_tweet_id": null,
+				"quoted_tweet_id": null,
+				"deleted_at": null,
+				"user": null,
+				"mentions": [
+					{"user_id": 2, "username": "gopher", "start": 3, "end": 10}
+				]
+			}
+		],
+		"next_cursor": null
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, mentionResponseJson, w.Body.String())
+	mockTweetService.AssertExpectations(t)
+}
+
+func prepareTestTweetController() (*mocks.MockTweetService, controllers.ITweetController) {
+	mockTweetService := &mocks.MockTweetService{}
+	testTweetController := controllers.NewTweetController(mockTweetService)
+
+	return mockTweetService, testTweetController
+}
@@ -0,0 +1,191 @@
+package controllers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEnrollTOTPSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockTwoFactorService, testTwoFactorController := prepareTestTwoFactorController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.POST("/api/v1/me/2fa/totp", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testTwoFactorController.EnrollTOTP(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/2fa/totp", nil)
+
+	// モックサービスのmockメソッドを準備
+	mockTwoFactorService.On("EnrollTOTP", uint(1)).Return(&services.TOTPEnrollment{
+		Secret: "JBSWY3DPEHPK3PXP",
+		URI:    "otpauth://totp/tweet-app:gopher@example.com?secret=JBSWY3DPEHPK3PXP",
+	}, nil)
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"secret": "JBSWY3DPEHPK3PXP", "otpauth_uri": "otpauth://totp/tweet-app:gopher@example.com?secret=JBSWY3DPEHPK3PXP"}}`, w.Body.String())
+	mockTwoFactorService.AssertExpectations(t)
+}
+
+func TestEnrollTOTPAlreadyEnabled(t *testing.T) {
+	// モックサービスを準備
+	mockTwoFactorService, testTwoFactorController := prepareTestTwoFactorController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.POST("/api/v1/me/2fa/totp", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testTwoFactorController.EnrollTOTP(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/2fa/totp", nil)
+
+	// モックサービスのmockメソッドを準備
+	mockTwoFactorService.On("EnrollTOTP", uint(1)).Return(nil, errors.New("two-factor authentication is already enabled"))
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// レスポンスを検証
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockTwoFactorService.AssertExpectations(t)
+}
+
+func TestConfirmTOTPSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockTwoFactorService, testTwoFactorController := prepareTestTwoFactorController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.POST("/api/v1/me/2fa/totp/confirm", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testTwoFactorController.ConfirmTOTP(c)
+	})
+
+	// リクエスト作成
+	reqBody := []byte(`{"code": "123456"}`)
+	req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/2fa/totp/confirm", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// モックサービスのmockメソッドを準備
+	mockTwoFactorService.On("ConfirmTOTP", uint(1), "123456").Return([]string{"abcde-23456", "fghij-34567"}, nil)
+
+	// テスト実行
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// リカバリーコードを返す
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"data": {"recovery_codes": ["abcde-23456", "fghij-34567"]}}`, w.Body.String())
+	mockTwoFactorService.AssertExpectations(t)
+}
+
+func TestConfirmTOTPErrors(t *testing.T) {
+	testCases := []struct {
+		reqBody string
+		err     error
+		code    int
+	}{
+		{`{"code": "000000"}`, errors.New("invalid code"), http.StatusBadRequest},
+		{`{"code": "123456"}`, errors.New("two-factor authentication is not enrolled"), http.StatusBadRequest},
+		{`{"code": "123456"}`, errors.New("two-factor authentication is already enabled"), http.StatusConflict},
+		{`{}`, nil, http.StatusBadRequest},
+	}
+
+	for _, testCase := range testCases {
+		// モックサービスを準備
+		mockTwoFactorService, testTwoFactorController := prepareTestTwoFactorController()
+
+		// ginエンジンの設定
+		r := setupTestRouter()
+		r.POST("/api/v1/me/2fa/totp/confirm", func(c *gin.Context) {
+			// テストのために context に user_id を設定
+			c.Set("user_id", "1")
+			testTwoFactorController.ConfirmTOTP(c)
+		})
+
+		// リクエスト作成
+		req, _ := http.NewRequest(http.MethodPost, "/api/v1/me/2fa/totp/confirm", bytes.NewBufferString(testCase.reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// モックサービスのmockメソッドを準備
+		mockTwoFactorService.On("ConfirmTOTP", uint(1), mock.Anything).Return(nil, testCase.err)
+
+		// テスト実行
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		// レスポンスを検証
+		assert.Equal(t, testCase.code, w.Code)
+	}
+}
+
+func TestDisableTOTP(t *testing.T) {
+	testCases := []struct {
+		err  error
+		code int
+	}{
+		{nil, http.StatusOK},
+		{errors.New("invalid code"), http.StatusBadRequest},
+		{errors.New("two-factor authentication is not enabled"), http.StatusBadRequest},
+	}
+
+	for _, testCase := range testCases {
+		// モックサービスを準備
+		mockTwoFactorService, testTwoFactorController := prepareTestTwoFactorController()
+
+		// ginエンジンの設定
+		r := setupTestRouter()
+		r.DELETE("/api/v1/me/2fa/totp", func(c *gin.Context) {
+			// テストのために context に user_id を設定
+			c.Set("user_id", "1")
+			testTwoFactorController.DisableTOTP(c)
+		})
+
+		// リクエスト作成
+		reqBody := []byte(`{"code": "abcde-23456"}`)
+		req, _ := http.NewRequest(http.MethodDelete, "/api/v1/me/2fa/totp", bytes.NewBuffer(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		// モックサービスのmockメソッドを準備
+		mockTwoFactorService.On("DisableTOTP", uint(1), "abcde-23456").Return(testCase.err)
+
+		// テスト実行
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		// レスポンスを検証
+		assert.Equal(t, testCase.code, w.Code)
+		mockTwoFactorService.AssertExpectations(t)
+	}
+}
+
+func prepareTestTwoFactorController() (*mocks.MockTwoFactorService, controllers.ITwoFactorController) {
+	mockTwoFactorService := &mocks.MockTwoFactorService{}
+	testTwoFactorController := controllers.NewTwoFactorController(mockTwoFactorService)
+
+	return mockTwoFactorService, testTwoFactorController
+}
@@ -0,0 +1,292 @@
+package controllers_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/controllers"
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetUserSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockUserService, testUserController := prepareTestUserController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/user/:id", testUserController.GetUser)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/1", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockUserService.On("GetUserProfile", uint(1)).Return(&dtos.UserProfile{
+		PublicUser:    dtos.PublicUser{ID: 1, Name: "gopher", Username: "gopher", CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)},
+		FollowerCount: 3, FollowingCount: 2, TweetCount: 10,
+	}, nil)
+
+	// user responseを準備
+	userResponseJson := `{
+		"data": {
+			"id": 1,
+			"name": "gopher",
+			"username": "gopher",
+			"bio": "",
+			"location": "",
+			"website": "",
+			"avatar_url": "",
+			"header_url": "",
+			"created_at": "2024-09-01T00:00:00Z",
+			"follower_count": 3,
+			"following_count": 2,
+			"tweet_count": 10
+		}
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, userResponseJson, w.Body.String())
+	mockUserService.AssertExpectations(t)
+}
+
+func TestGetUserByUsernameNotFound(t *testing.T) {
+	// モックサービスを準備
+	mockUserService, testUserController := prepareTestUserController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/user/by-username/:username", testUserController.GetUserByUsername)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/user/by-username/nobody", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockUserService.On("GetUserProfileByUsername", "nobody").Return(nil, errors.New("user not found"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error": "user not found"}`, w.Body.String())
+	mockUserService.AssertExpectations(t)
+}
+
+func TestSearchUsersSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockUserService, testUserController := prepareTestUserController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/search/users", testUserController.SearchUsers)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/search/users?q=go", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockUserService.On("SearchUsers", "go", mock.Anything).Return(&pagination.List[*dtos.UserProfile]{
+		Items: []*dtos.UserProfile{{PublicUser: dtos.PublicUser{ID: 1, Name: "gopher", Username: "gopher", CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)}}},
+	}, nil)
+
+	// search responseを準備
+	searchResponseJson := `{
+		"data": [
+			{
+				"id": 1,
+				"name": "gopher",
+				"username": "gopher",
+				"bio": "",
+				"location": "",
+				"website": "",
+				"avatar_url": "",
+				"header_url": "",
+				"created_at": "2024-09-01T00:00:00Z",
+				"follower_count": 0,
+				"following_count": 0,
+				"tweet_count": 0
+			}
+		],
+		"next_cursor": null
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, searchResponseJson, w.Body.String())
+	mockUserService.AssertExpectations(t)
+}
+
+func TestSearchUsersEmptyQuery(t *testing.T) {
+	// モックサービスを準備
+	mockUserService, testUserController := prepareTestUserController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/search/users", testUserController.SearchUsers)
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/search/users", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockUserService.On("SearchUsers", "", mock.Anything).Return(nil, errors.New("search query is empty"))
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUserService.AssertExpectations(t)
+}
+
+func prepareTestUserController() (*mocks.MockUserService, controllers.IUserController) {
+	mockUserService := &mocks.MockUserService{}
+	testUserController := controllers.NewUserController(mockUserService)
+
+	return mockUserService, testUserController
+}
+
+func TestGetMeSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockUserService, testUserController := prepareTestUserController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.GET("/api/v1/me", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testUserController.GetMe(c)
+	})
+
+	// リクエスト作成
+	req, _ := http.NewRequest(http.MethodGet, "/api/v1/me", nil)
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	mockUserService.On("GetMe", uint(1)).Return(&dtos.SelfUser{
+		PublicUser:    dtos.PublicUser{ID: 1, Name: "gopher", Username: "gopher", Bio: "hello", AvatarURL: "/media/avatar.png", CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)},
+		Email:         "gopher@example.com",
+		EmailVerified: true,
+		Dob:           time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, nil)
+
+	// me responseを準備(本人にはemail、emailの認証状態と生年月日も返す)
+	meResponseJson := `{
+		"data": {
+			"id": 1,
+			"name": "gopher",
+			"username": "gopher",
+			"bio": "hello",
+			"location": "",
+			"website": "",
+			"avatar_url": "/media/avatar.png",
+			"header_url": "",
+			"created_at": "2024-09-01T00:00:00Z",
+			"email": "gopher@example.com",
+			"email_verified": true,
+			"dob": "2000-01-01T00:00:00Z"
+		}
+	}`
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, meResponseJson, w.Body.String())
+	mockUserService.AssertExpectations(t)
+}
+
+func TestUpdateMeSuccess(t *testing.T) {
+	// モックサービスを準備
+	mockUserService, testUserController := prepareTestUserController()
+
+	// ginエンジンの設定
+	r := setupTestRouter()
+	r.PATCH("/api/v1/me", func(c *gin.Context) {
+		// テストのために context に user_id を設定
+		c.Set("user_id", "1")
+		testUserController.UpdateMe(c)
+	})
+
+	// リクエスト作成(指定していない項目はnilのまま)
+	reqBody := []byte(`{"bio": "hello", "avatar_media_id": 10}`)
+	req, _ := http.NewRequest(http.MethodPatch, "/api/v1/me", bytes.NewBuffer(reqBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	// レスポンスを準備
+	w := httptest.NewRecorder()
+
+	// モックサービスを準備
+	bio := "hello"
+	avatarId := uint(10)
+	mockUserService.On("UpdateMe", uint(1), &dtos.UpdateProfileInput{Bio: &bio, AvatarMediaID: &avatarId}).Return(&dtos.SelfUser{
+		PublicUser: dtos.PublicUser{ID: 1, Name: "gopher", Username: "gopher", Bio: "hello", AvatarURL: "/media/avatar.png"},
+		Email:      "gopher@example.com",
+	}, nil)
+
+	// リクエスト実行
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"avatar_url":"/media/avatar.png"`)
+	mockUserService.AssertExpectations(t)
+}
+
+func TestUpdateMeInvalidInput(t *testing.T) {
+	testCases := []struct {
+		err  string
+		code int
+	}{
+		{"invalid website", http.StatusBadRequest},
+		{"bio is too long", http.StatusBadRequest},
+		{"profile image must be an image", http.StatusBadRequest},
+		{"media not found", http.StatusNotFound},
+		{"invalid username", http.StatusBadRequest},
+		{"username is already taken", http.StatusConflict},
+	}
+
+	for _, testCase := range testCases {
+		// モックサービスを準備
+		mockUserService, testUserController := prepareTestUserController()
+
+		// ginエンジンの設定
+		r := setupTestRouter()
+		r.PATCH("/api/v1/me", func(c *gin.Context) {
+			// テストのために context に user_id を設定
+			c.Set("user_id", "1")
+			testUserController.UpdateMe(c)
+		})
+
+		// リクエスト作成
+		req, _ := http.NewRequest(http.MethodPatch, "/api/v1/me", bytes.NewBufferString(`{"website": "example"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		// レスポンスを準備
+		w := httptest.NewRecorder()
+
+		// モックサービスを準備
+		mockUserService.On("UpdateMe", uint(1), mock.Anything).Return(nil, errors.New(testCase.err))
+
+		// リクエスト実行
+		r.ServeHTTP(w, req)
+		assert.Equal(t, testCase.code, w.Code)
+		assert.JSONEq(t, `{"error": "`+testCase.err+`"}`, w.Body.String())
+	}
+}
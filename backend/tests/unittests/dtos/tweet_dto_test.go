@@ -0,0 +1,75 @@
+package dtos_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// 投稿者とretweetの元のTweetの投稿者はPublicUserとして返す
+func TestTweetResponse(t *testing.T) {
+	createdAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	originalId := uint(1)
+	originalTweet := &models.Tweet{ID: 1, UserID: 1, Type: models.Text, Content: "original", CreatedAt: createdAt, UpdatedAt: createdAt, User: prepareTestUser()}
+	retweet := &models.Tweet{ID: 2, UserID: 1, Type: models.Retweet, CreatedAt: createdAt, UpdatedAt: createdAt, RetweetedTweetID: &originalId, RetweetedTweet: originalTweet, User: prepareTestUser()}
+
+	tweetJson, err := json.Marshal(dtos.NewTweetResponse(retweet))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 2,
+		"user_id": 1,
+		"type": "retweet",
+		"content": "",
+		"created_at": "2024-09-01T00:00:00Z",
+		"updated_at": "2024-09-01T00:00:00Z",
+		"in_reply_to_tweet_id": null,
+		"conversation_id": null,
+		"deleted_at": null,
+		"retweeted_tweet_id": 1,
+		"quoted_tweet_id": null,
+		"user": {
+			"id": 1,
+			"name": "gopher",
+			"username": "gopher",
+			"bio": "",
+			"location": "",
+			"website": "",
+			"avatar_url": "",
+			"header_url": "",
+			"created_at": "2024-09-01T00:00:00Z"
+		},
+		"retweeted_tweet": {
+			"id": 1,
+			"user_id": 1,
+			"type": "text",
+			"content": "original",
+			"created_at": "2024-09-01T00:00:00Z",
+			"updated_at": "2024-09-01T00:00:00Z",
+			"in_reply_to_tweet_id": null,
+			"conversation_id": null,
+			"deleted_at": null,
+			"retweeted_tweet_id": null,
+			"quoted_tweet_id": null,
+			"user": {
+				"id": 1,
+				"name": "gopher",
+				"username": "gopher",
+				"bio": "",
+				"location": "",
+				"website": "",
+				"avatar_url": "",
+				"header_url": "",
+				"created_at": "2024-09-01T00:00:00Z"
+			}
+		}
+	}`, string(tweetJson))
+}
+
+// Preloadしていない場合はnilを返す
+func TestTweetResponseNil(t *testing.T) {
+	assert.Nil(t, dtos.NewTweetResponse(nil))
+}
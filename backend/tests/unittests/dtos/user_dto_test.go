@@ -0,0 +1,96 @@
+package dtos_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func prepareTestUser() *models.User {
+	return &models.User{
+		ID:        1,
+		Name:      "gopher",
+		Username:  "gopher",
+		Email:     "gopher@example.com",
+		Password:  "$2a$10$hashedpassword",
+		Dob:       time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// 公開viewにはパスワード、email、生年月日を含めない
+func TestPublicUser(t *testing.T) {
+	userJson, err := json.Marshal(dtos.NewPublicUser(prepareTestUser()))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 1,
+		"name": "gopher",
+		"username": "gopher",
+		"bio": "",
+		"location": "",
+		"website": "",
+		"avatar_url": "",
+		"header_url": "",
+		"created_at": "2024-09-01T00:00:00Z"
+	}`, string(userJson))
+}
+
+// 本人向けのviewにはemail、emailの認証状態と生年月日を含めるが、パスワードは含めない
+func TestSelfUser(t *testing.T) {
+	userJson, err := json.Marshal(dtos.NewSelfUser(prepareTestUser()))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 1,
+		"name": "gopher",
+		"username": "gopher",
+		"bio": "",
+		"location": "",
+		"website": "",
+		"avatar_url": "",
+		"header_url": "",
+		"created_at": "2024-09-01T00:00:00Z",
+		"email": "gopher@example.com",
+		"email_verified": false,
+		"dob": "2000-01-01T00:00:00Z"
+	}`, string(userJson))
+
+	// メール認証済みの場合
+	user := prepareTestUser()
+	verifiedAt := time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC)
+	user.EmailVerifiedAt = &verifiedAt
+	assert.True(t, dtos.NewSelfUser(user).EmailVerified)
+}
+
+// 管理者向けのviewにもパスワードは含めない
+func TestAdminUser(t *testing.T) {
+	user := prepareTestUser()
+	userJson, err := json.Marshal(dtos.NewAdminUser(user))
+	assert.NoError(t, err)
+	assert.Contains(t, string(userJson), user.Email)
+	assert.NotContains(t, string(userJson), "password")
+	assert.NotContains(t, string(userJson), user.Password)
+
+	// roleとアカウントの停止状態を含める
+	suspendedAt := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	user.Role = "moderator"
+	user.SuspendedAt = &suspendedAt
+	userJson, err = json.Marshal(dtos.NewAdminUser(user))
+	assert.NoError(t, err)
+	assert.Contains(t, string(userJson), `"role":"moderator"`)
+	assert.Contains(t, string(userJson), `"suspended_at":"2024-10-01T00:00:00Z"`)
+}
+
+// Preloadしていないユーザーはnullのまま
+func TestNilUser(t *testing.T) {
+	assert.Nil(t, dtos.NewPublicUser(nil))
+	assert.Nil(t, dtos.NewSelfUser(nil))
+	assert.Nil(t, dtos.NewAdminUser(nil))
+
+	followerJson, err := json.Marshal(dtos.NewFollowerResponse(&models.Follower{ID: 1, FollowerID: 1, FolloweeID: 2}))
+	assert.NoError(t, err)
+	assert.Contains(t, string(followerJson), `"follower":null`)
+}
@@ -0,0 +1,84 @@
+package middlewares_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/middlewares"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireVerifiedEmailVerifiedUser(t *testing.T) {
+	// email認証済みのユーザーを準備
+	mockUserRepo := &mocks.MockUserRepository{}
+	verifiedAt := time.Now()
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, EmailVerifiedAt: &verifiedAt}, nil)
+	r := setupTestEmailVerificationRouter(mockUserRepo, true)
+
+	// リクエスト実行
+	w := serveAsUser(r, "1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestRequireVerifiedEmailUnverifiedUser(t *testing.T) {
+	// email未認証のユーザーを準備
+	mockUserRepo := &mocks.MockUserRepository{}
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1}, nil)
+	r := setupTestEmailVerificationRouter(mockUserRepo, true)
+
+	// リクエスト実行
+	w := serveAsUser(r, "1")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"error": "email is not verified"}`, w.Body.String())
+}
+
+func TestRequireVerifiedEmailUserNotFound(t *testing.T) {
+	// 存在しないユーザー、DBエラー
+	mockUserRepo := &mocks.MockUserRepository{}
+	mockUserRepo.On("FindUserById", uint(1)).Return(nil, errors.New("user not found"))
+	mockUserRepo.On("FindUserById", uint(2)).Return(nil, errors.New("db error"))
+	r := setupTestEmailVerificationRouter(mockUserRepo, true)
+
+	// リクエスト実行
+	assert.Equal(t, http.StatusUnauthorized, serveAsUser(r, "1").Code)
+	assert.Equal(t, http.StatusInternalServerError, serveAsUser(r, "2").Code)
+}
+
+func TestRequireVerifiedEmailNotRequired(t *testing.T) {
+	// email認証が不要な設定の場合はユーザーを取得しない
+	mockUserRepo := &mocks.MockUserRepository{}
+	r := setupTestEmailVerificationRouter(mockUserRepo, false)
+
+	// リクエスト実行
+	w := serveAsUser(r, "1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockUserRepo.AssertNotCalled(t, "FindUserById", uint(1))
+}
+
+func setupTestEmailVerificationRouter(userRepository *mocks.MockUserRepository, required bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.POST("/test", func(ctx *gin.Context) {
+		// JwtTokenVerifierの代わりにuser_idを設定
+		ctx.Set("user_id", ctx.GetHeader("X-User-Id"))
+		ctx.Next()
+	}, middlewares.RequireVerifiedEmail(userRepository, required), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	return r
+}
+
+func serveAsUser(r *gin.Engine, userId string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodPost, "/test", nil)
+	req.Header.Set("X-User-Id", userId)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
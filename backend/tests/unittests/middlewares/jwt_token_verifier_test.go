@@ -0,0 +1,108 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/middlewares"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJwtTokenVerifierValidToken(t *testing.T) {
+	// 失効リストとginエンジンを準備
+	testRevocationRepo := repositories.NewInMemoryTokenRevocationRepository()
+	r := setupTestRouter(testRevocationRepo)
+
+	// アクセストークンを発行
+	token, err := auth.NewClaim("1").GenerateToken()
+	assert.NoError(t, err)
+
+	// リクエスト実行
+	w := serveWithToken(r, token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Body.String())
+}
+
+func TestJwtTokenVerifierRevokedToken(t *testing.T) {
+	// 失効リストとginエンジンを準備
+	testRevocationRepo := repositories.NewInMemoryTokenRevocationRepository()
+	r := setupTestRouter(testRevocationRepo)
+
+	// アクセストークンを発行して失効させる
+	claim := auth.NewClaim("1")
+	token, err := claim.GenerateToken()
+	assert.NoError(t, err)
+	err = testRevocationRepo.RevokeToken(claim.ID, 1, claim.ExpiresAt.Time)
+	assert.NoError(t, err)
+
+	// リクエスト実行
+	w := serveWithToken(r, token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestJwtTokenVerifierUserTokensRevoked(t *testing.T) {
+	// 失効リストとginエンジンを準備
+	testRevocationRepo := repositories.NewInMemoryTokenRevocationRepository()
+	r := setupTestRouter(testRevocationRepo)
+
+	// アクセストークンを発行してからユーザーの全てのトークンを失効させる
+	token, err := auth.NewClaim("1").GenerateToken()
+	assert.NoError(t, err)
+	now := time.Now()
+	err = testRevocationRepo.RevokeUserTokens(1, now, now.Add(auth.TokenExpiration))
+	assert.NoError(t, err)
+
+	// リクエスト実行
+	w := serveWithToken(r, token)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestJwtTokenVerifierRefreshToken(t *testing.T) {
+	// 失効リストとginエンジンを準備
+	testRevocationRepo := repositories.NewInMemoryTokenRevocationRepository()
+	r := setupTestRouter(testRevocationRepo)
+
+	// リフレッシュトークンはアクセストークンとして使用できない
+	refreshToken, err := auth.NewClaim("1").GenerateRefreshToken()
+	assert.NoError(t, err)
+
+	// リクエスト実行
+	w := serveWithToken(r, refreshToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestJwtTokenVerifierMFAToken(t *testing.T) {
+	// 失効リストとginエンジンを準備
+	testRevocationRepo := repositories.NewInMemoryTokenRevocationRepository()
+	r := setupTestRouter(testRevocationRepo)
+
+	// 2段階認証前のmfaトークンはアクセストークンとして使用できない
+	mfaToken, err := auth.NewClaim("1").GenerateMFAToken()
+	assert.NoError(t, err)
+
+	// リクエスト実行
+	w := serveWithToken(r, mfaToken)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func setupTestRouter(revocationRepository repositories.ITokenRevocationRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/test", middlewares.JwtTokenVerifier(revocationRepository), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString("user_id"))
+	})
+	return r
+}
+
+func serveWithToken(r *gin.Engine, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
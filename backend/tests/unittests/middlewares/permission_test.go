@@ -0,0 +1,70 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/middlewares"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequirePermission(t *testing.T) {
+	testCases := []struct {
+		name string
+		role string
+		code int
+	}{
+		{name: "admin", role: auth.RoleAdmin, code: http.StatusOK},
+		// モデレーターはユーザー一覧を取得できない
+		{name: "moderator", role: auth.RoleModerator, code: http.StatusForbidden},
+		{name: "user", role: auth.RoleUser, code: http.StatusForbidden},
+		// roleを含まない(導入前に発行された)アクセストークン
+		{name: "without role", role: "", code: http.StatusForbidden},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := setupTestPermissionRouter(auth.PermissionReadUsers)
+
+			// roleの権限を含めたアクセストークンを発行
+			claim := auth.NewClaim("1")
+			claim.SetRole(testCase.role)
+			token, err := claim.GenerateToken()
+			assert.NoError(t, err)
+
+			// リクエスト実行
+			w := serveWithToken(r, token)
+			assert.Equal(t, testCase.code, w.Code)
+			if testCase.code == http.StatusForbidden {
+				assert.JSONEq(t, `{"error": "permission denied"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+// JwtTokenVerifierを通していない場合は権限がないものとして扱う
+func TestRequirePermissionWithoutToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/test", middlewares.RequirePermission(auth.PermissionDeleteTweets), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func setupTestPermissionRouter(permission string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.GET("/test", middlewares.JwtTokenVerifier(repositories.NewInMemoryTokenRevocationRepository()), middlewares.RequirePermission(permission), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString("role"))
+	})
+	return r
+}
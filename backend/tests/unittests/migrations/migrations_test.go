@@ -0,0 +1,282 @@
+package migrations_test
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/migrations"
+	"github.com/daiki-kim/tweet-app/backend/pkg/migrate"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// テスト用のSQLiteデータベースを作成
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(models.SQLiteDSN(filepath.Join(t.TempDir(), "migrations.db"))), &gorm.Config{})
+	assert.NoError(t, err)
+	return db
+}
+
+// MySQLとSQLiteで同じバージョンのマイグレーションが用意されているか確認
+func TestDialectsHaveSameMigrations(t *testing.T) {
+	mysqlMigrations, err := migrations.Load("mysql")
+	assert.NoError(t, err)
+	sqliteMigrations, err := migrations.Load("sqlite")
+	assert.NoError(t, err)
+
+	assert.Equal(t, len(mysqlMigrations), len(sqliteMigrations))
+	for i := range mysqlMigrations {
+		assert.Equal(t, mysqlMigrations[i].Version, sqliteMigrations[i].Version)
+		assert.Equal(t, mysqlMigrations[i].Name, sqliteMigrations[i].Name)
+	}
+}
+
+// 方言ごとのディレクトリに同じ内容のファイルがないことを確認(同じSQLはcommon/に置く)
+func TestDialectMigrationsDiffer(t *testing.T) {
+	entries, err := fs.ReadDir(migrations.FS, "mysql")
+	assert.NoError(t, err)
+
+	for _, entry := range entries {
+		mysqlSQL, err := fs.ReadFile(migrations.FS, path.Join("mysql", entry.Name()))
+		assert.NoError(t, err)
+		sqliteSQL, err := fs.ReadFile(migrations.FS, path.Join("sqlite", entry.Name()))
+		if err != nil {
+			continue
+		}
+		assert.NotEqual(t, string(mysqlSQL), string(sqliteSQL), "%s is the same in mysql and sqlite, move it to common", entry.Name())
+	}
+}
+
+// 複数のディレクトリに同じバージョンのupまたはdownがある場合はエラーになることを確認
+func TestLoadDuplicateMigration(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common/0001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id INTEGER);")},
+		"common/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"sqlite/0001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	}
+
+	_, err := migrate.Load(fsys, "common", "sqlite")
+	assert.EqualError(t, err, "duplicate migration file: 0001_create_users.down.sql")
+
+	migrationList, err := migrate.Load(fsys, "common")
+	assert.NoError(t, err)
+	assert.Len(t, migrationList, 1)
+}
+
+// マイグレーション後のSQLiteで外部キー制約(ON DELETE CASCADE)が有効になっているか確認
+func TestMigrationsEnforceForeignKeys(t *testing.T) {
+	db := openTestDB(t)
+	assert.NoError(t, migrations.Up(db))
+
+	user := &models.User{Name: "gopher", Username: "gopher", Email: "gopher@example.com", Password: "testpassword"}
+	assert.NoError(t, db.Create(user).Error)
+	tweet := &models.Tweet{UserID: user.ID, Type: models.Text, Content: "hello"}
+	assert.NoError(t, db.Create(tweet).Error)
+
+	// 存在しないユーザーのツイートは作成できない
+	assert.Error(t, db.Create(&models.Tweet{UserID: 9999, Type: models.Text, Content: "orphan"}).Error)
+
+	// ユーザーを削除するとツイートも削除される
+	assert.NoError(t, db.Delete(user).Error)
+	var count int64
+	assert.NoError(t, db.Unscoped().Model(&models.Tweet{}).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+// マイグレーション後に全てのモデルのテーブルとカラムが存在するか確認(モデルとスキーマのずれを検出)
+func TestMigrationsMatchModels(t *testing.T) {
+	db := openTestDB(t)
+	assert.NoError(t, migrations.Up(db))
+
+	for _, model := range models.GetModels() {
+		statement := &gorm.Statement{DB: db}
+		assert.NoError(t, statement.Parse(model))
+		modelSchema := statement.Schema
+		assert.True(t, db.Migrator().HasTable(modelSchema.Table), "table %s does not exist", modelSchema.Table)
+
+		for _, field := range modelSchema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			assert.True(t, db.Migrator().HasColumn(model, field.DBName), "column %s.%s does not exist", modelSchema.Table, field.DBName)
+		}
+	}
+}
+
+// up, down, statusの一連の動作を確認
+func TestMigratorUpDownStatus(t *testing.T) {
+	db := openTestDB(t)
+	migrator, err := migrations.NewMigrator(db)
+	assert.NoError(t, err)
+
+	// 1つだけ適用
+	applied, err := migrator.Up(1)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 1)
+	assert.True(t, db.Migrator().HasTable("users"))
+	assert.False(t, db.Migrator().HasTable("followers"))
+
+	// 残りを全て適用
+	applied, err = migrator.Up(0)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, applied)
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.False(t, status.Dirty)
+	}
+
+	// 適用済みの場合は何もしない
+	applied, err = migrator.Up(0)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// 最後のマイグレーションを戻す
+	last := statuses[len(statuses)-1]
+	rolledBack, err := migrator.Down(1)
+	assert.NoError(t, err)
+	assert.Len(t, rolledBack, 1)
+	assert.Equal(t, last.Version, rolledBack[0].Version)
+
+	// 全て戻す
+	_, err = migrator.Down(0)
+	assert.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable("users"))
+
+	statuses, err = migrator.Status()
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.False(t, status.Applied)
+	}
+}
+
+// 途中で失敗したマイグレーションがある場合は実行しないことを確認
+func TestMigratorDirty(t *testing.T) {
+	db := openTestDB(t)
+	migrator, err := migrations.NewMigrator(db)
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(1)
+	assert.NoError(t, err)
+	result := db.Model(&migrate.SchemaMigration{}).Where("version = ?", 1).Update("dirty", true)
+	assert.NoError(t, result.Error)
+
+	_, err = migrator.Up(0)
+	assert.EqualError(t, err, "database is dirty at version 1, fix the schema and the schema_migrations row manually")
+}
+
+// 失敗したマイグレーションはdirtyとして記録されることを確認
+func TestMigratorFailedMigration(t *testing.T) {
+	db := openTestDB(t)
+	migrator := migrate.New(db, []*migrate.Migration{
+		{Version: 1, Name: "broken", Up: "CREATE TABLE broken (;", Down: "DROP TABLE broken;"},
+	})
+
+	_, err := migrator.Up(0)
+	assert.Error(t, err)
+
+	statuses, err := migrator.Status()
+	assert.NoError(t, err)
+	assert.True(t, statuses[0].Dirty)
+}
+
+// tweetsテーブルを作り直す0009でlikes、feed_tweetsの行が外部キーのカスケードで削除されないことを確認
+func TestTweetSharesMigrationKeepsReferences(t *testing.T) {
+	db := openTestDB(t)
+	migrator, err := migrations.NewMigrator(db)
+	assert.NoError(t, err)
+
+	_, err = migrator.Up(8)
+	assert.NoError(t, err)
+	for _, statement := range []string{
+		"INSERT INTO users (id, name, email, password) VALUES (1, 'gopher', 'gopher@example.com', 'testpassword')",
+		"INSERT INTO tweets (id, user_id, type, content) VALUES (1, 1, 'text', 'hello')",
+		"INSERT INTO feeds (id, user_id) VALUES (1, 1)",
+		"INSERT INTO feed_tweets (tweet_id, feed_id) VALUES (1, 1)",
+		"INSERT INTO likes (user_id, tweet_id) VALUES (1, 1)",
+	} {
+		assert.NoError(t, db.Exec(statement).Error)
+	}
+
+	countRows := func(table string) int64 {
+		var count int64
+		assert.NoError(t, db.Table(table).Count(&count).Error)
+		return count
+	}
+
+	_, err = migrator.Up(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), countRows("likes"))
+	assert.Equal(t, int64(1), countRows("feed_tweets"))
+
+	// リツイートを戻すとリツイートへのいいねだけが削除される
+	assert.NoError(t, db.Exec("INSERT INTO tweets (id, user_id, type, content, retweeted_tweet_id) VALUES (2, 1, 'retweet', '', 1)").Error)
+	assert.NoError(t, db.Exec("INSERT INTO likes (user_id, tweet_id) VALUES (1, 2)").Error)
+
+	_, err = migrator.Down(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), countRows("tweets"))
+	assert.Equal(t, int64(1), countRows("likes"))
+	assert.Equal(t, int64(1), countRows("feed_tweets"))
+
+	// マイグレーション後も外部キー制約は有効
+	var foreignKeys int
+	assert.NoError(t, db.Raw("PRAGMA foreign_keys").Scan(&foreignKeys).Error)
+	assert.Equal(t, 1, foreignKeys)
+}
+
+// 外部キーを無効にしたマイグレーションで参照先のない行が残る場合は失敗することを確認
+func TestMigratorForeignKeyCheck(t *testing.T) {
+	db := openTestDB(t)
+	migrator := migrate.New(db, []*migrate.Migration{
+		{
+			Version: 1,
+			Name:    "orphan",
+			Up: migrate.ForeignKeysOffDirective + `
+CREATE TABLE parents (id INTEGER PRIMARY KEY);
+CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER NOT NULL REFERENCES parents(id));
+INSERT INTO children (id, parent_id) VALUES (1, 1);`,
+			Down: "DROP TABLE children;\nDROP TABLE parents;",
+		},
+	})
+
+	_, err := migrator.Up(0)
+	assert.EqualError(t, err, "migration 1_orphan failed: foreign key violation in children referencing parents")
+	assert.False(t, db.Migrator().HasTable("children"))
+}
+
+// migrateサブコマンドの出力を確認
+func TestRunCommand(t *testing.T) {
+	db := openTestDB(t)
+	out := &bytes.Buffer{}
+
+	err := migrations.RunCommand(db, []string{"up", "2"}, out)
+	assert.NoError(t, err)
+	assert.Equal(t, "applied 0001_create_users\napplied 0002_create_followers\n", out.String())
+
+	out.Reset()
+	err = migrations.RunCommand(db, []string{"status"}, out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "0001_create_users\tapplied at "+time.Now().Format("2006-01-02"))
+	assert.Contains(t, out.String(), "0003_create_tweets\tpending")
+
+	out.Reset()
+	err = migrations.RunCommand(db, []string{"down"}, out)
+	assert.NoError(t, err)
+	assert.Equal(t, "rolled back 0002_create_followers\n", out.String())
+
+	err = migrations.RunCommand(db, []string{"down", "zero"}, out)
+	assert.EqualError(t, err, "steps must be a positive number")
+
+	err = migrations.RunCommand(db, []string{"sideways"}, out)
+	assert.EqualError(t, err, "unknown migrate command: sideways")
+}
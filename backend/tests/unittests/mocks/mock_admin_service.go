@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) GetUsers(page *pagination.Page) (*pagination.List[*dtos.AdminUser], error) {
+	args := m.Called(page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*dtos.AdminUser]), args.Error(1)
+}
+
+func (m *MockAdminService) SuspendUser(actorId, userId uint) (*dtos.AdminUser, error) {
+	args := m.Called(actorId, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*dtos.AdminUser), args.Error(1)
+}
+
+func (m *MockAdminService) UnsuspendUser(actorId, userId uint) (*dtos.AdminUser, error) {
+	args := m.Called(actorId, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*dtos.AdminUser), args.Error(1)
+}
+
+func (m *MockAdminService) DeleteTweet(actorId, id uint) error {
+	args := m.Called(actorId, id)
+	return args.Error(0)
+}
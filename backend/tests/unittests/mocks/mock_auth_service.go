@@ -9,28 +9,85 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) SignupUsingOAuth(name, email, dobString string) error {
-	args := m.Called(name, email, dobString)
-	return args.Error(0)
+func (m *MockAuthService) SignupUsingOAuth(ticket, name, username, dobString string) (*services.LoginResponse, error) {
+	args := m.Called(ticket, name, username, dobString)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.LoginResponse), args.Error(1)
 }
 
-func (m *MockAuthService) Signup(name, email, dobString, password string) error {
-	args := m.Called(name, email, dobString, password)
+func (m *MockAuthService) Signup(name, username, email, dobString, password string) error {
+	args := m.Called(name, username, email, dobString, password)
 	return args.Error(0)
 }
 
-func (m *MockAuthService) LoginUsingOAuth(email string) (*services.LoginResponse, error) {
-	args := m.Called(email)
+func (m *MockAuthService) LoginUsingOAuth(provider, subject, email string) (*services.LoginResponse, error) {
+	args := m.Called(provider, subject, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.LoginResponse), args.Error(1)
+}
+
+func (m *MockAuthService) Login(email, password, ipAddress string) (*services.LoginResponse, error) {
+	args := m.Called(email, password, ipAddress)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*services.LoginResponse), args.Error(1)
 }
 
-func (m *MockAuthService) Login(email, password string) (*services.LoginResponse, error) {
-	args := m.Called(email, password)
+func (m *MockAuthService) LoginWithTwoFactor(mfaToken, code, ipAddress string) (*services.LoginResponse, error) {
+	args := m.Called(mfaToken, code, ipAddress)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*services.LoginResponse), args.Error(1)
 }
+
+func (m *MockAuthService) RefreshToken(refreshToken string) (*services.LoginResponse, error) {
+	args := m.Called(refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.LoginResponse), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(refreshToken, accessToken string) error {
+	args := m.Called(refreshToken, accessToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) LogoutAll(userId uint) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ChangePassword(userId uint, currentPassword, newPassword string) (*services.LoginResponse, error) {
+	args := m.Called(userId, currentPassword, newPassword)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.LoginResponse), args.Error(1)
+}
+
+func (m *MockAuthService) ForgotPassword(email string) error {
+	args := m.Called(email)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResetPassword(token, newPassword string) error {
+	args := m.Called(token, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) VerifyEmail(token string) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ResendVerificationEmail(userId uint) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockEmailVerificationTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockEmailVerificationTokenRepository) CreateEmailVerificationToken(verificationToken *models.EmailVerificationToken) error {
+	args := m.Called(verificationToken)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationTokenRepository) FindEmailVerificationToken(tokenHash string) (*models.EmailVerificationToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmailVerificationToken), args.Error(1)
+}
+
+func (m *MockEmailVerificationTokenRepository) MarkEmailVerificationTokenUsed(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationTokenRepository) CountEmailVerificationTokensSince(userId uint, since time.Time) (int64, error) {
+	args := m.Called(userId, since)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFeedRepository struct {
+	mock.Mock
+}
+
+func (m *MockFeedRepository) GetOrCreateFeed(userId uint) (*models.Feed, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Feed), args.Error(1)
+}
+
+func (m *MockFeedRepository) AddTweetToFeeds(tweetId uint, userIds []uint) error {
+	args := m.Called(tweetId, userIds)
+	return args.Error(0)
+}
+
+func (m *MockFeedRepository) AddUserTweetsToFeed(userId, authorId uint, limit int) error {
+	args := m.Called(userId, authorId, limit)
+	return args.Error(0)
+}
+
+func (m *MockFeedRepository) RemoveUserTweetsFromFeed(userId, authorId uint) error {
+	args := m.Called(userId, authorId)
+	return args.Error(0)
+}
+
+func (m *MockFeedRepository) GetFeedTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	args := m.Called(userId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Tweet]), args.Error(1)
+}
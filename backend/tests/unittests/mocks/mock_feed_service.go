@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFeedService struct {
+	mock.Mock
+}
+
+func (m *MockFeedService) DistributeTweet(tweet *models.Tweet) error {
+	args := m.Called(tweet)
+	return args.Error(0)
+}
+
+func (m *MockFeedService) AddFolloweeTweets(userId, followeeId uint) error {
+	args := m.Called(userId, followeeId)
+	return args.Error(0)
+}
+
+func (m *MockFeedService) RemoveFolloweeTweets(userId, followeeId uint) error {
+	args := m.Called(userId, followeeId)
+	return args.Error(0)
+}
+
+func (m *MockFeedService) GetTimeline(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	args := m.Called(userId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Tweet]), args.Error(1)
+}
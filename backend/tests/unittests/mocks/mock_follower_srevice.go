@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -26,22 +27,22 @@ func (m *MockFollowerService) GetFollower(id uint) (*models.Follower, error) {
 	return args.Get(0).(*models.Follower), args.Error(1)
 }
 
-func (m *MockFollowerService) GetFollows(followerId uint) ([]*models.Follower, error) {
-	args := m.Called(followerId)
+func (m *MockFollowerService) GetFollows(followerId uint, page *pagination.Page) (*pagination.List[*models.Follower], error) {
+	args := m.Called(followerId, page)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 
-	return args.Get(0).([]*models.Follower), args.Error(1)
+	return args.Get(0).(*pagination.List[*models.Follower]), args.Error(1)
 }
 
-func (m *MockFollowerService) GetFollowers(followeeId uint) ([]*models.Follower, error) {
-	args := m.Called(followeeId)
+func (m *MockFollowerService) GetFollowers(followeeId uint, page *pagination.Page) (*pagination.List[*models.Follower], error) {
+	args := m.Called(followeeId, page)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 
-	return args.Get(0).([]*models.Follower), args.Error(1)
+	return args.Get(0).(*pagination.List[*models.Follower]), args.Error(1)
 }
 
 func (m *MockFollowerService) DeleteFollower(id uint, user_id uint) error {
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdentityService struct {
+	mock.Mock
+}
+
+func (m *MockIdentityService) GetIdentities(userId uint) ([]*models.UserIdentity, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.UserIdentity), args.Error(1)
+}
+
+func (m *MockIdentityService) LinkIdentity(userId uint, ticket string) (*models.UserIdentity, error) {
+	args := m.Called(userId, ticket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserIdentity), args.Error(1)
+}
+
+func (m *MockIdentityService) UnlinkIdentity(userId uint, provider string) error {
+	args := m.Called(userId, provider)
+	return args.Error(0)
+}
@@ -0,0 +1,61 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLikeRepository struct {
+	mock.Mock
+}
+
+func (m *MockLikeRepository) CreateLike(like *models.Like) (*models.Like, error) {
+	args := m.Called(like)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Like), args.Error(1)
+}
+
+func (m *MockLikeRepository) DeleteLike(userId, tweetId uint) error {
+	args := m.Called(userId, tweetId)
+	return args.Error(0)
+}
+
+func (m *MockLikeRepository) GetTweetLikes(tweetId uint, page *pagination.Page) (*pagination.List[*models.Like], error) {
+	args := m.Called(tweetId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Like]), args.Error(1)
+}
+
+func (m *MockLikeRepository) GetUserLikes(userId uint, page *pagination.Page) (*pagination.List[*models.Like], error) {
+	args := m.Called(userId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Like]), args.Error(1)
+}
+
+func (m *MockLikeRepository) CountLikes(tweetIds []uint) (map[uint]int64, error) {
+	args := m.Called(tweetIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[uint]int64), args.Error(1)
+}
+
+func (m *MockLikeRepository) GetLikedTweetIds(userId uint, tweetIds []uint) (map[uint]bool, error) {
+	args := m.Called(userId, tweetIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[uint]bool), args.Error(1)
+}
@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLikeService struct {
+	mock.Mock
+}
+
+func (m *MockLikeService) Like(userId, tweetId uint) (*models.Like, error) {
+	args := m.Called(userId, tweetId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Like), args.Error(1)
+}
+
+func (m *MockLikeService) Unlike(userId, tweetId uint) error {
+	args := m.Called(userId, tweetId)
+	return args.Error(0)
+}
+
+func (m *MockLikeService) GetLikers(tweetId uint, page *pagination.Page) (*pagination.List[*models.Like], error) {
+	args := m.Called(tweetId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Like]), args.Error(1)
+}
+
+func (m *MockLikeService) GetUserLikes(userId uint, page *pagination.Page) (*pagination.List[*models.Like], error) {
+	args := m.Called(userId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Like]), args.Error(1)
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMediaRepository struct {
+	mock.Mock
+}
+
+func (m *MockMediaRepository) CreateMedia(media *models.Media) (*models.Media, error) {
+	args := m.Called(media)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetMediaByIds(mediaIds []uint) ([]*models.Media, error) {
+	args := m.Called(mediaIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) GetTweetMedia(tweetId uint) ([]*models.Media, error) {
+	args := m.Called(tweetId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) DeleteMedia(mediaIds []uint) error {
+	args := m.Called(mediaIds)
+	return args.Error(0)
+}
@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"io"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMediaService struct {
+	mock.Mock
+}
+
+func (m *MockMediaService) UploadMedia(userId uint, file io.Reader, size int64) (*models.Media, error) {
+	args := m.Called(userId, file, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Media), args.Error(1)
+}
+
+func (m *MockMediaService) GetAttachableMedia(userId uint, tweetType models.TweetType, mediaIds []uint) ([]*models.Media, error) {
+	args := m.Called(userId, tweetType, mediaIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Media), args.Error(1)
+}
+
+func (m *MockMediaService) GetProfileImage(userId, mediaId uint) (*models.Media, error) {
+	args := m.Called(userId, mediaId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Media), args.Error(1)
+}
+
+func (m *MockMediaService) DeleteTweetMedia(tweetId uint) error {
+	args := m.Called(tweetId)
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPasswordResetTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordResetTokenRepository) CreatePasswordResetToken(resetToken *models.PasswordResetToken) error {
+	args := m.Called(resetToken)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetTokenRepository) FindPasswordResetToken(tokenHash string) (*models.PasswordResetToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockPasswordResetTokenRepository) MarkPasswordResetTokenUsed(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPasswordResetTokenRepository) InvalidateUserPasswordResetTokens(userId uint) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
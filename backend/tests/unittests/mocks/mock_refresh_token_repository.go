@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) CreateRefreshToken(refreshToken *models.RefreshToken) error {
+	args := m.Called(refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) FindRefreshToken(tokenId string) (*models.RefreshToken, error) {
+	args := m.Called(tokenId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) MarkRefreshTokenUsed(tokenId string) error {
+	args := m.Called(tokenId)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(familyId string) error {
+	args := m.Called(familyId)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) RevokeUserRefreshTokens(userId uint) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
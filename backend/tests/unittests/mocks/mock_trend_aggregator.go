@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTrendAggregator struct {
+	mock.Mock
+}
+
+func (m *MockTrendAggregator) Record(tweet *models.Tweet) {
+	m.Called(tweet)
+}
+
+func (m *MockTrendAggregator) Flush() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockTrendAggregator) Purge() error {
+	args := m.Called()
+	return args.Error(0)
+}
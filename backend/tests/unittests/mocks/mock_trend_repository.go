@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTrendRepository struct {
+	mock.Mock
+}
+
+func (m *MockTrendRepository) AddCounts(slotStart time.Time, counts map[string]int64) error {
+	args := m.Called(slotStart, counts)
+	return args.Error(0)
+}
+
+func (m *MockTrendRepository) SumCounts(from, to time.Time) (map[string]int64, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+func (m *MockTrendRepository) DeleteBucketsBefore(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTrendService struct {
+	mock.Mock
+}
+
+func (m *MockTrendService) GetTrends(window string, limit int) ([]*dtos.Trend, error) {
+	args := m.Called(window, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*dtos.Trend), args.Error(1)
+}
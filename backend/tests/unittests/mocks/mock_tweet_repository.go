@@ -0,0 +1,120 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTweetRepository struct {
+	mock.Mock
+}
+
+func (m *MockTweetRepository) CreateTweet(tweet *models.Tweet) (*models.Tweet, error) {
+	args := m.Called(tweet)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetRepository) GetTweet(id uint) (*models.Tweet, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetRepository) GetUserTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	args := m.Called(userId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Tweet]), args.Error(1)
+}
+
+func (m *MockTweetRepository) UpdateTweet(updateTweet *models.Tweet) (*models.Tweet, error) {
+	args := m.Called(updateTweet)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetRepository) DeleteTweet(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTweetRepository) GetReplyPath(id uint) ([]*models.Tweet, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetRepository) GetReplies(tweetId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	args := m.Called(tweetId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Tweet]), args.Error(1)
+}
+
+func (m *MockTweetRepository) GetRepliesOf(tweetIds []uint, limit int) ([]*models.Tweet, error) {
+	args := m.Called(tweetIds, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetRepository) DeleteRetweet(userId, tweetId uint) error {
+	args := m.Called(userId, tweetId)
+	return args.Error(0)
+}
+
+func (m *MockTweetRepository) CountRetweets(tweetIds []uint) (map[uint]int64, error) {
+	args := m.Called(tweetIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[uint]int64), args.Error(1)
+}
+
+func (m *MockTweetRepository) CountQuotes(tweetIds []uint) (map[uint]int64, error) {
+	args := m.Called(tweetIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(map[uint]int64), args.Error(1)
+}
+
+func (m *MockTweetRepository) GetHashtagTweets(name string, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	args := m.Called(name, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Tweet]), args.Error(1)
+}
+
+func (m *MockTweetRepository) GetMentionTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	args := m.Called(userId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Tweet]), args.Error(1)
+}
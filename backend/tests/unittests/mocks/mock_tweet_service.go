@@ -0,0 +1,117 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTweetService struct {
+	mock.Mock
+}
+
+func (m *MockTweetService) CreateTweet(userId uint, tweetTypeString string, content string, mediaIds []uint) (*models.Tweet, error) {
+	args := m.Called(userId, tweetTypeString, content, mediaIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetService) GetTweet(id, userId uint) (*models.Tweet, error) {
+	args := m.Called(id, userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetService) GetUserTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	args := m.Called(userId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Tweet]), args.Error(1)
+}
+
+func (m *MockTweetService) UpdateTweet(id, userId uint, inputTweet *dtos.UpdateTweetInput) (*models.Tweet, error) {
+	args := m.Called(id, userId, inputTweet)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetService) DeleteTweet(id, userId uint) error {
+	args := m.Called(id, userId)
+	return args.Error(0)
+}
+
+func (m *MockTweetService) DeleteAnyTweet(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockTweetService) ReplyTweet(userId, tweetId uint, tweetTypeString string, content string) (*models.Tweet, error) {
+	args := m.Called(userId, tweetId, tweetTypeString, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetService) GetThread(id uint, depth int, page *pagination.Page) (*dtos.TweetThread, error) {
+	args := m.Called(id, depth, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*dtos.TweetThread), args.Error(1)
+}
+
+func (m *MockTweetService) Retweet(userId, tweetId uint) (*models.Tweet, error) {
+	args := m.Called(userId, tweetId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetService) Unretweet(userId, tweetId uint) error {
+	args := m.Called(userId, tweetId)
+	return args.Error(0)
+}
+
+func (m *MockTweetService) QuoteTweet(userId, tweetId uint, content string) (*models.Tweet, error) {
+	args := m.Called(userId, tweetId, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*models.Tweet), args.Error(1)
+}
+
+func (m *MockTweetService) GetHashtagTweets(tag string, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	args := m.Called(tag, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Tweet]), args.Error(1)
+}
+
+func (m *MockTweetService) GetMentionTweets(userId uint, page *pagination.Page) (*pagination.List[*models.Tweet], error) {
+	args := m.Called(userId, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*models.Tweet]), args.Error(1)
+}
@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) FindUserTOTP(userId uint) (*models.UserTOTP, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserTOTP), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) SaveUserTOTP(userTOTP *models.UserTOTP) error {
+	args := m.Called(userTOTP)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) EnableUserTOTP(userId uint, step int64, recoveryCodeHashes []string) error {
+	args := m.Called(userId, step, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UpdateTOTPLastUsedStep(userId uint, step int64) error {
+	args := m.Called(userId, step)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(userId uint, codeHash string) error {
+	args := m.Called(userId, codeHash)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) DeleteUserTOTP(userId uint) error {
+	args := m.Called(userId)
+	return args.Error(0)
+}
@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTwoFactorService struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorService) EnrollTOTP(userId uint) (*services.TOTPEnrollment, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockTwoFactorService) ConfirmTOTP(userId uint, code string) ([]string, error) {
+	args := m.Called(userId, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockTwoFactorService) DisableTOTP(userId uint, code string) error {
+	args := m.Called(userId, code)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorService) IsEnabled(userId uint) (bool, error) {
+	args := m.Called(userId)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTwoFactorService) VerifyCode(userId uint, code string) error {
+	args := m.Called(userId, code)
+	return args.Error(0)
+}
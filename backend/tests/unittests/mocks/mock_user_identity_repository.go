@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserIdentityRepository) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	args := m.Called(user, identity)
+	return args.Error(0)
+}
+
+func (m *MockUserIdentityRepository) CreateUserIdentity(identity *models.UserIdentity) error {
+	args := m.Called(identity)
+	return args.Error(0)
+}
+
+func (m *MockUserIdentityRepository) FindUserIdentity(provider, subject string) (*models.UserIdentity, error) {
+	args := m.Called(provider, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserIdentity), args.Error(1)
+}
+
+func (m *MockUserIdentityRepository) FindUserIdentitiesByUserId(userId uint) ([]*models.UserIdentity, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.UserIdentity), args.Error(1)
+}
+
+func (m *MockUserIdentityRepository) UpdateUserIdentityEmail(id uint, email string) error {
+	args := m.Called(id, email)
+	return args.Error(0)
+}
+
+func (m *MockUserIdentityRepository) DeleteUserIdentity(userId uint, provider string) error {
+	args := m.Called(userId, provider)
+	return args.Error(0)
+}
@@ -1,7 +1,11 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -21,3 +25,74 @@ func (m *MockUserRepository) FindUserByEmail(email string) (*models.User, error)
 	}
 	return args.Get(0).(*models.User), args.Error(1)
 }
+
+func (m *MockUserRepository) FindUserByUsername(username string) (*models.User, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindUsersByUsernames(usernames []string) ([]*models.User, error) {
+	args := m.Called(usernames)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindUserById(id uint) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) SearchUsers(q string, page *pagination.Page) (*pagination.List[*models.User], error) {
+	args := m.Called(q, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pagination.List[*models.User]), args.Error(1)
+}
+
+func (m *MockUserRepository) FindUsers(page *pagination.Page) (*pagination.List[*models.User], error) {
+	args := m.Called(page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pagination.List[*models.User]), args.Error(1)
+}
+
+func (m *MockUserRepository) CountUserStats(userIds []uint) (map[uint]*repositories.UserStats, error) {
+	args := m.Called(userIds)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uint]*repositories.UserStats), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUserProfile(user *models.User) (*models.User, error) {
+	args := m.Called(user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdatePassword(userId uint, hashedPassword string) error {
+	args := m.Called(userId, hashedPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(userId uint, email string) error {
+	args := m.Called(userId, email)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateSuspendedAt(userId uint, suspendedAt *time.Time) error {
+	args := m.Called(userId, suspendedAt)
+	return args.Error(0)
+}
@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) GetUserProfile(id uint) (*dtos.UserProfile, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*dtos.UserProfile), args.Error(1)
+}
+
+func (m *MockUserService) GetUserProfileByUsername(username string) (*dtos.UserProfile, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*dtos.UserProfile), args.Error(1)
+}
+
+func (m *MockUserService) SearchUsers(q string, page *pagination.Page) (*pagination.List[*dtos.UserProfile], error) {
+	args := m.Called(q, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*pagination.List[*dtos.UserProfile]), args.Error(1)
+}
+
+func (m *MockUserService) GetMe(userId uint) (*dtos.SelfUser, error) {
+	args := m.Called(userId)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.SelfUser), args.Error(1)
+}
+
+func (m *MockUserService) UpdateMe(userId uint, input *dtos.UpdateProfileInput) (*dtos.SelfUser, error) {
+	args := m.Called(userId, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dtos.SelfUser), args.Error(1)
+}
@@ -0,0 +1,36 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// 全てのTweetTypeがValueとScanで元に戻るか確認
+func TestTweetTypeRoundTrip(t *testing.T) {
+	for _, tweetType := range []models.TweetType{models.Text, models.Image, models.Video, models.Retweet, models.Quote} {
+		value, err := tweetType.Value()
+		assert.NoError(t, err)
+
+		var scanned models.TweetType
+		assert.NoError(t, scanned.Scan(value))
+		assert.Equal(t, tweetType, scanned)
+
+		var scannedBytes models.TweetType
+		assert.NoError(t, scannedBytes.Scan([]byte(value.(string))))
+		assert.Equal(t, tweetType, scannedBytes)
+	}
+
+	_, err := models.TweetType("unknown").Value()
+	assert.Error(t, err)
+}
+
+// retweetとquoteはユーザーの入力からは作成できない
+func TestStr2TweetTypeRejectsShareTypes(t *testing.T) {
+	_, err := models.Str2TweetType("retweet")
+	assert.Error(t, err)
+
+	_, err = models.Str2TweetType("quote")
+	assert.Error(t, err)
+}
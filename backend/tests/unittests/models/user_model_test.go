@@ -0,0 +1,45 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Userをそのまま、またはTweetに含めてJSONにしてもパスワード、email、生年月日、roleが含まれないか確認
+func TestUserJSONHidesSensitiveFields(t *testing.T) {
+	user := &models.User{
+		ID:        1,
+		Name:      "gopher",
+		Username:  "gopher",
+		Email:     "gopher@example.com",
+		Password:  "$2a$10$hashedpassword",
+		Role:      "admin",
+		Dob:       time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	userJson, err := json.Marshal(user)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 1,
+		"name": "gopher",
+		"username": "gopher",
+		"bio": "",
+		"location": "",
+		"website": "",
+		"avatar_media_id": null,
+		"header_media_id": null,
+		"created_at": "2024-09-01T00:00:00Z"
+	}`, string(userJson))
+
+	tweetJson, err := json.Marshal(&models.Tweet{ID: 1, UserID: 1, Type: models.Text, Content: "hello", User: user})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(tweetJson), "password")
+	assert.NotContains(t, string(tweetJson), user.Password)
+	assert.NotContains(t, string(tweetJson), user.Email)
+	assert.NotContains(t, string(tweetJson), "dob")
+}
@@ -0,0 +1,84 @@
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationTokenTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestEmailVerificationTokenTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailVerificationTokenTestSuite))
+}
+
+func (suite *EmailVerificationTokenTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *EmailVerificationTokenTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *EmailVerificationTokenTestSuite) TestEmailVerificationTokenRepository() {
+	expiresAt := time.Now().Add(time.Hour)
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testVerificationTokenRepository := repositories.NewEmailVerificationTokenRepository(models.DB)
+
+	user := &models.User{Name: "testuser", Username: "testuser", Email: "test@example.com", Password: "testpassword"}
+	suite.Nil(testUserRepository.CreateUser(user))
+	suite.False(user.EmailVerified())
+
+	// create tokens
+	suite.Nil(testVerificationTokenRepository.CreateEmailVerificationToken(&models.EmailVerificationToken{
+		UserID: user.ID, Email: user.Email, TokenHash: "hash1", ExpiresAt: expiresAt,
+	}))
+	suite.Nil(testVerificationTokenRepository.CreateEmailVerificationToken(&models.EmailVerificationToken{
+		UserID: user.ID, Email: user.Email, TokenHash: "hash2", ExpiresAt: expiresAt,
+	}))
+
+	// count tokens created after since
+	count, err := testVerificationTokenRepository.CountEmailVerificationTokensSince(user.ID, time.Now().Add(-time.Minute))
+	suite.Nil(err)
+	suite.Equal(int64(2), count)
+	count, err = testVerificationTokenRepository.CountEmailVerificationTokensSince(user.ID, time.Now().Add(time.Minute))
+	suite.Nil(err)
+	suite.Equal(int64(0), count)
+
+	// find token by hash
+	verificationToken, err := testVerificationTokenRepository.FindEmailVerificationToken("hash1")
+	suite.Nil(err)
+	suite.Equal(user.ID, verificationToken.UserID)
+	suite.Equal(user.Email, verificationToken.Email)
+	suite.Nil(verificationToken.UsedAt)
+
+	_, err = testVerificationTokenRepository.FindEmailVerificationToken("unknown")
+	suite.Equal("email verification token not found", err.Error())
+
+	// mark used only once
+	suite.Nil(testVerificationTokenRepository.MarkEmailVerificationTokenUsed(verificationToken.ID))
+	err = testVerificationTokenRepository.MarkEmailVerificationTokenUsed(verificationToken.ID)
+	suite.Equal("email verification token already used", err.Error())
+
+	// email is not verified when it has been changed
+	suite.Equal("user not found", testUserRepository.MarkEmailVerified(user.ID, "old@example.com").Error())
+
+	// mark email verified
+	suite.Nil(testUserRepository.MarkEmailVerified(user.ID, user.Email))
+	verifiedUser, err := testUserRepository.FindUserById(user.ID)
+	suite.Nil(err)
+	suite.True(verifiedUser.EmailVerified())
+}
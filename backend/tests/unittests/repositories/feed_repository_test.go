@@ -0,0 +1,108 @@
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type FeedTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestFeedTestSuite(t *testing.T) {
+	suite.Run(t, new(FeedTestSuite))
+}
+
+func (suite *FeedTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *FeedTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *FeedTestSuite) TestFeedRepository() {
+	// prepare test repository
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testTweetRepository := repositories.NewTweetRepository(models.DB)
+	testFeedRepository := repositories.NewFeedRepository(models.DB)
+
+	// create users
+	testuser1 := &models.User{
+		Name:     "testuser1",
+		Username: "testuser1",
+		Email:    "test1@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	testuser2 := &models.User{
+		Name:     "testuser2",
+		Username: "testuser2",
+		Email:    "test2@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	err := testUserRepository.CreateUser(testuser1)
+	suite.Nil(err)
+	err = testUserRepository.CreateUser(testuser2)
+	suite.Nil(err)
+
+	// user2 tweets 3 times before user1 follows user2
+	var user2Tweets []*models.Tweet
+	for _, content := range []string{"tweet1", "tweet2", "tweet3"} {
+		tweet, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser2.ID, Type: models.Text, Content: content})
+		suite.Nil(err)
+		user2Tweets = append(user2Tweets, tweet)
+	}
+
+	// user1's own tweet is distributed to user1's feed
+	user1Tweet, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser1.ID, Type: models.Text, Content: "own tweet"})
+	suite.Nil(err)
+	err = testFeedRepository.AddTweetToFeeds(user1Tweet.ID, []uint{testuser1.ID})
+	suite.Nil(err)
+
+	// distributing the same tweet again does not duplicate it
+	err = testFeedRepository.AddTweetToFeeds(user1Tweet.ID, []uint{testuser1.ID, testuser1.ID})
+	suite.Nil(err)
+
+	// backfill the latest 2 tweets of user2
+	err = testFeedRepository.AddUserTweetsToFeed(testuser1.ID, testuser2.ID, 2)
+	suite.Nil(err)
+
+	tweets, err := testFeedRepository.GetFeedTweets(testuser1.ID, &pagination.Page{})
+	suite.Nil(err)
+	suite.Equal(3, len(tweets.Items))
+	suite.Equal(user1Tweet.ID, tweets.Items[0].ID)
+	suite.Equal(user2Tweets[2].ID, tweets.Items[1].ID)
+	suite.Equal(user2Tweets[1].ID, tweets.Items[2].ID)
+
+	// tweets already in the feed are not added twice
+	err = testFeedRepository.AddUserTweetsToFeed(testuser1.ID, testuser2.ID, 3)
+	suite.Nil(err)
+
+	tweets, err = testFeedRepository.GetFeedTweets(testuser1.ID, &pagination.Page{})
+	suite.Nil(err)
+	suite.Equal(4, len(tweets.Items))
+
+	// unfollow removes only the tweets of user2
+	err = testFeedRepository.RemoveUserTweetsFromFeed(testuser1.ID, testuser2.ID)
+	suite.Nil(err)
+
+	tweets, err = testFeedRepository.GetFeedTweets(testuser1.ID, &pagination.Page{})
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	suite.Equal(user1Tweet.ID, tweets.Items[0].ID)
+}
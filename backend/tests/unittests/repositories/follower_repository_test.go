@@ -7,6 +7,7 @@ import (
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
 	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 	"github.com/daiki-kim/tweet-app/backend/tests"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
@@ -37,18 +38,21 @@ func (suite *FollowerTestSuite) TestFollowerRepository() {
 	// prepare test user data
 	testuser1 := &models.User{
 		Name:     "testuser1",
+		Username: "testuser1",
 		Email:    "test1@example.com",
 		Password: "testpassword",
 		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
 	}
 	testuser2 := &models.User{
 		Name:     "testuser2",
+		Username: "testuser2",
 		Email:    "test2@example.com",
 		Password: "testpassword",
 		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
 	}
 	testuser3 := &models.User{
 		Name:     "testuser3",
+		Username: "testuser3",
 		Email:    "test3@example.com",
 		Password: "testpassword",
 		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
@@ -104,23 +108,38 @@ func (suite *FollowerTestSuite) TestFollowerRepository() {
 
 	// get followees
 	// get follower datas user1 follows
-	followees, err := testFollowerRepository.GetFollowees(1)
+	// newest first
+	followees, err := testFollowerRepository.GetFollowees(1, &pagination.Page{})
 	suite.Nil(err)
-	suite.Equal(2, len(followees))
-	suite.Equal(testuser2.Name, followees[0].Followee.Name)
-	suite.Equal(testuser2.Email, followees[0].Followee.Email)
-	suite.Equal(testuser3.Name, followees[1].Followee.Name)
-	suite.Equal(testuser3.Email, followees[1].Followee.Email)
+	suite.Equal(2, len(followees.Items))
+	suite.Nil(followees.NextCursor)
+	suite.Equal(testuser3.Name, followees.Items[0].Followee.Name)
+	suite.Equal(testuser3.Email, followees.Items[0].Followee.Email)
+	suite.Equal(testuser2.Name, followees.Items[1].Followee.Name)
+	suite.Equal(testuser2.Email, followees.Items[1].Followee.Email)
+
+	// get followees page by page
+	firstPage, err := testFollowerRepository.GetFollowees(1, &pagination.Page{Limit: 1})
+	suite.Nil(err)
+	suite.Equal(1, len(firstPage.Items))
+	suite.Equal(testuser3.Name, firstPage.Items[0].Followee.Name)
+	suite.NotNil(firstPage.NextCursor)
+
+	secondPage, err := testFollowerRepository.GetFollowees(1, &pagination.Page{Limit: 1, Cursor: firstPage.NextCursor})
+	suite.Nil(err)
+	suite.Equal(1, len(secondPage.Items))
+	suite.Equal(testuser2.Name, secondPage.Items[0].Followee.Name)
+	suite.Nil(secondPage.NextCursor)
 
 	// get followers
 	// get follower datas user2 is followed
-	followers, err := testFollowerRepository.GetFollowers(2)
+	followers, err := testFollowerRepository.GetFollowers(2, &pagination.Page{})
 	suite.Nil(err)
-	suite.Equal(2, len(followers))
-	suite.Equal(testuser1.Name, followers[0].Follower.Name)
-	suite.Equal(testuser1.Email, followers[0].Follower.Email)
-	suite.Equal(testuser3.Name, followers[1].Follower.Name)
-	suite.Equal(testuser3.Email, followers[1].Follower.Email)
+	suite.Equal(2, len(followers.Items))
+	suite.Equal(testuser3.Name, followers.Items[0].Follower.Name)
+	suite.Equal(testuser3.Email, followers.Items[0].Follower.Email)
+	suite.Equal(testuser1.Name, followers.Items[1].Follower.Name)
+	suite.Equal(testuser1.Email, followers.Items[1].Follower.Email)
 
 	// delete followers
 	err = testFollowerRepository.DeleteFollower(1) // delete follower1
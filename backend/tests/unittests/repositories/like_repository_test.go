@@ -0,0 +1,107 @@
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type LikeTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestLikeTestSuite(t *testing.T) {
+	suite.Run(t, new(LikeTestSuite))
+}
+
+func (suite *LikeTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *LikeTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *LikeTestSuite) TestLikeRepository() {
+	// prepare test user data
+	testuser1 := &models.User{
+		Name:     "testuser1",
+		Username: "testuser1",
+		Email:    "test1@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	testuser2 := &models.User{
+		Name:     "testuser2",
+		Username: "testuser2",
+		Email:    "test2@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// prepare test repository
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testLikeRepository := repositories.NewLikeRepository(models.DB)
+
+	// create users
+	err := testUserRepository.CreateUser(testuser1)
+	suite.Nil(err)
+	err = testUserRepository.CreateUser(testuser2)
+	suite.Nil(err)
+
+	// create liked tweets (tweet1, tweet2)
+	for _, content := range []string{"tweet1", "tweet2"} {
+		result := models.DB.Create(&models.Tweet{UserID: testuser1.ID, Type: models.Text, Content: content})
+		suite.Nil(result.Error)
+	}
+
+	// user1 and user2 like tweet1, user1 likes tweet2
+	_, err = testLikeRepository.CreateLike(&models.Like{UserID: testuser1.ID, TweetID: 1})
+	suite.Nil(err)
+	_, err = testLikeRepository.CreateLike(&models.Like{UserID: testuser2.ID, TweetID: 1})
+	suite.Nil(err)
+	_, err = testLikeRepository.CreateLike(&models.Like{UserID: testuser1.ID, TweetID: 2})
+	suite.Nil(err)
+
+	// duplicate like
+	_, err = testLikeRepository.CreateLike(&models.Like{UserID: testuser1.ID, TweetID: 1})
+	suite.Equal("already liked", err.Error())
+
+	// get likers of tweet1 (newest first)
+	likes, err := testLikeRepository.GetTweetLikes(1, &pagination.Page{})
+	suite.Nil(err)
+	suite.Equal(2, len(likes.Items))
+	suite.Equal(testuser2.Name, likes.Items[0].User.Name)
+	suite.Equal(testuser1.Name, likes.Items[1].User.Name)
+
+	// count likes
+	counts, err := testLikeRepository.CountLikes([]uint{1, 2, 3})
+	suite.Nil(err)
+	suite.Equal(int64(2), counts[1])
+	suite.Equal(int64(1), counts[2])
+	suite.Equal(int64(0), counts[3])
+
+	// liked tweet ids
+	liked, err := testLikeRepository.GetLikedTweetIds(testuser2.ID, []uint{1, 2})
+	suite.Nil(err)
+	suite.True(liked[1])
+	suite.False(liked[2])
+
+	// unlike
+	err = testLikeRepository.DeleteLike(testuser1.ID, 1)
+	suite.Nil(err)
+	err = testLikeRepository.DeleteLike(testuser1.ID, 1)
+	suite.Equal("like not found", err.Error())
+}
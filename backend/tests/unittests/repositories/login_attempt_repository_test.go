@@ -0,0 +1,102 @@
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type LoginAttemptTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestLoginAttemptTestSuite(t *testing.T) {
+	suite.Run(t, new(LoginAttemptTestSuite))
+}
+
+func (suite *LoginAttemptTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *LoginAttemptTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *LoginAttemptTestSuite) TestLoginAttemptRepository() {
+	suite.testLoginAttemptRepository(repositories.NewLoginAttemptRepository(models.DB), "account:db@example.com")
+}
+
+func (suite *LoginAttemptTestSuite) TestInMemoryLoginAttemptRepository() {
+	suite.testLoginAttemptRepository(repositories.NewInMemoryLoginAttemptRepository(), "account:memory@example.com")
+}
+
+// DB、メモリのどちらの実装でも同じ結果になることを確認する
+func (suite *LoginAttemptTestSuite) testLoginAttemptRepository(testRepository repositories.ILoginAttemptRepository, identifier string) {
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := testRepository.FindLoginAttempt(identifier)
+	suite.Equal("login attempt not found", err.Error())
+
+	// count failures within the window
+	attempt, err := testRepository.RecordFailedLogin(identifier, now, now.Add(-time.Hour))
+	suite.Nil(err)
+	suite.Equal(1, attempt.Failures)
+	attempt, err = testRepository.RecordFailedLogin(identifier, now.Add(time.Minute), now.Add(-time.Hour))
+	suite.Nil(err)
+	suite.Equal(2, attempt.Failures)
+	suite.True(attempt.LastFailedAt.Equal(now.Add(time.Minute)))
+
+	// restart from 1 when the last failure is older than the window
+	attempt, err = testRepository.RecordFailedLogin(identifier, now.Add(2*time.Hour), now.Add(time.Hour))
+	suite.Nil(err)
+	suite.Equal(1, attempt.Failures)
+
+	// lock resets failures and records an audit entry
+	lockedUntil := now.Add(3 * time.Hour)
+	suite.Nil(testRepository.LockLoginAttempt(&models.LoginLockout{
+		Identifier: identifier, IPAddress: "127.0.0.1", Failures: 10, LockedUntil: lockedUntil,
+	}))
+	attempt, err = testRepository.FindLoginAttempt(identifier)
+	suite.Nil(err)
+	suite.Equal(0, attempt.Failures)
+	suite.True(attempt.LockedUntil.Equal(lockedUntil))
+
+	lockouts, err := testRepository.FindLoginLockouts(identifier)
+	suite.Nil(err)
+	suite.Len(lockouts, 1)
+	suite.Equal("127.0.0.1", lockouts[0].IPAddress)
+	suite.Equal(10, lockouts[0].Failures)
+
+	// locked attempts are not purged until the lock expires
+	purged, err := testRepository.PurgeLoginAttempts(now.Add(2*time.Hour + time.Minute))
+	suite.Nil(err)
+	suite.Equal(int64(0), purged)
+	purged, err = testRepository.PurgeLoginAttempts(lockedUntil.Add(time.Minute))
+	suite.Nil(err)
+	suite.Equal(int64(1), purged)
+	_, err = testRepository.FindLoginAttempt(identifier)
+	suite.Equal("login attempt not found", err.Error())
+
+	// audit entries are kept after purge
+	lockouts, err = testRepository.FindLoginLockouts(identifier)
+	suite.Nil(err)
+	suite.Len(lockouts, 1)
+
+	// reset deletes the failures
+	_, err = testRepository.RecordFailedLogin(identifier, now, now.Add(-time.Hour))
+	suite.Nil(err)
+	suite.Nil(testRepository.ResetLoginAttempt(identifier))
+	_, err = testRepository.FindLoginAttempt(identifier)
+	suite.Equal("login attempt not found", err.Error())
+}
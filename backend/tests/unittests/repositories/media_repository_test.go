@@ -0,0 +1,112 @@
+// repository unit test by using sqlite
+
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type MediaTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestMediaTestSuite(t *testing.T) {
+	suite.Run(t, new(MediaTestSuite))
+}
+
+func (suite *MediaTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *MediaTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *MediaTestSuite) TestMediaRepository() {
+	// prepare test user data
+	testuser := &models.User{
+		Name:     "testuser1",
+		Username: "testuser1",
+		Email:    "test1@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// prepare test repository
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testTweetRepository := repositories.NewTweetRepository(models.DB)
+	testMediaRepository := repositories.NewMediaRepository(models.DB)
+
+	// create user
+	err := testUserRepository.CreateUser(testuser)
+	suite.Nil(err)
+
+	// upload media
+	image1, err := testMediaRepository.CreateMedia(&models.Media{
+		UserID: testuser.ID, Type: models.Image, ContentType: "image/png", Size: 10,
+		StorageKey: "images/1.png", URL: "http://localhost:8080/media/images/1.png",
+		ThumbnailKey: "thumbnails/1.jpg", ThumbnailURL: "http://localhost:8080/media/thumbnails/1.jpg",
+	})
+	suite.Nil(err)
+	image2, err := testMediaRepository.CreateMedia(&models.Media{
+		UserID: testuser.ID, Type: models.Image, ContentType: "image/jpeg", Size: 20,
+		StorageKey: "images/2.jpg", URL: "http://localhost:8080/media/images/2.jpg",
+	})
+	suite.Nil(err)
+
+	media, err := testMediaRepository.GetMediaByIds([]uint{image2.ID, image1.ID, 100})
+	suite.Nil(err)
+	suite.Equal(2, len(media))
+	suite.Equal(image1.ID, media[0].ID)
+	suite.Nil(media[0].TweetID)
+
+	// create tweet with media
+	tweet, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Image, Content: "photos", Media: media})
+	suite.Nil(err)
+	suite.Equal(tweet.ID, *media[0].TweetID)
+
+	gotTweet, err := testTweetRepository.GetTweet(tweet.ID)
+	suite.Nil(err)
+	suite.Equal(2, len(gotTweet.Media))
+	suite.Equal("http://localhost:8080/media/images/1.png", gotTweet.Media[0].URL)
+	suite.Equal("http://localhost:8080/media/thumbnails/1.jpg", gotTweet.Media[0].ThumbnailURL)
+
+	// attached media cannot be attached to another tweet and the tweet is not created
+	_, err = testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Image, Content: "again", Media: []*models.Media{image1}})
+	suite.Equal("media is already attached", err.Error())
+	userTweets, err := testTweetRepository.GetUserTweets(testuser.ID, nil)
+	suite.Nil(err)
+	suite.Equal(1, len(userTweets.Items))
+
+	// media is loaded with quoted tweet
+	quote, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Quote, Content: "quote", QuotedTweetID: &tweet.ID})
+	suite.Nil(err)
+	gotQuote, err := testTweetRepository.GetTweet(quote.ID)
+	suite.Nil(err)
+	suite.Equal(0, len(gotQuote.Media))
+	suite.Equal(2, len(gotQuote.QuotedTweet.Media))
+
+	// delete media of tweet
+	tweetMedia, err := testMediaRepository.GetTweetMedia(tweet.ID)
+	suite.Nil(err)
+	suite.Equal(2, len(tweetMedia))
+
+	err = testMediaRepository.DeleteMedia([]uint{image1.ID, image2.ID})
+	suite.Nil(err)
+	tweetMedia, err = testMediaRepository.GetTweetMedia(tweet.ID)
+	suite.Nil(err)
+	suite.Equal(0, len(tweetMedia))
+}
@@ -0,0 +1,78 @@
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type PasswordResetTokenTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestPasswordResetTokenTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordResetTokenTestSuite))
+}
+
+func (suite *PasswordResetTokenTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *PasswordResetTokenTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *PasswordResetTokenTestSuite) TestPasswordResetTokenRepository() {
+	expiresAt := time.Now().Add(time.Hour)
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testResetTokenRepository := repositories.NewPasswordResetTokenRepository(models.DB)
+
+	user := &models.User{Name: "testuser", Username: "testuser", Email: "test@example.com", Password: "testpassword"}
+	suite.Nil(testUserRepository.CreateUser(user))
+
+	// create tokens
+	suite.Nil(testResetTokenRepository.CreatePasswordResetToken(&models.PasswordResetToken{
+		UserID: user.ID, TokenHash: "hash1", ExpiresAt: expiresAt,
+	}))
+	suite.Nil(testResetTokenRepository.CreatePasswordResetToken(&models.PasswordResetToken{
+		UserID: user.ID, TokenHash: "hash2", ExpiresAt: expiresAt,
+	}))
+
+	// find token by hash
+	resetToken, err := testResetTokenRepository.FindPasswordResetToken("hash1")
+	suite.Nil(err)
+	suite.Equal(user.ID, resetToken.UserID)
+	suite.Nil(resetToken.UsedAt)
+
+	_, err = testResetTokenRepository.FindPasswordResetToken("unknown")
+	suite.Equal("password reset token not found", err.Error())
+
+	// mark used only once
+	suite.Nil(testResetTokenRepository.MarkPasswordResetTokenUsed(resetToken.ID))
+	err = testResetTokenRepository.MarkPasswordResetTokenUsed(resetToken.ID)
+	suite.Equal("password reset token already used", err.Error())
+
+	// invalidate remaining tokens of the user
+	suite.Nil(testResetTokenRepository.InvalidateUserPasswordResetTokens(user.ID))
+	resetToken, err = testResetTokenRepository.FindPasswordResetToken("hash2")
+	suite.Nil(err)
+	suite.NotNil(resetToken.UsedAt)
+
+	// update password
+	suite.Nil(testUserRepository.UpdatePassword(user.ID, "newhashedpassword"))
+	updatedUser, err := testUserRepository.FindUserById(user.ID)
+	suite.Nil(err)
+	suite.Equal("newhashedpassword", updatedUser.Password)
+	suite.Equal("user not found", testUserRepository.UpdatePassword(9999, "hash").Error())
+}
@@ -0,0 +1,78 @@
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type RefreshTokenTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestRefreshTokenTestSuite(t *testing.T) {
+	suite.Run(t, new(RefreshTokenTestSuite))
+}
+
+func (suite *RefreshTokenTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *RefreshTokenTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *RefreshTokenTestSuite) TestRefreshTokenRepository() {
+	expiresAt := time.Now().Add(time.Hour)
+	testRefreshTokenRepository := repositories.NewRefreshTokenRepository(models.DB)
+
+	// create owner of refresh tokens (user1)
+	err := repositories.NewUserRepository(models.DB).CreateUser(&models.User{
+		Name: "testuser", Username: "testuser", Email: "test@example.com", Password: "testpassword",
+		Dob: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	suite.Nil(err)
+
+	// create refresh tokens of the same family
+	err = testRefreshTokenRepository.CreateRefreshToken(&models.RefreshToken{
+		UserID: 1, TokenID: "token1", FamilyID: "family1", ExpiresAt: expiresAt,
+	})
+	suite.Nil(err)
+	err = testRefreshTokenRepository.CreateRefreshToken(&models.RefreshToken{
+		UserID: 1, TokenID: "token2", FamilyID: "family1", ExpiresAt: expiresAt,
+	})
+	suite.Nil(err)
+
+	// find refresh token
+	refreshToken, err := testRefreshTokenRepository.FindRefreshToken("token1")
+	suite.Nil(err)
+	suite.Equal("family1", refreshToken.FamilyID)
+	suite.Nil(refreshToken.UsedAt)
+
+	_, err = testRefreshTokenRepository.FindRefreshToken("unknown")
+	suite.Equal("refresh token not found", err.Error())
+
+	// mark used only once
+	err = testRefreshTokenRepository.MarkRefreshTokenUsed("token1")
+	suite.Nil(err)
+	err = testRefreshTokenRepository.MarkRefreshTokenUsed("token1")
+	suite.Equal("refresh token already used", err.Error())
+
+	// revoke family
+	err = testRefreshTokenRepository.RevokeFamily("family1")
+	suite.Nil(err)
+	refreshToken, err = testRefreshTokenRepository.FindRefreshToken("token2")
+	suite.Nil(err)
+	suite.NotNil(refreshToken.RevokedAt)
+}
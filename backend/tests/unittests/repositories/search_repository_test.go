@@ -0,0 +1,138 @@
+//go:build sqlite_fts5
+
+// repository unit test by using sqlite
+// SQLiteのFTS5を使用するので go test -tags sqlite_fts5 で実行する
+
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/pkg/search"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type SearchTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestSearchTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchTestSuite))
+}
+
+func (suite *SearchTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *SearchTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *SearchTestSuite) TestSearchTweets() {
+	// prepare test user data
+	testuser1 := &models.User{
+		Name:     "testuser1",
+		Username: "testuser1",
+		Email:    "test1@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	testuser2 := &models.User{
+		Name:     "testuser2",
+		Username: "testuser2",
+		Email:    "test2@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// prepare test repository
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testTweetRepository := repositories.NewTweetRepository(models.DB)
+
+	// create users
+	err := testUserRepository.CreateUser(testuser1)
+	suite.Nil(err)
+	err = testUserRepository.CreateUser(testuser2)
+	suite.Nil(err)
+
+	// existing tweets are indexed when the index is created
+	tweet1, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser1.ID, Type: models.Text, Content: "Golang full text search"})
+	suite.Nil(err)
+
+	searchIndex, err := search.NewSQLiteIndex(models.DB, "tweets", "content")
+	suite.Nil(err)
+	testSearchRepository := repositories.NewSearchRepository(models.DB, searchIndex)
+
+	// new tweets are indexed by the triggers
+	tweet2, err := testTweetRepository.CreateTweet(&models.Tweet{
+		UserID: testuser2.ID, Type: models.Image, Content: "東京でラーメンを食べた #go",
+		Hashtags: []*models.Hashtag{{Name: "go"}},
+	})
+	suite.Nil(err)
+	tweet3, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser2.ID, Type: models.Text, Content: "text search in golang"})
+	suite.Nil(err)
+	_, err = testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser1.ID, Type: models.Retweet, RetweetedTweetID: &tweet3.ID})
+	suite.Nil(err)
+
+	// terms are case-insensitive and all of them are required
+	tweets, err := testSearchRepository.SearchTweets(&repositories.TweetSearchCondition{Terms: []string{"golang", "search"}}, nil)
+	suite.Nil(err)
+	suite.Equal(2, len(tweets.Items))
+	suite.Equal(tweet3.ID, tweets.Items[0].ID)
+	suite.Equal(tweet1.ID, tweets.Items[1].ID)
+
+	// phrase
+	tweets, err = testSearchRepository.SearchTweets(&repositories.TweetSearchCondition{Terms: []string{"full text"}}, nil)
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	suite.Equal(tweet1.ID, tweets.Items[0].ID)
+
+	// japanese terms shorter than trigram are searched with LIKE
+	tweets, err = testSearchRepository.SearchTweets(&repositories.TweetSearchCondition{Terms: []string{"東京", "ラーメン"}}, nil)
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	suite.Equal(tweet2.ID, tweets.Items[0].ID)
+
+	// from, has and hashtag filters
+	tweets, err = testSearchRepository.SearchTweets(&repositories.TweetSearchCondition{Terms: []string{"golang"}, FromUsername: "TestUser2"}, nil)
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	suite.Equal(tweet3.ID, tweets.Items[0].ID)
+
+	tweets, err = testSearchRepository.SearchTweets(&repositories.TweetSearchCondition{Types: []models.TweetType{models.Image}, Hashtags: []string{"go"}}, nil)
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	suite.Equal(tweet2.ID, tweets.Items[0].ID)
+
+	// since and until
+	tomorrow := time.Now().Add(24 * time.Hour)
+	tweets, err = testSearchRepository.SearchTweets(&repositories.TweetSearchCondition{Since: &tomorrow}, nil)
+	suite.Nil(err)
+	suite.Equal(0, len(tweets.Items))
+	tweets, err = testSearchRepository.SearchTweets(&repositories.TweetSearchCondition{Until: &tomorrow}, &pagination.Page{Limit: 2})
+	suite.Nil(err)
+	suite.Equal(2, len(tweets.Items))
+	suite.NotNil(tweets.NextCursor)
+
+	// updated and deleted tweets are re-indexed
+	tweet1.Content = "rust"
+	_, err = testTweetRepository.UpdateTweet(tweet1)
+	suite.Nil(err)
+	err = testTweetRepository.DeleteTweet(tweet3.ID)
+	suite.Nil(err)
+	tweets, err = testSearchRepository.SearchTweets(&repositories.TweetSearchCondition{Terms: []string{"golang"}}, nil)
+	suite.Nil(err)
+	suite.Equal(0, len(tweets.Items))
+}
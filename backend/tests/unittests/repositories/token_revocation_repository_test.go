@@ -0,0 +1,97 @@
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TokenRevocationTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestTokenRevocationTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenRevocationTestSuite))
+}
+
+func (suite *TokenRevocationTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *TokenRevocationTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *TokenRevocationTestSuite) TestTokenRevocationRepository() {
+	// create owners of tokens (user1, user2)
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	for _, name := range []string{"testuser1", "testuser2"} {
+		err := testUserRepository.CreateUser(&models.User{
+			Name: name, Username: name, Email: name + "@example.com", Password: "testpassword",
+			Dob: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+		suite.Nil(err)
+	}
+
+	suite.testTokenRevocationRepository(repositories.NewTokenRevocationRepository(models.DB))
+}
+
+func (suite *TokenRevocationTestSuite) TestInMemoryTokenRevocationRepository() {
+	suite.testTokenRevocationRepository(repositories.NewInMemoryTokenRevocationRepository())
+}
+
+// DB、メモリのどちらの実装でも同じ結果になることを確認する
+func (suite *TokenRevocationTestSuite) testTokenRevocationRepository(testRepository repositories.ITokenRevocationRepository) {
+	now := time.Now()
+	issuedAt := now.Add(-time.Minute)
+
+	// revoke single token
+	err := testRepository.RevokeToken("token1", 1, now.Add(time.Hour))
+	suite.Nil(err)
+	err = testRepository.RevokeToken("token1", 1, now.Add(time.Hour))
+	suite.Nil(err)
+
+	revoked, err := testRepository.IsRevoked("token1", 1, issuedAt)
+	suite.Nil(err)
+	suite.True(revoked)
+
+	revoked, err = testRepository.IsRevoked("token2", 1, issuedAt)
+	suite.Nil(err)
+	suite.False(revoked)
+
+	// revoke all tokens of user2 issued before now
+	err = testRepository.RevokeUserTokens(2, now, now.Add(time.Hour))
+	suite.Nil(err)
+
+	revoked, err = testRepository.IsRevoked("token3", 2, issuedAt)
+	suite.Nil(err)
+	suite.True(revoked)
+
+	revoked, err = testRepository.IsRevoked("token4", 2, now.Add(time.Second))
+	suite.Nil(err)
+	suite.False(revoked)
+
+	// purge entries after they expire
+	purged, err := testRepository.PurgeExpired(now.Add(30 * time.Minute))
+	suite.Nil(err)
+	suite.Equal(int64(0), purged)
+
+	purged, err = testRepository.PurgeExpired(now.Add(2 * time.Hour))
+	suite.Nil(err)
+	suite.Equal(int64(2), purged)
+
+	revoked, err = testRepository.IsRevoked("token1", 1, issuedAt)
+	suite.Nil(err)
+	suite.False(revoked)
+}
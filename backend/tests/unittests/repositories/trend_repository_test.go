@@ -0,0 +1,73 @@
+// repository unit test by using sqlite
+
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TrendTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestTrendTestSuite(t *testing.T) {
+	suite.Run(t, new(TrendTestSuite))
+}
+
+func (suite *TrendTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *TrendTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *TrendTestSuite) TestTrendRepository() {
+	// prepare test repository
+	testTrendRepository := repositories.NewTrendRepository(models.DB)
+
+	slot1 := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+	slot2 := slot1.Add(5 * time.Minute)
+	slot3 := slot1.Add(time.Hour)
+
+	// add counts to slots (counts of the same slot are accumulated)
+	err := testTrendRepository.AddCounts(slot1, map[string]int64{"#go": 2, "東京": 1})
+	suite.Nil(err)
+	err = testTrendRepository.AddCounts(slot1, map[string]int64{"#go": 1})
+	suite.Nil(err)
+	err = testTrendRepository.AddCounts(slot2, map[string]int64{"#go": 4})
+	suite.Nil(err)
+	err = testTrendRepository.AddCounts(slot3, map[string]int64{"#go": 8})
+	suite.Nil(err)
+
+	// sum counts of slots in range [from, to)
+	counts, err := testTrendRepository.SumCounts(slot1, slot3)
+	suite.Nil(err)
+	suite.Equal(map[string]int64{"#go": 7, "東京": 1}, counts)
+
+	counts, err = testTrendRepository.SumCounts(slot2, slot3.Add(time.Minute))
+	suite.Nil(err)
+	suite.Equal(map[string]int64{"#go": 12}, counts)
+
+	// delete old slots
+	deleted, err := testTrendRepository.DeleteBucketsBefore(slot2)
+	suite.Nil(err)
+	suite.Equal(int64(2), deleted)
+
+	counts, err = testTrendRepository.SumCounts(slot1, slot3.Add(time.Minute))
+	suite.Nil(err)
+	suite.Equal(map[string]int64{"#go": 12}, counts)
+}
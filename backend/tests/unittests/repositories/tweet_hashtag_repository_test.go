@@ -0,0 +1,112 @@
+// repository unit test by using sqlite
+
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type HashtagTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestHashtagTestSuite(t *testing.T) {
+	suite.Run(t, new(HashtagTestSuite))
+}
+
+func (suite *HashtagTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *HashtagTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *HashtagTestSuite) TestHashtagTweets() {
+	// prepare test user data
+	testuser := &models.User{
+		Name:     "testuser1",
+		Username: "testuser1",
+		Email:    "test1@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// prepare test repository
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testTweetRepository := repositories.NewTweetRepository(models.DB)
+
+	// create user
+	err := testUserRepository.CreateUser(testuser)
+	suite.Nil(err)
+
+	// create tweets with hashtags
+	tweet1, err := testTweetRepository.CreateTweet(&models.Tweet{
+		UserID: testuser.ID, Type: models.Text, Content: "#go #東京",
+		Hashtags: []*models.Hashtag{{Name: "go"}, {Name: "東京"}},
+	})
+	suite.Nil(err)
+	suite.Equal(2, len(tweet1.Hashtags))
+	suite.NotZero(tweet1.Hashtags[0].ID)
+
+	// existing hashtag is reused
+	tweet2, err := testTweetRepository.CreateTweet(&models.Tweet{
+		UserID: testuser.ID, Type: models.Text, Content: "#go",
+		Hashtags: []*models.Hashtag{{Name: "go"}},
+	})
+	suite.Nil(err)
+	suite.Equal(tweet1.Hashtags[0].ID, tweet2.Hashtags[0].ID)
+
+	// get hashtag tweets
+	tweets, err := testTweetRepository.GetHashtagTweets("go", &pagination.Page{Limit: 1})
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	suite.Equal(tweet2.ID, tweets.Items[0].ID)
+	suite.Equal("go", tweets.Items[0].Hashtags[0].Name)
+	suite.NotNil(tweets.NextCursor)
+
+	tweets, err = testTweetRepository.GetHashtagTweets("go", &pagination.Page{Limit: 1, Cursor: tweets.NextCursor})
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	suite.Equal(tweet1.ID, tweets.Items[0].ID)
+	suite.Nil(tweets.NextCursor)
+
+	// update re-syncs hashtags
+	tweet1.Content = "#大阪"
+	tweet1.Hashtags = []*models.Hashtag{{Name: "大阪"}}
+	_, err = testTweetRepository.UpdateTweet(tweet1)
+	suite.Nil(err)
+
+	tweets, err = testTweetRepository.GetHashtagTweets("東京", nil)
+	suite.Nil(err)
+	suite.Equal(0, len(tweets.Items))
+	tweets, err = testTweetRepository.GetHashtagTweets("大阪", nil)
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+
+	gotTweet, err := testTweetRepository.GetTweet(tweet1.ID)
+	suite.Nil(err)
+	suite.Equal(1, len(gotTweet.Hashtags))
+	suite.Equal("大阪", gotTweet.Hashtags[0].Name)
+
+	// deleted tweets are not listed
+	err = testTweetRepository.DeleteTweet(tweet2.ID)
+	suite.Nil(err)
+	tweets, err = testTweetRepository.GetHashtagTweets("go", nil)
+	suite.Nil(err)
+	suite.Equal(0, len(tweets.Items))
+}
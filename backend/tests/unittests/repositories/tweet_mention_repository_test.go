@@ -0,0 +1,127 @@
+// repository unit test by using sqlite
+
+package repositories_test
+
+import (
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type MentionTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestMentionTestSuite(t *testing.T) {
+	suite.Run(t, new(MentionTestSuite))
+}
+
+func (suite *MentionTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *MentionTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *MentionTestSuite) TestMentionTweets() {
+	// prepare test user data
+	testuser1 := &models.User{
+		Name:     "testuser1",
+		Username: "testuser1",
+		Email:    "test1@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	testuser2 := &models.User{
+		Name:     "testuser2",
+		Username: "testuser2",
+		Email:    "test2@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// prepare test repository
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testTweetRepository := repositories.NewTweetRepository(models.DB)
+
+	// create users
+	err := testUserRepository.CreateUser(testuser1)
+	suite.Nil(err)
+	err = testUserRepository.CreateUser(testuser2)
+	suite.Nil(err)
+
+	// create tweets with mentions
+	// the same user mentioned twice is listed once
+	tweet1, err := testTweetRepository.CreateTweet(&models.Tweet{
+		UserID: testuser1.ID, Type: models.Text, Content: "@testuser2 @TestUser2",
+		Mentions: []*models.TweetMention{
+			{UserID: testuser2.ID, Username: "testuser2", Start: 0, End: 10},
+			{UserID: testuser2.ID, Username: "TestUser2", Start: 11, End: 21},
+		},
+	})
+	suite.Nil(err)
+	suite.NotZero(tweet1.Mentions[0].ID)
+
+	tweet2, err := testTweetRepository.CreateTweet(&models.Tweet{
+		UserID: testuser1.ID, Type: models.Text, Content: "hi @testuser2",
+		Mentions: []*models.TweetMention{{UserID: testuser2.ID, Username: "testuser2", Start: 3, End: 13}},
+	})
+	suite.Nil(err)
+
+	_, err = testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser2.ID, Type: models.Text, Content: "no mention"})
+	suite.Nil(err)
+
+	// get mention tweets
+	tweets, err := testTweetRepository.GetMentionTweets(testuser2.ID, &pagination.Page{Limit: 1})
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	suite.Equal(tweet2.ID, tweets.Items[0].ID)
+	suite.Equal(testuser2.ID, tweets.Items[0].Mentions[0].UserID)
+	suite.NotNil(tweets.NextCursor)
+
+	tweets, err = testTweetRepository.GetMentionTweets(testuser2.ID, &pagination.Page{Limit: 1, Cursor: tweets.NextCursor})
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	suite.Equal(tweet1.ID, tweets.Items[0].ID)
+	suite.Nil(tweets.NextCursor)
+
+	// mentions are loaded in the order of appearance
+	gotTweet, err := testTweetRepository.GetTweet(tweet1.ID)
+	suite.Nil(err)
+	suite.Equal(2, len(gotTweet.Mentions))
+	suite.Equal(0, gotTweet.Mentions[0].Start)
+	suite.Equal(11, gotTweet.Mentions[1].Start)
+
+	// update re-syncs mentions
+	tweet1.Content = "@testuser1"
+	tweet1.Mentions = []*models.TweetMention{{UserID: testuser1.ID, Username: "testuser1", Start: 0, End: 10}}
+	_, err = testTweetRepository.UpdateTweet(tweet1)
+	suite.Nil(err)
+
+	tweets, err = testTweetRepository.GetMentionTweets(testuser2.ID, nil)
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+	tweets, err = testTweetRepository.GetMentionTweets(testuser1.ID, nil)
+	suite.Nil(err)
+	suite.Equal(1, len(tweets.Items))
+
+	// deleted tweets are not listed
+	err = testTweetRepository.DeleteTweet(tweet2.ID)
+	suite.Nil(err)
+	tweets, err = testTweetRepository.GetMentionTweets(testuser2.ID, nil)
+	suite.Nil(err)
+	suite.Equal(0, len(tweets.Items))
+}
@@ -1,19 +1,170 @@
-// repository unit test by using mock
-// TODO: ENUM型も使えるDBでテストを実装する 2024/09/22
+// repository unit test by using sqlite
+// tweetsテーブルのENUM型はマイグレーションでSQLiteのCHECK制約に置き換えている
 
 package repositories_test
 
 import (
-	"github.com/stretchr/testify/mock"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
 )
 
-type MockDB struct {
-	mock.Mock
-	*gorm.DB
+type TweetTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestTweetTestSuite(t *testing.T) {
+	suite.Run(t, new(TweetTestSuite))
+}
+
+func (suite *TweetTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *TweetTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
 }
 
-func (m *MockDB) Create(value interface{}) *gorm.DB {
-	args := m.Called(value)
-	return args.Get(0).(*gorm.DB)
+func (suite *TweetTestSuite) TestTweetRepository() {
+	// prepare test user data
+	testuser := &models.User{
+		Name:     "testuser1",
+		Username: "testuser1",
+		Email:    "test1@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// prepare test repository
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testTweetRepository := repositories.NewTweetRepository(models.DB)
+
+	// create user
+	err := testUserRepository.CreateUser(testuser)
+	suite.Nil(err)
+
+	// create root tweet and replies
+	// rootTweet <- reply1 <- reply2
+	//           <- reply3
+	rootTweet, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Text, Content: "root"})
+	suite.Nil(err)
+
+	reply1, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Text, Content: "reply1", InReplyToTweetID: &rootTweet.ID, ConversationID: &rootTweet.ID})
+	suite.Nil(err)
+	reply2, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Text, Content: "reply2", InReplyToTweetID: &reply1.ID, ConversationID: &rootTweet.ID})
+	suite.Nil(err)
+	reply3, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Text, Content: "reply3", InReplyToTweetID: &rootTweet.ID, ConversationID: &rootTweet.ID})
+	suite.Nil(err)
+
+	// get direct replies of root tweet
+	replies, err := testTweetRepository.GetReplies(rootTweet.ID, &pagination.Page{Limit: 1})
+	suite.Nil(err)
+	suite.Equal(1, len(replies.Items))
+	suite.Equal(reply3.ID, replies.Items[0].ID)
+	suite.NotNil(replies.NextCursor)
+
+	replies, err = testTweetRepository.GetReplies(rootTweet.ID, &pagination.Page{Limit: 1, Cursor: replies.NextCursor})
+	suite.Nil(err)
+	suite.Equal(1, len(replies.Items))
+	suite.Equal(reply1.ID, replies.Items[0].ID)
+	suite.Nil(replies.NextCursor)
+
+	// replies are loaded with author
+	suite.Equal("testuser1", replies.Items[0].User.Username)
+	suite.Empty(replies.Items[0].User.Email)
+
+	// reply path from root tweet
+	path, err := testTweetRepository.GetReplyPath(reply2.ID)
+	suite.Nil(err)
+	suite.Equal(3, len(path))
+	suite.Equal([]uint{rootTweet.ID, reply1.ID, reply2.ID}, []uint{path[0].ID, path[1].ID, path[2].ID})
+	suite.Equal("testuser1", path[2].User.Username)
+
+	path, err = testTweetRepository.GetReplyPath(rootTweet.ID)
+	suite.Nil(err)
+	suite.Equal(1, len(path))
+
+	_, err = testTweetRepository.GetReplyPath(9999)
+	suite.Equal("tweet not found", err.Error())
+
+	// delete tweet leaves tombstone
+	err = testTweetRepository.DeleteTweet(reply1.ID)
+	suite.Nil(err)
+
+	err = testTweetRepository.DeleteTweet(reply1.ID)
+	suite.Equal("tweet not found", err.Error())
+
+	_, err = testTweetRepository.GetTweet(reply1.ID)
+	suite.Equal("tweet not found", err.Error())
+
+	path, err = testTweetRepository.GetReplyPath(reply2.ID)
+	suite.Nil(err)
+	tombstone := path[1]
+	suite.Equal(reply1.ID, tombstone.ID)
+	suite.Equal("", tombstone.Content)
+	suite.True(tombstone.DeletedAt.Valid)
+
+	// replies of deleted tweet are still reachable
+	childReplies, err := testTweetRepository.GetRepliesOf([]uint{reply1.ID, reply3.ID}, 10)
+	suite.Nil(err)
+	suite.Equal(1, len(childReplies))
+	suite.Equal(reply2.ID, childReplies[0].ID)
+
+	// replies are limited per parent tweet (newest first)
+	reply4, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Text, Content: "reply4", InReplyToTweetID: &reply1.ID, ConversationID: &rootTweet.ID})
+	suite.Nil(err)
+	reply5, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Text, Content: "reply5", InReplyToTweetID: &reply3.ID, ConversationID: &rootTweet.ID})
+	suite.Nil(err)
+	childReplies, err = testTweetRepository.GetRepliesOf([]uint{reply1.ID, reply3.ID}, 1)
+	suite.Nil(err)
+	suite.Equal(2, len(childReplies))
+	suite.ElementsMatch([]uint{reply4.ID, reply5.ID}, []uint{childReplies[0].ID, childReplies[1].ID})
+
+	// deleted tweets are not listed in user tweets
+	userTweets, err := testTweetRepository.GetUserTweets(testuser.ID, nil)
+	suite.Nil(err)
+	suite.Equal(5, len(userTweets.Items))
+
+	// retweet and quote
+	retweet, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Retweet, RetweetedTweetID: &rootTweet.ID})
+	suite.Nil(err)
+	_, err = testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Retweet, RetweetedTweetID: &rootTweet.ID})
+	suite.Equal("already retweeted", err.Error())
+	_, err = testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Quote, Content: "quote", QuotedTweetID: &rootTweet.ID})
+	suite.Nil(err)
+
+	retweetCounts, err := testTweetRepository.CountRetweets([]uint{rootTweet.ID, reply3.ID})
+	suite.Nil(err)
+	suite.Equal(map[uint]int64{rootTweet.ID: 1}, retweetCounts)
+	quoteCounts, err := testTweetRepository.CountQuotes([]uint{rootTweet.ID})
+	suite.Nil(err)
+	suite.Equal(map[uint]int64{rootTweet.ID: 1}, quoteCounts)
+
+	// retweet is loaded with original tweet and its author
+	gotRetweet, err := testTweetRepository.GetTweet(retweet.ID)
+	suite.Nil(err)
+	suite.Equal(models.Retweet, gotRetweet.Type)
+	suite.Equal("root", gotRetweet.RetweetedTweet.Content)
+	suite.Equal("testuser1", gotRetweet.RetweetedTweet.User.Name)
+	suite.Equal("", gotRetweet.RetweetedTweet.User.Password)
+
+	// unretweet
+	err = testTweetRepository.DeleteRetweet(testuser.ID, rootTweet.ID)
+	suite.Nil(err)
+	err = testTweetRepository.DeleteRetweet(testuser.ID, rootTweet.ID)
+	suite.Equal("retweet not found", err.Error())
+	_, err = testTweetRepository.CreateTweet(&models.Tweet{UserID: testuser.ID, Type: models.Retweet, RetweetedTweetID: &rootTweet.ID})
+	suite.Nil(err)
 }
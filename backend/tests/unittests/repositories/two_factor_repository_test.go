@@ -0,0 +1,76 @@
+package repositories_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type TwoFactorTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestTwoFactorTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorTestSuite))
+}
+
+func (suite *TwoFactorTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *TwoFactorTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *TwoFactorTestSuite) TestTwoFactorRepository() {
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testTwoFactorRepository := repositories.NewTwoFactorRepository(models.DB)
+
+	user := &models.User{Name: "testuser", Username: "testuser", Email: "test@example.com", Password: "testpassword"}
+	suite.Nil(testUserRepository.CreateUser(user))
+
+	_, err := testTwoFactorRepository.FindUserTOTP(user.ID)
+	suite.Equal("totp not found", err.Error())
+
+	// enroll twice, the secret is replaced
+	suite.Nil(testTwoFactorRepository.SaveUserTOTP(&models.UserTOTP{UserID: user.ID, Secret: "SECRET1"}))
+	suite.Nil(testTwoFactorRepository.SaveUserTOTP(&models.UserTOTP{UserID: user.ID, Secret: "SECRET2"}))
+	userTOTP, err := testTwoFactorRepository.FindUserTOTP(user.ID)
+	suite.Nil(err)
+	suite.Equal("SECRET2", userTOTP.Secret)
+	suite.False(userTOTP.Enabled())
+
+	// enable only once
+	suite.Nil(testTwoFactorRepository.EnableUserTOTP(user.ID, 100, []string{"hash1", "hash2"}))
+	suite.Equal("totp already enabled", testTwoFactorRepository.EnableUserTOTP(user.ID, 101, []string{"hash3"}).Error())
+	userTOTP, err = testTwoFactorRepository.FindUserTOTP(user.ID)
+	suite.Nil(err)
+	suite.True(userTOTP.Enabled())
+	suite.Equal(int64(100), userTOTP.LastUsedStep)
+
+	// code of the same or earlier step cannot be used again
+	suite.Equal("totp code already used", testTwoFactorRepository.UpdateTOTPLastUsedStep(user.ID, 100).Error())
+	suite.Nil(testTwoFactorRepository.UpdateTOTPLastUsedStep(user.ID, 101))
+	suite.Equal("totp code already used", testTwoFactorRepository.UpdateTOTPLastUsedStep(user.ID, 99).Error())
+
+	// recovery code can be used only once
+	suite.Nil(testTwoFactorRepository.UseRecoveryCode(user.ID, "hash1"))
+	suite.Equal("recovery code not found", testTwoFactorRepository.UseRecoveryCode(user.ID, "hash1").Error())
+	suite.Equal("recovery code not found", testTwoFactorRepository.UseRecoveryCode(user.ID, "hash3").Error())
+
+	// disable
+	suite.Nil(testTwoFactorRepository.DeleteUserTOTP(user.ID))
+	_, err = testTwoFactorRepository.FindUserTOTP(user.ID)
+	suite.Equal("totp not found", err.Error())
+	suite.Equal("recovery code not found", testTwoFactorRepository.UseRecoveryCode(user.ID, "hash2").Error())
+}
@@ -0,0 +1,95 @@
+package repositories_test
+
+import (
+	"log"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type UserIdentityTestSuite struct {
+	tests.DBSQLiteSuite
+	originalDB *gorm.DB
+}
+
+func TestUserIdentityTestSuite(t *testing.T) {
+	suite.Run(t, new(UserIdentityTestSuite))
+}
+
+func (suite *UserIdentityTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+	if models.DB == nil {
+		log.Fatal("models.DB is nil")
+	}
+	suite.originalDB = models.DB
+}
+
+func (suite *UserIdentityTestSuite) AfterTest(suiteName, testName string) {
+	models.DB = suite.originalDB
+}
+
+func (suite *UserIdentityTestSuite) TestUserIdentityRepository() {
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testUserIdentityRepository := repositories.NewUserIdentityRepository(models.DB)
+
+	// OAuthでのサインアップ(パスワードなし)
+	oauthUser := &models.User{Name: "oauthuser", Username: "oauthuser", Email: "oauth@example.com"}
+	suite.Nil(testUserIdentityRepository.CreateUserWithIdentity(oauthUser, &models.UserIdentity{Provider: "google", Subject: "google-1", Email: "oauth@example.com"}))
+	identity, err := testUserIdentityRepository.FindUserIdentity("google", "google-1")
+	suite.Nil(err)
+	suite.Equal(oauthUser.ID, identity.UserID)
+	suite.Equal("oauth@example.com", identity.Email)
+
+	// 紐づけ済みのアカウントで別のユーザーを作成した場合はユーザーも作成されない
+	otherUser := &models.User{Name: "otheruser", Username: "otheruser", Email: "other@example.com"}
+	err = testUserIdentityRepository.CreateUserWithIdentity(otherUser, &models.UserIdentity{Provider: "google", Subject: "google-1", Email: "other@example.com"})
+	suite.Equal("identity already linked", err.Error())
+	_, err = testUserRepository.FindUserByEmail("other@example.com")
+	suite.Equal("user not found", err.Error())
+
+	// 同じemailのユーザーは作成できない
+	duplicateUser := &models.User{Name: "duplicateuser", Username: "duplicateuser", Email: "oauth@example.com"}
+	err = testUserIdentityRepository.CreateUserWithIdentity(duplicateUser, &models.UserIdentity{Provider: "github", Subject: "github-1", Email: "oauth@example.com"})
+	suite.Equal("user already exists", err.Error())
+	_, err = testUserIdentityRepository.FindUserIdentity("github", "github-1")
+	suite.Equal("user identity not found", err.Error())
+
+	// プロバイダーとsubjectの組み合わせで識別する
+	_, err = testUserIdentityRepository.FindUserIdentity("github", "google-1")
+	suite.Equal("user identity not found", err.Error())
+
+	// 同じプロバイダーのアカウントは1つまで
+	err = testUserIdentityRepository.CreateUserIdentity(&models.UserIdentity{UserID: oauthUser.ID, Provider: "google", Subject: "google-2", Email: "oauth@example.com"})
+	suite.Equal("identity already linked", err.Error())
+	suite.Nil(testUserIdentityRepository.CreateUserIdentity(&models.UserIdentity{UserID: oauthUser.ID, Provider: "github", Subject: "42", Email: "oauth@example.com"}))
+
+	// プロバイダーでemailが変わった場合
+	suite.Nil(testUserIdentityRepository.UpdateUserIdentityEmail(identity.ID, "changed@example.com"))
+	identities, err := testUserIdentityRepository.FindUserIdentitiesByUserId(oauthUser.ID)
+	suite.Nil(err)
+	suite.Len(identities, 2)
+	suite.Equal("google", identities[0].Provider)
+	suite.Equal("changed@example.com", identities[0].Email)
+	suite.Equal("github", identities[1].Provider)
+
+	// パスワードがない場合は最後の1つは解除できない
+	suite.Nil(testUserIdentityRepository.DeleteUserIdentity(oauthUser.ID, "google"))
+	suite.Equal("user identity not found", testUserIdentityRepository.DeleteUserIdentity(oauthUser.ID, "google").Error())
+	suite.Equal("cannot unlink the last login method", testUserIdentityRepository.DeleteUserIdentity(oauthUser.ID, "github").Error())
+	identities, err = testUserIdentityRepository.FindUserIdentitiesByUserId(oauthUser.ID)
+	suite.Nil(err)
+	suite.Len(identities, 1)
+
+	// パスワードがある場合は全て解除できる
+	passwordUser := &models.User{Name: "passworduser", Username: "passworduser", Email: "password@example.com", Password: "hashedpassword"}
+	suite.Nil(testUserRepository.CreateUser(passwordUser))
+	suite.Nil(testUserIdentityRepository.CreateUserIdentity(&models.UserIdentity{UserID: passwordUser.ID, Provider: "google", Subject: "google-3", Email: "password@example.com"}))
+	suite.Nil(testUserIdentityRepository.DeleteUserIdentity(passwordUser.ID, "google"))
+	identities, err = testUserIdentityRepository.FindUserIdentitiesByUserId(passwordUser.ID)
+	suite.Nil(err)
+	suite.Len(identities, 0)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
 	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 	"github.com/daiki-kim/tweet-app/backend/tests"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
@@ -37,6 +38,7 @@ func (suite *UserTestSuite) TestUserRepository() {
 	testDob := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 	user := &models.User{
 		Name:     "testuser",
+		Username: "testuser",
 		Email:    "test@example.com",
 		Password: "testpassword",
 		Dob:      testDob,
@@ -56,3 +58,129 @@ func (suite *UserTestSuite) TestUserRepository() {
 	suite.Equal("testpassword", user.Password)
 	suite.Equal(testDob, user.Dob)
 }
+
+func (suite *UserTestSuite) TestFindUsersByUsername() {
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	err := testUserRepository.CreateUser(&models.User{Name: "gopher", Username: "GoPher", Email: "gopher@example.com", Password: "testpassword"})
+	suite.Nil(err)
+
+	// usernameは大文字小文字を区別せずにuniqueなので、大文字小文字だけ異なるusernameは登録できない
+	err = testUserRepository.CreateUser(&models.User{Name: "gopher2", Username: "gopher", Email: "gopher2@example.com", Password: "testpassword"})
+	suite.NotNil(err)
+
+	// 大文字小文字を区別せずに検索できる
+	user, err := testUserRepository.FindUserByUsername("GOPHER")
+	suite.Nil(err)
+	suite.Equal("GoPher", user.Username)
+
+	_, err = testUserRepository.FindUserByUsername("nobody")
+	suite.Equal("user not found", err.Error())
+
+	// 存在しないusernameは無視し、パスワードは取得しない
+	users, err := testUserRepository.FindUsersByUsernames([]string{"gopher", "nobody"})
+	suite.Nil(err)
+	suite.Equal(1, len(users))
+	suite.Equal("GoPher", users[0].Username)
+	suite.Equal("", users[0].Password)
+}
+
+func (suite *UserTestSuite) TestUserProfileQueries() {
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testFollowerRepository := repositories.NewFollowerRepository(models.DB)
+	testTweetRepository := repositories.NewTweetRepository(models.DB)
+
+	alice := &models.User{Name: "Alice Smith", Username: "alice", Email: "alice@example.com", Password: "testpassword"}
+	suite.Nil(testUserRepository.CreateUser(alice))
+	bob := &models.User{Name: "Bob 100%", Username: "bob_alice", Email: "bob@example.com", Password: "testpassword"}
+	suite.Nil(testUserRepository.CreateUser(bob))
+	carol := &models.User{Name: "Carol Bobson", Username: "carol", Email: "carol@example.com", Password: "testpassword"}
+	suite.Nil(testUserRepository.CreateUser(carol))
+
+	// find user by id
+	user, err := testUserRepository.FindUserById(alice.ID)
+	suite.Nil(err)
+	suite.Equal("alice", user.Username)
+	_, err = testUserRepository.FindUserById(9999)
+	suite.Equal("user not found", err.Error())
+
+	// usernameの前方一致または名前の部分一致で検索する(大文字小文字は区別しない)
+	users, err := testUserRepository.SearchUsers("ALICE", nil)
+	suite.Nil(err)
+	suite.Equal(1, len(users.Items))
+	suite.Equal(alice.ID, users.Items[0].ID)
+
+	users, err = testUserRepository.SearchUsers("bob", &pagination.Page{Limit: 1})
+	suite.Nil(err)
+	suite.Equal(1, len(users.Items))
+	suite.Equal(carol.ID, users.Items[0].ID)
+	suite.NotNil(users.NextCursor)
+
+	users, err = testUserRepository.SearchUsers("bob", &pagination.Page{Limit: 1, Cursor: users.NextCursor})
+	suite.Nil(err)
+	suite.Equal(1, len(users.Items))
+	suite.Equal(bob.ID, users.Items[0].ID)
+	suite.Nil(users.NextCursor)
+
+	// LIKEのワイルドカードはそのままの文字として検索する
+	users, err = testUserRepository.SearchUsers("0%", nil)
+	suite.Nil(err)
+	suite.Equal(1, len(users.Items))
+	users, err = testUserRepository.SearchUsers("%", nil)
+	suite.Nil(err)
+	suite.Equal(1, len(users.Items))
+	suite.Equal(bob.ID, users.Items[0].ID)
+
+	// count followers, followings and tweets
+	_, err = testFollowerRepository.CreateFollower(&models.Follower{FollowerID: bob.ID, FolloweeID: alice.ID})
+	suite.Nil(err)
+	_, err = testTweetRepository.CreateTweet(&models.Tweet{UserID: alice.ID, Type: models.Text, Content: "hello"})
+	suite.Nil(err)
+	deletedTweet, err := testTweetRepository.CreateTweet(&models.Tweet{UserID: alice.ID, Type: models.Text, Content: "deleted"})
+	suite.Nil(err)
+	suite.Nil(testTweetRepository.DeleteTweet(deletedTweet.ID))
+
+	stats, err := testUserRepository.CountUserStats([]uint{alice.ID, bob.ID})
+	suite.Nil(err)
+	suite.Equal(repositories.UserStats{FollowerCount: 1, FollowingCount: 0, TweetCount: 1}, *stats[alice.ID])
+	suite.Equal(repositories.UserStats{FollowerCount: 0, FollowingCount: 1, TweetCount: 0}, *stats[bob.ID])
+}
+
+func (suite *UserTestSuite) TestUpdateUserProfile() {
+	testUserRepository := repositories.NewUserRepository(models.DB)
+	testMediaRepository := repositories.NewMediaRepository(models.DB)
+
+	dave := &models.User{Name: "Dave", Username: "dave", Email: "dave@example.com", Password: "testpassword"}
+	suite.Nil(testUserRepository.CreateUser(dave))
+
+	avatar, err := testMediaRepository.CreateMedia(&models.Media{
+		UserID: dave.ID, Type: models.Image, ContentType: "image/png", Size: 1, StorageKey: "avatar.png", URL: "/media/avatar.png",
+	})
+	suite.Nil(err)
+
+	// update profile fields, username and avatar
+	dave.Name = "Dave Jr."
+	dave.Username = "dave_jr"
+	dave.Bio = "hello"
+	dave.Website = "https://example.com"
+	dave.AvatarMediaID = &avatar.ID
+	dave.Email = "changed@example.com" // email is not updated by UpdateUserProfile
+	updated, err := testUserRepository.UpdateUserProfile(dave)
+	suite.Nil(err)
+	suite.Equal("Dave Jr.", updated.Name)
+	suite.Equal("dave_jr", updated.Username)
+	suite.Equal("hello", updated.Bio)
+	suite.Equal("https://example.com", updated.Website)
+	suite.Equal("dave@example.com", updated.Email)
+	suite.NotNil(updated.Avatar)
+	suite.Equal("/media/avatar.png", updated.Avatar.URL)
+	suite.Nil(updated.Header)
+
+	// clear bio and avatar
+	updated.Bio = ""
+	updated.AvatarMediaID = nil
+	updated, err = testUserRepository.UpdateUserProfile(updated)
+	suite.Nil(err)
+	suite.Equal("", updated.Bio)
+	suite.Nil(updated.AvatarMediaID)
+	suite.Nil(updated.Avatar)
+}
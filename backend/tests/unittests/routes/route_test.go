@@ -0,0 +1,82 @@
+//go:build sqlite_fts5
+
+// SetupRouterで作成したルーティングのテスト
+// SQLiteのFTS5を使用するので go test -tags sqlite_fts5 で実行する
+
+package routes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/routes"
+	"github.com/daiki-kim/tweet-app/backend/tests"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+)
+
+type RouteTestSuite struct {
+	tests.DBSQLiteSuite
+	router *gin.Engine
+	stop   func()
+}
+
+func TestRouteTestSuite(t *testing.T) {
+	suite.Run(t, new(RouteTestSuite))
+}
+
+func (suite *RouteTestSuite) SetupSuite() {
+	suite.DBSQLiteSuite.SetupSuite()
+
+	// アップロード先はテスト用の一時ディレクトリにする
+	suite.T().Setenv("MEDIA_LOCAL_DIR", suite.T().TempDir())
+	gin.SetMode(gin.TestMode)
+	suite.router, suite.stop = routes.SetupRouter(models.DB)
+}
+
+func (suite *RouteTestSuite) TearDownSuite() {
+	suite.stop()
+	suite.DBSQLiteSuite.TearDownSuite()
+}
+
+// email未認証のユーザーはtweetを作成するAPI(retweetを含む)を使用できない
+func (suite *RouteTestSuite) TestTweetCreationRequiresVerifiedEmail() {
+	// email未認証のユーザーとそのtweetを準備
+	user := &models.User{
+		Name:     "unverified",
+		Username: "unverified",
+		Email:    "unverified@example.com",
+		Password: "testpassword",
+		Dob:      time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	suite.Nil(repositories.NewUserRepository(models.DB).CreateUser(user))
+	tweet, err := repositories.NewTweetRepository(models.DB).CreateTweet(&models.Tweet{UserID: user.ID, Type: models.Text, Content: "tweet"})
+	suite.Nil(err)
+	tweetId := strconv.FormatUint(uint64(tweet.ID), 10)
+
+	token, err := auth.NewClaim(strconv.FormatUint(uint64(user.ID), 10)).GenerateToken()
+	suite.Nil(err)
+
+	for _, path := range []string{
+		"/api/v1/tweet/",
+		"/api/v1/tweet/" + tweetId + "/reply",
+		"/api/v1/tweet/" + tweetId + "/retweet",
+		"/api/v1/tweet/" + tweetId + "/quote",
+	} {
+		req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(`{"type": "text", "content": "tweet"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		suite.router.ServeHTTP(w, req)
+
+		suite.Equal(http.StatusForbidden, w.Code, path)
+		suite.JSONEq(`{"error": "email is not verified"}`, w.Body.String(), path)
+	}
+}
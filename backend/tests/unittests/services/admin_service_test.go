@@ -0,0 +1,215 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdminGetUsers(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, _, _, testAdminService := prepareTestAdminService()
+
+	// 停止されたユーザーを含めて準備
+	suspendedAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	page := &pagination.Page{Limit: 20}
+	mockUserRepo.On("FindUsers", page).Return(&pagination.List[*models.User]{
+		Items: []*models.User{
+			{ID: 2, Username: "moderator", Email: "moderator@example.com", Password: "hashed", Role: auth.RoleModerator},
+			{ID: 1, Username: "gopher", Email: "gopher@example.com", Password: "hashed", Role: auth.RoleUser, SuspendedAt: &suspendedAt},
+		},
+		NextCursor: &pagination.Cursor{CreatedAt: suspendedAt, ID: 1},
+	}, nil)
+
+	users, err := testAdminService.GetUsers(page)
+
+	assert.NoError(t, err)
+	assert.Len(t, users.Items, 2)
+	assert.Equal(t, uint(1), users.NextCursor.ID)
+	assert.Equal(t, "moderator@example.com", users.Items[0].Email)
+	assert.Equal(t, auth.RoleModerator, users.Items[0].Role)
+	assert.Nil(t, users.Items[0].SuspendedAt)
+	assert.Equal(t, &suspendedAt, users.Items[1].SuspendedAt)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestSuspendUserSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, mockAuthService, _, testAdminService := prepareTestAdminService()
+
+	// モックレポジトリを呼び出し
+	mockUserRepo.On("FindUserById", uint(2)).Return(&models.User{ID: 2, Role: auth.RoleUser}, nil)
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Role: auth.RoleModerator}, nil)
+	mockUserRepo.On("UpdateSuspendedAt", uint(2), mock.MatchedBy(func(suspendedAt *time.Time) bool {
+		return suspendedAt != nil
+	})).Return(nil)
+	// 発行済みのトークンを全て失効させる
+	mockAuthService.On("LogoutAll", uint(2)).Return(nil)
+
+	user, err := testAdminService.SuspendUser(1, 2)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, user.SuspendedAt)
+	mockUserRepo.AssertExpectations(t)
+	mockAuthService.AssertExpectations(t)
+}
+
+// 既に停止されている場合は停止日時を変更せず、トークンの失効のみ行う
+func TestSuspendUserAlreadySuspended(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, mockAuthService, _, testAdminService := prepareTestAdminService()
+
+	// モックレポジトリを呼び出し
+	suspendedAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	mockUserRepo.On("FindUserById", uint(2)).Return(&models.User{ID: 2, Role: auth.RoleUser, SuspendedAt: &suspendedAt}, nil)
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Role: auth.RoleAdmin}, nil)
+	mockAuthService.On("LogoutAll", uint(2)).Return(nil)
+
+	user, err := testAdminService.SuspendUser(1, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &suspendedAt, user.SuspendedAt)
+	mockUserRepo.AssertNotCalled(t, "UpdateSuspendedAt", mock.Anything, mock.Anything)
+	mockAuthService.AssertExpectations(t)
+}
+
+// 自分自身、同じ以上のroleのユーザーは停止できない
+func TestSuspendUserCannotModerate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		actorRole  string
+		targetRole string
+		targetId   uint
+	}{
+		{name: "self", actorRole: auth.RoleAdmin, targetRole: auth.RoleAdmin, targetId: 1},
+		{name: "same role", actorRole: auth.RoleModerator, targetRole: auth.RoleModerator, targetId: 2},
+		{name: "higher role", actorRole: auth.RoleModerator, targetRole: auth.RoleAdmin, targetId: 2},
+		// トークン発行後にroleを外されたユーザー
+		{name: "demoted", actorRole: auth.RoleUser, targetRole: auth.RoleUser, targetId: 2},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックレポジトリを準備
+			mockUserRepo, _, mockAuthService, _, testAdminService := prepareTestAdminService()
+
+			// モックレポジトリを呼び出し
+			mockUserRepo.On("FindUserById", testCase.targetId).Return(&models.User{ID: testCase.targetId, Role: testCase.targetRole}, nil)
+			mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Role: testCase.actorRole}, nil)
+
+			user, err := testAdminService.SuspendUser(1, testCase.targetId)
+
+			assert.Nil(t, user)
+			assert.Equal(t, "cannot moderate this user", err.Error())
+			mockUserRepo.AssertNotCalled(t, "UpdateSuspendedAt", mock.Anything, mock.Anything)
+			mockAuthService.AssertNotCalled(t, "LogoutAll", mock.Anything)
+		})
+	}
+}
+
+func TestSuspendUserNotFound(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, mockAuthService, _, testAdminService := prepareTestAdminService()
+
+	// モックレポジトリを呼び出し
+	mockUserRepo.On("FindUserById", uint(2)).Return(nil, errors.New("user not found"))
+
+	user, err := testAdminService.SuspendUser(1, 2)
+
+	assert.Nil(t, user)
+	assert.Equal(t, "user not found", err.Error())
+	mockAuthService.AssertNotCalled(t, "LogoutAll", mock.Anything)
+}
+
+func TestUnsuspendUserSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, mockAuthService, _, testAdminService := prepareTestAdminService()
+
+	// モックレポジトリを呼び出し
+	suspendedAt := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	mockUserRepo.On("FindUserById", uint(2)).Return(&models.User{ID: 2, Role: auth.RoleUser, SuspendedAt: &suspendedAt}, nil)
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Role: auth.RoleModerator}, nil)
+	mockUserRepo.On("UpdateSuspendedAt", uint(2), (*time.Time)(nil)).Return(nil)
+
+	user, err := testAdminService.UnsuspendUser(1, 2)
+
+	assert.NoError(t, err)
+	assert.Nil(t, user.SuspendedAt)
+	mockUserRepo.AssertExpectations(t)
+	mockAuthService.AssertNotCalled(t, "LogoutAll", mock.Anything)
+}
+
+func TestAdminDeleteTweet(t *testing.T) {
+	// モックサービスを準備
+	mockUserRepo, mockTweetRepo, _, mockTweetService, testAdminService := prepareTestAdminService()
+
+	// moderatorがuserのtweetを削除する
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 2}, nil)
+	mockUserRepo.On("FindUserById", uint(2)).Return(&models.User{ID: 2, Role: auth.RoleUser}, nil)
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Role: auth.RoleModerator}, nil)
+	mockTweetService.On("DeleteAnyTweet", uint(3)).Return(nil)
+
+	err := testAdminService.DeleteTweet(1, 3)
+
+	assert.NoError(t, err)
+	mockTweetService.AssertExpectations(t)
+	mockTweetService.AssertNotCalled(t, "DeleteTweet", mock.Anything, mock.Anything)
+}
+
+func TestAdminDeleteTweetCannotModerate(t *testing.T) {
+	testCases := []struct {
+		name       string
+		actorRole  string
+		authorId   uint
+		authorRole string
+	}{
+		{name: "moderator deletes admin's tweet", actorRole: auth.RoleModerator, authorId: 2, authorRole: auth.RoleAdmin},
+		{name: "moderator deletes moderator's tweet", actorRole: auth.RoleModerator, authorId: 2, authorRole: auth.RoleModerator},
+		{name: "admin deletes own tweet", actorRole: auth.RoleAdmin, authorId: 1, authorRole: auth.RoleAdmin},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックサービスを準備
+			mockUserRepo, mockTweetRepo, _, mockTweetService, testAdminService := prepareTestAdminService()
+
+			mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: testCase.authorId}, nil)
+			mockUserRepo.On("FindUserById", testCase.authorId).Return(&models.User{ID: testCase.authorId, Role: testCase.authorRole}, nil)
+			mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Role: testCase.actorRole}, nil)
+
+			err := testAdminService.DeleteTweet(1, 3)
+
+			assert.Equal(t, "cannot moderate this user", err.Error())
+			mockTweetService.AssertNotCalled(t, "DeleteAnyTweet", mock.Anything)
+		})
+	}
+}
+
+func TestAdminDeleteTweetNotFound(t *testing.T) {
+	// モックサービスを準備
+	_, mockTweetRepo, _, mockTweetService, testAdminService := prepareTestAdminService()
+
+	mockTweetRepo.On("GetTweet", uint(3)).Return(nil, errors.New("tweet not found"))
+
+	err := testAdminService.DeleteTweet(1, 3)
+
+	assert.Equal(t, "tweet not found", err.Error())
+	mockTweetService.AssertNotCalled(t, "DeleteAnyTweet", mock.Anything)
+}
+
+func prepareTestAdminService() (*mocks.MockUserRepository, *mocks.MockTweetRepository, *mocks.MockAuthService, *mocks.MockTweetService, services.IAdminService) {
+	mockUserRepo := &mocks.MockUserRepository{}
+	mockTweetRepo := &mocks.MockTweetRepository{}
+	mockAuthService := &mocks.MockAuthService{}
+	mockTweetService := &mocks.MockTweetService{}
+	testAdminService := services.NewAdminService(mockUserRepo, mockTweetRepo, mockAuthService, mockTweetService)
+	return mockUserRepo, mockTweetRepo, mockAuthService, mockTweetService, testAdminService
+}
@@ -3,11 +3,16 @@ package services_test
 import (
 	"errors"
 	"log"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
 	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+	"github.com/daiki-kim/tweet-app/backend/pkg/mailer"
 	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -17,77 +22,126 @@ import (
 func TestPrepareBaseUserModel(t *testing.T) {
 	// ユーザーモデルを準備
 	name := "testuser"
+	username := "testuser"
 	email := "test@example.com"
 	dobString := "2020-01-01"
 	dob := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 
-	user, err := services.PrepareBaseUserModel(name, email, dobString)
+	user, err := services.PrepareBaseUserModel(name, username, email, dobString)
 
 	assert.NoError(t, err)
 	assert.Equal(t, name, user.Name)
+	assert.Equal(t, username, user.Username)
 	assert.Equal(t, email, user.Email)
 	assert.Equal(t, dob, user.Dob)
 }
 
+// OAuthのcallbackで発行されるaction(サインアップまたは紐づけ)用のticketを準備
+func generateTestOAuthTicket(t *testing.T, action, provider, subject, email, name string) string {
+	ticket, err := (&auth.OAuthTicketClaim{Action: action, Provider: provider, ProviderSubject: subject, Email: email, Name: name}).Generate()
+	assert.NoError(t, err)
+	return ticket
+}
+
 func TestSignupUsingOAuthSuccess(t *testing.T) {
 	// モックレポジトリを準備
 	mockRepo := &mocks.MockUserRepository{}
-	testAuthService := services.NewAuthService(mockRepo)
+	mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, mockUserIdentityRepo, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
 
 	// ユーザーモデルを準備
-	name := "testuser"
 	email := "test@example.com"
 	dobString := "2020-01-01"
 	dob := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
 
+	// nameを入力しない場合はプロバイダーの名前を使用する
 	expectedUser := &models.User{
-		Name:  name,
-		Email: email,
-		Dob:   dob,
+		Name:     "provider name",
+		Username: "testuser",
+		Email:    email,
+		Dob:      dob,
 	}
 
 	// SignupUsingOAuthで使用するmockメソッドを準備
-	mockRepo.On("CreateUser", mock.MatchedBy(func(user *models.User) bool {
-		return user.Name == expectedUser.Name && user.Email == expectedUser.Email && user.Dob == expectedUser.Dob
+	mockRepo.On("FindUserByUsername", "testuser").Return(nil, errors.New("user not found"))
+	mockRepo.On("FindUserByEmail", email).Return(nil, errors.New("user not found"))
+	// Googleで認証済みのemailなので認証済みとして作成し、Googleのアカウントを紐づける
+	mockUserIdentityRepo.On("CreateUserWithIdentity", mock.MatchedBy(func(user *models.User) bool {
+		return user.Name == expectedUser.Name && user.Username == expectedUser.Username && user.Email == expectedUser.Email && user.Dob == expectedUser.Dob && user.EmailVerified() && user.Password == ""
+	}), &models.UserIdentity{Provider: "google", Subject: "google-1", Email: email}).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.User).ID = 1
+	}).Return(nil)
+	// 作成したユーザーのtokenを発行する
+	mockRefreshTokenRepo.On("CreateRefreshToken", mock.MatchedBy(func(refreshToken *models.RefreshToken) bool {
+		return refreshToken.UserID == 1
 	})).Return(nil)
 
 	// サインアップ
-	err := testAuthService.SignupUsingOAuth(name, email, dobString)
+	ticket := generateTestOAuthTicket(t, auth.OAuthTicketActionSignup, "google", "google-1", email, "provider name")
+	loginResponse, err := testAuthService.SignupUsingOAuth(ticket, "", "testuser", dobString)
 
 	assert.NoError(t, err)
+	assert.NotEmpty(t, loginResponse.Token)
+	assert.NotEmpty(t, loginResponse.RefreshToken)
 	mockRepo.AssertExpectations(t)
+	mockUserIdentityRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
 }
 
-func TestSignupUsingOAuthErrorByNonEmail(t *testing.T) {
+func TestSignupUsingOAuthInvalidTicket(t *testing.T) {
 	mockRepo := &mocks.MockUserRepository{}
-	testAuthService := services.NewAuthService(mockRepo)
+	mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+	testAuthService := services.NewAuthService(mockRepo, &mocks.MockRefreshTokenRepository{}, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, mockUserIdentityRepo, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
 
-	// emailが入力されていないユーザーモデルを準備
-	name := "testuser"
-	email := ""
-	dobString := "2020-01-01"
-	dob := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// ticket以外のトークン(mfaトークン)、改ざんされたticket、紐づけ用のticketは受け付けない
+	mfaToken, _ := auth.NewClaim("1").GenerateMFAToken()
+	ticket := generateTestOAuthTicket(t, auth.OAuthTicketActionSignup, "google", "google-1", "test@example.com", "testuser")
+	linkTicket := generateTestOAuthTicket(t, auth.OAuthTicketActionLink, "google", "google-1", "test@example.com", "testuser")
+	for _, invalidTicket := range []string{mfaToken, ticket + "x", "invalid", linkTicket} {
+		loginResponse, err := testAuthService.SignupUsingOAuth(invalidTicket, "testuser", "testuser", "2020-01-01")
 
-	wrongUser := &models.User{
-		Name:  name,
-		Email: email,
-		Dob:   dob,
+		assert.Nil(t, loginResponse)
+		assert.Equal(t, "invalid oauth ticket", err.Error())
 	}
+	mockUserIdentityRepo.AssertNotCalled(t, "CreateUserWithIdentity", mock.Anything, mock.Anything)
+}
 
-	// SignupUsingOAuthで使用するmockメソッドを準備
-	mockRepo.On("CreateUser", wrongUser).Return(errors.New("email is required"))
+func TestSignupUsingOAuthUsernameAlreadyTaken(t *testing.T) {
+	mockRepo := &mocks.MockUserRepository{}
+	mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+	testAuthService := services.NewAuthService(mockRepo, &mocks.MockRefreshTokenRepository{}, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, mockUserIdentityRepo, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
 
-	// サインアップ
-	err := testAuthService.SignupUsingOAuth(name, email, dobString)
+	mockRepo.On("FindUserByUsername", "testuser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
 
-	assert.Error(t, err)
-	mockRepo.AssertExpectations(t)
+	ticket := generateTestOAuthTicket(t, auth.OAuthTicketActionSignup, "google", "google-1", "test@example.com", "testuser")
+	loginResponse, err := testAuthService.SignupUsingOAuth(ticket, "testuser", "testuser", "2020-01-01")
+
+	assert.Nil(t, loginResponse)
+	assert.Equal(t, "username is already taken", err.Error())
+	mockUserIdentityRepo.AssertNotCalled(t, "CreateUserWithIdentity", mock.Anything, mock.Anything)
+}
+
+func TestSignupUsingOAuthEmailAlreadyTaken(t *testing.T) {
+	mockRepo := &mocks.MockUserRepository{}
+	mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+	testAuthService := services.NewAuthService(mockRepo, &mocks.MockRefreshTokenRepository{}, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, mockUserIdentityRepo, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	// 同じemailのユーザーが既にいる場合はサインアップできない
+	mockRepo.On("FindUserByUsername", "testuser").Return(nil, errors.New("user not found"))
+	mockRepo.On("FindUserByEmail", "test@example.com").Return(&models.User{ID: 1, Email: "test@example.com"}, nil)
+
+	ticket := generateTestOAuthTicket(t, auth.OAuthTicketActionSignup, "google", "google-1", "test@example.com", "testuser")
+	loginResponse, err := testAuthService.SignupUsingOAuth(ticket, "testuser", "testuser", "2020-01-01")
+
+	assert.Nil(t, loginResponse)
+	assert.Equal(t, "email is already taken", err.Error())
+	mockUserIdentityRepo.AssertNotCalled(t, "CreateUserWithIdentity", mock.Anything, mock.Anything)
 }
 
 func TestSignupSuccess(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo := &mocks.MockUserRepository{}
-	testAuthService := services.NewAuthService(mockRepo)
+	mockRepo, mockVerificationTokenRepo, logMailer, testAuthService := prepareTestEmailVerificationAuthService()
 
 	// ユーザーモデルを準備
 	name := "testuser"
@@ -100,6 +154,7 @@ func TestSignupSuccess(t *testing.T) {
 
 	expectedUser := &models.User{
 		Name:     name,
+		Username: "testuser",
 		Email:    email,
 		Password: hashedPasswordString,
 		Dob:      dob,
@@ -108,60 +163,182 @@ func TestSignupSuccess(t *testing.T) {
 	log.Println(hashedPassword)
 
 	// SignupUsingOAuthで使用するmockメソッドを準備
+	mockRepo.On("FindUserByUsername", "testuser").Return(nil, errors.New("user not found"))
 	mockRepo.On("CreateUser", mock.MatchedBy(func(user *models.User) bool {
 		return user.Name == expectedUser.Name &&
+			user.Username == expectedUser.Username &&
 			user.Email == expectedUser.Email &&
 			user.Dob == expectedUser.Dob &&
+			!user.EmailVerified() &&
 			bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil
-	})).Return(nil)
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.User).ID = 1
+	}).Return(nil)
+	var savedToken *models.EmailVerificationToken
+	mockVerificationTokenRepo.On("CreateEmailVerificationToken", mock.Anything).Run(func(args mock.Arguments) {
+		savedToken = args.Get(0).(*models.EmailVerificationToken)
+	}).Return(nil)
 
 	// サインアップ
-	err := testAuthService.Signup(name, email, dobString, password)
+	err := testAuthService.Signup(name, "testuser", email, dobString, password)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+
+	// email未認証で作成し、認証メールを送信する
+	assert.Equal(t, uint(1), savedToken.UserID)
+	assert.Equal(t, email, savedToken.Email)
+	messages := logMailer.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, email, messages[0].To)
+	token := regexp.MustCompile(`verify-email\?token=([A-Za-z0-9_-]+)`).FindStringSubmatch(messages[0].Body)
+	assert.Equal(t, 2, len(token))
+	assert.Equal(t, auth.HashOneTimeToken(token[1]), savedToken.TokenHash)
 }
 
-func TestLoginUsingOAuthSuccess(t *testing.T) {
+func TestSignupSendVerificationEmailFailed(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, mockVerificationTokenRepo, logMailer, testAuthService := prepareTestEmailVerificationAuthService()
+
+	mockRepo.On("FindUserByUsername", "testuser").Return(nil, errors.New("user not found"))
+	mockRepo.On("CreateUser", mock.Anything).Return(nil)
+	mockVerificationTokenRepo.On("CreateEmailVerificationToken", mock.Anything).Return(errors.New("db error"))
+
+	// 認証メールの送信に失敗してもサインアップは成功する(再送信できる)
+	err := testAuthService.Signup("testuser", "testuser", "test@example.com", "2020-01-01", "testpassword")
+
+	assert.NoError(t, err)
+	assert.Empty(t, logMailer.Messages())
+}
+
+func TestSignupUsernameAlreadyTaken(t *testing.T) {
 	// モックレポジトリを準備
 	mockRepo := &mocks.MockUserRepository{}
-	testAuthService := services.NewAuthService(mockRepo)
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
 
-	// ユーザーモデルを準備
-	name := "testuser"
-	email := "test@example.com"
-	dob := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 大文字小文字だけ異なるusernameのユーザーが既に存在する
+	mockRepo.On("FindUserByUsername", "TestUser").Return(&models.User{ID: 1, Username: "testuser"}, nil)
 
-	expectedUser := &models.User{
-		Name:  name,
-		Email: email,
-		Dob:   dob,
-	}
+	// サインアップ
+	err := testAuthService.Signup("testuser", "TestUser", "test@example.com", "2020-01-01", "testpassword")
 
-	// FindUserByEmailで使用するmockメソッドを準備
-	mockRepo.On("FindUserByEmail", email).Return(expectedUser, nil)
+	assert.Equal(t, "username is already taken", err.Error())
+	mockRepo.AssertNotCalled(t, "CreateUser")
+}
 
-	// ログイン
-	loginResponse, err := testAuthService.LoginUsingOAuth(expectedUser.Email)
+func TestSignupInvalidUsername(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	// usernameに使えない文字を含む
+	err := testAuthService.Signup("testuser", "test-user", "test@example.com", "2020-01-01", "testpassword")
+
+	assert.Equal(t, "invalid username", err.Error())
+	mockRepo.AssertNotCalled(t, "CreateUser")
+}
+
+// OAuthのログインのテスト用にAuthServiceを準備(2段階認証は無効)
+func prepareTestOAuthLoginAuthService() (*mocks.MockUserRepository, *mocks.MockUserIdentityRepository, *mocks.MockRefreshTokenRepository, services.IAuthService) {
+	mockRepo := &mocks.MockUserRepository{}
+	mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	mockTwoFactorService := &mocks.MockTwoFactorService{}
+	mockTwoFactorService.On("IsEnabled", mock.Anything).Return(false, nil)
+	mockRefreshTokenRepo.On("CreateRefreshToken", mock.MatchedBy(func(refreshToken *models.RefreshToken) bool {
+		return refreshToken.TokenID != "" && refreshToken.FamilyID != ""
+	})).Return(nil)
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, mockUserIdentityRepo, mockTwoFactorService, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	return mockRepo, mockUserIdentityRepo, mockRefreshTokenRepo, testAuthService
+}
+
+// 紐づけられたプロバイダーのアカウントでログイン
+// プロバイダーでemailが変わっていてもログインでき、紐づけのemailを更新する
+func TestLoginUsingOAuthSuccess(t *testing.T) {
+	mockRepo, mockUserIdentityRepo, mockRefreshTokenRepo, testAuthService := prepareTestOAuthLoginAuthService()
+
+	mockUserIdentityRepo.On("FindUserIdentity", "google", "google-1").Return(&models.UserIdentity{ID: 10, UserID: 1, Provider: "google", Subject: "google-1", Email: "old@example.com"}, nil)
+	mockUserIdentityRepo.On("UpdateUserIdentityEmail", uint(10), "new@example.com").Return(nil)
+	mockRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Email: "old@example.com"}, nil)
+
+	// ログイン
+	loginResponse, err := testAuthService.LoginUsingOAuth("google", "google-1", "new@example.com")
 
 	assert.NoError(t, err)
-	assert.NotNil(t, loginResponse)
-	mockRepo.AssertExpectations(t)
+	assert.NotEmpty(t, loginResponse.Token)
+	mockUserIdentityRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
+	// emailでユーザーを探さない
+	mockRepo.AssertNotCalled(t, "FindUserByEmail", mock.Anything)
+}
+
+// 紐づけの導入前にOAuthでサインアップしたユーザー(パスワードなし、紐づけなし)はemailで紐づけてログインする
+func TestLoginUsingOAuthLinksLegacyUser(t *testing.T) {
+	mockRepo, mockUserIdentityRepo, mockRefreshTokenRepo, testAuthService := prepareTestOAuthLoginAuthService()
+
+	mockUserIdentityRepo.On("FindUserIdentity", "google", "google-1").Return(nil, errors.New("user identity not found"))
+	mockRepo.On("FindUserByEmail", "test@example.com").Return(&models.User{ID: 1, Email: "test@example.com"}, nil)
+	mockUserIdentityRepo.On("FindUserIdentitiesByUserId", uint(1)).Return([]*models.UserIdentity{}, nil)
+	mockUserIdentityRepo.On("CreateUserIdentity", &models.UserIdentity{UserID: 1, Provider: "google", Subject: "google-1", Email: "test@example.com"}).Return(nil)
+
+	loginResponse, err := testAuthService.LoginUsingOAuth("google", "google-1", "test@example.com")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, loginResponse.Token)
+	mockUserIdentityRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+// 同じemailのユーザーがいても、パスワードがあるか別のアカウントを紐づけ済みの場合はログインしない
+func TestLoginUsingOAuthIdentityNotLinked(t *testing.T) {
+	testCases := []struct {
+		name       string
+		user       *models.User
+		identities []*models.UserIdentity
+	}{
+		{
+			name: "user has password",
+			user: &models.User{ID: 1, Email: "test@example.com", Password: "hashedpassword"},
+		},
+		{
+			name:       "user has another identity",
+			user:       &models.User{ID: 1, Email: "test@example.com"},
+			identities: []*models.UserIdentity{{UserID: 1, Provider: "google", Subject: "google-2"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockRepo, mockUserIdentityRepo, mockRefreshTokenRepo, testAuthService := prepareTestOAuthLoginAuthService()
+
+			mockUserIdentityRepo.On("FindUserIdentity", "google", "google-1").Return(nil, errors.New("user identity not found"))
+			mockRepo.On("FindUserByEmail", "test@example.com").Return(testCase.user, nil)
+			mockUserIdentityRepo.On("FindUserIdentitiesByUserId", uint(1)).Return(testCase.identities, nil)
+
+			loginResponse, err := testAuthService.LoginUsingOAuth("google", "google-1", "test@example.com")
+
+			assert.Equal(t, "identity is not linked", err.Error())
+			assert.Nil(t, loginResponse)
+			mockUserIdentityRepo.AssertNotCalled(t, "CreateUserIdentity", mock.Anything)
+			mockRefreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+		})
+	}
 }
 
 func TestLoginUsingOAuthUserNotFound(t *testing.T) {
-	// モックレポジトリを準備
-	mockRepo := &mocks.MockUserRepository{}
-	testAuthService := services.NewAuthService(mockRepo)
+	mockRepo, mockUserIdentityRepo, _, testAuthService := prepareTestOAuthLoginAuthService()
 
 	// ユーザーが存在しないemailを準備
 	notExistEmail := "test@example.com"
 
-	// FindUserByEmailで使用するmockメソッドを準備
+	mockUserIdentityRepo.On("FindUserIdentity", "google", "google-1").Return(nil, errors.New("user identity not found"))
 	mockRepo.On("FindUserByEmail", notExistEmail).Return(nil, errors.New("user not found"))
 
-	// ログイン
-	loginResponse, err := testAuthService.LoginUsingOAuth(notExistEmail)
+	// ログイン
+	loginResponse, err := testAuthService.LoginUsingOAuth("google", "google-1", notExistEmail)
 
 	assert.Equal(t, "user not found", err.Error())
 	assert.Nil(t, loginResponse)
@@ -171,7 +348,9 @@ func TestLoginUsingOAuthUserNotFound(t *testing.T) {
 func TestLoginSuccess(t *testing.T) {
 	// モックレポジトリを準備
 	mockRepo := &mocks.MockUserRepository{}
-	testAuthService := services.NewAuthService(mockRepo)
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	mockTwoFactorService := &mocks.MockTwoFactorService{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, mockTwoFactorService, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
 
 	// ユーザーモデルを準備
 	name := "testuser"
@@ -185,6 +364,7 @@ func TestLoginSuccess(t *testing.T) {
 		Name:     name,
 		Email:    email,
 		Password: hashedPasswordString,
+		Role:     auth.RoleAdmin,
 		Dob:      dob,
 	}
 
@@ -193,18 +373,61 @@ func TestLoginSuccess(t *testing.T) {
 		return email == expectedUser.Email
 	})).Return(expectedUser, nil)
 
+	// 2段階認証は無効
+	mockTwoFactorService.On("IsEnabled", expectedUser.ID).Return(false, nil)
+
+	// 発行したリフレッシュトークンの保存で使用するmockメソッドを準備
+	mockRefreshTokenRepo.On("CreateRefreshToken", mock.MatchedBy(func(refreshToken *models.RefreshToken) bool {
+		return refreshToken.TokenID != "" && refreshToken.FamilyID != ""
+	})).Return(nil)
+
 	// ログイン
-	loginResponse, err := testAuthService.Login(email, password)
+	loginResponse, err := testAuthService.Login(email, password, "127.0.0.1")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, loginResponse)
+	mockRefreshTokenRepo.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
+
+	// アクセストークンにはユーザーのroleと権限を含める
+	claim, err := auth.ValidateAccessToken(loginResponse.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, auth.RoleAdmin, claim.Role)
+	assert.ElementsMatch(t, auth.RolePermissions(auth.RoleAdmin), claim.Permissions)
+}
+
+// 停止されたアカウントはパスワードが正しくてもログインできない
+func TestLoginSuspendedUser(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	mockTwoFactorService := &mocks.MockTwoFactorService{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, mockTwoFactorService, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("testpassword"), bcrypt.DefaultCost)
+	suspendedAt := time.Now()
+	mockRepo.On("FindUserByEmail", "test@example.com").Return(&models.User{ID: 1, Email: "test@example.com", Password: string(hashedPassword), SuspendedAt: &suspendedAt}, nil)
+
+	// パスワードが正しい場合
+	loginResponse, err := testAuthService.Login("test@example.com", "testpassword", "127.0.0.1")
+	assert.Nil(t, loginResponse)
+	assert.Equal(t, "account is suspended", err.Error())
+
+	// パスワードが違う場合は停止されていることを返さない
+	loginResponse, err = testAuthService.Login("test@example.com", "wrongpassword", "127.0.0.1")
+	assert.Nil(t, loginResponse)
+	assert.Equal(t, "invalid email or password", err.Error())
+
+	// 2段階認証の確認、トークンの発行を行わない
+	mockTwoFactorService.AssertNotCalled(t, "IsEnabled", mock.Anything)
+	mockRefreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
 }
 
 func TestLoginNotUserFound(t *testing.T) {
 	// モックレポジトリを準備
 	mockRepo := &mocks.MockUserRepository{}
-	testAuthService := services.NewAuthService(mockRepo)
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
 
 	// ユーザーが存在しないemailとpasswordを準備
 	notExistEmail := "test@example.com"
@@ -214,9 +437,10 @@ func TestLoginNotUserFound(t *testing.T) {
 	mockRepo.On("FindUserByEmail", notExistEmail).Return(nil, errors.New("user not found"))
 
 	// ログイン
-	loginResponse, err := testAuthService.Login(notExistEmail, notExistPassword)
+	loginResponse, err := testAuthService.Login(notExistEmail, notExistPassword, "127.0.0.1")
 
-	assert.Equal(t, "user not found", err.Error())
+	// パスワードが違う場合と同じエラーを返す
+	assert.Equal(t, "invalid email or password", err.Error())
 	assert.Nil(t, loginResponse)
 	mockRepo.AssertExpectations(t)
 }
@@ -224,7 +448,8 @@ func TestLoginNotUserFound(t *testing.T) {
 func TestLoginInvalidPassword(t *testing.T) {
 	// モックレポジトリを準備
 	mockRepo := &mocks.MockUserRepository{}
-	testAuthService := services.NewAuthService(mockRepo)
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
 
 	// ユーザーモデルを準備
 	name := "testuser"
@@ -248,9 +473,745 @@ func TestLoginInvalidPassword(t *testing.T) {
 	})).Return(expectedUser, nil)
 
 	// 不正なパスワードでログイン
-	loginResponse, err := testAuthService.Login(email, wrongPassword)
+	loginResponse, err := testAuthService.Login(email, wrongPassword, "127.0.0.1")
+
+	assert.Equal(t, "invalid email or password", err.Error())
+	assert.Nil(t, loginResponse)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefreshTokenSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	// 発行済みのリフレッシュトークンを準備
+	refreshToken, storedToken := prepareTestRefreshToken(t)
+
+	// RefreshTokenで使用するmockメソッドを準備
+	mockRefreshTokenRepo.On("FindRefreshToken", storedToken.TokenID).Return(storedToken, nil)
+	mockRefreshTokenRepo.On("MarkRefreshTokenUsed", storedToken.TokenID).Return(nil)
+	mockRefreshTokenRepo.On("CreateRefreshToken", mock.MatchedBy(func(refreshToken *models.RefreshToken) bool {
+		// rotateされたリフレッシュトークンは同じfamilyで新しいjtiを持つ
+		return refreshToken.TokenID != storedToken.TokenID &&
+			refreshToken.FamilyID == storedToken.FamilyID &&
+			refreshToken.UserID == storedToken.UserID
+	})).Return(nil)
+
+	// ログイン後に変更されたroleでアクセストークンを発行する
+	mockRepo.On("FindUserById", storedToken.UserID).Return(&models.User{ID: storedToken.UserID, Role: auth.RoleModerator}, nil)
+
+	// トークンを再発行
+	loginResponse, err := testAuthService.RefreshToken(refreshToken)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, loginResponse)
+	assert.NotEqual(t, refreshToken, loginResponse.RefreshToken)
+	claim, err := auth.ValidateAccessToken(loginResponse.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, auth.RoleModerator, claim.Role)
+	assert.True(t, claim.HasPermission(auth.PermissionDeleteTweets))
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+// 停止されたアカウントはトークンを再発行できない
+func TestRefreshTokenSuspendedUser(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	refreshToken, storedToken := prepareTestRefreshToken(t)
+	suspendedAt := time.Now()
+	mockRefreshTokenRepo.On("FindRefreshToken", storedToken.TokenID).Return(storedToken, nil)
+	mockRefreshTokenRepo.On("MarkRefreshTokenUsed", storedToken.TokenID).Return(nil)
+	mockRepo.On("FindUserById", storedToken.UserID).Return(&models.User{ID: storedToken.UserID, SuspendedAt: &suspendedAt}, nil)
+
+	loginResponse, err := testAuthService.RefreshToken(refreshToken)
 
-	assert.Equal(t, "invalid password", err.Error())
 	assert.Nil(t, loginResponse)
+	assert.Equal(t, "account is suspended", err.Error())
+	mockRefreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+func TestRefreshTokenReuseDetected(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	// rotate済みのリフレッシュトークンを準備
+	refreshToken, storedToken := prepareTestRefreshToken(t)
+	usedAt := time.Now()
+	storedToken.UsedAt = &usedAt
+
+	// RefreshTokenで使用するmockメソッドを準備
+	mockRefreshTokenRepo.On("FindRefreshToken", storedToken.TokenID).Return(storedToken, nil)
+	mockRefreshTokenRepo.On("MarkRefreshTokenUsed", storedToken.TokenID).Return(errors.New("refresh token already used"))
+	mockRefreshTokenRepo.On("RevokeFamily", storedToken.FamilyID).Return(nil)
+
+	// 再利用されたリフレッシュトークンでトークンを再発行
+	loginResponse, err := testAuthService.RefreshToken(refreshToken)
+
+	assert.Equal(t, "refresh token reuse detected", err.Error())
+	assert.Nil(t, loginResponse)
+	mockRefreshTokenRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+func TestRefreshTokenRevoked(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	// 失効済みのリフレッシュトークンを準備
+	refreshToken, storedToken := prepareTestRefreshToken(t)
+	revokedAt := time.Now()
+	storedToken.RevokedAt = &revokedAt
+
+	// RefreshTokenで使用するmockメソッドを準備
+	mockRefreshTokenRepo.On("FindRefreshToken", storedToken.TokenID).Return(storedToken, nil)
+
+	loginResponse, err := testAuthService.RefreshToken(refreshToken)
+
+	assert.Equal(t, "invalid refresh token", err.Error())
+	assert.Nil(t, loginResponse)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+func TestRefreshTokenWithAccessToken(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	// アクセストークンはリフレッシュトークンとして使用できない
+	accessToken, err := auth.NewClaim("1").GenerateToken()
+	assert.NoError(t, err)
+
+	loginResponse, err := testAuthService.RefreshToken(accessToken)
+
+	assert.Equal(t, "invalid refresh token", err.Error())
+	assert.Nil(t, loginResponse)
+	mockRefreshTokenRepo.AssertNotCalled(t, "FindRefreshToken", mock.Anything)
+}
+
+func TestLogoutSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	// 発行済みのリフレッシュトークンを準備
+	refreshToken, storedToken := prepareTestRefreshToken(t)
+
+	// Logoutで使用するmockメソッドを準備
+	mockRefreshTokenRepo.On("FindRefreshToken", storedToken.TokenID).Return(storedToken, nil)
+	mockRefreshTokenRepo.On("RevokeFamily", storedToken.FamilyID).Return(nil)
+
+	err := testAuthService.Logout(refreshToken, "")
+
+	assert.NoError(t, err)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+// ログアウト時に渡されたアクセストークンはjtiで失効させる
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	// モックレポジトリを準備
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testTokenRevocationRepo := repositories.NewInMemoryTokenRevocationRepository()
+	testAuthService := services.NewAuthService(&mocks.MockUserRepository{}, mockRefreshTokenRepo, testTokenRevocationRepo, &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	// 発行済みのリフレッシュトークンを準備
+	refreshToken, storedToken := prepareTestRefreshToken(t)
+	mockRefreshTokenRepo.On("FindRefreshToken", storedToken.TokenID).Return(storedToken, nil)
+	mockRefreshTokenRepo.On("RevokeFamily", storedToken.FamilyID).Return(nil)
+
+	// 同じユーザーのアクセストークンと他のユーザーのアクセストークンを準備
+	accessTokenClaim := auth.NewClaim("1")
+	accessToken, err := accessTokenClaim.GenerateToken()
+	assert.NoError(t, err)
+	otherUserClaim := auth.NewClaim("2")
+	otherUserAccessToken, err := otherUserClaim.GenerateToken()
+	assert.NoError(t, err)
+
+	// 同じユーザーのアクセストークンは失効する
+	err = testAuthService.Logout(refreshToken, accessToken)
+	assert.NoError(t, err)
+	revoked, err := testTokenRevocationRepo.IsRevoked(accessTokenClaim.ID, 1, accessTokenClaim.IssuedAt.Time)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	// 他のユーザーのアクセストークンは失効させない
+	err = testAuthService.Logout(refreshToken, otherUserAccessToken)
+	assert.NoError(t, err)
+	revoked, err = testTokenRevocationRepo.IsRevoked(otherUserClaim.ID, 2, otherUserClaim.IssuedAt.Time)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// 検証できないアクセストークンは無視する
+	err = testAuthService.Logout(refreshToken, "invalid")
+	assert.NoError(t, err)
+}
+
+// テスト用のリフレッシュトークンと保存されているリフレッシュトークンを準備
+func prepareTestRefreshToken(t *testing.T) (string, *models.RefreshToken) {
+	claim := auth.NewClaim("1")
+	claim.FamilyId = "test-family"
+	refreshToken, err := claim.GenerateRefreshToken()
+	assert.NoError(t, err)
+
+	storedToken := &models.RefreshToken{
+		ID:        1,
+		UserID:    1,
+		TokenID:   claim.ID,
+		FamilyID:  claim.FamilyId,
+		ExpiresAt: claim.ExpiresAt.Time,
+	}
+
+	return refreshToken, storedToken
+}
+
+func TestLogoutAllSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testTokenRevocationRepo := repositories.NewInMemoryTokenRevocationRepository()
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, testTokenRevocationRepo, &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	// ログアウト前に発行されたアクセストークンを準備
+	issuedAt := time.Now().Add(-time.Minute)
+
+	// LogoutAllで使用するmockメソッドを準備
+	mockRefreshTokenRepo.On("RevokeUserRefreshTokens", uint(1)).Return(nil)
+
+	err := testAuthService.LogoutAll(1)
+	assert.NoError(t, err)
+
+	// ログアウト前に発行されたトークンは失効している
+	revoked, err := testTokenRevocationRepo.IsRevoked("token-before-logout", 1, issuedAt)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	// 他のユーザーのトークンは失効していない
+	revoked, err = testTokenRevocationRepo.IsRevoked("other-user-token", 2, issuedAt)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// ログアウト直後(iatが同じ秒)に再ログインして発行したトークンは失効していない
+	newClaim := auth.NewClaim("1")
+	_, err = newClaim.GenerateToken()
+	assert.NoError(t, err)
+	revoked, err = testTokenRevocationRepo.IsRevoked(newClaim.ID, 1, newClaim.IssuedAt.Time)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+func TestChangePasswordSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, mockRefreshTokenRepo, testTokenRevocationRepo, mockResetTokenRepo, _, testAuthService := prepareTestPasswordAuthService()
+
+	// 現在のパスワードを準備
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("oldpassword"), bcrypt.DefaultCost)
+	assert.NoError(t, err)
+	mockRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword)}, nil)
+
+	// 新しいパスワードはハッシュ化して保存する
+	mockRepo.On("UpdatePassword", uint(1), mock.MatchedBy(func(hashed string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hashed), []byte("newpassword")) == nil
+	})).Return(nil)
+	mockResetTokenRepo.On("InvalidateUserPasswordResetTokens", uint(1)).Return(nil)
+	mockRefreshTokenRepo.On("RevokeUserRefreshTokens", uint(1)).Return(nil)
+	mockRefreshTokenRepo.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	// 変更前に発行されたアクセストークン
+	issuedAt := time.Now().Add(-time.Minute)
+
+	loginResponse, err := testAuthService.ChangePassword(1, "oldpassword", "newpassword")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, loginResponse.Token)
+	assert.NotEmpty(t, loginResponse.RefreshToken)
+
+	// 変更前のセッションは失効し、新しく発行したトークンは使用できる
+	revoked, err := testTokenRevocationRepo.IsRevoked("token-before-change", 1, issuedAt)
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	claim, err := auth.ValidateAccessToken(loginResponse.Token)
+	assert.NoError(t, err)
+	revoked, err = testTokenRevocationRepo.IsRevoked(claim.ID, 1, claim.IssuedAt.Time)
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
 	mockRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
+	mockResetTokenRepo.AssertExpectations(t)
+}
+
+func TestChangePasswordInvalidPassword(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, _, _, _, _, testAuthService := prepareTestPasswordAuthService()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("oldpassword"), bcrypt.DefaultCost)
+	assert.NoError(t, err)
+	mockRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Password: string(hashedPassword)}, nil)
+
+	// 現在のパスワードが違う場合は変更しない
+	_, err = testAuthService.ChangePassword(1, "wrongpassword", "newpassword")
+	assert.Equal(t, "invalid password", err.Error())
+
+	// 同じパスワードには変更できない
+	_, err = testAuthService.ChangePassword(1, "oldpassword", "oldpassword")
+	assert.Equal(t, "new password must be different from the current password", err.Error())
+
+	mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+}
+
+func TestForgotPasswordSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, _, _, mockResetTokenRepo, logMailer, testAuthService := prepareTestPasswordAuthService()
+
+	mockRepo.On("FindUserByEmail", "test@example.com").Return(&models.User{ID: 1, Name: "testuser", Email: "test@example.com"}, nil)
+	mockResetTokenRepo.On("InvalidateUserPasswordResetTokens", uint(1)).Return(nil)
+
+	// DBにはtokenのhashのみを保存する
+	var savedToken *models.PasswordResetToken
+	mockResetTokenRepo.On("CreatePasswordResetToken", mock.Anything).Run(func(args mock.Arguments) {
+		savedToken = args.Get(0).(*models.PasswordResetToken)
+	}).Return(nil)
+
+	err := testAuthService.ForgotPassword("test@example.com")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), savedToken.UserID)
+	assert.WithinDuration(t, time.Now().Add(services.PasswordResetTokenExpiration), savedToken.ExpiresAt, time.Minute)
+
+	// メールで送信したtokenのhashが保存されている
+	messages := logMailer.Messages()
+	assert.Equal(t, 1, len(messages))
+	assert.Equal(t, "test@example.com", messages[0].To)
+	token := regexp.MustCompile(`token=([A-Za-z0-9_-]+)`).FindStringSubmatch(messages[0].Body)
+	assert.Equal(t, 2, len(token))
+	assert.Equal(t, auth.HashOneTimeToken(token[1]), savedToken.TokenHash)
+	assert.NotContains(t, messages[0].Body, savedToken.TokenHash)
+	mockResetTokenRepo.AssertExpectations(t)
+}
+
+func TestForgotPasswordUserNotFound(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, _, _, mockResetTokenRepo, logMailer, testAuthService := prepareTestPasswordAuthService()
+
+	mockRepo.On("FindUserByEmail", "unknown@example.com").Return(nil, errors.New("user not found"))
+
+	// 登録されていないemailでもエラーにしないが、メールは送信しない
+	err := testAuthService.ForgotPassword("unknown@example.com")
+
+	assert.NoError(t, err)
+	assert.Empty(t, logMailer.Messages())
+	mockResetTokenRepo.AssertNotCalled(t, "CreatePasswordResetToken", mock.Anything)
+}
+
+func TestResetPasswordSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, mockRefreshTokenRepo, testTokenRevocationRepo, mockResetTokenRepo, _, testAuthService := prepareTestPasswordAuthService()
+
+	token, tokenHash, err := auth.GenerateOneTimeToken()
+	assert.NoError(t, err)
+	mockResetTokenRepo.On("FindPasswordResetToken", tokenHash).Return(&models.PasswordResetToken{
+		ID: 5, UserID: 1, TokenHash: tokenHash, ExpiresAt: time.Now().Add(time.Minute),
+	}, nil)
+	mockResetTokenRepo.On("MarkPasswordResetTokenUsed", uint(5)).Return(nil)
+	mockResetTokenRepo.On("InvalidateUserPasswordResetTokens", uint(1)).Return(nil)
+	mockRepo.On("UpdatePassword", uint(1), mock.AnythingOfType("string")).Return(nil)
+	mockRefreshTokenRepo.On("RevokeUserRefreshTokens", uint(1)).Return(nil)
+
+	err = testAuthService.ResetPassword(token, "newpassword")
+
+	// パスワードを変更して全てのセッションを失効させる
+	assert.NoError(t, err)
+	revoked, err := testTokenRevocationRepo.IsRevoked("token-before-reset", 1, time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	mockRepo.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
+	mockResetTokenRepo.AssertExpectations(t)
+}
+
+func TestResetPasswordInvalidToken(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	testCases := []struct {
+		name       string
+		resetToken *models.PasswordResetToken
+		findErr    error
+		markErr    error
+	}{
+		{name: "not found", findErr: errors.New("password reset token not found")},
+		{name: "expired", resetToken: &models.PasswordResetToken{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(-time.Second)}},
+		{name: "used", resetToken: &models.PasswordResetToken{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(time.Minute), UsedAt: &usedAt}},
+		{name: "used concurrently", resetToken: &models.PasswordResetToken{ID: 5, UserID: 1, ExpiresAt: time.Now().Add(time.Minute)}, markErr: errors.New("password reset token already used")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックレポジトリを準備
+			mockRepo, _, _, mockResetTokenRepo, _, testAuthService := prepareTestPasswordAuthService()
+
+			mockResetTokenRepo.On("FindPasswordResetToken", auth.HashOneTimeToken("token")).Return(testCase.resetToken, testCase.findErr)
+			mockResetTokenRepo.On("MarkPasswordResetTokenUsed", uint(5)).Return(testCase.markErr)
+
+			err := testAuthService.ResetPassword("token", "newpassword")
+
+			assert.Equal(t, "invalid password reset token", err.Error())
+			mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func prepareTestPasswordAuthService() (
+	*mocks.MockUserRepository,
+	*mocks.MockRefreshTokenRepository,
+	repositories.ITokenRevocationRepository,
+	*mocks.MockPasswordResetTokenRepository,
+	*mailer.LogMailer,
+	services.IAuthService,
+) {
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testTokenRevocationRepo := repositories.NewInMemoryTokenRevocationRepository()
+	mockResetTokenRepo := &mocks.MockPasswordResetTokenRepository{}
+	logMailer := mailer.NewLogMailer()
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, testTokenRevocationRepo, mockResetTokenRepo, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), logMailer)
+
+	return mockRepo, mockRefreshTokenRepo, testTokenRevocationRepo, mockResetTokenRepo, logMailer, testAuthService
+}
+
+func TestVerifyEmailSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, mockVerificationTokenRepo, _, testAuthService := prepareTestEmailVerificationAuthService()
+
+	token, tokenHash, err := auth.GenerateOneTimeToken()
+	assert.NoError(t, err)
+	mockVerificationTokenRepo.On("FindEmailVerificationToken", tokenHash).Return(&models.EmailVerificationToken{
+		ID: 5, UserID: 1, Email: "test@example.com", TokenHash: tokenHash, ExpiresAt: time.Now().Add(time.Minute),
+	}, nil)
+	mockVerificationTokenRepo.On("MarkEmailVerificationTokenUsed", uint(5)).Return(nil)
+	mockRepo.On("MarkEmailVerified", uint(1), "test@example.com").Return(nil)
+
+	err = testAuthService.VerifyEmail(token)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockVerificationTokenRepo.AssertExpectations(t)
+}
+
+func TestVerifyEmailInvalidToken(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+	testCases := []struct {
+		name              string
+		verificationToken *models.EmailVerificationToken
+		findErr           error
+		markErr           error
+		verifyErr         error
+	}{
+		{name: "not found", findErr: errors.New("email verification token not found")},
+		{name: "expired", verificationToken: &models.EmailVerificationToken{ID: 5, UserID: 1, Email: "test@example.com", ExpiresAt: time.Now().Add(-time.Second)}},
+		{name: "used", verificationToken: &models.EmailVerificationToken{ID: 5, UserID: 1, Email: "test@example.com", ExpiresAt: time.Now().Add(time.Minute), UsedAt: &usedAt}},
+		{name: "used concurrently", verificationToken: &models.EmailVerificationToken{ID: 5, UserID: 1, Email: "test@example.com", ExpiresAt: time.Now().Add(time.Minute)}, markErr: errors.New("email verification token already used")},
+		{name: "email changed", verificationToken: &models.EmailVerificationToken{ID: 5, UserID: 1, Email: "test@example.com", ExpiresAt: time.Now().Add(time.Minute)}, verifyErr: errors.New("user not found")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックレポジトリを準備
+			mockRepo, mockVerificationTokenRepo, _, testAuthService := prepareTestEmailVerificationAuthService()
+
+			mockVerificationTokenRepo.On("FindEmailVerificationToken", auth.HashOneTimeToken("token")).Return(testCase.verificationToken, testCase.findErr)
+			mockVerificationTokenRepo.On("MarkEmailVerificationTokenUsed", uint(5)).Return(testCase.markErr)
+			mockRepo.On("MarkEmailVerified", uint(1), "test@example.com").Return(testCase.verifyErr)
+
+			err := testAuthService.VerifyEmail("token")
+
+			assert.Equal(t, "invalid email verification token", err.Error())
+		})
+	}
+}
+
+func TestResendVerificationEmailSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, mockVerificationTokenRepo, logMailer, testAuthService := prepareTestEmailVerificationAuthService()
+
+	mockRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Name: "testuser", Email: "test@example.com"}, nil)
+	mockVerificationTokenRepo.On("CountEmailVerificationTokensSince", uint(1), mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+	mockVerificationTokenRepo.On("CreateEmailVerificationToken", mock.MatchedBy(func(verificationToken *models.EmailVerificationToken) bool {
+		return verificationToken.UserID == 1 && verificationToken.Email == "test@example.com"
+	})).Return(nil)
+
+	err := testAuthService.ResendVerificationEmail(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(logMailer.Messages()))
+	mockVerificationTokenRepo.AssertExpectations(t)
+}
+
+func TestResendVerificationEmailAlreadyVerified(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, mockVerificationTokenRepo, logMailer, testAuthService := prepareTestEmailVerificationAuthService()
+
+	verifiedAt := time.Now()
+	mockRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Email: "test@example.com", EmailVerifiedAt: &verifiedAt}, nil)
+
+	err := testAuthService.ResendVerificationEmail(1)
+
+	assert.Equal(t, "email is already verified", err.Error())
+	assert.Empty(t, logMailer.Messages())
+	mockVerificationTokenRepo.AssertNotCalled(t, "CreateEmailVerificationToken", mock.Anything)
+}
+
+func TestResendVerificationEmailThrottled(t *testing.T) {
+	testCases := []struct {
+		name        string
+		recentCount int64
+		hourlyCount int64
+	}{
+		// 前回の送信から間隔が空いていない
+		{name: "interval", recentCount: 1, hourlyCount: 1},
+		// 1時間の送信回数の上限に達している
+		{name: "hourly limit", recentCount: 0, hourlyCount: services.EmailVerificationResendLimit},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックレポジトリを準備
+			mockRepo, mockVerificationTokenRepo, logMailer, testAuthService := prepareTestEmailVerificationAuthService()
+
+			mockRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Email: "test@example.com"}, nil)
+			mockVerificationTokenRepo.On("CountEmailVerificationTokensSince", uint(1), mock.MatchedBy(func(since time.Time) bool {
+				return time.Since(since) < services.EmailVerificationResendInterval+time.Second
+			})).Return(testCase.recentCount, nil)
+			mockVerificationTokenRepo.On("CountEmailVerificationTokensSince", uint(1), mock.MatchedBy(func(since time.Time) bool {
+				return time.Since(since) >= time.Hour
+			})).Return(testCase.hourlyCount, nil)
+
+			err := testAuthService.ResendVerificationEmail(1)
+
+			assert.Equal(t, "verification email was sent recently", err.Error())
+			assert.Empty(t, logMailer.Messages())
+			mockVerificationTokenRepo.AssertNotCalled(t, "CreateEmailVerificationToken", mock.Anything)
+		})
+	}
+}
+
+func prepareTestEmailVerificationAuthService() (
+	*mocks.MockUserRepository,
+	*mocks.MockEmailVerificationTokenRepository,
+	*mailer.LogMailer,
+	services.IAuthService,
+) {
+	mockRepo := &mocks.MockUserRepository{}
+	mockVerificationTokenRepo := &mocks.MockEmailVerificationTokenRepository{}
+	logMailer := mailer.NewLogMailer()
+	testAuthService := services.NewAuthService(mockRepo, &mocks.MockRefreshTokenRepository{}, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, mockVerificationTokenRepo, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), logMailer)
+
+	return mockRepo, mockVerificationTokenRepo, logMailer, testAuthService
+}
+
+func TestLoginTwoFactorRequired(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	mockTwoFactorService := &mocks.MockTwoFactorService{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, mockTwoFactorService, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("testpassword"), bcrypt.DefaultCost)
+	mockRepo.On("FindUserByEmail", "test@example.com").Return(&models.User{ID: 1, Email: "test@example.com", Password: string(hashedPassword)}, nil)
+	mockTwoFactorService.On("IsEnabled", uint(1)).Return(true, nil)
+
+	loginResponse, err := testAuthService.Login("test@example.com", "testpassword", "127.0.0.1")
+
+	// トークンは発行せず、mfaトークンを返す
+	assert.NoError(t, err)
+	assert.True(t, loginResponse.MFARequired)
+	assert.Empty(t, loginResponse.Token)
+	assert.Empty(t, loginResponse.RefreshToken)
+	claim, err := auth.ValidateMFAToken(loginResponse.MFAToken)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", claim.UserId)
+	mockRefreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+func TestLoginWithTwoFactorSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	mockTwoFactorService := &mocks.MockTwoFactorService{}
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, mockTwoFactorService, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	mfaToken, err := auth.NewClaim("1").GenerateMFAToken()
+	assert.NoError(t, err)
+	mockRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Email: "test@example.com"}, nil)
+	mockTwoFactorService.On("VerifyCode", uint(1), "123456").Return(nil)
+	mockRefreshTokenRepo.On("CreateRefreshToken", mock.MatchedBy(func(refreshToken *models.RefreshToken) bool {
+		return refreshToken.UserID == 1
+	})).Return(nil)
+
+	loginResponse, err := testAuthService.LoginWithTwoFactor(mfaToken, "123456", "127.0.0.1")
+
+	assert.NoError(t, err)
+	assert.False(t, loginResponse.MFARequired)
+	claim, err := auth.ValidateAccessToken(loginResponse.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", claim.UserId)
+	mockTwoFactorService.AssertExpectations(t)
+	mockRefreshTokenRepo.AssertExpectations(t)
+}
+
+func TestLoginWithTwoFactorErrors(t *testing.T) {
+	mfaToken, err := auth.NewClaim("1").GenerateMFAToken()
+	assert.NoError(t, err)
+	accessToken, err := auth.NewClaim("1").GenerateToken()
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name      string
+		mfaToken  string
+		verifyErr error
+		err       string
+	}{
+		// アクセストークンはmfaトークンとして使用できない
+		{name: "access token", mfaToken: accessToken, err: "invalid mfa token"},
+		{name: "invalid code", mfaToken: mfaToken, verifyErr: errors.New("invalid code"), err: "invalid code"},
+		// mfaトークンの発行後に2段階認証が無効になった
+		{name: "disabled", mfaToken: mfaToken, verifyErr: errors.New("two-factor authentication is not enabled"), err: "invalid mfa token"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックレポジトリを準備
+			mockRepo := &mocks.MockUserRepository{}
+			mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+			mockTwoFactorService := &mocks.MockTwoFactorService{}
+			testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, mockTwoFactorService, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+			mockRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Email: "test@example.com"}, nil)
+			mockTwoFactorService.On("VerifyCode", uint(1), "123456").Return(testCase.verifyErr)
+
+			_, err := testAuthService.LoginWithTwoFactor(testCase.mfaToken, "123456", "127.0.0.1")
+
+			assert.Equal(t, testCase.err, err.Error())
+			mockRefreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+		})
+	}
+}
+
+// 失敗が続いたアカウントは正しいパスワードでもログインできない
+func TestLoginTooManyAttempts(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	testClock := clock.NewFake(time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC))
+	testLoginAttemptRepo := repositories.NewInMemoryLoginAttemptRepository()
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(testLoginAttemptRepo, testClock), mailer.NewLogMailer())
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.MinCost)
+	mockRepo.On("FindUserByEmail", "test@example.com").Return(&models.User{ID: 1, Email: "test@example.com", Password: string(hashedPassword)}, nil)
+
+	// 待ち時間なしで失敗できる回数を超えて失敗する
+	for i := 0; i <= services.AccountLoginAttemptPolicy.FreeAttempts; i++ {
+		_, err := testAuthService.Login("test@example.com", "wrongpassword", "127.0.0.1")
+		assert.Equal(t, "invalid email or password", err.Error())
+	}
+
+	// 待ち時間中は正しいパスワードでもパスワードを確認せずに拒否する
+	_, err := testAuthService.Login("test@example.com", "correctpassword", "127.0.0.1")
+	assert.Equal(t, "too many login attempts", err.Error())
+	mockRepo.AssertNumberOfCalls(t, "FindUserByEmail", services.AccountLoginAttemptPolicy.FreeAttempts+1)
+	mockRefreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+// パスワードでのログインでは2段階認証のコードの失敗回数がリセットされず、コードを試し続けるとロックされる
+func TestLoginWithTwoFactorTooManyAttempts(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	mockRefreshTokenRepo := &mocks.MockRefreshTokenRepository{}
+	mockTwoFactorService := &mocks.MockTwoFactorService{}
+	testClock := clock.NewFake(time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC))
+	testAuthService := services.NewAuthService(mockRepo, mockRefreshTokenRepo, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, mockTwoFactorService, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), testClock), mailer.NewLogMailer())
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("correctpassword"), bcrypt.MinCost)
+	testUser := &models.User{ID: 1, Email: "test@example.com", Password: string(hashedPassword)}
+	mockRepo.On("FindUserByEmail", "test@example.com").Return(testUser, nil)
+	mockRepo.On("FindUserById", uint(1)).Return(testUser, nil)
+	mockTwoFactorService.On("IsEnabled", uint(1)).Return(true, nil)
+	mockTwoFactorService.On("VerifyCode", uint(1), "000000").Return(errors.New("invalid code"))
+
+	// 待ち時間が過ぎるまで時間を進めてからログインする
+	loginWithPassword := func() string {
+		testClock.Advance(time.Minute)
+		loginResponse, err := testAuthService.Login("test@example.com", "correctpassword", "127.0.0.1")
+		assert.NoError(t, err)
+		assert.True(t, loginResponse.MFARequired)
+		return loginResponse.MFAToken
+	}
+	tryCode := func(mfaToken string) {
+		testClock.Advance(time.Minute)
+		_, err := testAuthService.LoginWithTwoFactor(mfaToken, "000000", "127.0.0.1")
+		assert.Equal(t, "invalid code", err.Error())
+	}
+
+	// パスワードは正しいがコードを間違え続ける
+	mfaToken := loginWithPassword()
+	for i := 0; i < services.AccountLoginAttemptPolicy.MaxFailures-1; i++ {
+		tryCode(mfaToken)
+	}
+
+	// 正しいパスワードで再度ログインしても失敗回数はリセットされない
+	mfaToken = loginWithPassword()
+	tryCode(mfaToken)
+
+	// 失敗回数が上限に達したので正しいパスワードでもロックされる
+	_, err := testAuthService.Login("test@example.com", "correctpassword", "127.0.0.1")
+	assert.Equal(t, "too many login attempts", err.Error())
+	_, err = testAuthService.LoginWithTwoFactor(mfaToken, "000000", "127.0.0.1")
+	assert.Equal(t, "too many login attempts", err.Error())
+	mockRefreshTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+// 存在しないemailへの失敗も数える
+func TestLoginTooManyAttemptsUnknownEmail(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	testClock := clock.NewFake(time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC))
+	testAuthService := services.NewAuthService(mockRepo, &mocks.MockRefreshTokenRepository{}, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), testClock), mailer.NewLogMailer())
+
+	mockRepo.On("FindUserByEmail", "unknown@example.com").Return(nil, errors.New("user not found"))
+
+	for i := 0; i <= services.AccountLoginAttemptPolicy.FreeAttempts; i++ {
+		_, err := testAuthService.Login("unknown@example.com", "password", "127.0.0.1")
+		assert.Equal(t, "invalid email or password", err.Error())
+	}
+
+	_, err := testAuthService.Login("unknown@example.com", "password", "127.0.0.1")
+	assert.Equal(t, "too many login attempts", err.Error())
+}
+
+// ダミーのハッシュは通常のパスワードと同じコストで比較する
+func TestLoginDummyPasswordHashCost(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo := &mocks.MockUserRepository{}
+	testAuthService := services.NewAuthService(mockRepo, &mocks.MockRefreshTokenRepository{}, repositories.NewInMemoryTokenRevocationRepository(), &mocks.MockPasswordResetTokenRepository{}, &mocks.MockEmailVerificationTokenRepository{}, &mocks.MockUserIdentityRepository{}, &mocks.MockTwoFactorService{}, services.NewLoginAttemptService(repositories.NewInMemoryLoginAttemptRepository(), clock.New()), mailer.NewLogMailer())
+
+	mockRepo.On("FindUserByEmail", "unknown@example.com").Return(nil, errors.New("user not found"))
+
+	start := time.Now()
+	_, err := testAuthService.Login("unknown@example.com", "password", "127.0.0.1")
+	elapsed := time.Since(start)
+
+	// bcrypt.DefaultCostの比較には少なくとも数msかかる(不正なハッシュの場合はすぐに失敗する)
+	assert.Equal(t, "invalid email or password", err.Error())
+	assert.Greater(t, elapsed, time.Millisecond)
 }
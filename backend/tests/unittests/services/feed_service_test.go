@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistributeTweetSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockFeedRepo, mockFollowerRepo, _, testFeedService := prepareTestFeedService()
+
+	// user1のtweetを準備
+	testTweet := &models.Tweet{
+		ID:      1,
+		UserID:  1,
+		Type:    models.Text,
+		Content: "test tweet",
+	}
+
+	// user2, user3がuser1をフォローしている
+	testFollowers := []*models.Follower{
+		{FollowerID: 2, FolloweeID: 1},
+		{FollowerID: 3, FolloweeID: 1},
+	}
+
+	// モックレポジトリを呼び出し
+	mockFollowerRepo.On("GetFollowers", uint(1), &pagination.Page{Limit: pagination.MaxLimit}).Return(&pagination.List[*models.Follower]{Items: testFollowers}, nil)
+	mockFeedRepo.On("AddTweetToFeeds", uint(1), []uint{1, 2, 3}).Return(nil)
+
+	err := testFeedService.DistributeTweet(testTweet)
+
+	assert.NoError(t, err)
+	mockFollowerRepo.AssertExpectations(t)
+	mockFeedRepo.AssertExpectations(t)
+}
+
+func TestDistributeTweetSkipsAuthorInFollowers(t *testing.T) {
+	// モックレポジトリを準備
+	mockFeedRepo, mockFollowerRepo, _, testFeedService := prepareTestFeedService()
+
+	// user1のtweetを準備
+	testTweet := &models.Tweet{
+		ID:      1,
+		UserID:  1,
+		Type:    models.Text,
+		Content: "test tweet",
+	}
+
+	// フォロワーに投稿者本人が含まれている
+	testFollowers := []*models.Follower{
+		{FollowerID: 1, FolloweeID: 1},
+		{FollowerID: 2, FolloweeID: 1},
+	}
+
+	// 投稿者本人のFeedには1回だけ配信される
+	mockFollowerRepo.On("GetFollowers", uint(1), &pagination.Page{Limit: pagination.MaxLimit}).Return(&pagination.List[*models.Follower]{Items: testFollowers}, nil)
+	mockFeedRepo.On("AddTweetToFeeds", uint(1), []uint{1, 2}).Return(nil)
+
+	err := testFeedService.DistributeTweet(testTweet)
+
+	assert.NoError(t, err)
+	mockFollowerRepo.AssertExpectations(t)
+	mockFeedRepo.AssertExpectations(t)
+}
+
+func TestDistributeTweetFollowersError(t *testing.T) {
+	// モックレポジトリを準備
+	mockFeedRepo, mockFollowerRepo, _, testFeedService := prepareTestFeedService()
+
+	testTweet := &models.Tweet{ID: 1, UserID: 1}
+
+	// モックレポジトリを呼び出し
+	mockFollowerRepo.On("GetFollowers", uint(1), &pagination.Page{Limit: pagination.MaxLimit}).Return(nil, errors.New("db error"))
+
+	err := testFeedService.DistributeTweet(testTweet)
+
+	assert.Error(t, err)
+	mockFollowerRepo.AssertExpectations(t)
+	mockFeedRepo.AssertNotCalled(t, "AddTweetToFeeds")
+}
+
+func TestGetTimelineSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockFeedRepo, mockFollowerRepo, mockTweetRepo, testFeedService := prepareTestFeedService()
+
+	// タイムラインのtweetを新しい順に準備
+	now := time.Now()
+	testTweets := []*models.Tweet{
+		{ID: 3, UserID: 3, Type: models.Text, Content: "tweet3", CreatedAt: now},
+		{ID: 2, UserID: 1, Type: models.Text, Content: "tweet2", CreatedAt: now.Add(-time.Minute)},
+		{ID: 1, UserID: 2, Type: models.Text, Content: "tweet1", CreatedAt: now.Add(-time.Hour)},
+	}
+
+	// モックレポジトリを呼び出し
+	testPage := &pagination.Page{Limit: pagination.DefaultLimit}
+	mockFeedRepo.On("GetFeedTweets", uint(1), testPage).Return(&pagination.List[*models.Tweet]{Items: testTweets}, nil)
+	mockTweetRepo.On("CountRetweets", []uint{3, 2, 1}).Return(map[uint]int64{3: 2}, nil)
+	mockTweetRepo.On("CountQuotes", []uint{3, 2, 1}).Return(map[uint]int64{1: 1}, nil)
+
+	tweets, err := testFeedService.GetTimeline(1, testPage)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testTweets, tweets.Items)
+	assert.Equal(t, int64(2), *tweets.Items[0].RetweetCount)
+	assert.Equal(t, int64(1), *tweets.Items[2].QuoteCount)
+	mockFollowerRepo.AssertNotCalled(t, "GetFollowees")
+	mockFeedRepo.AssertExpectations(t)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestGetTimelineWithRetweet(t *testing.T) {
+	// モックレポジトリを準備
+	mockFeedRepo, _, mockTweetRepo, testFeedService := prepareTestFeedService()
+
+	// user2のtweetをuser1がretweetしている
+	originalTweetId := uint(1)
+	originalTweet := &models.Tweet{ID: 1, UserID: 2, Type: models.Text, Content: "original", User: &models.User{ID: 2, Name: "user2"}}
+	testTweets := []*models.Tweet{
+		{ID: 2, UserID: 1, Type: models.Retweet, RetweetedTweetID: &originalTweetId, RetweetedTweet: originalTweet},
+	}
+
+	// モックレポジトリを呼び出し
+	testPage := &pagination.Page{Limit: pagination.DefaultLimit}
+	mockFeedRepo.On("GetFeedTweets", uint(1), testPage).Return(&pagination.List[*models.Tweet]{Items: testTweets}, nil)
+	mockTweetRepo.On("CountRetweets", []uint{2, 1}).Return(map[uint]int64{1: 1}, nil)
+	mockTweetRepo.On("CountQuotes", []uint{2, 1}).Return(map[uint]int64{}, nil)
+
+	tweets, err := testFeedService.GetTimeline(1, testPage)
+
+	// retweetには元のtweetと元の投稿者が含まれる
+	assert.NoError(t, err)
+	assert.Equal(t, "user2", tweets.Items[0].RetweetedTweet.User.Name)
+	assert.Equal(t, int64(1), *tweets.Items[0].RetweetedTweet.RetweetCount)
+	assert.Equal(t, int64(0), *tweets.Items[0].RetweetCount)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestAddFolloweeTweetsSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockFeedRepo, _, _, testFeedService := prepareTestFeedService()
+
+	// user1がuser2をフォローした時にuser2の最新のtweetをuser1のFeedに追加する
+	mockFeedRepo.On("AddUserTweetsToFeed", uint(1), uint(2), services.FolloweeBackfillLimit).Return(nil)
+
+	err := testFeedService.AddFolloweeTweets(1, 2)
+
+	assert.NoError(t, err)
+	mockFeedRepo.AssertExpectations(t)
+}
+
+func TestRemoveFolloweeTweetsSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockFeedRepo, _, _, testFeedService := prepareTestFeedService()
+
+	// user1がuser2のフォローを解除した時にuser2のtweetをuser1のFeedから削除する
+	mockFeedRepo.On("RemoveUserTweetsFromFeed", uint(1), uint(2)).Return(nil)
+
+	err := testFeedService.RemoveFolloweeTweets(1, 2)
+
+	assert.NoError(t, err)
+	mockFeedRepo.AssertExpectations(t)
+}
+
+func prepareTestFeedService() (*mocks.MockFeedRepository, *mocks.MockFollowerRepository, *mocks.MockTweetRepository, services.IFeedService) {
+	mockFeedRepo := &mocks.MockFeedRepository{}
+	mockFollowerRepo := &mocks.MockFollowerRepository{}
+	mockTweetRepo := &mocks.MockTweetRepository{}
+	testFeedService := services.NewFeedService(mockFeedRepo, mockFollowerRepo, mockTweetRepo)
+	return mockFeedRepo, mockFollowerRepo, mockTweetRepo, testFeedService
+}
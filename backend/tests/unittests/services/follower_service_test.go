@@ -7,13 +7,15 @@ import (
 
 	"github.com/daiki-kim/tweet-app/backend/apps/models"
 	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
 	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestFollowSuccess(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, mockFeedService, testFollowerService := prepareTestFollowerService()
 
 	// フォローモデルを準備
 	followerId := uint(1)
@@ -26,17 +28,36 @@ func TestFollowSuccess(t *testing.T) {
 
 	// モックレポジトリを呼び出し
 	mockRepo.On("CreateFollower", expectedFollower).Return(expectedFollower, nil)
+	mockFeedService.On("AddFolloweeTweets", followerId, followeeId).Return(nil)
 
 	follower, err := testFollowerService.Follow(followerId, followeeId)
 
 	assert.NoError(t, err)
 	assert.Equal(t, followerId, follower.FollowerID)
 	mockRepo.AssertExpectations(t)
+	mockFeedService.AssertExpectations(t)
+}
+
+func TestFollowBackfillError(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, mockFeedService, testFollowerService := prepareTestFollowerService()
+
+	expectedFollower := &models.Follower{FollowerID: 1, FolloweeID: 2}
+
+	// Feedへの追加に失敗してもフォローは成功する
+	mockRepo.On("CreateFollower", expectedFollower).Return(expectedFollower, nil)
+	mockFeedService.On("AddFolloweeTweets", uint(1), uint(2)).Return(errors.New("db error"))
+
+	follower, err := testFollowerService.Follow(1, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedFollower, follower)
+	mockFeedService.AssertExpectations(t)
 }
 
 func TestFollowFail(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, _, testFollowerService := prepareTestFollowerService()
 
 	// フォローモデルを準備
 	followerId := uint(1)
@@ -57,9 +78,21 @@ func TestFollowFail(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestFollowSelf(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, mockFeedService, testFollowerService := prepareTestFollowerService()
+
+	follower, err := testFollowerService.Follow(1, 1)
+
+	assert.Nil(t, follower)
+	assert.EqualError(t, err, "cannot follow yourself")
+	mockRepo.AssertNotCalled(t, "CreateFollower", mock.Anything)
+	mockFeedService.AssertNotCalled(t, "AddFolloweeTweets", mock.Anything, mock.Anything)
+}
+
 func TestGetFollowerSuccess(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, _, testFollowerService := prepareTestFollowerService()
 
 	// フォローモデルを準備
 	testFollower := &models.Follower{
@@ -80,7 +113,7 @@ func TestGetFollowerSuccess(t *testing.T) {
 
 func TestGetFollowsSuccess(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, _, testFollowerService := prepareTestFollowerService()
 
 	// フォロワーのユーザーデータを準備
 	testuser2 := &models.User{
@@ -111,24 +144,26 @@ func TestGetFollowsSuccess(t *testing.T) {
 	}
 
 	// モックレポジトリを呼び出し
-	mockRepo.On("GetFollowees", uint(1)).Return([]*models.Follower{testFollower1Follows2, testFollower1Follows3}, nil)
+	testPage := &pagination.Page{Limit: pagination.DefaultLimit}
+	mockRepo.On("GetFollowees", uint(1), testPage).Return(&pagination.List[*models.Follower]{Items: []*models.Follower{testFollower1Follows2, testFollower1Follows3}}, nil)
 
-	followers, err := testFollowerService.GetFollows(1)
+	followers, err := testFollowerService.GetFollows(1, testPage)
 
 	assert.NoError(t, err)
-	assert.Equal(t, testFollower1Follows2, followers[0])
-	assert.Equal(t, testFollower1Follows3, followers[1])
+	assert.Equal(t, testFollower1Follows2, followers.Items[0])
+	assert.Equal(t, testFollower1Follows3, followers.Items[1])
 	mockRepo.AssertExpectations(t)
 }
 
 func TestGetFollowsNotFound(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, _, testFollowerService := prepareTestFollowerService()
 
 	// モックレポジトリを呼び出し
-	mockRepo.On("GetFollowees", uint(1)).Return(nil, errors.New("followers not found"))
+	testPage := &pagination.Page{Limit: pagination.DefaultLimit}
+	mockRepo.On("GetFollowees", uint(1), testPage).Return(nil, errors.New("followers not found"))
 
-	followers, err := testFollowerService.GetFollows(1)
+	followers, err := testFollowerService.GetFollows(1, testPage)
 
 	assert.Error(t, err)
 	assert.Nil(t, followers)
@@ -138,7 +173,7 @@ func TestGetFollowsNotFound(t *testing.T) {
 
 func TestGetFollowersSuccess(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, _, testFollowerService := prepareTestFollowerService()
 
 	// フォロワーのユーザーデータを準備
 	testuser1 := &models.User{
@@ -169,24 +204,26 @@ func TestGetFollowersSuccess(t *testing.T) {
 	}
 
 	// モックレポジトリを呼び出し
-	mockRepo.On("GetFollowers", uint(2)).Return([]*models.Follower{testFollower1Follows2, testFollower3Follows2}, nil)
+	testPage := &pagination.Page{Limit: pagination.DefaultLimit}
+	mockRepo.On("GetFollowers", uint(2), testPage).Return(&pagination.List[*models.Follower]{Items: []*models.Follower{testFollower1Follows2, testFollower3Follows2}}, nil)
 
-	followers, err := testFollowerService.GetFollowers(2)
+	followers, err := testFollowerService.GetFollowers(2, testPage)
 
 	assert.NoError(t, err)
-	assert.Equal(t, testFollower1Follows2, followers[0])
-	assert.Equal(t, testFollower3Follows2, followers[1])
+	assert.Equal(t, testFollower1Follows2, followers.Items[0])
+	assert.Equal(t, testFollower3Follows2, followers.Items[1])
 	mockRepo.AssertExpectations(t)
 }
 
 func TestGetFollowersNotFound(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, _, testFollowerService := prepareTestFollowerService()
 
 	// モックレポジトリを呼び出し
-	mockRepo.On("GetFollowers", uint(1)).Return(nil, errors.New("followers not found"))
+	testPage := &pagination.Page{Limit: pagination.DefaultLimit}
+	mockRepo.On("GetFollowers", uint(1), testPage).Return(nil, errors.New("followers not found"))
 
-	followers, err := testFollowerService.GetFollowers(1)
+	followers, err := testFollowerService.GetFollowers(1, testPage)
 
 	assert.Error(t, err)
 	assert.Nil(t, followers)
@@ -196,7 +233,7 @@ func TestGetFollowersNotFound(t *testing.T) {
 
 func TestDeleteFollowerSuccess(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, mockFeedService, testFollowerService := prepareTestFollowerService()
 
 	// フォローモデルを準備
 	testFollower := &models.Follower{
@@ -207,17 +244,36 @@ func TestDeleteFollowerSuccess(t *testing.T) {
 
 	// モックレポジトリを呼び出し
 	mockRepo.On("GetFollower", uint(1)).Return(testFollower, nil)
+	mockFeedService.On("RemoveFolloweeTweets", uint(1), uint(2)).Return(nil)
 	mockRepo.On("DeleteFollower", uint(1)).Return(nil)
 
 	err := testFollowerService.DeleteFollower(1, 1)
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockFeedService.AssertExpectations(t)
+}
+
+func TestDeleteFollowerRemoveFeedTweetsError(t *testing.T) {
+	// モックレポジトリを準備
+	mockRepo, mockFeedService, testFollowerService := prepareTestFollowerService()
+
+	testFollower := &models.Follower{ID: 1, FollowerID: 1, FolloweeID: 2}
+
+	// Feedからの削除に失敗した場合はフォローを解除しない
+	mockRepo.On("GetFollower", uint(1)).Return(testFollower, nil)
+	mockFeedService.On("RemoveFolloweeTweets", uint(1), uint(2)).Return(errors.New("db error"))
+
+	err := testFollowerService.DeleteFollower(1, 1)
+
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "DeleteFollower", uint(1))
+	mockFeedService.AssertExpectations(t)
 }
 
 func TestDeleteFollowerNotFound(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, _, testFollowerService := prepareTestFollowerService()
 
 	// モックレポジトリを呼び出し
 	mockRepo.On("GetFollower", uint(1)).Return(nil, errors.New("follower not found"))
@@ -231,7 +287,7 @@ func TestDeleteFollowerNotFound(t *testing.T) {
 
 func TestDeleteFollowerNoPermission(t *testing.T) {
 	// モックレポジトリを準備
-	mockRepo, testFollowerService := prepareTestFollowerService()
+	mockRepo, _, testFollowerService := prepareTestFollowerService()
 
 	// フォローモデルを準備
 	testFollower := &models.Follower{
@@ -250,8 +306,9 @@ func TestDeleteFollowerNoPermission(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-func prepareTestFollowerService() (*mocks.MockFollowerRepository, services.IFollowerService) {
+func prepareTestFollowerService() (*mocks.MockFollowerRepository, *mocks.MockFeedService, services.IFollowerService) {
 	mockRepo := &mocks.MockFollowerRepository{}
-	testFollowerService := services.NewFollowerService(mockRepo)
-	return mockRepo, testFollowerService
+	mockFeedService := &mocks.MockFeedService{}
+	testFollowerService := services.NewFollowerService(mockRepo, mockFeedService)
+	return mockRepo, mockFeedService, testFollowerService
 }
@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLinkIdentitySuccess(t *testing.T) {
+	mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+	testIdentityService := services.NewIdentityService(mockUserIdentityRepo)
+
+	mockUserIdentityRepo.On("FindUserIdentity", "github", "42").Return(nil, errors.New("user identity not found"))
+	mockUserIdentityRepo.On("FindUserIdentitiesByUserId", uint(1)).Return([]*models.UserIdentity{{UserID: 1, Provider: "google", Subject: "google-1"}}, nil)
+	mockUserIdentityRepo.On("CreateUserIdentity", &models.UserIdentity{UserID: 1, Provider: "github", Subject: "42", Email: "test@example.com"}).Return(nil)
+
+	identity, err := testIdentityService.LinkIdentity(1, generateTestOAuthTicket(t, auth.OAuthTicketActionLink, "github", "42", "test@example.com", "gopher"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "github", identity.Provider)
+	mockUserIdentityRepo.AssertExpectations(t)
+}
+
+// 既にこのユーザーに紐づけ済みのアカウントはそのまま返す
+func TestLinkIdentityAlreadyLinkedToSelf(t *testing.T) {
+	mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+	testIdentityService := services.NewIdentityService(mockUserIdentityRepo)
+
+	linked := &models.UserIdentity{ID: 10, UserID: 1, Provider: "github", Subject: "42"}
+	mockUserIdentityRepo.On("FindUserIdentity", "github", "42").Return(linked, nil)
+
+	identity, err := testIdentityService.LinkIdentity(1, generateTestOAuthTicket(t, auth.OAuthTicketActionLink, "github", "42", "test@example.com", "gopher"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, linked, identity)
+	mockUserIdentityRepo.AssertNotCalled(t, "CreateUserIdentity", mock.Anything)
+}
+
+func TestLinkIdentityErrors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		prepareMock func(mockUserIdentityRepo *mocks.MockUserIdentityRepository)
+		errMsg      string
+	}{
+		{
+			name: "linked to another user",
+			prepareMock: func(mockUserIdentityRepo *mocks.MockUserIdentityRepository) {
+				mockUserIdentityRepo.On("FindUserIdentity", "github", "42").Return(&models.UserIdentity{UserID: 2, Provider: "github", Subject: "42"}, nil)
+			},
+			errMsg: "identity is linked to another user",
+		},
+		{
+			name: "another account of the provider is linked",
+			prepareMock: func(mockUserIdentityRepo *mocks.MockUserIdentityRepository) {
+				mockUserIdentityRepo.On("FindUserIdentity", "github", "42").Return(nil, errors.New("user identity not found"))
+				mockUserIdentityRepo.On("FindUserIdentitiesByUserId", uint(1)).Return([]*models.UserIdentity{{UserID: 1, Provider: "github", Subject: "43"}}, nil)
+			},
+			errMsg: "provider is already linked",
+		},
+		{
+			// 確認してから作成するまでに同時に紐づけられた場合
+			name: "linked concurrently",
+			prepareMock: func(mockUserIdentityRepo *mocks.MockUserIdentityRepository) {
+				mockUserIdentityRepo.On("FindUserIdentity", "github", "42").Return(nil, errors.New("user identity not found"))
+				mockUserIdentityRepo.On("FindUserIdentitiesByUserId", uint(1)).Return([]*models.UserIdentity{}, nil)
+				mockUserIdentityRepo.On("CreateUserIdentity", mock.Anything).Return(errors.New("identity already linked"))
+			},
+			errMsg: "provider is already linked",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+			testIdentityService := services.NewIdentityService(mockUserIdentityRepo)
+			testCase.prepareMock(mockUserIdentityRepo)
+
+			identity, err := testIdentityService.LinkIdentity(1, generateTestOAuthTicket(t, auth.OAuthTicketActionLink, "github", "42", "test@example.com", "gopher"))
+
+			assert.Nil(t, identity)
+			assert.Equal(t, testCase.errMsg, err.Error())
+		})
+	}
+}
+
+func TestLinkIdentityInvalidTicket(t *testing.T) {
+	mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+	testIdentityService := services.NewIdentityService(mockUserIdentityRepo)
+
+	// アクセストークン、サインアップ用のticketは紐づけに使用できない
+	accessToken, _ := auth.NewClaim("1").GenerateToken()
+	signupTicket := generateTestOAuthTicket(t, auth.OAuthTicketActionSignup, "github", "42", "test@example.com", "gopher")
+	for _, invalidTicket := range []string{accessToken, signupTicket} {
+		identity, err := testIdentityService.LinkIdentity(1, invalidTicket)
+
+		assert.Nil(t, identity)
+		assert.Equal(t, "invalid oauth ticket", err.Error())
+	}
+	mockUserIdentityRepo.AssertNotCalled(t, "CreateUserIdentity", mock.Anything)
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	mockUserIdentityRepo := &mocks.MockUserIdentityRepository{}
+	testIdentityService := services.NewIdentityService(mockUserIdentityRepo)
+
+	mockUserIdentityRepo.On("DeleteUserIdentity", uint(1), "google").Return(errors.New("cannot unlink the last login method"))
+	mockUserIdentityRepo.On("DeleteUserIdentity", uint(1), "github").Return(nil)
+
+	assert.Equal(t, "cannot unlink the last login method", testIdentityService.UnlinkIdentity(1, "google").Error())
+	assert.NoError(t, testIdentityService.UnlinkIdentity(1, "github"))
+}
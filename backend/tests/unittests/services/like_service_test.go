@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLikeSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockLikeRepo, mockTweetRepo, testLikeService := prepareTestLikeService()
+
+	// likeモデルを準備
+	expectedLike := &models.Like{
+		UserID:  1,
+		TweetID: 2,
+	}
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(&models.Tweet{ID: 2, UserID: 3}, nil)
+	mockLikeRepo.On("CreateLike", expectedLike).Return(expectedLike, nil)
+
+	like, err := testLikeService.Like(1, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLike, like)
+	mockTweetRepo.AssertExpectations(t)
+	mockLikeRepo.AssertExpectations(t)
+}
+
+func TestLikeTweetNotFound(t *testing.T) {
+	// モックレポジトリを準備
+	mockLikeRepo, mockTweetRepo, testLikeService := prepareTestLikeService()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(nil, errors.New("tweet not found"))
+
+	like, err := testLikeService.Like(1, 2)
+
+	assert.Error(t, err)
+	assert.Nil(t, like)
+	assert.Equal(t, "tweet not found", err.Error())
+	mockTweetRepo.AssertExpectations(t)
+	mockLikeRepo.AssertNotCalled(t, "CreateLike")
+}
+
+func TestLikeAlreadyLiked(t *testing.T) {
+	// モックレポジトリを準備
+	mockLikeRepo, mockTweetRepo, testLikeService := prepareTestLikeService()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(&models.Tweet{ID: 2, UserID: 3}, nil)
+	mockLikeRepo.On("CreateLike", &models.Like{UserID: 1, TweetID: 2}).Return(nil, errors.New("already liked"))
+
+	like, err := testLikeService.Like(1, 2)
+
+	assert.Error(t, err)
+	assert.Nil(t, like)
+	assert.Equal(t, "already liked", err.Error())
+	mockLikeRepo.AssertExpectations(t)
+}
+
+func TestGetLikersSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockLikeRepo, mockTweetRepo, testLikeService := prepareTestLikeService()
+
+	// likeモデルを準備
+	testLikes := &pagination.List[*models.Like]{
+		Items: []*models.Like{
+			{ID: 2, UserID: 3, TweetID: 1},
+			{ID: 1, UserID: 2, TweetID: 1},
+		},
+	}
+	testPage := &pagination.Page{Limit: pagination.DefaultLimit}
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(1)).Return(&models.Tweet{ID: 1, UserID: 1}, nil)
+	mockLikeRepo.On("GetTweetLikes", uint(1), testPage).Return(testLikes, nil)
+
+	likes, err := testLikeService.GetLikers(1, testPage)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testLikes, likes)
+	mockTweetRepo.AssertExpectations(t)
+	mockLikeRepo.AssertExpectations(t)
+}
+
+func prepareTestLikeService() (*mocks.MockLikeRepository, *mocks.MockTweetRepository, services.ILikeService) {
+	mockLikeRepo := &mocks.MockLikeRepository{}
+	mockTweetRepo := &mocks.MockTweetRepository{}
+	testLikeService := services.NewLikeService(mockLikeRepo, mockTweetRepo)
+	return mockLikeRepo, mockTweetRepo, testLikeService
+}
@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+var loginAttemptTestNow = time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+func prepareTestLoginAttemptService() (repositories.ILoginAttemptRepository, *clock.Fake, services.ILoginAttemptService) {
+	testLoginAttemptRepo := repositories.NewInMemoryLoginAttemptRepository()
+	fakeClock := clock.NewFake(loginAttemptTestNow)
+	testLoginAttemptService := services.NewLoginAttemptService(testLoginAttemptRepo, fakeClock)
+
+	return testLoginAttemptRepo, fakeClock, testLoginAttemptService
+}
+
+func TestLoginAttemptPolicyDelay(t *testing.T) {
+	policy := services.LoginAttemptPolicy{FreeAttempts: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+	testCases := []struct {
+		failures int
+		delay    time.Duration
+	}{
+		{0, 0},
+		{3, 0},
+		{4, time.Second},
+		{5, 2 * time.Second},
+		{6, 4 * time.Second},
+		{8, 16 * time.Second},
+		// 上限を超えない
+		{9, 30 * time.Second},
+		{1000, 30 * time.Second},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.delay, policy.Delay(testCase.failures), "failures: %d", testCase.failures)
+	}
+}
+
+// 失敗が続くと待ち時間が延び、待ち時間が過ぎるとログインできる
+func TestLoginAttemptProgressiveDelay(t *testing.T) {
+	_, fakeClock, testLoginAttemptService := prepareTestLoginAttemptService()
+	email := "test@example.com"
+
+	// 待ち時間なしで失敗できる回数を超えると待ち時間が必要になる
+	for i := 0; i <= services.AccountLoginAttemptPolicy.FreeAttempts; i++ {
+		assert.NoError(t, testLoginAttemptService.CheckLogin(email, "127.0.0.1"))
+		assert.NoError(t, testLoginAttemptService.RecordLoginFailure(email, "127.0.0.1"))
+	}
+	assert.Equal(t, "too many login attempts", testLoginAttemptService.CheckLogin(email, "127.0.0.1").Error())
+	// emailの大文字小文字は区別しない
+	assert.Equal(t, "too many login attempts", testLoginAttemptService.CheckLogin(" Test@Example.com ", "127.0.0.1").Error())
+	// 別のアカウントは制限しない
+	assert.NoError(t, testLoginAttemptService.CheckLogin("other@example.com", "127.0.0.1"))
+
+	fakeClock.Advance(services.AccountLoginAttemptPolicy.BaseDelay)
+	assert.NoError(t, testLoginAttemptService.CheckLogin(email, "127.0.0.1"))
+
+	// 次の失敗で待ち時間が2倍になる
+	assert.NoError(t, testLoginAttemptService.RecordLoginFailure(email, "127.0.0.1"))
+	fakeClock.Advance(services.AccountLoginAttemptPolicy.BaseDelay)
+	assert.Error(t, testLoginAttemptService.CheckLogin(email, "127.0.0.1"))
+	fakeClock.Advance(services.AccountLoginAttemptPolicy.BaseDelay)
+	assert.NoError(t, testLoginAttemptService.CheckLogin(email, "127.0.0.1"))
+
+	// ログインに成功すると失敗回数を消す
+	assert.NoError(t, testLoginAttemptService.RecordLoginSuccess(email))
+	assert.NoError(t, testLoginAttemptService.RecordLoginFailure(email, "127.0.0.1"))
+	assert.NoError(t, testLoginAttemptService.CheckLogin(email, "127.0.0.1"))
+}
+
+// 失敗回数が上限に達するとロックして監査記録を残す
+func TestLoginAttemptLockout(t *testing.T) {
+	testLoginAttemptRepo, fakeClock, testLoginAttemptService := prepareTestLoginAttemptService()
+	email := "test@example.com"
+
+	for i := 0; i < services.AccountLoginAttemptPolicy.MaxFailures; i++ {
+		assert.NoError(t, testLoginAttemptService.RecordLoginFailure(email, "127.0.0.1"))
+		fakeClock.Advance(services.AccountLoginAttemptPolicy.MaxDelay)
+	}
+
+	// 待ち時間の上限を過ぎてもロック中はログインできない
+	assert.Equal(t, "too many login attempts", testLoginAttemptService.CheckLogin(email, "127.0.0.1").Error())
+
+	lockouts, err := testLoginAttemptRepo.FindLoginLockouts("account:test@example.com")
+	assert.NoError(t, err)
+	assert.Len(t, lockouts, 1)
+	assert.Equal(t, "127.0.0.1", lockouts[0].IPAddress)
+	assert.Equal(t, services.AccountLoginAttemptPolicy.MaxFailures, lockouts[0].Failures)
+
+	// ロックが解除されるとログインできる
+	fakeClock.Set(lockouts[0].LockedUntil)
+	assert.NoError(t, testLoginAttemptService.CheckLogin(email, "127.0.0.1"))
+}
+
+// 同じIPアドレスから複数のアカウントへの失敗が続くとIPアドレスを制限する
+func TestLoginAttemptIPAddress(t *testing.T) {
+	_, _, testLoginAttemptService := prepareTestLoginAttemptService()
+
+	for i := 0; i <= services.IPLoginAttemptPolicy.FreeAttempts; i++ {
+		email := "user" + string(rune('a'+i)) + "@example.com"
+		assert.NoError(t, testLoginAttemptService.RecordLoginFailure(email, "192.0.2.1"))
+	}
+
+	assert.Equal(t, "too many login attempts", testLoginAttemptService.CheckLogin("new@example.com", "192.0.2.1").Error())
+	// 別のIPアドレスからは制限しない
+	assert.NoError(t, testLoginAttemptService.CheckLogin("new@example.com", "192.0.2.2"))
+
+	// アカウントのログインに成功してもIPアドレスの失敗回数は消さない
+	assert.NoError(t, testLoginAttemptService.RecordLoginSuccess("new@example.com"))
+	assert.Error(t, testLoginAttemptService.CheckLogin("new@example.com", "192.0.2.1"))
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/media"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUploadImage(t *testing.T) {
+	// モックレポジトリとローカルのstoreを準備
+	mockMediaRepo, dir, testMediaService := prepareTestMediaService(t)
+
+	// アップロードするPNG画像を準備
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 640, 480))))
+	size := int64(buf.Len())
+
+	// モックレポジトリを呼び出し
+	var uploadedMedia *models.Media
+	mockMediaRepo.On("CreateMedia", mock.MatchedBy(func(m *models.Media) bool {
+		return m.UserID == 5 && m.Type == models.Image && m.ContentType == "image/png" && m.Size == size
+	})).Run(func(args mock.Arguments) {
+		uploadedMedia = args.Get(0).(*models.Media)
+	}).Return(&models.Media{ID: 1}, nil)
+
+	createdMedia, err := testMediaService.UploadMedia(5, &buf, size)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), createdMedia.ID)
+	assert.True(t, strings.HasPrefix(uploadedMedia.StorageKey, "images/"))
+	assert.True(t, strings.HasSuffix(uploadedMedia.StorageKey, ".png"))
+	assert.Equal(t, "http://localhost:8080/media/"+uploadedMedia.StorageKey, uploadedMedia.URL)
+	assert.Equal(t, "http://localhost:8080/media/"+uploadedMedia.ThumbnailKey, uploadedMedia.ThumbnailURL)
+	assert.FileExists(t, filepath.Join(dir, uploadedMedia.StorageKey))
+	assert.FileExists(t, filepath.Join(dir, uploadedMedia.ThumbnailKey))
+	mockMediaRepo.AssertExpectations(t)
+}
+
+func TestUploadVideo(t *testing.T) {
+	// モックレポジトリとローカルのstoreを準備
+	mockMediaRepo, dir, testMediaService := prepareTestMediaService(t)
+
+	// mp4のftypボックスから始まるデータを準備
+	video := append([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), make([]byte, 1024)...)
+
+	// モックレポジトリを呼び出し
+	var uploadedMedia *models.Media
+	mockMediaRepo.On("CreateMedia", mock.Anything).Run(func(args mock.Arguments) {
+		uploadedMedia = args.Get(0).(*models.Media)
+	}).Return(&models.Media{ID: 1}, nil)
+
+	_, err := testMediaService.UploadMedia(5, bytes.NewReader(video), int64(len(video)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.Video, uploadedMedia.Type)
+	assert.Equal(t, "video/mp4", uploadedMedia.ContentType)
+	assert.Equal(t, "", uploadedMedia.ThumbnailKey)
+	data, err := os.ReadFile(filepath.Join(dir, uploadedMedia.StorageKey))
+	assert.NoError(t, err)
+	assert.Equal(t, video, data)
+}
+
+func TestUploadUnsupportedMedia(t *testing.T) {
+	// モックレポジトリとローカルのstoreを準備
+	mockMediaRepo, _, testMediaService := prepareTestMediaService(t)
+
+	// Content-Typeに関係なく中身がテキストのファイルは拒否する
+	uploadedMedia, err := testMediaService.UploadMedia(5, strings.NewReader("<html></html>"), 13)
+
+	assert.Nil(t, uploadedMedia)
+	assert.Equal(t, "unsupported media type", err.Error())
+	mockMediaRepo.AssertNotCalled(t, "CreateMedia")
+}
+
+func TestUploadTooLargeImage(t *testing.T) {
+	// モックレポジトリとローカルのstoreを準備
+	mockMediaRepo, _, testMediaService := prepareTestMediaService(t)
+
+	// PNGのシグネチャから始まるMaxImageSizeより大きいデータを準備
+	data := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), make([]byte, services.MaxImageSize)...)
+
+	uploadedMedia, err := testMediaService.UploadMedia(5, bytes.NewReader(data), int64(len(data)))
+
+	assert.Nil(t, uploadedMedia)
+	assert.Equal(t, "media is too large", err.Error())
+	mockMediaRepo.AssertNotCalled(t, "CreateMedia")
+}
+
+func TestUploadBrokenImage(t *testing.T) {
+	// モックレポジトリとローカルのstoreを準備
+	mockMediaRepo, dir, testMediaService := prepareTestMediaService(t)
+
+	// PNGのシグネチャだけで画像として読み込めないデータを準備
+	data := append([]byte("\x89PNG\x0D\x0A\x1A\x0A"), make([]byte, 100)...)
+
+	uploadedMedia, err := testMediaService.UploadMedia(5, bytes.NewReader(data), int64(len(data)))
+
+	assert.Nil(t, uploadedMedia)
+	assert.Equal(t, "invalid image", err.Error())
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+	mockMediaRepo.AssertNotCalled(t, "CreateMedia")
+}
+
+func TestGetAttachableMedia(t *testing.T) {
+	// モックレポジトリとローカルのstoreを準備
+	mockMediaRepo, _, testMediaService := prepareTestMediaService(t)
+
+	// 添付する画像を準備
+	testMedia := []*models.Media{{ID: 1, UserID: 5, Type: models.Image}, {ID: 2, UserID: 5, Type: models.Image}}
+
+	// モックレポジトリを呼び出し
+	mockMediaRepo.On("GetMediaByIds", []uint{1, 2}).Return(testMedia, nil)
+
+	attachableMedia, err := testMediaService.GetAttachableMedia(5, models.Image, []uint{1, 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, testMedia, attachableMedia)
+	mockMediaRepo.AssertExpectations(t)
+}
+
+func TestGetAttachableMediaErrors(t *testing.T) {
+	attachedTweetId := uint(3)
+	cases := []struct {
+		name      string
+		tweetType models.TweetType
+		mediaIds  []uint
+		media     []*models.Media
+		err       string
+	}{
+		{name: "other user's media", tweetType: models.Image, mediaIds: []uint{1}, media: []*models.Media{{ID: 1, UserID: 6, Type: models.Image}}, err: "media not found"},
+		{name: "missing media", tweetType: models.Image, mediaIds: []uint{1, 2}, media: []*models.Media{{ID: 1, UserID: 5, Type: models.Image}}, err: "media not found"},
+		{name: "attached media", tweetType: models.Image, mediaIds: []uint{1}, media: []*models.Media{{ID: 1, UserID: 5, Type: models.Image, TweetID: &attachedTweetId}}, err: "media is already attached"},
+		{name: "video for image tweet", tweetType: models.Image, mediaIds: []uint{1}, media: []*models.Media{{ID: 1, UserID: 5, Type: models.Video}}, err: "media does not match tweet type"},
+		{name: "media for text tweet", tweetType: models.Text, mediaIds: []uint{1}, err: "media does not match tweet type"},
+		{name: "two videos", tweetType: models.Video, mediaIds: []uint{1, 2}, err: "media does not match tweet type"},
+		{name: "too many images", tweetType: models.Image, mediaIds: []uint{1, 2, 3, 4, 5}, err: "media does not match tweet type"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// モックレポジトリとローカルのstoreを準備
+			mockMediaRepo, _, testMediaService := prepareTestMediaService(t)
+
+			// モックレポジトリを呼び出し
+			mockMediaRepo.On("GetMediaByIds", c.mediaIds).Return(c.media, nil)
+
+			attachableMedia, err := testMediaService.GetAttachableMedia(5, c.tweetType, c.mediaIds)
+
+			assert.Nil(t, attachableMedia)
+			assert.Equal(t, c.err, err.Error())
+		})
+	}
+}
+
+func TestGetProfileImage(t *testing.T) {
+	// モックレポジトリとローカルのstoreを準備
+	mockMediaRepo, _, testMediaService := prepareTestMediaService(t)
+
+	// プロフィール画像にする画像を準備
+	testMedia := &models.Media{ID: 1, UserID: 5, Type: models.Image}
+
+	// モックレポジトリを呼び出し
+	mockMediaRepo.On("GetMediaByIds", []uint{1}).Return([]*models.Media{testMedia}, nil)
+
+	profileImage, err := testMediaService.GetProfileImage(5, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testMedia, profileImage)
+	mockMediaRepo.AssertExpectations(t)
+}
+
+func TestGetProfileImageErrors(t *testing.T) {
+	attachedTweetId := uint(3)
+	cases := []struct {
+		name  string
+		media []*models.Media
+		err   string
+	}{
+		{name: "missing media", media: []*models.Media{}, err: "media not found"},
+		{name: "other user's media", media: []*models.Media{{ID: 1, UserID: 6, Type: models.Image}}, err: "media not found"},
+		{name: "attached media", media: []*models.Media{{ID: 1, UserID: 5, Type: models.Image, TweetID: &attachedTweetId}}, err: "media is already attached"},
+		{name: "video", media: []*models.Media{{ID: 1, UserID: 5, Type: models.Video}}, err: "profile image must be an image"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// モックレポジトリとローカルのstoreを準備
+			mockMediaRepo, _, testMediaService := prepareTestMediaService(t)
+
+			// モックレポジトリを呼び出し
+			mockMediaRepo.On("GetMediaByIds", []uint{1}).Return(c.media, nil)
+
+			profileImage, err := testMediaService.GetProfileImage(5, 1)
+
+			assert.Nil(t, profileImage)
+			assert.Equal(t, c.err, err.Error())
+		})
+	}
+}
+
+func TestDeleteTweetMedia(t *testing.T) {
+	// モックレポジトリとローカルのstoreを準備
+	mockMediaRepo, dir, testMediaService := prepareTestMediaService(t)
+
+	// 削除する画像とサムネイルのファイルを準備
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "images"), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "thumbnails"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "images", "1.png"), []byte("image"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "thumbnails", "1.jpg"), []byte("thumbnail"), 0644))
+
+	// モックレポジトリを呼び出し
+	mockMediaRepo.On("GetTweetMedia", uint(3)).Return([]*models.Media{{ID: 1, StorageKey: "images/1.png", ThumbnailKey: "thumbnails/1.jpg"}}, nil)
+	mockMediaRepo.On("DeleteMedia", []uint{1}).Return(nil)
+
+	err := testMediaService.DeleteTweetMedia(3)
+
+	assert.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "images", "1.png"))
+	assert.NoFileExists(t, filepath.Join(dir, "thumbnails", "1.jpg"))
+	mockMediaRepo.AssertExpectations(t)
+}
+
+func TestDeleteTweetMediaError(t *testing.T) {
+	// モックレポジトリとローカルのstoreを準備
+	mockMediaRepo, _, testMediaService := prepareTestMediaService(t)
+
+	// モックレポジトリを呼び出し
+	mockMediaRepo.On("GetTweetMedia", uint(3)).Return(nil, errors.New("database error"))
+
+	err := testMediaService.DeleteTweetMedia(3)
+
+	assert.Equal(t, "database error", err.Error())
+	mockMediaRepo.AssertNotCalled(t, "DeleteMedia")
+}
+
+func prepareTestMediaService(t *testing.T) (*mocks.MockMediaRepository, string, services.IMediaService) {
+	dir := t.TempDir()
+	store, err := media.NewLocalMediaStore(dir, "http://localhost:8080/media")
+	assert.NoError(t, err)
+
+	mockMediaRepo := &mocks.MockMediaRepository{}
+	testMediaService := services.NewMediaService(mockMediaRepo, store)
+	return mockMediaRepo, dir, testMediaService
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchTweets(t *testing.T) {
+	// モックレポジトリを準備
+	mockSearchRepo, mockTweetRepo, testSearchService := prepareTestSearchService()
+
+	// 検索クエリを検索条件に変換(has:image/has:videoはtweetの種類に変換)
+	since := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+	expectedCondition := &repositories.TweetSearchCondition{
+		Terms:        []string{"golang", "full text"},
+		FromUsername: "gopher",
+		Since:        &since,
+		Types:        []models.TweetType{models.Image, models.Video},
+		Hashtags:     []string{"go"},
+	}
+
+	// モックレポジトリを呼び出し
+	tweets := &pagination.List[*models.Tweet]{Items: []*models.Tweet{{ID: 1}}}
+	mockSearchRepo.On("SearchTweets", expectedCondition, mock.Anything).Return(tweets, nil)
+	mockTweetRepo.On("CountRetweets", []uint{1}).Return(map[uint]int64{1: 2}, nil)
+	mockTweetRepo.On("CountQuotes", []uint{1}).Return(map[uint]int64{}, nil)
+
+	gotTweets, err := testSearchService.SearchTweets(`golang "full text" from:gopher since:2024-09-01 has:image has:video #Go`, &pagination.Page{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), *gotTweets.Items[0].RetweetCount)
+	mockSearchRepo.AssertExpectations(t)
+}
+
+func TestSearchTweetsInvalidQuery(t *testing.T) {
+	// モックレポジトリを準備
+	mockSearchRepo, _, testSearchService := prepareTestSearchService()
+
+	cases := []struct {
+		q        string
+		expected string
+	}{
+		{q: "   ", expected: "search query is empty"},
+		{q: "since:2024-13-01", expected: "invalid since date"},
+		// has:はimage/videoのみ
+		{q: "golang has:text", expected: "invalid has filter"},
+		{q: "golang has:link", expected: "invalid has filter"},
+	}
+
+	for _, c := range cases {
+		_, err := testSearchService.SearchTweets(c.q, &pagination.Page{})
+		assert.Equal(t, c.expected, err.Error())
+	}
+	mockSearchRepo.AssertNotCalled(t, "SearchTweets")
+}
+
+func prepareTestSearchService() (*mocks.MockSearchRepository, *mocks.MockTweetRepository, services.ISearchService) {
+	mockSearchRepo := &mocks.MockSearchRepository{}
+	mockTweetRepo := &mocks.MockTweetRepository{}
+	testSearchService := services.NewSearchService(mockSearchRepo, mockTweetRepo)
+	return mockSearchRepo, mockTweetRepo, testSearchService
+}
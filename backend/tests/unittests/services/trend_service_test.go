@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/clock"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// テスト用の現在時刻(時間枠の途中)
+var trendTestNow = time.Date(2024, 9, 1, 12, 7, 0, 0, time.UTC)
+
+func TestTrendAggregatorFlush(t *testing.T) {
+	// モックレポジトリと時計を準備
+	mockTrendRepo := &mocks.MockTrendRepository{}
+	fakeClock := clock.NewFake(trendTestNow)
+	testTrendAggregator := services.NewTrendAggregator(mockTrendRepo, fakeClock)
+
+	// 同じ時間枠に2件、次の時間枠に1件のtweetを集計
+	testTrendAggregator.Record(&models.Tweet{Content: "#Go 勉強会 #go"})
+	testTrendAggregator.Record(&models.Tweet{Content: "#go 東京"})
+	fakeClock.Advance(services.TrendSlotDuration)
+	testTrendAggregator.Record(&models.Tweet{Content: "#go"})
+	// 単語のないtweetは集計しない
+	testTrendAggregator.Record(&models.Tweet{Type: models.Retweet})
+
+	// モックレポジトリを呼び出し
+	slot1 := time.Date(2024, 9, 1, 12, 5, 0, 0, time.UTC)
+	slot2 := time.Date(2024, 9, 1, 12, 10, 0, 0, time.UTC)
+	mockTrendRepo.On("AddCounts", slot1, map[string]int64{"#go": 2, "勉強会": 1, "東京": 1}).Return(nil).Once()
+	mockTrendRepo.On("AddCounts", slot2, map[string]int64{"#go": 1}).Return(nil).Once()
+
+	err := testTrendAggregator.Flush()
+
+	assert.NoError(t, err)
+	mockTrendRepo.AssertExpectations(t)
+
+	// 保存済みの出現数は再度保存しない
+	assert.NoError(t, testTrendAggregator.Flush())
+	mockTrendRepo.AssertNumberOfCalls(t, "AddCounts", 2)
+}
+
+func TestTrendAggregatorFlushRetry(t *testing.T) {
+	// モックレポジトリと時計を準備
+	mockTrendRepo := &mocks.MockTrendRepository{}
+	testTrendAggregator := services.NewTrendAggregator(mockTrendRepo, clock.NewFake(trendTestNow))
+
+	testTrendAggregator.Record(&models.Tweet{Content: "#go"})
+
+	// 1回目の保存に失敗した出現数は次のFlushで保存する
+	slot := time.Date(2024, 9, 1, 12, 5, 0, 0, time.UTC)
+	mockTrendRepo.On("AddCounts", slot, map[string]int64{"#go": 1}).Return(errors.New("database error")).Once()
+	assert.Error(t, testTrendAggregator.Flush())
+
+	testTrendAggregator.Record(&models.Tweet{Content: "#go"})
+	mockTrendRepo.On("AddCounts", slot, map[string]int64{"#go": 2}).Return(nil).Once()
+	assert.NoError(t, testTrendAggregator.Flush())
+	mockTrendRepo.AssertExpectations(t)
+}
+
+func TestTrendAggregatorPurge(t *testing.T) {
+	// モックレポジトリと時計を準備
+	mockTrendRepo := &mocks.MockTrendRepository{}
+	testTrendAggregator := services.NewTrendAggregator(mockTrendRepo, clock.NewFake(trendTestNow))
+
+	// モックレポジトリを呼び出し
+	mockTrendRepo.On("DeleteBucketsBefore", trendTestNow.Add(-services.TrendRetention)).Return(int64(3), nil)
+
+	err := testTrendAggregator.Purge()
+
+	assert.NoError(t, err)
+	mockTrendRepo.AssertExpectations(t)
+}
+
+func TestStartTrendAggregatorFlushesOnStop(t *testing.T) {
+	// モックの集計を準備
+	mockTrendAggregator := &mocks.MockTrendAggregator{}
+	mockTrendAggregator.On("Flush").Return(nil)
+
+	// 停止時に残りの出現数を保存する
+	stop := services.StartTrendAggregator(mockTrendAggregator, time.Hour, time.Hour)
+	stop()
+	stop()
+
+	mockTrendAggregator.AssertNumberOfCalls(t, "Flush", 1)
+}
+
+func TestGetTrends(t *testing.T) {
+	// モックレポジトリと時計を準備
+	mockTrendRepo := &mocks.MockTrendRepository{}
+	testTrendService := services.NewTrendService(mockTrendRepo, clock.NewFake(trendTestNow))
+
+	// 現在の時間枠の終わりまでの1時間と、その直前の1時間
+	to := time.Date(2024, 9, 1, 12, 10, 0, 0, time.UTC)
+	from := to.Add(-time.Hour)
+	mockTrendRepo.On("SumCounts", from, to).Return(map[string]int64{
+		"#perennial": 100, // 直前の期間と同じ出現数
+		"#new":       10,  // 急に使われ始めた
+		"spike":      300, // 直前の期間の3倍
+		"rare":       2,   // MinTrendCountより少ない
+		"declining":  5,   // 直前の期間より少ない
+	}, nil)
+	mockTrendRepo.On("SumCounts", from.Add(-time.Hour), from).Return(map[string]int64{
+		"#perennial": 100,
+		"spike":      100,
+		"declining":  50,
+	}, nil)
+
+	trends, err := testTrendService.GetTrends("1h", 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(trends))
+	assert.Equal(t, "spike", trends[0].Term)
+	assert.False(t, trends[0].Hashtag)
+	assert.Equal(t, int64(300), trends[0].Count)
+	assert.Equal(t, int64(100), trends[0].PreviousCount)
+	assert.Equal(t, "#new", trends[1].Term)
+	assert.True(t, trends[1].Hashtag)
+	assert.InDelta(t, 10.0, trends[1].Score, 0.001)
+
+	// limitの件数まで返す
+	trends, err = testTrendService.GetTrends("1h", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(trends))
+}
+
+func TestGetTrendsInvalidWindow(t *testing.T) {
+	// モックレポジトリと時計を準備
+	mockTrendRepo := &mocks.MockTrendRepository{}
+	testTrendService := services.NewTrendService(mockTrendRepo, clock.NewFake(trendTestNow))
+
+	_, err := testTrendService.GetTrends("30m", 10)
+
+	assert.Equal(t, "invalid window", err.Error())
+	mockTrendRepo.AssertNotCalled(t, "SumCounts", mock.Anything, mock.Anything)
+}
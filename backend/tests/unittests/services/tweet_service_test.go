@@ -0,0 +1,644 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTweetWithLikes(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockLikeRepo, testTweetService := prepareTestTweetService()
+
+	// tweetモデルを準備
+	testTweet := &models.Tweet{
+		ID:      1,
+		UserID:  2,
+		Type:    models.Text,
+		Content: "test tweet",
+	}
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(1)).Return(testTweet, nil)
+	mockLikeRepo.On("CountLikes", []uint{1}).Return(map[uint]int64{1: 3}, nil)
+	mockLikeRepo.On("GetLikedTweetIds", uint(5), []uint{1}).Return(map[uint]bool{1: true}, nil)
+	mockTweetRepo.On("CountRetweets", []uint{1}).Return(map[uint]int64{1: 2}, nil)
+	mockTweetRepo.On("CountQuotes", []uint{1}).Return(map[uint]int64{}, nil)
+
+	tweet, err := testTweetService.GetTweet(1, 5)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), *tweet.LikeCount)
+	assert.True(t, *tweet.LikedByMe)
+	assert.Equal(t, int64(2), *tweet.RetweetCount)
+	assert.Equal(t, int64(0), *tweet.QuoteCount)
+	mockTweetRepo.AssertExpectations(t)
+	mockLikeRepo.AssertExpectations(t)
+}
+
+func TestGetTweetNotFound(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockLikeRepo, testTweetService := prepareTestTweetService()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(1)).Return(nil, errors.New("tweet not found"))
+
+	tweet, err := testTweetService.GetTweet(1, 5)
+
+	assert.Error(t, err)
+	assert.Nil(t, tweet)
+	assert.Equal(t, "tweet not found", err.Error())
+	mockTweetRepo.AssertExpectations(t)
+	mockLikeRepo.AssertNotCalled(t, "CountLikes")
+}
+
+func TestReplyTweetSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockFeedService, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// 返信先のtweet(conversation 1に属する返信)を準備
+	conversationId := uint(1)
+	parentTweet := &models.Tweet{ID: 2, UserID: 2, Type: models.Text, Content: "parent", ConversationID: &conversationId}
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(parentTweet, nil)
+	mockTweetRepo.On("CreateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return *tweet.InReplyToTweetID == 2 && *tweet.ConversationID == 1 && tweet.UserID == 5
+	})).Return(&models.Tweet{ID: 3, UserID: 5}, nil)
+	mockFeedService.On("DistributeTweet", mock.Anything).Return(nil)
+
+	tweet, err := testTweetService.ReplyTweet(5, 2, "text", "reply")
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), tweet.ID)
+	mockTweetRepo.AssertExpectations(t)
+	mockFeedService.AssertExpectations(t)
+}
+
+func TestReplyTweetToRootTweet(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockFeedService, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// 返信先は最初のtweet(conversationなし)
+	parentTweet := &models.Tweet{ID: 2, UserID: 2, Type: models.Text, Content: "root"}
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(parentTweet, nil)
+	mockTweetRepo.On("CreateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return *tweet.InReplyToTweetID == 2 && *tweet.ConversationID == 2
+	})).Return(&models.Tweet{ID: 3, UserID: 5}, nil)
+	mockFeedService.On("DistributeTweet", mock.Anything).Return(nil)
+
+	_, err := testTweetService.ReplyTweet(5, 2, "text", "reply")
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestReplyTweetParentNotFound(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(nil, errors.New("tweet not found"))
+
+	tweet, err := testTweetService.ReplyTweet(5, 2, "text", "reply")
+
+	assert.Nil(t, tweet)
+	assert.Equal(t, "tweet not found", err.Error())
+	mockTweetRepo.AssertNotCalled(t, "CreateTweet")
+}
+
+func TestGetThread(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// スレッドを準備
+	// 1 <- 2 <- 4 <- 5
+	//   <- 3
+	id := func(id uint) *uint { return &id }
+	rootTweet := &models.Tweet{ID: 1}
+	tweet2 := &models.Tweet{ID: 2, InReplyToTweetID: id(1)}
+	tweet3 := &models.Tweet{ID: 3, InReplyToTweetID: id(1)}
+	tweet4 := &models.Tweet{ID: 4, InReplyToTweetID: id(2)}
+	page := &pagination.Page{Limit: 20}
+
+	// モックレポジトリを呼び出し(depth=2なので4の返信は取得しない)
+	mockTweetRepo.On("GetReplyPath", uint(1)).Return([]*models.Tweet{rootTweet}, nil)
+	mockTweetRepo.On("GetReplies", uint(1), page).Return(&pagination.List[*models.Tweet]{Items: []*models.Tweet{tweet3, tweet2}}, nil)
+	mockTweetRepo.On("GetRepliesOf", []uint{3, 2}, services.ThreadRepliesPerTweet).Return([]*models.Tweet{tweet4}, nil)
+
+	thread, err := testTweetService.GetThread(1, 2, page)
+
+	assert.NoError(t, err)
+	assert.Equal(t, dtos.NewTweetResponse(rootTweet), thread.Tweet)
+	assert.Empty(t, thread.Path)
+	assert.Equal(t, 2, len(thread.Replies.Items))
+	assert.Equal(t, 0, len(thread.Replies.Items[0].Replies))
+	assert.Equal(t, dtos.NewTweetResponse(tweet4), thread.Replies.Items[1].Replies[0].Tweet)
+	assert.Equal(t, 0, len(thread.Replies.Items[1].Replies[0].Replies))
+	mockTweetRepo.AssertNumberOfCalls(t, "GetRepliesOf", 1)
+}
+
+// 返信を指定した場合もconversationの最初のtweetから返信ツリーを作成し、指定した返信までの経路を返す
+func TestGetThreadOfReply(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// スレッドを準備
+	// 1 <- 2 <- 4
+	id := func(id uint) *uint { return &id }
+	rootTweet := &models.Tweet{ID: 1}
+	tweet2 := &models.Tweet{ID: 2, InReplyToTweetID: id(1), ConversationID: id(1)}
+	tweet4 := &models.Tweet{ID: 4, InReplyToTweetID: id(2), ConversationID: id(1)}
+	page := &pagination.Page{Limit: 20}
+
+	// モックレポジトリを呼び出し(depth=1なので2の返信は取得しない)
+	mockTweetRepo.On("GetReplyPath", uint(4)).Return([]*models.Tweet{rootTweet, tweet2, tweet4}, nil)
+	mockTweetRepo.On("GetReplies", uint(1), page).Return(&pagination.List[*models.Tweet]{Items: []*models.Tweet{tweet2}}, nil)
+
+	thread, err := testTweetService.GetThread(4, 1, page)
+
+	assert.NoError(t, err)
+	assert.Equal(t, dtos.NewTweetResponse(rootTweet), thread.Tweet)
+	assert.Equal(t, []*dtos.TweetResponse{dtos.NewTweetResponse(tweet2), dtos.NewTweetResponse(tweet4)}, thread.Path)
+	assert.Equal(t, dtos.NewTweetResponse(tweet2), thread.Replies.Items[0].Tweet)
+	mockTweetRepo.AssertNotCalled(t, "GetRepliesOf", mock.Anything, mock.Anything)
+}
+
+func TestGetThreadNotFound(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetReplyPath", uint(1)).Return(nil, errors.New("tweet not found"))
+
+	thread, err := testTweetService.GetThread(1, 2, nil)
+
+	assert.Nil(t, thread)
+	assert.Equal(t, "tweet not found", err.Error())
+	mockTweetRepo.AssertNotCalled(t, "GetReplies", mock.Anything, mock.Anything)
+}
+
+func TestRetweetSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockFeedService, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(&models.Tweet{ID: 2, UserID: 2, Type: models.Text}, nil)
+	mockTweetRepo.On("CreateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return tweet.Type == models.Retweet && *tweet.RetweetedTweetID == 2 && tweet.Content == ""
+	})).Return(&models.Tweet{ID: 3, UserID: 5}, nil)
+	mockFeedService.On("DistributeTweet", mock.Anything).Return(nil)
+
+	tweet, err := testTweetService.Retweet(5, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), tweet.ID)
+	mockTweetRepo.AssertExpectations(t)
+	mockFeedService.AssertExpectations(t)
+}
+
+func TestRetweetRetweet(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockFeedService, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// tweet3はtweet2のretweet
+	originalTweetId := uint(2)
+
+	// モックレポジトリを呼び出し(retweetをretweetすると元のtweetをretweetする)
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 4, Type: models.Retweet, RetweetedTweetID: &originalTweetId}, nil)
+	mockTweetRepo.On("GetTweet", uint(2)).Return(&models.Tweet{ID: 2, UserID: 2, Type: models.Text}, nil)
+	mockTweetRepo.On("CreateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return *tweet.RetweetedTweetID == 2
+	})).Return(&models.Tweet{ID: 4, UserID: 5}, nil)
+	mockFeedService.On("DistributeTweet", mock.Anything).Return(nil)
+
+	_, err := testTweetService.Retweet(5, 3)
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestRetweetAlreadyRetweeted(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockFeedService, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(&models.Tweet{ID: 2, UserID: 2, Type: models.Text}, nil)
+	mockTweetRepo.On("CreateTweet", mock.Anything).Return(nil, errors.New("already retweeted"))
+
+	tweet, err := testTweetService.Retweet(5, 2)
+
+	assert.Nil(t, tweet)
+	assert.Equal(t, "already retweeted", err.Error())
+	mockFeedService.AssertNotCalled(t, "DistributeTweet")
+}
+
+func TestUnretweetSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(&models.Tweet{ID: 2, UserID: 2, Type: models.Text}, nil)
+	mockTweetRepo.On("DeleteRetweet", uint(5), uint(2)).Return(nil)
+
+	err := testTweetService.Unretweet(5, 2)
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+// retweetをretweetして取り消すと、どちらも元のtweetのretweetになる
+func TestRetweetAndUnretweetRetweet(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockFeedService, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// tweet3はtweet2のretweet
+	originalTweetId := uint(2)
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 4, Type: models.Retweet, RetweetedTweetID: &originalTweetId}, nil)
+	mockTweetRepo.On("GetTweet", uint(2)).Return(&models.Tweet{ID: 2, UserID: 2, Type: models.Text}, nil)
+	mockTweetRepo.On("CreateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return *tweet.RetweetedTweetID == 2
+	})).Return(&models.Tweet{ID: 4, UserID: 5}, nil)
+	mockTweetRepo.On("DeleteRetweet", uint(5), uint(2)).Return(nil)
+	mockFeedService.On("DistributeTweet", mock.Anything).Return(nil)
+
+	_, err := testTweetService.Retweet(5, 3)
+	assert.NoError(t, err)
+
+	err = testTweetService.Unretweet(5, 3)
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+	mockTweetRepo.AssertNotCalled(t, "DeleteRetweet", uint(5), uint(3))
+}
+
+// 元のtweetが削除されていてもretweetを取り消せる
+func TestUnretweetDeletedTweet(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(nil, errors.New("tweet not found"))
+	mockTweetRepo.On("DeleteRetweet", uint(5), uint(2)).Return(nil)
+
+	err := testTweetService.Unretweet(5, 2)
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestQuoteTweetSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockFeedService, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(2)).Return(&models.Tweet{ID: 2, UserID: 2, Type: models.Text}, nil)
+	mockTweetRepo.On("CreateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return tweet.Type == models.Quote && *tweet.QuotedTweetID == 2 && tweet.Content == "quote"
+	})).Return(&models.Tweet{ID: 3, UserID: 5}, nil)
+	mockFeedService.On("DistributeTweet", mock.Anything).Return(nil)
+
+	_, err := testTweetService.QuoteTweet(5, 2, "quote")
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestDeleteRetweet(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// tweet3はuser5によるtweet2のretweet
+	originalTweetId := uint(2)
+
+	// モックレポジトリを呼び出し(retweetはtombstoneを残さずに削除する)
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 5, Type: models.Retweet, RetweetedTweetID: &originalTweetId}, nil)
+	mockTweetRepo.On("DeleteRetweet", uint(5), uint(2)).Return(nil)
+
+	err := testTweetService.DeleteTweet(3, 5)
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+	mockTweetRepo.AssertNotCalled(t, "DeleteTweet")
+}
+
+func TestCreateTweetWithMedia(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockFeedService, mockMediaService, testTweetService := prepareTestTweetServiceWithMedia()
+
+	// 添付する画像を準備
+	testMedia := []*models.Media{{ID: 1, UserID: 5, Type: models.Image}, {ID: 2, UserID: 5, Type: models.Image}}
+
+	// モックレポジトリを呼び出し
+	mockMediaService.On("GetAttachableMedia", uint(5), models.Image, []uint{1, 2}).Return(testMedia, nil)
+	mockTweetRepo.On("CreateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return tweet.Type == models.Image && len(tweet.Media) == 2
+	})).Return(&models.Tweet{ID: 3, UserID: 5, Type: models.Image, Media: testMedia}, nil)
+	mockFeedService.On("DistributeTweet", mock.Anything).Return(nil)
+
+	tweet, err := testTweetService.CreateTweet(5, "image", "photos", []uint{1, 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(tweet.Media))
+	mockTweetRepo.AssertExpectations(t)
+	mockMediaService.AssertExpectations(t)
+}
+
+func TestCreateTweetWithMismatchedMedia(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, mockMediaService, testTweetService := prepareTestTweetServiceWithMedia()
+
+	// モックレポジトリを呼び出し
+	mockMediaService.On("GetAttachableMedia", uint(5), models.Text, []uint{1}).Return(nil, errors.New("media does not match tweet type"))
+
+	tweet, err := testTweetService.CreateTweet(5, "text", "text with image", []uint{1})
+
+	assert.Nil(t, tweet)
+	assert.Equal(t, "media does not match tweet type", err.Error())
+	mockTweetRepo.AssertNotCalled(t, "CreateTweet")
+}
+
+func TestDeleteTweetWithMedia(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, mockMediaService, testTweetService := prepareTestTweetServiceWithMedia()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 5, Type: models.Image, Media: []*models.Media{{ID: 1}}}, nil)
+	mockTweetRepo.On("DeleteTweet", uint(3)).Return(nil)
+	mockMediaService.On("DeleteTweetMedia", uint(3)).Return(nil)
+
+	err := testTweetService.DeleteTweet(3, 5)
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+	mockMediaService.AssertExpectations(t)
+}
+
+// 管理者は他のユーザーのtweetも削除できる
+func TestDeleteAnyTweet(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, mockMediaService, testTweetService := prepareTestTweetServiceWithMedia()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 5, Type: models.Image, Media: []*models.Media{{ID: 1}}}, nil)
+	mockTweetRepo.On("DeleteTweet", uint(3)).Return(nil)
+	mockMediaService.On("DeleteTweetMedia", uint(3)).Return(nil)
+
+	err := testTweetService.DeleteAnyTweet(3)
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+	mockMediaService.AssertExpectations(t)
+}
+
+// 他のユーザーのretweetは投稿したユーザーのretweetとして取り消す
+func TestDeleteAnyTweetRetweet(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// モックレポジトリを呼び出し
+	retweetedTweetId := uint(2)
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 5, Type: models.Retweet, RetweetedTweetID: &retweetedTweetId}, nil)
+	mockTweetRepo.On("DeleteRetweet", uint(5), uint(2)).Return(nil)
+
+	err := testTweetService.DeleteAnyTweet(3)
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+	mockTweetRepo.AssertNotCalled(t, "DeleteTweet", mock.Anything)
+}
+
+// 画像/動画のtweetは添付なしでは作成できない
+func TestCreateMediaTweetWithoutMedia(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, mockMediaService, testTweetService := prepareTestTweetServiceWithMedia()
+
+	for _, tweetType := range []string{"image", "video"} {
+		tweet, err := testTweetService.CreateTweet(5, tweetType, "no photo", nil)
+
+		assert.Nil(t, tweet)
+		assert.Equal(t, "media does not match tweet type", err.Error())
+	}
+	mockMediaService.AssertNotCalled(t, "GetAttachableMedia", mock.Anything, mock.Anything, mock.Anything)
+	mockTweetRepo.AssertNotCalled(t, "CreateTweet", mock.Anything)
+}
+
+// 返信には画像/動画を添付できないので画像/動画の返信は作成できない
+func TestReplyTweetWithMediaType(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(1)).Return(&models.Tweet{ID: 1, UserID: 2, Type: models.Text}, nil)
+
+	tweet, err := testTweetService.ReplyTweet(5, 1, "image", "reply")
+
+	assert.Nil(t, tweet)
+	assert.Equal(t, "media does not match tweet type", err.Error())
+	mockTweetRepo.AssertNotCalled(t, "CreateTweet", mock.Anything)
+}
+
+// 添付のないtweetは画像/動画のtweetに変更できない
+func TestUpdateTweetToMediaTypeWithoutMedia(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 5, Type: models.Text}, nil)
+
+	_, err := testTweetService.UpdateTweet(3, 5, &dtos.UpdateTweetInput{Type: "video"})
+
+	assert.Equal(t, "media does not match tweet type", err.Error())
+	mockTweetRepo.AssertNotCalled(t, "UpdateTweet")
+}
+
+func TestUpdateTweetWithMediaType(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, _, testTweetService := prepareTestTweetServiceWithMedia()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 5, Type: models.Image, Media: []*models.Media{{ID: 1}}}, nil)
+
+	_, err := testTweetService.UpdateTweet(3, 5, &dtos.UpdateTweetInput{Type: "text"})
+
+	assert.Equal(t, "type of tweet with media cannot be changed", err.Error())
+	mockTweetRepo.AssertNotCalled(t, "UpdateTweet")
+}
+
+func TestCreateTweetWithHashtags(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockFeedService, testTweetService := prepareTestTweetServiceWithFeed()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("CreateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return len(tweet.Hashtags) == 2 && tweet.Hashtags[0].Name == "go" && tweet.Hashtags[1].Name == "東京"
+	})).Return(&models.Tweet{ID: 3, UserID: 5}, nil)
+	mockFeedService.On("DistributeTweet", mock.Anything).Return(nil)
+
+	_, err := testTweetService.CreateTweet(5, "text", "#Go 勉強会 in #東京", nil)
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestUpdateTweetResyncsHashtags(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{
+		ID: 3, UserID: 5, Type: models.Text, Content: "#go", Hashtags: []*models.Hashtag{{ID: 1, Name: "go"}},
+	}, nil)
+	mockTweetRepo.On("UpdateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return len(tweet.Hashtags) == 1 && tweet.Hashtags[0].Name == "rust"
+	})).Return(&models.Tweet{ID: 3}, nil)
+
+	_, err := testTweetService.UpdateTweet(3, 5, &dtos.UpdateTweetInput{Content: "#rust"})
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestGetHashtagTweets(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// モックレポジトリを呼び出し(tagは正規化して検索する)
+	tweets := &pagination.List[*models.Tweet]{Items: []*models.Tweet{{ID: 1}}}
+	mockTweetRepo.On("GetHashtagTweets", "go", mock.Anything).Return(tweets, nil)
+	mockTweetRepo.On("CountRetweets", []uint{1}).Return(map[uint]int64{}, nil)
+	mockTweetRepo.On("CountQuotes", []uint{1}).Return(map[uint]int64{}, nil)
+
+	gotTweets, err := testTweetService.GetHashtagTweets("#ＧＯ", &pagination.Page{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(gotTweets.Items))
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestGetHashtagTweetsInvalidTag(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	_, err := testTweetService.GetHashtagTweets("123", &pagination.Page{})
+
+	assert.Equal(t, "invalid hashtag", err.Error())
+	mockTweetRepo.AssertNotCalled(t, "GetHashtagTweets")
+}
+
+func TestCreateTweetWithMentions(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockUserRepo, mockFeedService, testTweetService := prepareTestTweetServiceWithUsers()
+
+	// モックレポジトリを呼び出し(usernameは小文字にまとめて検索し、存在しないユーザーへのmentionは無視する)
+	mockUserRepo.On("FindUsersByUsernames", []string{"gopher", "nobody"}).Return([]*models.User{{ID: 7, Username: "Gopher"}}, nil)
+	mockTweetRepo.On("CreateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return len(tweet.Mentions) == 2 &&
+			*tweet.Mentions[0] == models.TweetMention{UserID: 7, Username: "gopher", Start: 3, End: 10} &&
+			*tweet.Mentions[1] == models.TweetMention{UserID: 7, Username: "GOPHER", Start: 19, End: 26}
+	})).Return(&models.Tweet{ID: 3, UserID: 5}, nil)
+	mockFeedService.On("DistributeTweet", mock.Anything).Return(nil)
+
+	_, err := testTweetService.CreateTweet(5, "text", "こんに@gopher @nobody @GOPHER", nil)
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestUpdateTweetResyncsMentions(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, mockUserRepo, _, testTweetService := prepareTestTweetServiceWithUsers()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{
+		ID: 3, UserID: 5, Type: models.Text, Content: "@gopher", Mentions: []*models.TweetMention{{UserID: 7, Username: "gopher", Start: 0, End: 7}},
+	}, nil)
+	mockUserRepo.On("FindUsersByUsernames", []string{"rustacean"}).Return([]*models.User{{ID: 8, Username: "rustacean"}}, nil)
+	mockTweetRepo.On("UpdateTweet", mock.MatchedBy(func(tweet *models.Tweet) bool {
+		return len(tweet.Mentions) == 1 && tweet.Mentions[0].UserID == 8
+	})).Return(&models.Tweet{ID: 3}, nil)
+
+	_, err := testTweetService.UpdateTweet(3, 5, &dtos.UpdateTweetInput{Content: "@rustacean"})
+
+	assert.NoError(t, err)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestGetMentionTweets(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// モックレポジトリを呼び出し
+	tweets := &pagination.List[*models.Tweet]{Items: []*models.Tweet{{ID: 1}}}
+	mockTweetRepo.On("GetMentionTweets", uint(5), mock.Anything).Return(tweets, nil)
+	mockTweetRepo.On("CountRetweets", []uint{1}).Return(map[uint]int64{}, nil)
+	mockTweetRepo.On("CountQuotes", []uint{1}).Return(map[uint]int64{1: 1}, nil)
+
+	gotTweets, err := testTweetService.GetMentionTweets(5, &pagination.Page{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(gotTweets.Items))
+	assert.Equal(t, int64(1), *gotTweets.Items[0].QuoteCount)
+	mockTweetRepo.AssertExpectations(t)
+}
+
+func TestUpdateRetweet(t *testing.T) {
+	// モックレポジトリを準備
+	mockTweetRepo, _, testTweetService := prepareTestTweetService()
+
+	// モックレポジトリを呼び出し
+	mockTweetRepo.On("GetTweet", uint(3)).Return(&models.Tweet{ID: 3, UserID: 5, Type: models.Retweet}, nil)
+
+	_, err := testTweetService.UpdateTweet(3, 5, &dtos.UpdateTweetInput{Content: "edited"})
+
+	assert.Equal(t, "retweet cannot be updated", err.Error())
+	mockTweetRepo.AssertNotCalled(t, "UpdateTweet")
+}
+
+func prepareTestTweetService() (*mocks.MockTweetRepository, *mocks.MockLikeRepository, services.ITweetService) {
+	mockTweetRepo := &mocks.MockTweetRepository{}
+	mockLikeRepo := &mocks.MockLikeRepository{}
+	testTweetService := services.NewTweetService(mockTweetRepo, mockLikeRepo, nil, nil, nil, nil)
+	return mockTweetRepo, mockLikeRepo, testTweetService
+}
+
+func prepareTestTweetServiceWithFeed() (*mocks.MockTweetRepository, *mocks.MockFeedService, services.ITweetService) {
+	mockTweetRepo := &mocks.MockTweetRepository{}
+	mockFeedService := &mocks.MockFeedService{}
+	testTweetService := services.NewTweetService(mockTweetRepo, &mocks.MockLikeRepository{}, nil, mockFeedService, nil, prepareMockTrendAggregator())
+	return mockTweetRepo, mockFeedService, testTweetService
+}
+
+func prepareTestTweetServiceWithMedia() (*mocks.MockTweetRepository, *mocks.MockFeedService, *mocks.MockMediaService, services.ITweetService) {
+	mockTweetRepo := &mocks.MockTweetRepository{}
+	mockFeedService := &mocks.MockFeedService{}
+	mockMediaService := &mocks.MockMediaService{}
+	testTweetService := services.NewTweetService(mockTweetRepo, &mocks.MockLikeRepository{}, nil, mockFeedService, mockMediaService, prepareMockTrendAggregator())
+	return mockTweetRepo, mockFeedService, mockMediaService, testTweetService
+}
+
+func prepareTestTweetServiceWithUsers() (*mocks.MockTweetRepository, *mocks.MockUserRepository, *mocks.MockFeedService, services.ITweetService) {
+	mockTweetRepo := &mocks.MockTweetRepository{}
+	mockUserRepo := &mocks.MockUserRepository{}
+	mockFeedService := &mocks.MockFeedService{}
+	testTweetService := services.NewTweetService(mockTweetRepo, &mocks.MockLikeRepository{}, mockUserRepo, mockFeedService, nil, prepareMockTrendAggregator())
+	return mockTweetRepo, mockUserRepo, mockFeedService, testTweetService
+}
+
+// trendの集計は全てのtweetの作成で呼ばれるので常に受け付ける
+func prepareMockTrendAggregator() *mocks.MockTrendAggregator {
+	mockTrendAggregator := &mocks.MockTrendAggregator{}
+	mockTrendAggregator.On("Record", mock.Anything).Return()
+	return mockTrendAggregator
+}
@@ -0,0 +1,195 @@
+package services_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/auth"
+	"github.com/daiki-kim/tweet-app/backend/pkg/totp"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEnrollTOTPSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, mockTwoFactorRepo, testTwoFactorService := prepareTestTwoFactorService()
+
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Email: "gopher@example.com"}, nil)
+	mockTwoFactorRepo.On("FindUserTOTP", uint(1)).Return(nil, errors.New("totp not found"))
+	var savedTOTP *models.UserTOTP
+	mockTwoFactorRepo.On("SaveUserTOTP", mock.Anything).Run(func(args mock.Arguments) {
+		savedTOTP = args.Get(0).(*models.UserTOTP)
+	}).Return(nil)
+
+	enrollment, err := testTwoFactorService.EnrollTOTP(1)
+
+	// 確認前なので有効にはならない
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), savedTOTP.UserID)
+	assert.Equal(t, enrollment.Secret, savedTOTP.Secret)
+	assert.Nil(t, savedTOTP.ConfirmedAt)
+	assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/tweet-app:gopher@example.com?"))
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+}
+
+func TestEnrollTOTPAlreadyEnabled(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, mockTwoFactorRepo, testTwoFactorService := prepareTestTwoFactorService()
+
+	confirmedAt := time.Now()
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Email: "gopher@example.com"}, nil)
+	mockTwoFactorRepo.On("FindUserTOTP", uint(1)).Return(&models.UserTOTP{UserID: 1, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &confirmedAt}, nil)
+
+	_, err := testTwoFactorService.EnrollTOTP(1)
+
+	// 有効な2段階認証のsecretは上書きしない
+	assert.Equal(t, "two-factor authentication is already enabled", err.Error())
+	mockTwoFactorRepo.AssertNotCalled(t, "SaveUserTOTP", mock.Anything)
+}
+
+func TestConfirmTOTPSuccess(t *testing.T) {
+	// モックレポジトリを準備
+	_, mockTwoFactorRepo, testTwoFactorService := prepareTestTwoFactorService()
+
+	secret, _ := totp.GenerateSecret()
+	code, _ := totp.Code(secret, time.Now())
+	mockTwoFactorRepo.On("FindUserTOTP", uint(1)).Return(&models.UserTOTP{UserID: 1, Secret: secret}, nil)
+	var savedHashes []string
+	mockTwoFactorRepo.On("EnableUserTOTP", uint(1), mock.AnythingOfType("int64"), mock.Anything).Run(func(args mock.Arguments) {
+		savedHashes = args.Get(2).([]string)
+	}).Return(nil)
+
+	recoveryCodes, err := testTwoFactorService.ConfirmTOTP(1, code)
+
+	// リカバリーコードを返し、DBにはhashのみを保存する
+	assert.NoError(t, err)
+	assert.Equal(t, totp.RecoveryCodeCount, len(recoveryCodes))
+	assert.Equal(t, totp.RecoveryCodeCount, len(savedHashes))
+	for i, recoveryCode := range recoveryCodes {
+		assert.Equal(t, auth.HashOneTimeToken(totp.NormalizeRecoveryCode(recoveryCode)), savedHashes[i])
+	}
+	mockTwoFactorRepo.AssertExpectations(t)
+}
+
+func TestConfirmTOTPErrors(t *testing.T) {
+	confirmedAt := time.Now()
+	testCases := []struct {
+		name     string
+		userTOTP *models.UserTOTP
+		findErr  error
+		code     string
+		err      string
+	}{
+		{name: "not enrolled", findErr: errors.New("totp not found"), code: "123456", err: "two-factor authentication is not enrolled"},
+		{name: "already enabled", userTOTP: &models.UserTOTP{UserID: 1, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &confirmedAt}, code: "123456", err: "two-factor authentication is already enabled"},
+		{name: "invalid code", userTOTP: &models.UserTOTP{UserID: 1, Secret: "JBSWY3DPEHPK3PXP"}, code: "abcdef", err: "invalid code"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			// モックレポジトリを準備
+			_, mockTwoFactorRepo, testTwoFactorService := prepareTestTwoFactorService()
+
+			mockTwoFactorRepo.On("FindUserTOTP", uint(1)).Return(testCase.userTOTP, testCase.findErr)
+
+			_, err := testTwoFactorService.ConfirmTOTP(1, testCase.code)
+
+			assert.Equal(t, testCase.err, err.Error())
+			mockTwoFactorRepo.AssertNotCalled(t, "EnableUserTOTP", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestVerifyCodeTOTP(t *testing.T) {
+	// モックレポジトリを準備
+	_, mockTwoFactorRepo, testTwoFactorService := prepareTestTwoFactorService()
+
+	secret, _ := totp.GenerateSecret()
+	now := time.Now()
+	code, _ := totp.Code(secret, now)
+	confirmedAt := now
+	mockTwoFactorRepo.On("FindUserTOTP", uint(1)).Return(&models.UserTOTP{UserID: 1, Secret: secret, ConfirmedAt: &confirmedAt}, nil)
+	mockTwoFactorRepo.On("UpdateTOTPLastUsedStep", uint(1), mock.AnythingOfType("int64")).Return(nil)
+
+	// 入力時の前後の空白は無視する
+	err := testTwoFactorService.VerifyCode(1, " "+code+" ")
+
+	assert.NoError(t, err)
+	mockTwoFactorRepo.AssertExpectations(t)
+}
+
+func TestVerifyCodeTOTPReused(t *testing.T) {
+	// モックレポジトリを準備
+	_, mockTwoFactorRepo, testTwoFactorService := prepareTestTwoFactorService()
+
+	secret, _ := totp.GenerateSecret()
+	now := time.Now()
+	code, _ := totp.Code(secret, now)
+	confirmedAt := now
+	mockTwoFactorRepo.On("FindUserTOTP", uint(1)).Return(&models.UserTOTP{UserID: 1, Secret: secret, ConfirmedAt: &confirmedAt, LastUsedStep: totp.Step(now) + 1}, nil)
+
+	// 使用済みのstep以前のコードは使用できない
+	err := testTwoFactorService.VerifyCode(1, code)
+
+	assert.Equal(t, "invalid code", err.Error())
+	mockTwoFactorRepo.AssertNotCalled(t, "UpdateTOTPLastUsedStep", mock.Anything, mock.Anything)
+}
+
+func TestVerifyCodeRecoveryCode(t *testing.T) {
+	// モックレポジトリを準備
+	_, mockTwoFactorRepo, testTwoFactorService := prepareTestTwoFactorService()
+
+	confirmedAt := time.Now()
+	mockTwoFactorRepo.On("FindUserTOTP", uint(1)).Return(&models.UserTOTP{UserID: 1, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &confirmedAt}, nil)
+	mockTwoFactorRepo.On("UseRecoveryCode", uint(1), auth.HashOneTimeToken("abcde23456")).Return(nil)
+	mockTwoFactorRepo.On("UseRecoveryCode", uint(1), auth.HashOneTimeToken("zzzzz22222")).Return(errors.New("recovery code not found"))
+
+	// 大文字、ハイフンは無視する
+	assert.NoError(t, testTwoFactorService.VerifyCode(1, "ABCDE-23456"))
+
+	// 存在しないか使用済みのリカバリーコード
+	assert.Equal(t, "invalid code", testTwoFactorService.VerifyCode(1, "zzzzz-22222").Error())
+}
+
+func TestVerifyCodeNotEnabled(t *testing.T) {
+	// モックレポジトリを準備
+	_, mockTwoFactorRepo, testTwoFactorService := prepareTestTwoFactorService()
+
+	// 確認前の場合も有効ではない
+	mockTwoFactorRepo.On("FindUserTOTP", uint(1)).Return(&models.UserTOTP{UserID: 1, Secret: "JBSWY3DPEHPK3PXP"}, nil)
+	mockTwoFactorRepo.On("FindUserTOTP", uint(2)).Return(nil, errors.New("totp not found"))
+
+	assert.Equal(t, "two-factor authentication is not enabled", testTwoFactorService.VerifyCode(1, "123456").Error())
+	assert.Equal(t, "two-factor authentication is not enabled", testTwoFactorService.VerifyCode(2, "123456").Error())
+}
+
+func TestDisableTOTP(t *testing.T) {
+	// モックレポジトリを準備
+	_, mockTwoFactorRepo, testTwoFactorService := prepareTestTwoFactorService()
+
+	confirmedAt := time.Now()
+	mockTwoFactorRepo.On("FindUserTOTP", uint(1)).Return(&models.UserTOTP{UserID: 1, Secret: "JBSWY3DPEHPK3PXP", ConfirmedAt: &confirmedAt}, nil)
+	mockTwoFactorRepo.On("UseRecoveryCode", uint(1), auth.HashOneTimeToken("abcde23456")).Return(nil)
+	mockTwoFactorRepo.On("UseRecoveryCode", uint(1), auth.HashOneTimeToken("zzzzz22222")).Return(errors.New("recovery code not found"))
+	mockTwoFactorRepo.On("DeleteUserTOTP", uint(1)).Return(nil)
+
+	// コードが正しくない場合は無効にしない
+	assert.Equal(t, "invalid code", testTwoFactorService.DisableTOTP(1, "zzzzz-22222").Error())
+	mockTwoFactorRepo.AssertNotCalled(t, "DeleteUserTOTP", uint(1))
+
+	assert.NoError(t, testTwoFactorService.DisableTOTP(1, "abcde-23456"))
+	mockTwoFactorRepo.AssertCalled(t, "DeleteUserTOTP", uint(1))
+}
+
+func prepareTestTwoFactorService() (*mocks.MockUserRepository, *mocks.MockTwoFactorRepository, services.ITwoFactorService) {
+	mockUserRepo := &mocks.MockUserRepository{}
+	mockTwoFactorRepo := &mocks.MockTwoFactorRepository{}
+	testTwoFactorService := services.NewTwoFactorService(mockUserRepo, mockTwoFactorRepo)
+
+	return mockUserRepo, mockTwoFactorRepo, testTwoFactorService
+}
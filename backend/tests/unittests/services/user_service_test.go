@@ -0,0 +1,290 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/daiki-kim/tweet-app/backend/apps/dtos"
+	"github.com/daiki-kim/tweet-app/backend/apps/models"
+	"github.com/daiki-kim/tweet-app/backend/apps/repositories"
+	"github.com/daiki-kim/tweet-app/backend/apps/services"
+	"github.com/daiki-kim/tweet-app/backend/pkg/mention"
+	"github.com/daiki-kim/tweet-app/backend/pkg/pagination"
+	"github.com/daiki-kim/tweet-app/backend/tests/unittests/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetUserProfile(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, testUserService := prepareTestUserService()
+
+	// パスワードやemailを含むユーザーを準備
+	user := &models.User{
+		ID: 1, Name: "gopher", Username: "gopher", Email: "gopher@example.com", Password: "hashed",
+		Dob: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), CreatedAt: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// モックレポジトリを呼び出し
+	mockUserRepo.On("FindUserById", uint(1)).Return(user, nil)
+	mockUserRepo.On("CountUserStats", []uint{1}).Return(map[uint]*repositories.UserStats{
+		1: {FollowerCount: 3, FollowingCount: 2, TweetCount: 10},
+	}, nil)
+
+	profile, err := testUserService.GetUserProfile(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), profile.FollowerCount)
+	assert.Equal(t, int64(2), profile.FollowingCount)
+	assert.Equal(t, int64(10), profile.TweetCount)
+
+	// 公開プロフィールにはパスワード、email、生年月日を含めない
+	profileJson, err := json.Marshal(profile)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"id": 1,
+		"name": "gopher",
+		"username": "gopher",
+		"bio": "",
+		"location": "",
+		"website": "",
+		"avatar_url": "",
+		"header_url": "",
+		"created_at": "2024-09-01T00:00:00Z",
+		"follower_count": 3,
+		"following_count": 2,
+		"tweet_count": 10
+	}`, string(profileJson))
+}
+
+func TestGetUserProfileByUsername(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, testUserService := prepareTestUserService()
+
+	// モックレポジトリを呼び出し(先頭の@は取り除く)
+	mockUserRepo.On("FindUserByUsername", "Gopher").Return(&models.User{ID: 1, Username: "gopher"}, nil)
+	mockUserRepo.On("CountUserStats", []uint{1}).Return(map[uint]*repositories.UserStats{1: {}}, nil)
+
+	profile, err := testUserService.GetUserProfileByUsername("@Gopher")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "gopher", profile.Username)
+
+	// usernameとして使えない文字列は検索せずにuser not foundとする
+	_, err = testUserService.GetUserProfileByUsername("not-a-user")
+	assert.Equal(t, "user not found", err.Error())
+	mockUserRepo.AssertNumberOfCalls(t, "FindUserByUsername", 1)
+}
+
+func TestGetUserProfileNotFound(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, testUserService := prepareTestUserService()
+
+	// モックレポジトリを呼び出し
+	mockUserRepo.On("FindUserById", uint(2)).Return(nil, errors.New("user not found"))
+
+	_, err := testUserService.GetUserProfile(2)
+
+	assert.Equal(t, "user not found", err.Error())
+	mockUserRepo.AssertNotCalled(t, "CountUserStats")
+}
+
+func TestSearchUsers(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, testUserService := prepareTestUserService()
+
+	// モックレポジトリを呼び出し
+	users := &pagination.List[*models.User]{Items: []*models.User{{ID: 1, Username: "gopher"}, {ID: 2, Username: "gopher2"}}}
+	mockUserRepo.On("SearchUsers", "go", mock.Anything).Return(users, nil)
+	mockUserRepo.On("CountUserStats", []uint{1, 2}).Return(map[uint]*repositories.UserStats{
+		1: {TweetCount: 1},
+		2: {TweetCount: 2},
+	}, nil)
+
+	profiles, err := testUserService.SearchUsers(" @go ", &pagination.Page{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(profiles.Items))
+	assert.Equal(t, int64(2), profiles.Items[1].TweetCount)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestSearchUsersEmptyQuery(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, testUserService := prepareTestUserService()
+
+	_, err := testUserService.SearchUsers(" @ ", &pagination.Page{})
+
+	assert.Equal(t, "search query is empty", err.Error())
+	mockUserRepo.AssertNotCalled(t, "SearchUsers")
+}
+
+func TestGetMe(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, testUserService := prepareTestUserService()
+
+	// モックレポジトリを呼び出し
+	avatarId := uint(10)
+	user := &models.User{
+		ID: 1, Name: "gopher", Username: "gopher", Email: "gopher@example.com", Password: "hashed",
+		Dob: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), AvatarMediaID: &avatarId,
+		Avatar: &models.Media{ID: avatarId, URL: "/media/avatar.png"},
+	}
+	mockUserRepo.On("FindUserById", uint(1)).Return(user, nil)
+
+	me, err := testUserService.GetMe(1)
+
+	// 本人にはemailと生年月日を返すが、パスワードは返さない
+	assert.NoError(t, err)
+	assert.Equal(t, "gopher@example.com", me.Email)
+	assert.Equal(t, user.Dob, me.Dob)
+	assert.Equal(t, "/media/avatar.png", me.AvatarURL)
+	meJson, err := json.Marshal(me)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(meJson), "hashed")
+}
+
+func TestUpdateMe(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, mockMediaService, testUserService := prepareTestUserService()
+
+	// 既存のプロフィール
+	headerId := uint(20)
+	user := &models.User{ID: 1, Name: "gopher", Username: "gopher", Bio: "old bio", Location: "Tokyo", HeaderMediaID: &headerId}
+	mockUserRepo.On("FindUserById", uint(1)).Return(user, nil)
+	mockMediaService.On("GetProfileImage", uint(1), uint(10)).Return(&models.Media{ID: 10, UserID: 1, Type: models.Image}, nil)
+	mockUserRepo.On("UpdateUserProfile", user).Return(user, nil)
+
+	// 名前、bio、website、プロフィール画像を変更し、ヘッダー画像を削除する(場所は変更しない)
+	name := "  Gopher Kun  "
+	bio := "hello"
+	website := "https://example.com/gopher"
+	avatarId := uint(10)
+	removeHeader := uint(0)
+	me, err := testUserService.UpdateMe(1, &dtos.UpdateProfileInput{
+		Name: &name, Bio: &bio, Website: &website, AvatarMediaID: &avatarId, HeaderMediaID: &removeHeader,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Gopher Kun", me.Name)
+	assert.Equal(t, "hello", me.Bio)
+	assert.Equal(t, "Tokyo", me.Location)
+	assert.Equal(t, "https://example.com/gopher", me.Website)
+	assert.Equal(t, uint(10), *user.AvatarMediaID)
+	assert.Nil(t, user.HeaderMediaID)
+	mockUserRepo.AssertExpectations(t)
+	mockMediaService.AssertExpectations(t)
+}
+
+func TestUpdateMeUsername(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, testUserService := prepareTestUserService()
+
+	user := &models.User{ID: 1, Name: "gopher", Username: "gopher"}
+	mockUserRepo.On("FindUserById", uint(1)).Return(user, nil)
+	mockUserRepo.On("FindUserByUsername", "gopher_kun").Return(nil, errors.New("user not found"))
+	mockUserRepo.On("UpdateUserProfile", user).Return(user, nil)
+
+	username := "gopher_kun"
+	me, err := testUserService.UpdateMe(1, &dtos.UpdateProfileInput{Username: &username})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "gopher_kun", me.Username)
+	mockUserRepo.AssertExpectations(t)
+}
+
+// 自分のusernameは大文字小文字だけを変更できる
+func TestUpdateMeUsernameCase(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, testUserService := prepareTestUserService()
+
+	user := &models.User{ID: 1, Name: "gopher", Username: "gopher"}
+	mockUserRepo.On("FindUserById", uint(1)).Return(user, nil)
+	mockUserRepo.On("FindUserByUsername", "Gopher").Return(&models.User{ID: 1, Username: "gopher"}, nil)
+	mockUserRepo.On("UpdateUserProfile", user).Return(user, nil)
+
+	username := "Gopher"
+	me, err := testUserService.UpdateMe(1, &dtos.UpdateProfileInput{Username: &username})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Gopher", me.Username)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestUpdateMeUsernameTaken(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, _, testUserService := prepareTestUserService()
+
+	// 大文字小文字を区別せずに他のユーザーが使用している
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Name: "gopher", Username: "gopher"}, nil)
+	mockUserRepo.On("FindUserByUsername", "Alice").Return(&models.User{ID: 2, Username: "alice"}, nil)
+
+	username := "Alice"
+	_, err := testUserService.UpdateMe(1, &dtos.UpdateProfileInput{Username: &username})
+
+	assert.Equal(t, "username is already taken", err.Error())
+	mockUserRepo.AssertNotCalled(t, "UpdateUserProfile", mock.Anything)
+}
+
+func TestUpdateMeInvalidInput(t *testing.T) {
+	tooLongName := strings.Repeat("あ", services.MaxNameLength+1)
+	tooLongBio := strings.Repeat("a", services.MaxBioLength+1)
+	tooLongLocation := strings.Repeat("a", services.MaxLocationLength+1)
+	tooLongWebsite := "https://example.com/" + strings.Repeat("a", services.MaxWebsiteLength)
+	blank := "   "
+	ftpWebsite := "ftp://example.com"
+	relativeWebsite := "example.com"
+	invalidUsername := "gopher kun"
+	tooLongUsername := strings.Repeat("a", mention.MaxUsernameLength+1)
+
+	testCases := []struct {
+		input *dtos.UpdateProfileInput
+		err   string
+	}{
+		{&dtos.UpdateProfileInput{Name: &blank}, "invalid name"},
+		{&dtos.UpdateProfileInput{Name: &tooLongName}, "invalid name"},
+		{&dtos.UpdateProfileInput{Username: &invalidUsername}, "invalid username"},
+		{&dtos.UpdateProfileInput{Username: &tooLongUsername}, "invalid username"},
+		{&dtos.UpdateProfileInput{Bio: &tooLongBio}, "bio is too long"},
+		{&dtos.UpdateProfileInput{Location: &tooLongLocation}, "location is too long"},
+		{&dtos.UpdateProfileInput{Website: &tooLongWebsite}, "website is too long"},
+		{&dtos.UpdateProfileInput{Website: &ftpWebsite}, "invalid website"},
+		{&dtos.UpdateProfileInput{Website: &relativeWebsite}, "invalid website"},
+	}
+
+	for _, testCase := range testCases {
+		// モックレポジトリを準備
+		mockUserRepo, _, testUserService := prepareTestUserService()
+		mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Name: "gopher"}, nil)
+
+		_, err := testUserService.UpdateMe(1, testCase.input)
+
+		assert.Equal(t, testCase.err, err.Error())
+		mockUserRepo.AssertNotCalled(t, "UpdateUserProfile", mock.Anything)
+	}
+}
+
+func TestUpdateMeInvalidProfileImage(t *testing.T) {
+	// モックレポジトリを準備
+	mockUserRepo, mockMediaService, testUserService := prepareTestUserService()
+
+	// 動画や他のユーザーの画像はプロフィール画像にできない
+	mockUserRepo.On("FindUserById", uint(1)).Return(&models.User{ID: 1, Name: "gopher"}, nil)
+	mockMediaService.On("GetProfileImage", uint(1), uint(10)).Return(nil, errors.New("profile image must be an image"))
+
+	avatarId := uint(10)
+	_, err := testUserService.UpdateMe(1, &dtos.UpdateProfileInput{AvatarMediaID: &avatarId})
+
+	assert.Equal(t, "profile image must be an image", err.Error())
+	mockUserRepo.AssertNotCalled(t, "UpdateUserProfile", mock.Anything)
+}
+
+func prepareTestUserService() (*mocks.MockUserRepository, *mocks.MockMediaService, services.IUserService) {
+	mockUserRepo := &mocks.MockUserRepository{}
+	mockMediaService := &mocks.MockMediaService{}
+	testUserService := services.NewUserService(mockUserRepo, mockMediaService)
+	return mockUserRepo, mockMediaService, testUserService
+}